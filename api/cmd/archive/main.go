@@ -0,0 +1,98 @@
+// Comando archive roda a retenção do histórico sob demanda e restaura arquivos.
+//
+//	go run ./api/cmd/archive run                 # arquiva segundo RETENTION_MAX_AGE
+//	go run ./api/cmd/archive run -max-age 2160h  # ou com outra idade
+//	go run ./api/cmd/archive restore tmp/archive/conversions-2025-01-31.ndjson.gz
+//
+// As conversões restauradas voltam para conversion_history e não são apagadas de novo.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go-frete/api/internal/config"
+	"go-frete/api/internal/domain"
+	"go-frete/api/internal/infra"
+	"go-frete/api/pkg/logger"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	command := os.Args[1]
+	cfg := config.Load()
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	maxAge := flags.Duration("max-age", cfg.RetentionMaxAge, "idade a partir da qual as conversões são arquivadas")
+	deleteAfter := flags.Duration("delete-after", cfg.RetentionDeleteAfter, "tempo até apagar do banco as conversões arquivadas (0 = nunca)")
+	dir := flags.String("dir", cfg.RetentionArchiveDir, "diretório dos arquivos")
+	flags.Parse(os.Args[2:])
+
+	log, err := logger.New(logger.Config{
+		Level:      cfg.LogLevel,
+		Format:     cfg.LogFormat,
+		RedactKeys: cfg.LogRedactKeys,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Falha ao configurar o logger:", err)
+		os.Exit(1)
+	}
+
+	mongoAdapter, err := infra.NewMongoDBAdapter(cfg.MongoURI, cfg.MongoDB)
+	if err != nil {
+		log.Fatal("Falha ao conectar no MongoDB", "erro", err.Error())
+	}
+	archive, err := infra.NewFileArchive(*dir)
+	if err != nil {
+		log.Fatal("Falha ao preparar o diretório de arquivos", "caminho", *dir, "erro", err.Error())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	retention := domain.NewRetentionUseCase(mongoAdapter, archive, domain.RetentionPolicy{
+		MaxAge:      *maxAge,
+		DeleteAfter: *deleteAfter,
+	}, log)
+
+	switch command {
+	case "run":
+		if *maxAge <= 0 {
+			fmt.Fprintln(os.Stderr, "Informe -max-age ou RETENTION_MAX_AGE")
+			os.Exit(2)
+		}
+		result, err := retention.Archive(ctx)
+		for _, path := range result.Arquivos {
+			fmt.Println(path)
+		}
+		fmt.Printf("%d dias arquivados, %d conversões\n", result.Dias, result.Registros)
+		if err != nil {
+			os.Exit(1)
+		}
+	case "restore":
+		if flags.NArg() == 0 {
+			usage()
+		}
+		for _, path := range flags.Args() {
+			n, err := retention.Restore(ctx, path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+				os.Exit(1)
+			}
+			fmt.Printf("%s: %d conversões restauradas\n", path, n)
+		}
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "uso: archive run [-max-age 8760h] [-delete-after 168h] [-dir tmp/archive] | archive restore <arquivo>...")
+	os.Exit(2)
+}
@@ -0,0 +1,101 @@
+// Comando backfill importa o histórico diário da AwesomeAPI para a coleção de
+// cotações usada por variação e estatísticas.
+//
+//	go run ./api/cmd/backfill -currencies USD,EUR -days 365
+//
+// Pode ser interrompido (Ctrl+C) e executado de novo: dias já importados são pulados.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"go-frete/api/internal/config"
+	"go-frete/api/internal/domain"
+	"go-frete/api/internal/infra"
+	"go-frete/api/pkg/logger"
+)
+
+func main() {
+	cfg := config.Load()
+
+	currencies := flag.String("currencies", strings.Join(cfg.RateCollectorCurrencies, ","), "moedas separadas por vírgula")
+	days := flag.Int("days", 365, "quantidade de dias a importar, terminando em -until")
+	until := flag.String("until", "", "último dia importado (2006-01-02); padrão: ontem")
+	chunk := flag.Int("chunk", domain.DefaultBackfillChunkDays, "dias pedidos à AwesomeAPI por chamada")
+	flag.Parse()
+
+	req := domain.BackfillRequest{
+		Currencies: splitList(*currencies),
+		Days:       *days,
+		ChunkDays:  *chunk,
+	}
+	if *until != "" {
+		t, err := time.Parse(time.DateOnly, *until)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Data inválida em -until:", *until)
+			os.Exit(2)
+		}
+		req.Until = t
+	}
+
+	log, err := logger.New(logger.Config{
+		Level:      cfg.LogLevel,
+		Format:     cfg.LogFormat,
+		RedactKeys: cfg.LogRedactKeys,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Falha ao configurar o logger:", err)
+		os.Exit(1)
+	}
+
+	mongoAdapter, err := infra.NewMongoDBAdapter(cfg.MongoURI, cfg.MongoDB)
+	if err != nil {
+		log.Fatal("Falha ao conectar no MongoDB", "erro", err.Error())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	backfill := domain.NewBackfillUseCase(infra.NewAwesomeAPIAdapter(), mongoAdapter, log)
+	results, err := backfill.Execute(ctx, req, printProgress)
+
+	fmt.Println()
+	for _, r := range results {
+		status := "ok"
+		if r.Err != nil {
+			status = "falhou: " + r.Err.Error()
+		}
+		fmt.Printf("%s: %d importados, %d já existentes, %d retomados (%s)\n", r.Moeda, r.Imported, r.Skipped, r.Resumed, status)
+	}
+
+	if ctx.Err() != nil {
+		fmt.Println("Interrompido: execute de novo para continuar de onde parou.")
+		os.Exit(130)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+func printProgress(p domain.BackfillProgress) {
+	fmt.Printf("%s %s → %s  %d/%d dias (%d%%)  importados %d  existentes %d\n",
+		p.Moeda, p.From.Format(time.DateOnly), p.To.Format(time.DateOnly),
+		p.DaysDone, p.DaysTotal, p.DaysDone*100/p.DaysTotal, p.Imported, p.Skipped)
+}
+
+func splitList(raw string) []string {
+	var out []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
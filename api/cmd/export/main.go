@@ -0,0 +1,132 @@
+// Comando export grava o histórico de conversões num arquivo, com os mesmos
+// filtros e formatos de GET /v1/conversions/export.
+//
+//	go run ./api/cmd/export -format xlsx -currency USD -from 2026-01-01 -o janeiro.xlsx
+//	go run ./api/cmd/export -decimal-comma -to 2026-01-31 > historico.csv
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"go-frete/api/internal/config"
+	"go-frete/api/internal/domain"
+	"go-frete/api/internal/infra"
+	"go-frete/api/pkg/logger"
+)
+
+func main() {
+	cfg := config.Load()
+
+	format := flag.String("format", "csv", "csv, ndjson ou xlsx")
+	currency := flag.String("currency", "", "moeda de destino (vazio exporta todas)")
+	from := flag.String("from", "", "primeiro dia (2006-01-02) ou data e hora RFC 3339")
+	to := flag.String("to", "", "último dia (inclui o dia inteiro) ou data e hora RFC 3339")
+	asOf := flag.String("as-of", "", "true exporta só as conversões retroativas, false só as do dia")
+	limit := flag.Int("limit", 0, "máximo de conversões (0 exporta todas)")
+	delimiter := flag.String("delimiter", "", "separador de colunas do CSV: um caractere ou tab")
+	decimalComma := flag.Bool("decimal-comma", false, "números do CSV com vírgula decimal (Excel em pt-BR)")
+	output := flag.String("o", "", "arquivo de saída (padrão: saída padrão)")
+	flag.Parse()
+
+	filter := domain.ConversionFilter{Currency: strings.ToUpper(*currency), Limit: *limit}
+	var err error
+	if filter.From, err = parseTime(*from, false); err != nil {
+		fail("Data inválida em -from:", *from)
+	}
+	if filter.To, err = parseTime(*to, true); err != nil {
+		fail("Data inválida em -to:", *to)
+	}
+	if *asOf != "" {
+		retroativa, err := strconv.ParseBool(*asOf)
+		if err != nil {
+			fail("Use true ou false em -as-of:", *asOf)
+		}
+		filter.Retroativa = &retroativa
+	}
+
+	opts := domain.ExportOptions{DecimalComma: *decimalComma}
+	if opts.Format, err = domain.ParseExportFormat(*format); err != nil {
+		fail(err.Error())
+	}
+	switch {
+	case *delimiter == "":
+	case *delimiter == "tab":
+		opts.Delimiter = '\t'
+	case utf8.RuneCountInString(*delimiter) == 1:
+		opts.Delimiter, _ = utf8.DecodeRuneInString(*delimiter)
+	default:
+		fail(domain.ErrInvalidDelimiter.Error())
+	}
+
+	log, err := logger.New(logger.Config{
+		Level:      cfg.LogLevel,
+		Format:     cfg.LogFormat,
+		RedactKeys: cfg.LogRedactKeys,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Falha ao configurar o logger:", err)
+		os.Exit(1)
+	}
+
+	mongoAdapter, err := infra.NewMongoDBAdapter(cfg.MongoURI, cfg.MongoDB)
+	if err != nil {
+		log.Fatal("Falha ao conectar no MongoDB", "erro", err.Error())
+	}
+	export := domain.NewExportUseCase(mongoAdapter, log)
+	// Antes de criar o arquivo de saída, que sobraria vazio
+	if err := export.Validate(filter, opts); err != nil {
+		fail(err.Error())
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		if out, err = os.Create(*output); err != nil {
+			fail("Falha ao criar o arquivo:", err.Error())
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	n, err := export.Execute(ctx, filter, opts, out)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Exportação interrompida depois de %d conversões: %v\n", n, err)
+		if *output != "" {
+			os.Remove(*output)
+		}
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "%d conversões exportadas\n", n)
+}
+
+// parseTime aceita data ou data e hora RFC 3339; endOfDay faz uma data sem hora incluir o dia inteiro
+func parseTime(raw string, endOfDay bool) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, raw)
+	if err == nil && endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, err
+}
+
+func fail(msg ...any) {
+	fmt.Fprintln(os.Stderr, msg...)
+	os.Exit(2)
+}
@@ -0,0 +1,103 @@
+// Comando import grava no histórico as conversões de um arquivo CSV ou NDJSON,
+// com as mesmas regras de POST /v1/conversions/import.
+//
+//	go run ./api/cmd/import -columns "data=Data,currency=Moeda,valor_entrada=Valor,cotacao=Cotação" -decimal-comma -tz America/Sao_Paulo planilha.csv
+//	go run ./api/cmd/import -format ndjson -dry-run < conversoes.ndjson
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"go-frete/api/internal/config"
+	"go-frete/api/internal/domain"
+	"go-frete/api/internal/infra"
+	"go-frete/api/pkg/logger"
+)
+
+func main() {
+	cfg := config.Load()
+
+	format := flag.String("format", "csv", "csv ou ndjson")
+	delimiter := flag.String("delimiter", "", "separador de colunas do CSV: um caractere ou tab")
+	decimalComma := flag.Bool("decimal-comma", false, "números do CSV com vírgula decimal (Excel em pt-BR)")
+	columns := flag.String("columns", "", "coluna de cada campo: campo=coluna separados por vírgula")
+	tz := flag.String("tz", "", "fuso das datas sem fuso no arquivo (padrão: UTC)")
+	dryRun := flag.Bool("dry-run", false, "só valida e conta, sem gravar")
+	flag.Parse()
+
+	opts := domain.ImportOptions{DecimalComma: *decimalComma, DryRun: *dryRun}
+	var err error
+	if opts.Format, err = domain.ParseImportFormat(*format); err != nil {
+		fail(err.Error())
+	}
+	switch {
+	case *delimiter == "":
+	case *delimiter == "tab":
+		opts.Delimiter = '\t'
+	case utf8.RuneCountInString(*delimiter) == 1:
+		opts.Delimiter, _ = utf8.DecodeRuneInString(*delimiter)
+	default:
+		fail(domain.ErrInvalidDelimiter.Error())
+	}
+	if opts.Columns, err = domain.ParseColumnMapping(*columns); err != nil {
+		fail(err.Error())
+	}
+	if *tz != "" {
+		if opts.Location, err = time.LoadLocation(*tz); err != nil {
+			fail("Fuso horário desconhecido em -tz:", *tz)
+		}
+	}
+
+	var in io.ReadCloser = os.Stdin
+	if flag.NArg() > 0 {
+		if in, err = os.Open(flag.Arg(0)); err != nil {
+			fail("Falha ao abrir o arquivo:", err.Error())
+		}
+	}
+	defer in.Close()
+
+	log, err := logger.New(logger.Config{
+		Level:      cfg.LogLevel,
+		Format:     cfg.LogFormat,
+		RedactKeys: cfg.LogRedactKeys,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Falha ao configurar o logger:", err)
+		os.Exit(1)
+	}
+
+	mongoAdapter, err := infra.NewMongoDBAdapter(cfg.MongoURI, cfg.MongoDB)
+	if err != nil {
+		log.Fatal("Falha ao conectar no MongoDB", "erro", err.Error())
+	}
+	imports := domain.NewImportUseCase(mongoAdapter, mongoAdapter, domain.NewCurrencyRegistry(cfg.CurrenciesExtra), log)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := imports.Execute(ctx, in, opts)
+	out := json.NewEncoder(os.Stdout)
+	out.SetIndent("", "  ")
+	out.Encode(report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Importação interrompida depois de %d conversões (reexecutar não as duplica): %v\n", report.Importadas, err)
+		os.Exit(1)
+	}
+	if report.Rejeitadas > 0 {
+		os.Exit(3)
+	}
+}
+
+func fail(msg ...any) {
+	fmt.Fprintln(os.Stderr, msg...)
+	os.Exit(2)
+}
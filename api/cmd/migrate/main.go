@@ -0,0 +1,121 @@
+// Comando migrate aplica, desfaz e lista as migrations do esquema do MongoDB.
+//
+//	go run ./api/cmd/migrate status
+//	go run ./api/cmd/migrate up            # todas as pendentes
+//	go run ./api/cmd/migrate up -to 2      # só até a versão 2
+//	go run ./api/cmd/migrate down          # desfaz a última aplicada
+//	go run ./api/cmd/migrate down -to 1    # desfaz tudo acima da versão 1
+//
+// A API também aplica as pendentes na subida quando MIGRATE_ON_STARTUP=true.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go-frete/api/internal/config"
+	"go-frete/api/internal/infra"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	command := os.Args[1]
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	to := flags.Int("to", 0, "versão alvo (up: 0 = a última; down: 0 = só a última aplicada)")
+	flags.Parse(os.Args[2:])
+
+	cfg := config.Load()
+	mongoAdapter, err := infra.NewMongoDBAdapter(cfg.MongoURI, cfg.MongoDB)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Falha ao conectar no MongoDB:", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	migrator := mongoAdapter.Migrator()
+	printStep := func(verb string) func(infra.Migration) {
+		return func(m infra.Migration) {
+			fmt.Printf("%s %d: %s\n", verb, m.Version, m.Description)
+		}
+	}
+
+	switch command {
+	case "status":
+		err = printStatus(ctx, migrator)
+	case "up":
+		var done []infra.Migration
+		done, err = migrator.Up(ctx, *to, printStep("aplicando"))
+		if err == nil && len(done) == 0 {
+			fmt.Println("Nenhuma migration pendente.")
+		}
+	case "down":
+		target := *to
+		if target == 0 {
+			target, err = previousVersion(ctx, migrator)
+		}
+		if err == nil {
+			_, err = migrator.Down(ctx, target, printStep("desfazendo"))
+		}
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Falha:", err)
+		os.Exit(1)
+	}
+}
+
+func printStatus(ctx context.Context, migrator *infra.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Versão do banco: %d (última conhecida: %d)\n", version, migrator.Latest())
+	for _, s := range statuses {
+		applied := "pendente"
+		if s.Applied() {
+			applied = "aplicada em " + s.AppliedAt.Local().Format(time.DateTime)
+		}
+		fmt.Printf("  %3d  %-60s %s\n", s.Version, s.Description, applied)
+	}
+	return nil
+}
+
+// previousVersion é a versão aplicada logo abaixo da atual: o alvo para desfazer só a última
+func previousVersion(ctx context.Context, migrator *infra.Migrator) (int, error) {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var applied []int
+	for _, s := range statuses {
+		if s.Applied() {
+			applied = append(applied, s.Version)
+		}
+	}
+	if len(applied) < 2 {
+		return 0, nil
+	}
+	return applied[len(applied)-2], nil
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "uso: migrate status | up [-to versão] | down [-to versão]")
+	os.Exit(2)
+}
@@ -0,0 +1,826 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: frete/v1/converter.proto
+
+package fretev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ConvertRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Moeda    string                 `protobuf:"bytes,1,opt,name=moeda,proto3" json:"moeda,omitempty"`
+	ValorBrl float64                `protobuf:"fixed64,2,opt,name=valor_brl,json=valorBrl,proto3" json:"valor_brl,omitempty"`
+	// Converte com a cotação vigente neste instante em vez da atual (conversão retroativa)
+	AsOf          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertRequest) Reset() {
+	*x = ConvertRequest{}
+	mi := &file_frete_v1_converter_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertRequest) ProtoMessage() {}
+
+func (x *ConvertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_frete_v1_converter_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertRequest.ProtoReflect.Descriptor instead.
+func (*ConvertRequest) Descriptor() ([]byte, []int) {
+	return file_frete_v1_converter_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ConvertRequest) GetMoeda() string {
+	if x != nil {
+		return x.Moeda
+	}
+	return ""
+}
+
+func (x *ConvertRequest) GetValorBrl() float64 {
+	if x != nil {
+		return x.ValorBrl
+	}
+	return 0
+}
+
+func (x *ConvertRequest) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
+type ConvertResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Conversion    *Conversion            `protobuf:"bytes,1,opt,name=conversion,proto3" json:"conversion,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertResponse) Reset() {
+	*x = ConvertResponse{}
+	mi := &file_frete_v1_converter_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertResponse) ProtoMessage() {}
+
+func (x *ConvertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_frete_v1_converter_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertResponse.ProtoReflect.Descriptor instead.
+func (*ConvertResponse) Descriptor() ([]byte, []int) {
+	return file_frete_v1_converter_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ConvertResponse) GetConversion() *Conversion {
+	if x != nil {
+		return x.Conversion
+	}
+	return nil
+}
+
+type Conversion struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Currency        string                 `protobuf:"bytes,1,opt,name=currency,proto3" json:"currency,omitempty"`
+	Cotacao         float64                `protobuf:"fixed64,2,opt,name=cotacao,proto3" json:"cotacao,omitempty"`
+	ValorEntrada    float64                `protobuf:"fixed64,3,opt,name=valor_entrada,json=valorEntrada,proto3" json:"valor_entrada,omitempty"`
+	ValorConvertido float64                `protobuf:"fixed64,4,opt,name=valor_convertido,json=valorConvertido,proto3" json:"valor_convertido,omitempty"`
+	Data            *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
+	ApiKeyId        string                 `protobuf:"bytes,6,opt,name=api_key_id,json=apiKeyId,proto3" json:"api_key_id,omitempty"`
+	Fonte           string                 `protobuf:"bytes,7,opt,name=fonte,proto3" json:"fonte,omitempty"`
+	// Conversão feita com a cotação de um instante passado
+	Retroativa     bool                   `protobuf:"varint,8,opt,name=retroativa,proto3" json:"retroativa,omitempty"`
+	DataReferencia *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=data_referencia,json=dataReferencia,proto3" json:"data_referencia,omitempty"`
+	// Horário da cotação usada na conversão retroativa
+	DataCotacao   *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=data_cotacao,json=dataCotacao,proto3" json:"data_cotacao,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Conversion) Reset() {
+	*x = Conversion{}
+	mi := &file_frete_v1_converter_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Conversion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Conversion) ProtoMessage() {}
+
+func (x *Conversion) ProtoReflect() protoreflect.Message {
+	mi := &file_frete_v1_converter_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Conversion.ProtoReflect.Descriptor instead.
+func (*Conversion) Descriptor() ([]byte, []int) {
+	return file_frete_v1_converter_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Conversion) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Conversion) GetCotacao() float64 {
+	if x != nil {
+		return x.Cotacao
+	}
+	return 0
+}
+
+func (x *Conversion) GetValorEntrada() float64 {
+	if x != nil {
+		return x.ValorEntrada
+	}
+	return 0
+}
+
+func (x *Conversion) GetValorConvertido() float64 {
+	if x != nil {
+		return x.ValorConvertido
+	}
+	return 0
+}
+
+func (x *Conversion) GetData() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Conversion) GetApiKeyId() string {
+	if x != nil {
+		return x.ApiKeyId
+	}
+	return ""
+}
+
+func (x *Conversion) GetFonte() string {
+	if x != nil {
+		return x.Fonte
+	}
+	return ""
+}
+
+func (x *Conversion) GetRetroativa() bool {
+	if x != nil {
+		return x.Retroativa
+	}
+	return false
+}
+
+func (x *Conversion) GetDataReferencia() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DataReferencia
+	}
+	return nil
+}
+
+func (x *Conversion) GetDataCotacao() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DataCotacao
+	}
+	return nil
+}
+
+type ListConversionsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Filtros opcionais; campos vazios não filtram
+	Currency string                 `protobuf:"bytes,1,opt,name=currency,proto3" json:"currency,omitempty"`
+	From     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	// Padrão 10, máximo 100
+	Limit  int32 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset int32 `protobuf:"varint,5,opt,name=offset,proto3" json:"offset,omitempty"`
+	// Ausente traz todas; true só as retroativas, false só as feitas com a cotação do dia
+	AsOf          *bool `protobuf:"varint,6,opt,name=as_of,json=asOf,proto3,oneof" json:"as_of,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConversionsRequest) Reset() {
+	*x = ListConversionsRequest{}
+	mi := &file_frete_v1_converter_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConversionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConversionsRequest) ProtoMessage() {}
+
+func (x *ListConversionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_frete_v1_converter_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConversionsRequest.ProtoReflect.Descriptor instead.
+func (*ListConversionsRequest) Descriptor() ([]byte, []int) {
+	return file_frete_v1_converter_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListConversionsRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *ListConversionsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ListConversionsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *ListConversionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListConversionsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListConversionsRequest) GetAsOf() bool {
+	if x != nil && x.AsOf != nil {
+		return *x.AsOf
+	}
+	return false
+}
+
+type ListConversionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Conversions   []*Conversion          `protobuf:"bytes,1,rep,name=conversions,proto3" json:"conversions,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	HasMore       bool                   `protobuf:"varint,4,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConversionsResponse) Reset() {
+	*x = ListConversionsResponse{}
+	mi := &file_frete_v1_converter_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConversionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConversionsResponse) ProtoMessage() {}
+
+func (x *ListConversionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_frete_v1_converter_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConversionsResponse.ProtoReflect.Descriptor instead.
+func (*ListConversionsResponse) Descriptor() ([]byte, []int) {
+	return file_frete_v1_converter_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListConversionsResponse) GetConversions() []*Conversion {
+	if x != nil {
+		return x.Conversions
+	}
+	return nil
+}
+
+func (x *ListConversionsResponse) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListConversionsResponse) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListConversionsResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+type GetVariationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Moeda         string                 `protobuf:"bytes,1,opt,name=moeda,proto3" json:"moeda,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVariationRequest) Reset() {
+	*x = GetVariationRequest{}
+	mi := &file_frete_v1_converter_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVariationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVariationRequest) ProtoMessage() {}
+
+func (x *GetVariationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_frete_v1_converter_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVariationRequest.ProtoReflect.Descriptor instead.
+func (*GetVariationRequest) Descriptor() ([]byte, []int) {
+	return file_frete_v1_converter_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetVariationRequest) GetMoeda() string {
+	if x != nil {
+		return x.Moeda
+	}
+	return ""
+}
+
+type GetVariationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Variations    []*Variation           `protobuf:"bytes,1,rep,name=variations,proto3" json:"variations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVariationResponse) Reset() {
+	*x = GetVariationResponse{}
+	mi := &file_frete_v1_converter_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVariationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVariationResponse) ProtoMessage() {}
+
+func (x *GetVariationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_frete_v1_converter_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVariationResponse.ProtoReflect.Descriptor instead.
+func (*GetVariationResponse) Descriptor() ([]byte, []int) {
+	return file_frete_v1_converter_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetVariationResponse) GetVariations() []*Variation {
+	if x != nil {
+		return x.Variations
+	}
+	return nil
+}
+
+type Variation struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Data               *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Cotacao            float64                `protobuf:"fixed64,2,opt,name=cotacao,proto3" json:"cotacao,omitempty"`
+	VariacaoValor      float64                `protobuf:"fixed64,3,opt,name=variacao_valor,json=variacaoValor,proto3" json:"variacao_valor,omitempty"`
+	VariacaoPercentual float64                `protobuf:"fixed64,4,opt,name=variacao_percentual,json=variacaoPercentual,proto3" json:"variacao_percentual,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Variation) Reset() {
+	*x = Variation{}
+	mi := &file_frete_v1_converter_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Variation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Variation) ProtoMessage() {}
+
+func (x *Variation) ProtoReflect() protoreflect.Message {
+	mi := &file_frete_v1_converter_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Variation.ProtoReflect.Descriptor instead.
+func (*Variation) Descriptor() ([]byte, []int) {
+	return file_frete_v1_converter_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Variation) GetData() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Variation) GetCotacao() float64 {
+	if x != nil {
+		return x.Cotacao
+	}
+	return 0
+}
+
+func (x *Variation) GetVariacaoValor() float64 {
+	if x != nil {
+		return x.VariacaoValor
+	}
+	return 0
+}
+
+func (x *Variation) GetVariacaoPercentual() float64 {
+	if x != nil {
+		return x.VariacaoPercentual
+	}
+	return 0
+}
+
+type WatchRatesRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Moedas []string               `protobuf:"bytes,1,rep,name=moedas,proto3" json:"moedas,omitempty"`
+	// Ignorado: as cotações chegam no ritmo da transmissão do servidor
+	// (RATE_STREAM_INTERVAL); mantido para não quebrar clientes antigos
+	IntervalSeconds int32 `protobuf:"varint,2,opt,name=interval_seconds,json=intervalSeconds,proto3" json:"interval_seconds,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *WatchRatesRequest) Reset() {
+	*x = WatchRatesRequest{}
+	mi := &file_frete_v1_converter_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRatesRequest) ProtoMessage() {}
+
+func (x *WatchRatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_frete_v1_converter_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRatesRequest.ProtoReflect.Descriptor instead.
+func (*WatchRatesRequest) Descriptor() ([]byte, []int) {
+	return file_frete_v1_converter_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WatchRatesRequest) GetMoedas() []string {
+	if x != nil {
+		return x.Moedas
+	}
+	return nil
+}
+
+func (x *WatchRatesRequest) GetIntervalSeconds() int32 {
+	if x != nil {
+		return x.IntervalSeconds
+	}
+	return 0
+}
+
+type RateUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Moeda         string                 `protobuf:"bytes,1,opt,name=moeda,proto3" json:"moeda,omitempty"`
+	Cotacao       float64                `protobuf:"fixed64,2,opt,name=cotacao,proto3" json:"cotacao,omitempty"`
+	Fonte         string                 `protobuf:"bytes,3,opt,name=fonte,proto3" json:"fonte,omitempty"`
+	Data          *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RateUpdate) Reset() {
+	*x = RateUpdate{}
+	mi := &file_frete_v1_converter_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateUpdate) ProtoMessage() {}
+
+func (x *RateUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_frete_v1_converter_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateUpdate.ProtoReflect.Descriptor instead.
+func (*RateUpdate) Descriptor() ([]byte, []int) {
+	return file_frete_v1_converter_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RateUpdate) GetMoeda() string {
+	if x != nil {
+		return x.Moeda
+	}
+	return ""
+}
+
+func (x *RateUpdate) GetCotacao() float64 {
+	if x != nil {
+		return x.Cotacao
+	}
+	return 0
+}
+
+func (x *RateUpdate) GetFonte() string {
+	if x != nil {
+		return x.Fonte
+	}
+	return ""
+}
+
+func (x *RateUpdate) GetData() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+var File_frete_v1_converter_proto protoreflect.FileDescriptor
+
+const file_frete_v1_converter_proto_rawDesc = "" +
+	"\n" +
+	"\x18frete/v1/converter.proto\x12\bfrete.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"t\n" +
+	"\x0eConvertRequest\x12\x14\n" +
+	"\x05moeda\x18\x01 \x01(\tR\x05moeda\x12\x1b\n" +
+	"\tvalor_brl\x18\x02 \x01(\x01R\bvalorBrl\x12/\n" +
+	"\x05as_of\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\"G\n" +
+	"\x0fConvertResponse\x124\n" +
+	"\n" +
+	"conversion\x18\x01 \x01(\v2\x14.frete.v1.ConversionR\n" +
+	"conversion\"\x9a\x03\n" +
+	"\n" +
+	"Conversion\x12\x1a\n" +
+	"\bcurrency\x18\x01 \x01(\tR\bcurrency\x12\x18\n" +
+	"\acotacao\x18\x02 \x01(\x01R\acotacao\x12#\n" +
+	"\rvalor_entrada\x18\x03 \x01(\x01R\fvalorEntrada\x12)\n" +
+	"\x10valor_convertido\x18\x04 \x01(\x01R\x0fvalorConvertido\x12.\n" +
+	"\x04data\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x04data\x12\x1c\n" +
+	"\n" +
+	"api_key_id\x18\x06 \x01(\tR\bapiKeyId\x12\x14\n" +
+	"\x05fonte\x18\a \x01(\tR\x05fonte\x12\x1e\n" +
+	"\n" +
+	"retroativa\x18\b \x01(\bR\n" +
+	"retroativa\x12C\n" +
+	"\x0fdata_referencia\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x0edataReferencia\x12=\n" +
+	"\fdata_cotacao\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\vdataCotacao\"\xe2\x01\n" +
+	"\x16ListConversionsRequest\x12\x1a\n" +
+	"\bcurrency\x18\x01 \x01(\tR\bcurrency\x12.\n" +
+	"\x04from\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x05 \x01(\x05R\x06offset\x12\x18\n" +
+	"\x05as_of\x18\x06 \x01(\bH\x00R\x04asOf\x88\x01\x01B\b\n" +
+	"\x06_as_of\"\x9a\x01\n" +
+	"\x17ListConversionsResponse\x126\n" +
+	"\vconversions\x18\x01 \x03(\v2\x14.frete.v1.ConversionR\vconversions\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\x12\x19\n" +
+	"\bhas_more\x18\x04 \x01(\bR\ahasMore\"+\n" +
+	"\x13GetVariationRequest\x12\x14\n" +
+	"\x05moeda\x18\x01 \x01(\tR\x05moeda\"K\n" +
+	"\x14GetVariationResponse\x123\n" +
+	"\n" +
+	"variations\x18\x01 \x03(\v2\x13.frete.v1.VariationR\n" +
+	"variations\"\xad\x01\n" +
+	"\tVariation\x12.\n" +
+	"\x04data\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04data\x12\x18\n" +
+	"\acotacao\x18\x02 \x01(\x01R\acotacao\x12%\n" +
+	"\x0evariacao_valor\x18\x03 \x01(\x01R\rvariacaoValor\x12/\n" +
+	"\x13variacao_percentual\x18\x04 \x01(\x01R\x12variacaoPercentual\"V\n" +
+	"\x11WatchRatesRequest\x12\x16\n" +
+	"\x06moedas\x18\x01 \x03(\tR\x06moedas\x12)\n" +
+	"\x10interval_seconds\x18\x02 \x01(\x05R\x0fintervalSeconds\"\x82\x01\n" +
+	"\n" +
+	"RateUpdate\x12\x14\n" +
+	"\x05moeda\x18\x01 \x01(\tR\x05moeda\x12\x18\n" +
+	"\acotacao\x18\x02 \x01(\x01R\acotacao\x12\x14\n" +
+	"\x05fonte\x18\x03 \x01(\tR\x05fonte\x12.\n" +
+	"\x04data\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04data2\xbc\x02\n" +
+	"\x10ConverterService\x12>\n" +
+	"\aConvert\x12\x18.frete.v1.ConvertRequest\x1a\x19.frete.v1.ConvertResponse\x12V\n" +
+	"\x0fListConversions\x12 .frete.v1.ListConversionsRequest\x1a!.frete.v1.ListConversionsResponse\x12M\n" +
+	"\fGetVariation\x12\x1d.frete.v1.GetVariationRequest\x1a\x1e.frete.v1.GetVariationResponse\x12A\n" +
+	"\n" +
+	"WatchRates\x12\x1b.frete.v1.WatchRatesRequest\x1a\x14.frete.v1.RateUpdate0\x01B#Z!go-frete/api/gen/frete/v1;fretev1b\x06proto3"
+
+var (
+	file_frete_v1_converter_proto_rawDescOnce sync.Once
+	file_frete_v1_converter_proto_rawDescData []byte
+)
+
+func file_frete_v1_converter_proto_rawDescGZIP() []byte {
+	file_frete_v1_converter_proto_rawDescOnce.Do(func() {
+		file_frete_v1_converter_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_frete_v1_converter_proto_rawDesc), len(file_frete_v1_converter_proto_rawDesc)))
+	})
+	return file_frete_v1_converter_proto_rawDescData
+}
+
+var file_frete_v1_converter_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_frete_v1_converter_proto_goTypes = []any{
+	(*ConvertRequest)(nil),          // 0: frete.v1.ConvertRequest
+	(*ConvertResponse)(nil),         // 1: frete.v1.ConvertResponse
+	(*Conversion)(nil),              // 2: frete.v1.Conversion
+	(*ListConversionsRequest)(nil),  // 3: frete.v1.ListConversionsRequest
+	(*ListConversionsResponse)(nil), // 4: frete.v1.ListConversionsResponse
+	(*GetVariationRequest)(nil),     // 5: frete.v1.GetVariationRequest
+	(*GetVariationResponse)(nil),    // 6: frete.v1.GetVariationResponse
+	(*Variation)(nil),               // 7: frete.v1.Variation
+	(*WatchRatesRequest)(nil),       // 8: frete.v1.WatchRatesRequest
+	(*RateUpdate)(nil),              // 9: frete.v1.RateUpdate
+	(*timestamppb.Timestamp)(nil),   // 10: google.protobuf.Timestamp
+}
+var file_frete_v1_converter_proto_depIdxs = []int32{
+	10, // 0: frete.v1.ConvertRequest.as_of:type_name -> google.protobuf.Timestamp
+	2,  // 1: frete.v1.ConvertResponse.conversion:type_name -> frete.v1.Conversion
+	10, // 2: frete.v1.Conversion.data:type_name -> google.protobuf.Timestamp
+	10, // 3: frete.v1.Conversion.data_referencia:type_name -> google.protobuf.Timestamp
+	10, // 4: frete.v1.Conversion.data_cotacao:type_name -> google.protobuf.Timestamp
+	10, // 5: frete.v1.ListConversionsRequest.from:type_name -> google.protobuf.Timestamp
+	10, // 6: frete.v1.ListConversionsRequest.to:type_name -> google.protobuf.Timestamp
+	2,  // 7: frete.v1.ListConversionsResponse.conversions:type_name -> frete.v1.Conversion
+	7,  // 8: frete.v1.GetVariationResponse.variations:type_name -> frete.v1.Variation
+	10, // 9: frete.v1.Variation.data:type_name -> google.protobuf.Timestamp
+	10, // 10: frete.v1.RateUpdate.data:type_name -> google.protobuf.Timestamp
+	0,  // 11: frete.v1.ConverterService.Convert:input_type -> frete.v1.ConvertRequest
+	3,  // 12: frete.v1.ConverterService.ListConversions:input_type -> frete.v1.ListConversionsRequest
+	5,  // 13: frete.v1.ConverterService.GetVariation:input_type -> frete.v1.GetVariationRequest
+	8,  // 14: frete.v1.ConverterService.WatchRates:input_type -> frete.v1.WatchRatesRequest
+	1,  // 15: frete.v1.ConverterService.Convert:output_type -> frete.v1.ConvertResponse
+	4,  // 16: frete.v1.ConverterService.ListConversions:output_type -> frete.v1.ListConversionsResponse
+	6,  // 17: frete.v1.ConverterService.GetVariation:output_type -> frete.v1.GetVariationResponse
+	9,  // 18: frete.v1.ConverterService.WatchRates:output_type -> frete.v1.RateUpdate
+	15, // [15:19] is the sub-list for method output_type
+	11, // [11:15] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_frete_v1_converter_proto_init() }
+func file_frete_v1_converter_proto_init() {
+	if File_frete_v1_converter_proto != nil {
+		return
+	}
+	file_frete_v1_converter_proto_msgTypes[3].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_frete_v1_converter_proto_rawDesc), len(file_frete_v1_converter_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_frete_v1_converter_proto_goTypes,
+		DependencyIndexes: file_frete_v1_converter_proto_depIdxs,
+		MessageInfos:      file_frete_v1_converter_proto_msgTypes,
+	}.Build()
+	File_frete_v1_converter_proto = out.File
+	file_frete_v1_converter_proto_goTypes = nil
+	file_frete_v1_converter_proto_depIdxs = nil
+}
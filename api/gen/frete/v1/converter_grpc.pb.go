@@ -0,0 +1,259 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: frete/v1/converter.proto
+
+package fretev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ConverterService_Convert_FullMethodName         = "/frete.v1.ConverterService/Convert"
+	ConverterService_ListConversions_FullMethodName = "/frete.v1.ConverterService/ListConversions"
+	ConverterService_GetVariation_FullMethodName    = "/frete.v1.ConverterService/GetVariation"
+	ConverterService_WatchRates_FullMethodName      = "/frete.v1.ConverterService/WatchRates"
+)
+
+// ConverterServiceClient is the client API for ConverterService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ConverterService expõe os mesmos casos de uso da API REST para os serviços
+// internos que preferem um contrato tipado.
+//
+// Autenticação: envie a chave de API no metadata "x-api-key" ou
+// "authorization: Bearer <chave>". Os escopos são os mesmos do REST.
+type ConverterServiceClient interface {
+	// Converte um valor em BRL e salva no histórico (escopo convert:write)
+	Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error)
+	// Busca o histórico com filtros e paginação (escopo history:read)
+	ListConversions(ctx context.Context, in *ListConversionsRequest, opts ...grpc.CallOption) (*ListConversionsResponse, error)
+	// Variação entre cotações coletadas consecutivas da moeda nos últimos 30 dias (escopo history:read)
+	GetVariation(ctx context.Context, in *GetVariationRequest, opts ...grpc.CallOption) (*GetVariationResponse, error)
+	// Envia a cotação atual das moedas e cada mudança seguinte (escopo history:read)
+	WatchRates(ctx context.Context, in *WatchRatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RateUpdate], error)
+}
+
+type converterServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewConverterServiceClient(cc grpc.ClientConnInterface) ConverterServiceClient {
+	return &converterServiceClient{cc}
+}
+
+func (c *converterServiceClient) Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConvertResponse)
+	err := c.cc.Invoke(ctx, ConverterService_Convert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *converterServiceClient) ListConversions(ctx context.Context, in *ListConversionsRequest, opts ...grpc.CallOption) (*ListConversionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListConversionsResponse)
+	err := c.cc.Invoke(ctx, ConverterService_ListConversions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *converterServiceClient) GetVariation(ctx context.Context, in *GetVariationRequest, opts ...grpc.CallOption) (*GetVariationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetVariationResponse)
+	err := c.cc.Invoke(ctx, ConverterService_GetVariation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *converterServiceClient) WatchRates(ctx context.Context, in *WatchRatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RateUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ConverterService_ServiceDesc.Streams[0], ConverterService_WatchRates_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRatesRequest, RateUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ConverterService_WatchRatesClient = grpc.ServerStreamingClient[RateUpdate]
+
+// ConverterServiceServer is the server API for ConverterService service.
+// All implementations must embed UnimplementedConverterServiceServer
+// for forward compatibility.
+//
+// ConverterService expõe os mesmos casos de uso da API REST para os serviços
+// internos que preferem um contrato tipado.
+//
+// Autenticação: envie a chave de API no metadata "x-api-key" ou
+// "authorization: Bearer <chave>". Os escopos são os mesmos do REST.
+type ConverterServiceServer interface {
+	// Converte um valor em BRL e salva no histórico (escopo convert:write)
+	Convert(context.Context, *ConvertRequest) (*ConvertResponse, error)
+	// Busca o histórico com filtros e paginação (escopo history:read)
+	ListConversions(context.Context, *ListConversionsRequest) (*ListConversionsResponse, error)
+	// Variação entre cotações coletadas consecutivas da moeda nos últimos 30 dias (escopo history:read)
+	GetVariation(context.Context, *GetVariationRequest) (*GetVariationResponse, error)
+	// Envia a cotação atual das moedas e cada mudança seguinte (escopo history:read)
+	WatchRates(*WatchRatesRequest, grpc.ServerStreamingServer[RateUpdate]) error
+	mustEmbedUnimplementedConverterServiceServer()
+}
+
+// UnimplementedConverterServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedConverterServiceServer struct{}
+
+func (UnimplementedConverterServiceServer) Convert(context.Context, *ConvertRequest) (*ConvertResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Convert not implemented")
+}
+func (UnimplementedConverterServiceServer) ListConversions(context.Context, *ListConversionsRequest) (*ListConversionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListConversions not implemented")
+}
+func (UnimplementedConverterServiceServer) GetVariation(context.Context, *GetVariationRequest) (*GetVariationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetVariation not implemented")
+}
+func (UnimplementedConverterServiceServer) WatchRates(*WatchRatesRequest, grpc.ServerStreamingServer[RateUpdate]) error {
+	return status.Error(codes.Unimplemented, "method WatchRates not implemented")
+}
+func (UnimplementedConverterServiceServer) mustEmbedUnimplementedConverterServiceServer() {}
+func (UnimplementedConverterServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeConverterServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ConverterServiceServer will
+// result in compilation errors.
+type UnsafeConverterServiceServer interface {
+	mustEmbedUnimplementedConverterServiceServer()
+}
+
+func RegisterConverterServiceServer(s grpc.ServiceRegistrar, srv ConverterServiceServer) {
+	// If the following call panics, it indicates UnimplementedConverterServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ConverterService_ServiceDesc, srv)
+}
+
+func _ConverterService_Convert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConverterServiceServer).Convert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConverterService_Convert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConverterServiceServer).Convert(ctx, req.(*ConvertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConverterService_ListConversions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListConversionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConverterServiceServer).ListConversions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConverterService_ListConversions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConverterServiceServer).ListConversions(ctx, req.(*ListConversionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConverterService_GetVariation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVariationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConverterServiceServer).GetVariation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConverterService_GetVariation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConverterServiceServer).GetVariation(ctx, req.(*GetVariationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConverterService_WatchRates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRatesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConverterServiceServer).WatchRates(m, &grpc.GenericServerStream[WatchRatesRequest, RateUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ConverterService_WatchRatesServer = grpc.ServerStreamingServer[RateUpdate]
+
+// ConverterService_ServiceDesc is the grpc.ServiceDesc for ConverterService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ConverterService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "frete.v1.ConverterService",
+	HandlerType: (*ConverterServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Convert",
+			Handler:    _ConverterService_Convert_Handler,
+		},
+		{
+			MethodName: "ListConversions",
+			Handler:    _ConverterService_ListConversions_Handler,
+		},
+		{
+			MethodName: "GetVariation",
+			Handler:    _ConverterService_GetVariation_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchRates",
+			Handler:       _ConverterService_WatchRates_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "frete/v1/converter.proto",
+}
@@ -0,0 +1,640 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go-frete/api/pkg/logger"
+)
+
+// Tipos de regra de alerta. Cotacao é em reais por unidade da moeda: "abaixo"
+// avisa quando a moeda fica mais barata que o valor.
+const (
+	AlertAbove = "acima"
+	AlertBelow = "abaixo"
+	// Variação percentual dentro da janela; o sinal do valor dá a direção
+	AlertMove = "variacao"
+	// Nova mínima ou máxima dos últimos N dias
+	AlertLow  = "minima"
+	AlertHigh = "maxima"
+)
+
+// Canais de notificação de um alerta
+const (
+	AlertChannelWebhook = "webhook"
+	AlertChannelEmail   = "email"
+)
+
+// Resultado de cada notificação no histórico de disparos
+const (
+	NotificationSent   = "enviada"
+	NotificationFailed = "falhou"
+)
+
+const (
+	defaultAlertCooldownMinutes = 60
+	maxAlertCooldownMinutes     = 7 * 24 * 60
+	maxAlertWindowMinutes       = 30 * 24 * 60
+	maxAlertDays                = 365
+	// Cotações iguais à última avaliada voltam a ser avaliadas só depois disso
+	alertReevaluateAfter = time.Minute
+	// A série lida para variação e mínima/máxima para antes disso: a cotação
+	// avaliada pode já ter sido gravada pela coleta e não conta contra si mesma
+	alertSeriesGap = time.Minute
+)
+
+var (
+	ErrAlertNotFound = errors.New("alerta não encontrado")
+	ErrAlertCurrency = errors.New("moeda não suportada")
+	ErrAlertType     = errors.New("tipo de alerta inválido: use acima, abaixo, variacao, minima ou maxima")
+	ErrAlertValue    = errors.New("valor inválido para o tipo de alerta")
+	ErrAlertWindow   = fmt.Errorf("janela_minutos deve ficar entre 1 e %d", maxAlertWindowMinutes)
+	ErrAlertDays     = fmt.Errorf("dias deve ficar entre 1 e %d", maxAlertDays)
+	ErrAlertCooldown = fmt.Errorf("cooldown_minutos deve ficar entre 0 e %d", maxAlertCooldownMinutes)
+	ErrAlertChannel  = errors.New("canal de notificação inválido: use webhook ou email")
+	ErrAlertEmail    = errors.New("e-mail inválido ou envio de e-mail desligado")
+)
+
+// AlertRule é a regra de um cliente para uma moeda. Depois de disparar ela
+// fica Disparada até a condição deixar de valer, e só volta a disparar passado
+// o cooldown desde o último disparo.
+type AlertRule struct {
+	ID       string `bson:"_id" json:"id"`
+	APIKeyID string `bson:"api_key_id,omitempty" json:"api_key_id,omitempty"`
+	Moeda    string `bson:"moeda" json:"moeda"`
+	Tipo     string `bson:"tipo" json:"tipo"`
+	// Cotação limite em acima/abaixo; percentual em variacao
+	Valor           float64   `bson:"valor,omitempty" json:"valor,omitempty"`
+	JanelaMinutos   int       `bson:"janela_minutos,omitempty" json:"janela_minutos,omitempty"`
+	Dias            int       `bson:"dias,omitempty" json:"dias,omitempty"`
+	CooldownMinutos int       `bson:"cooldown_minutos" json:"cooldown_minutos"`
+	Canais          []string  `bson:"canais" json:"canais"`
+	Email           string    `bson:"email,omitempty" json:"email,omitempty"`
+	Ativa           bool      `bson:"ativa" json:"ativa"`
+	Disparada       bool      `bson:"disparada" json:"disparada"`
+	UltimoDisparo   time.Time `bson:"ultimo_disparo,omitempty" json:"ultimo_disparo,omitzero"`
+	CriadaEm        time.Time `bson:"criada_em" json:"criada_em"`
+	AtualizadaEm    time.Time `bson:"atualizada_em" json:"atualizada_em"`
+}
+
+// AlertRuleInput são os campos que o cliente define ao criar ou alterar a regra
+type AlertRuleInput struct {
+	Moeda         string
+	Tipo          string
+	Valor         float64
+	JanelaMinutos int
+	Dias          int
+	// nil usa o padrão de 60 minutos
+	CooldownMinutos *int
+	// Vazio notifica por webhook
+	Canais []string
+	Email  string
+	// nil cria a regra ativa
+	Ativa *bool
+}
+
+// AlertTrigger é um disparo no histórico, com o resultado de cada notificação
+type AlertTrigger struct {
+	ID       string  `bson:"_id" json:"id"`
+	AlertaID string  `bson:"alerta_id" json:"alerta_id"`
+	APIKeyID string  `bson:"api_key_id,omitempty" json:"api_key_id,omitempty"`
+	Moeda    string  `bson:"moeda" json:"moeda"`
+	Tipo     string  `bson:"tipo" json:"tipo"`
+	Valor    float64 `bson:"valor,omitempty" json:"valor,omitempty"`
+	Cotacao  float64 `bson:"cotacao" json:"cotacao"`
+	Fonte    string  `bson:"fonte,omitempty" json:"fonte,omitempty"`
+	// Cotação do início da janela em variacao; mínima ou máxima anterior em minima/maxima
+	Referencia   float64             `bson:"referencia,omitempty" json:"referencia,omitempty"`
+	Variacao     float64             `bson:"variacao_percentual,omitempty" json:"variacao_percentual,omitempty"`
+	Descricao    string              `bson:"descricao" json:"descricao"`
+	DisparadoEm  time.Time           `bson:"disparado_em" json:"disparado_em"`
+	Notificacoes []AlertNotification `bson:"notificacoes,omitempty" json:"notificacoes,omitempty"`
+}
+
+type AlertNotification struct {
+	Canal  string `bson:"canal" json:"canal"`
+	Status string `bson:"status" json:"status"`
+	Erro   string `bson:"erro,omitempty" json:"erro,omitempty"`
+}
+
+// AlertTriggerFilter restringe o histórico de disparos; campos vazios não filtram
+type AlertTriggerFilter struct {
+	AlertaID string
+	APIKeyID string
+	Moeda    string
+	Limit    int
+}
+
+type AlertRepository interface {
+	// SaveAlertRule insere a regra ou substitui a de mesmo id
+	SaveAlertRule(rule AlertRule) error
+	GetAlertRule(id string) (*AlertRule, error)
+	// ListAlertRules devolve as regras da chave; apiKeyID vazio devolve todas
+	ListAlertRules(apiKeyID string) ([]AlertRule, error)
+	DeleteAlertRule(id string) error
+	ActiveAlertRules(moeda string) ([]AlertRule, error)
+	// TryTriggerAlert marca a regra como disparada em at, só se ela estiver
+	// armada e o cooldown tiver passado. Com várias instâncias avaliando a
+	// mesma cotação, só uma recebe true.
+	TryTriggerAlert(id string, at time.Time, cooldown time.Duration) (bool, error)
+	RearmAlert(id string) error
+}
+
+type AlertTriggerStore interface {
+	SaveAlertTrigger(t AlertTrigger) error
+	ListAlertTriggers(filter AlertTriggerFilter) ([]AlertTrigger, error)
+}
+
+// AlertMailer envia o e-mail de um disparo
+type AlertMailer interface {
+	SendAlert(to, subject, body string) error
+}
+
+// RateObserver recebe as cotações obtidas do provedor
+type RateObserver interface {
+	Observe(q RateQuote)
+}
+
+// WatchedRateProvider entrega ao observador cada cotação obtida com sucesso,
+// venha ela de uma conversão, do streaming ou da coleta
+type WatchedRateProvider struct {
+	next     RateProvider
+	observer RateObserver
+}
+
+func NewWatchedRateProvider(next RateProvider, observer RateObserver) *WatchedRateProvider {
+	return &WatchedRateProvider{next: next, observer: observer}
+}
+
+func (p *WatchedRateProvider) GetRate(moeda string) (float64, error) {
+	cotacao, err := p.next.GetRate(moeda)
+	if err == nil {
+		p.observe(RateQuote{Moeda: moeda, Cotacao: cotacao, Fonte: sourceOf(p.next)})
+	}
+	return cotacao, err
+}
+
+// GetQuote repassa o horário da cotação, se o provedor decorado o informar
+func (p *WatchedRateProvider) GetQuote(moeda string) (RateQuote, error) {
+	quote, err := quoteOf(p.next, moeda)
+	if err == nil {
+		if quote.Moeda == "" {
+			quote.Moeda = moeda
+		}
+		p.observe(quote)
+	}
+	return quote, err
+}
+
+// Source repassa a identificação do provedor decorado
+func (p *WatchedRateProvider) Source() string {
+	return sourceOf(p.next)
+}
+
+func (p *WatchedRateProvider) observe(q RateQuote) {
+	q.Moeda = strings.ToUpper(q.Moeda)
+	p.observer.Observe(q)
+}
+
+// AlertUseCase cadastra as regras de alerta e as avalia contra as cotações
+// observadas. A avaliação roda em segundo plano (Run), fora do caminho da
+// conversão; regras de variação e mínima/máxima usam a série da coleta.
+type AlertUseCase struct {
+	rules      AlertRepository
+	triggers   AlertTriggerStore
+	series     RateSeriesReader
+	currencies *CurrencyRegistry
+	events     EventSink
+	mailer     AlertMailer
+	log        logger.Logger
+	now        func() time.Time
+	queue      chan RateQuote
+
+	mu   sync.Mutex
+	last map[string]RateQuote
+}
+
+func NewAlertUseCase(rules AlertRepository, triggers AlertTriggerStore, series RateSeriesReader, currencies *CurrencyRegistry, events EventSink, l logger.Logger) *AlertUseCase {
+	return &AlertUseCase{
+		rules:      rules,
+		triggers:   triggers,
+		series:     series,
+		currencies: currencies,
+		events:     events,
+		log:        l,
+		now:        time.Now,
+		queue:      make(chan RateQuote, 256),
+		last:       map[string]RateQuote{},
+	}
+}
+
+// WithMailer liga o canal email; sem ele, regras com email são recusadas
+func (uc *AlertUseCase) WithMailer(m AlertMailer) *AlertUseCase {
+	uc.mailer = m
+	return uc
+}
+
+// Create cadastra a regra para a chave da requisição
+func (uc *AlertUseCase) Create(ctx context.Context, in AlertRuleInput) (AlertRule, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	rule, err := uc.validate(in)
+	if err != nil {
+		log.Warn("Regra de alerta inválida", "erro", err.Error())
+		return AlertRule{}, err
+	}
+	if rule.ID, err = randomHex(12); err != nil {
+		return AlertRule{}, err
+	}
+	rule.CriadaEm = uc.now().UTC()
+	rule.AtualizadaEm = rule.CriadaEm
+	if key, ok := APIKeyFromContext(ctx); ok {
+		rule.APIKeyID = key.ID
+	}
+
+	if err := uc.rules.SaveAlertRule(rule); err != nil {
+		log.Error("Falha ao salvar alerta", "erro", err.Error())
+		return AlertRule{}, err
+	}
+	uc.forget(rule.Moeda)
+	log.Info("Alerta cadastrado", "alerta_id", rule.ID, "moeda", rule.Moeda, "tipo", rule.Tipo)
+	return rule, nil
+}
+
+// Update substitui a definição da regra e a rearma; o cooldown continua
+// contando do último disparo
+func (uc *AlertUseCase) Update(ctx context.Context, id string, in AlertRuleInput) (AlertRule, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	current, err := uc.Get(ctx, id)
+	if err != nil {
+		return AlertRule{}, err
+	}
+	rule, err := uc.validate(in)
+	if err != nil {
+		log.Warn("Regra de alerta inválida", "erro", err.Error(), "alerta_id", id)
+		return AlertRule{}, err
+	}
+	rule.ID = current.ID
+	rule.APIKeyID = current.APIKeyID
+	rule.CriadaEm = current.CriadaEm
+	rule.UltimoDisparo = current.UltimoDisparo
+	rule.AtualizadaEm = uc.now().UTC()
+
+	if err := uc.rules.SaveAlertRule(rule); err != nil {
+		log.Error("Falha ao salvar alerta", "erro", err.Error(), "alerta_id", id)
+		return AlertRule{}, err
+	}
+	uc.forget(rule.Moeda)
+	log.Info("Alerta alterado", "alerta_id", rule.ID)
+	return rule, nil
+}
+
+// Get devolve a regra; a de outra chave responde como inexistente
+func (uc *AlertUseCase) Get(ctx context.Context, id string) (AlertRule, error) {
+	rule, err := uc.rules.GetAlertRule(id)
+	if err != nil {
+		return AlertRule{}, err
+	}
+	if owner := ownerOf(ctx); owner != "" && rule.APIKeyID != owner {
+		return AlertRule{}, ErrAlertNotFound
+	}
+	return *rule, nil
+}
+
+// List devolve as regras da chave da requisição; a chave admin vê todas
+func (uc *AlertUseCase) List(ctx context.Context) ([]AlertRule, error) {
+	rules, err := uc.rules.ListAlertRules(ownerOf(ctx))
+	if err != nil {
+		logger.FromContext(ctx, uc.log).Error("Falha ao listar alertas", "erro", err.Error())
+		return nil, err
+	}
+	if rules == nil {
+		rules = []AlertRule{}
+	}
+	return rules, nil
+}
+
+// Delete remove a regra; o histórico de disparos dela continua disponível
+func (uc *AlertUseCase) Delete(ctx context.Context, id string) error {
+	log := logger.FromContext(ctx, uc.log)
+
+	if _, err := uc.Get(ctx, id); err != nil {
+		return err
+	}
+	if err := uc.rules.DeleteAlertRule(id); err != nil {
+		log.Error("Falha ao remover alerta", "erro", err.Error(), "alerta_id", id)
+		return err
+	}
+	log.Info("Alerta removido", "alerta_id", id)
+	return nil
+}
+
+// History devolve os disparos mais recentes primeiro, só os da chave da requisição
+func (uc *AlertUseCase) History(ctx context.Context, filter AlertTriggerFilter) ([]AlertTrigger, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 100
+	}
+	filter.Limit = min(filter.Limit, 1000)
+	filter.APIKeyID = ownerOf(ctx)
+	filter.Moeda = strings.ToUpper(filter.Moeda)
+
+	triggers, err := uc.triggers.ListAlertTriggers(filter)
+	if err != nil {
+		logger.FromContext(ctx, uc.log).Error("Falha ao listar disparos de alerta", "erro", err.Error())
+		return nil, err
+	}
+	if triggers == nil {
+		triggers = []AlertTrigger{}
+	}
+	return triggers, nil
+}
+
+func (uc *AlertUseCase) validate(in AlertRuleInput) (AlertRule, error) {
+	rule := AlertRule{
+		Moeda:           strings.ToUpper(strings.TrimSpace(in.Moeda)),
+		Tipo:            in.Tipo,
+		CooldownMinutos: defaultAlertCooldownMinutes,
+		Canais:          []string{AlertChannelWebhook},
+		Email:           strings.TrimSpace(in.Email),
+		Ativa:           in.Ativa == nil || *in.Ativa,
+	}
+	if !uc.currencies.Known(rule.Moeda) {
+		return AlertRule{}, ErrAlertCurrency
+	}
+
+	switch rule.Tipo {
+	case AlertAbove, AlertBelow:
+		if in.Valor <= 0 {
+			return AlertRule{}, ErrAlertValue
+		}
+		rule.Valor = in.Valor
+	case AlertMove:
+		if in.Valor == 0 {
+			return AlertRule{}, ErrAlertValue
+		}
+		if in.JanelaMinutos < 1 || in.JanelaMinutos > maxAlertWindowMinutes {
+			return AlertRule{}, ErrAlertWindow
+		}
+		rule.Valor, rule.JanelaMinutos = in.Valor, in.JanelaMinutos
+	case AlertLow, AlertHigh:
+		if in.Dias < 1 || in.Dias > maxAlertDays {
+			return AlertRule{}, ErrAlertDays
+		}
+		rule.Dias = in.Dias
+	default:
+		return AlertRule{}, ErrAlertType
+	}
+
+	if in.CooldownMinutos != nil {
+		if *in.CooldownMinutos < 0 || *in.CooldownMinutos > maxAlertCooldownMinutes {
+			return AlertRule{}, ErrAlertCooldown
+		}
+		rule.CooldownMinutos = *in.CooldownMinutos
+	}
+	if len(in.Canais) > 0 {
+		for _, c := range in.Canais {
+			if c != AlertChannelWebhook && c != AlertChannelEmail {
+				return AlertRule{}, ErrAlertChannel
+			}
+		}
+		rule.Canais = slices.Compact(slices.Sorted(slices.Values(in.Canais)))
+	}
+	if slices.Contains(rule.Canais, AlertChannelEmail) {
+		if uc.mailer == nil || rule.Email == "" {
+			return AlertRule{}, ErrAlertEmail
+		}
+		addr, err := mail.ParseAddress(rule.Email)
+		if err != nil || addr.Address != rule.Email {
+			return AlertRule{}, ErrAlertEmail
+		}
+	}
+	return rule, nil
+}
+
+// forget faz a próxima cotação da moeda ser avaliada mesmo se repetida, para
+// que uma regra nova ou alterada não espere a cotação mudar
+func (uc *AlertUseCase) forget(moeda string) {
+	uc.mu.Lock()
+	delete(uc.last, moeda)
+	uc.mu.Unlock()
+}
+
+// Observe implementa RateObserver: enfileira a cotação sem bloquear quem a
+// obteve. Com a fila cheia a cotação é descartada; a próxima a substitui.
+func (uc *AlertUseCase) Observe(q RateQuote) {
+	select {
+	case uc.queue <- q:
+	default:
+		uc.log.Warn("Fila de avaliação de alertas cheia; cotação descartada", "moeda", q.Moeda)
+	}
+}
+
+// Run avalia as cotações observadas até o contexto ser cancelado
+func (uc *AlertUseCase) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case q := <-uc.queue:
+			uc.Evaluate(ctx, q)
+		}
+	}
+}
+
+// Evaluate confere as regras ativas da moeda contra a cotação e devolve
+// quantas dispararam
+func (uc *AlertUseCase) Evaluate(ctx context.Context, q RateQuote) (int, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	at := uc.now().UTC()
+	if !uc.changed(q, at) {
+		return 0, nil
+	}
+	rules, err := uc.rules.ActiveAlertRules(q.Moeda)
+	if err != nil {
+		log.Error("Falha ao buscar alertas da moeda", "erro", err.Error(), "moeda", q.Moeda)
+		uc.forget(q.Moeda)
+		return 0, err
+	}
+
+	fired := 0
+	for _, rule := range rules {
+		met, trigger, err := uc.check(rule, q, at)
+		if err != nil {
+			log.Error("Falha ao avaliar alerta", "erro", err.Error(), "alerta_id", rule.ID)
+			continue
+		}
+		if !met {
+			if rule.Disparada {
+				if err := uc.rules.RearmAlert(rule.ID); err != nil {
+					log.Error("Falha ao rearmar alerta", "erro", err.Error(), "alerta_id", rule.ID)
+				}
+			}
+			continue
+		}
+		if rule.Disparada {
+			continue
+		}
+		// Outra instância disparou primeiro ou o cooldown ainda não passou
+		claimed, err := uc.rules.TryTriggerAlert(rule.ID, at, time.Duration(rule.CooldownMinutos)*time.Minute)
+		if err != nil {
+			log.Error("Falha ao marcar disparo de alerta", "erro", err.Error(), "alerta_id", rule.ID)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		uc.fire(ctx, rule, trigger)
+		fired++
+	}
+	return fired, nil
+}
+
+// changed descarta a cotação igual à última avaliada da moeda, a não ser que
+// ela tenha sido avaliada há mais de alertReevaluateAfter
+func (uc *AlertUseCase) changed(q RateQuote, at time.Time) bool {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	last, ok := uc.last[q.Moeda]
+	if ok && last.Cotacao == q.Cotacao && at.Sub(last.Data) < alertReevaluateAfter {
+		return false
+	}
+	uc.last[q.Moeda] = RateQuote{Moeda: q.Moeda, Cotacao: q.Cotacao, Data: at}
+	return true
+}
+
+// check diz se a cotação satisfaz a regra e monta o disparo correspondente.
+// Sem série no período, variação e mínima/máxima não disparam.
+func (uc *AlertUseCase) check(rule AlertRule, q RateQuote, at time.Time) (bool, AlertTrigger, error) {
+	trigger := AlertTrigger{
+		AlertaID:    rule.ID,
+		APIKeyID:    rule.APIKeyID,
+		Moeda:       rule.Moeda,
+		Tipo:        rule.Tipo,
+		Valor:       rule.Valor,
+		Cotacao:     q.Cotacao,
+		Fonte:       q.Fonte,
+		DisparadoEm: at,
+	}
+
+	switch rule.Tipo {
+	case AlertAbove:
+		return q.Cotacao >= rule.Valor, trigger, nil
+	case AlertBelow:
+		return q.Cotacao <= rule.Valor, trigger, nil
+	case AlertMove:
+		series, err := uc.series.GetRateSeries(rule.Moeda, at.Add(-time.Duration(rule.JanelaMinutos)*time.Minute), at.Add(-alertSeriesGap))
+		if err != nil || len(series) == 0 {
+			return false, trigger, err
+		}
+		ref := series[0].Cotacao
+		if ref <= 0 {
+			return false, trigger, nil
+		}
+		move := (q.Cotacao - ref) / ref * 100
+		trigger.Referencia, trigger.Variacao = ref, move
+		if rule.Valor > 0 {
+			return move >= rule.Valor, trigger, nil
+		}
+		return move <= rule.Valor, trigger, nil
+	case AlertLow, AlertHigh:
+		series, err := uc.series.GetRateSeries(rule.Moeda, at.AddDate(0, 0, -rule.Dias), at.Add(-alertSeriesGap))
+		if err != nil || len(series) == 0 {
+			return false, trigger, err
+		}
+		ref := series[0].Cotacao
+		for _, s := range series[1:] {
+			if rule.Tipo == AlertLow {
+				ref = min(ref, s.Cotacao)
+			} else {
+				ref = max(ref, s.Cotacao)
+			}
+		}
+		trigger.Referencia = ref
+		if rule.Tipo == AlertLow {
+			return q.Cotacao < ref, trigger, nil
+		}
+		return q.Cotacao > ref, trigger, nil
+	}
+	return false, trigger, nil
+}
+
+// fire notifica os canais da regra e grava o disparo com o resultado de cada um
+func (uc *AlertUseCase) fire(ctx context.Context, rule AlertRule, trigger AlertTrigger) {
+	log := logger.FromContext(ctx, uc.log)
+
+	id, err := randomHex(12)
+	if err != nil {
+		log.Error("Falha ao gerar id do disparo", "erro", err.Error(), "alerta_id", rule.ID)
+		return
+	}
+	trigger.ID = id
+	trigger.Descricao = describeAlert(rule, trigger)
+
+	for _, canal := range rule.Canais {
+		n := AlertNotification{Canal: canal, Status: NotificationSent}
+		if err := uc.notify(canal, rule, trigger); err != nil {
+			n.Status, n.Erro = NotificationFailed, err.Error()
+			log.Warn("Falha ao notificar alerta", "erro", err.Error(), "alerta_id", rule.ID, "canal", canal)
+		}
+		trigger.Notificacoes = append(trigger.Notificacoes, n)
+	}
+
+	if err := uc.triggers.SaveAlertTrigger(trigger); err != nil {
+		log.Error("Falha ao gravar disparo de alerta", "erro", err.Error(), "alerta_id", rule.ID)
+	}
+	log.Info("Alerta de cotação disparado", "alerta_id", rule.ID, "moeda", rule.Moeda, "cotacao", trigger.Cotacao, "descricao", trigger.Descricao)
+}
+
+func (uc *AlertUseCase) notify(canal string, rule AlertRule, trigger AlertTrigger) error {
+	switch canal {
+	case AlertChannelWebhook:
+		raw, err := json.Marshal(trigger)
+		if err != nil {
+			return err
+		}
+		// O evento só vai para os webhooks da chave dona da regra
+		return uc.events.Publish(DomainEvent{
+			ID:       trigger.ID,
+			Tipo:     EventRateAlertTriggered,
+			Chave:    rule.Moeda,
+			APIKeyID: rule.APIKeyID,
+			CriadoEm: trigger.DisparadoEm,
+			Dados:    raw,
+		})
+	case AlertChannelEmail:
+		if uc.mailer == nil {
+			return ErrAlertEmail
+		}
+		subject := "Alerta de cotação: " + trigger.Descricao
+		body := fmt.Sprintf("%s\n\nCotação: %.4f\nDisparado em: %s\nAlerta: %s\n",
+			trigger.Descricao, trigger.Cotacao, trigger.DisparadoEm.Format(time.RFC3339), rule.ID)
+		return uc.mailer.SendAlert(rule.Email, subject, body)
+	}
+	return ErrAlertChannel
+}
+
+// describeAlert resume o disparo numa frase, usada no histórico e no e-mail
+func describeAlert(rule AlertRule, t AlertTrigger) string {
+	switch rule.Tipo {
+	case AlertAbove:
+		return fmt.Sprintf("%s acima de %.4f (cotação %.4f)", rule.Moeda, rule.Valor, t.Cotacao)
+	case AlertBelow:
+		return fmt.Sprintf("%s abaixo de %.4f (cotação %.4f)", rule.Moeda, rule.Valor, t.Cotacao)
+	case AlertMove:
+		return fmt.Sprintf("%s variou %+.2f%% em %d minutos (cotação %.4f)", rule.Moeda, t.Variacao, rule.JanelaMinutos, t.Cotacao)
+	case AlertLow:
+		return fmt.Sprintf("%s na menor cotação em %d dias (cotação %.4f, mínima anterior %.4f)", rule.Moeda, rule.Dias, t.Cotacao, t.Referencia)
+	case AlertHigh:
+		return fmt.Sprintf("%s na maior cotação em %d dias (cotação %.4f, máxima anterior %.4f)", rule.Moeda, rule.Dias, t.Cotacao, t.Referencia)
+	}
+	return rule.Moeda
+}
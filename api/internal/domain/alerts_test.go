@@ -0,0 +1,433 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAlertStoreFake faz o papel das coleções de regras e de disparos
+type memoryAlertStoreFake struct {
+	mu       sync.Mutex
+	rules    map[string]AlertRule
+	triggers []AlertTrigger
+}
+
+func newMemoryAlertStoreFake() *memoryAlertStoreFake {
+	return &memoryAlertStoreFake{rules: map[string]AlertRule{}}
+}
+
+func (f *memoryAlertStoreFake) SaveAlertRule(rule AlertRule) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules[rule.ID] = rule
+	return nil
+}
+
+func (f *memoryAlertStoreFake) GetAlertRule(id string) (*AlertRule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rule, ok := f.rules[id]
+	if !ok {
+		return nil, ErrAlertNotFound
+	}
+	return &rule, nil
+}
+
+func (f *memoryAlertStoreFake) ListAlertRules(apiKeyID string) ([]AlertRule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []AlertRule
+	for _, rule := range f.rules {
+		if apiKeyID == "" || rule.APIKeyID == apiKeyID {
+			out = append(out, rule)
+		}
+	}
+	return out, nil
+}
+
+func (f *memoryAlertStoreFake) DeleteAlertRule(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.rules, id)
+	return nil
+}
+
+func (f *memoryAlertStoreFake) ActiveAlertRules(moeda string) ([]AlertRule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []AlertRule
+	for _, rule := range f.rules {
+		if rule.Moeda == moeda && rule.Ativa {
+			out = append(out, rule)
+		}
+	}
+	return out, nil
+}
+
+func (f *memoryAlertStoreFake) TryTriggerAlert(id string, at time.Time, cooldown time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rule := f.rules[id]
+	if rule.Disparada || at.Sub(rule.UltimoDisparo) < cooldown {
+		return false, nil
+	}
+	rule.Disparada, rule.UltimoDisparo = true, at
+	f.rules[id] = rule
+	return true, nil
+}
+
+func (f *memoryAlertStoreFake) RearmAlert(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rule := f.rules[id]
+	rule.Disparada = false
+	f.rules[id] = rule
+	return nil
+}
+
+func (f *memoryAlertStoreFake) SaveAlertTrigger(t AlertTrigger) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.triggers = append(f.triggers, t)
+	return nil
+}
+
+func (f *memoryAlertStoreFake) ListAlertTriggers(filter AlertTriggerFilter) ([]AlertTrigger, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []AlertTrigger
+	for _, t := range f.triggers {
+		if filter.APIKeyID == "" || t.APIKeyID == filter.APIKeyID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// seriesFake devolve os pontos da série dentro do período pedido
+type seriesFake []RateSnapshot
+
+func (s seriesFake) GetRateSeries(moeda string, from, to time.Time) ([]RateSnapshot, error) {
+	var out []RateSnapshot
+	for _, p := range s {
+		if p.Moeda == moeda && !p.Data.Before(from) && !p.Data.After(to) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+type alertMailerFake struct {
+	sent []string
+	err  error
+}
+
+func (m *alertMailerFake) SendAlert(to, subject, body string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, to+": "+subject)
+	return nil
+}
+
+func newAlertFixture(series seriesFake) (*AlertUseCase, *memoryAlertStoreFake, *recordingSinkFake, *time.Time) {
+	store := newMemoryAlertStoreFake()
+	sink := &recordingSinkFake{name: "webhooks"}
+	uc := NewAlertUseCase(store, store, series, NewCurrencyRegistry(nil), sink, newOutboxLogger())
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+	return uc, store, sink, &now
+}
+
+func cooldown(minutes int) *int {
+	return &minutes
+}
+
+func usd(cotacao float64) RateQuote {
+	return RateQuote{Moeda: "USD", Cotacao: cotacao, Fonte: "awesomeapi"}
+}
+
+func TestAlerts(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should reject invalid rules",
+			run:  shouldRejectInvalidRules,
+		},
+		{
+			name: "should fire below target once until it rearms",
+			run:  shouldFireBelowTargetOnceUntilItRearms,
+		},
+		{
+			name: "should hold a rearmed rule until the cooldown passes",
+			run:  shouldHoldARearmedRuleUntilTheCooldownPasses,
+		},
+		{
+			name: "should fire on percent move within the window",
+			run:  shouldFireOnPercentMoveWithinTheWindow,
+		},
+		{
+			name: "should fire on new N day low",
+			run:  shouldFireOnNewNDayLow,
+		},
+		{
+			name: "should skip repeated quote until a rule changes",
+			run:  shouldSkipRepeatedQuoteUntilARuleChanges,
+		},
+		{
+			name: "should record failed email notification",
+			run:  shouldRecordFailedEmailNotification,
+		},
+		{
+			name: "should hide alerts of another key",
+			run:  shouldHideAlertsOfAnotherKey,
+		},
+		{
+			name: "should observe only successful quotes",
+			run:  shouldObserveOnlySuccessfulQuotes,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldRejectInvalidRules(t *testing.T) {
+	uc, _, _, _ := newAlertFixture(nil)
+
+	cases := []struct {
+		in  AlertRuleInput
+		err error
+	}{
+		{AlertRuleInput{Moeda: "XYZ", Tipo: AlertBelow, Valor: 5}, ErrAlertCurrency},
+		{AlertRuleInput{Moeda: "USD", Tipo: "igual", Valor: 5}, ErrAlertType},
+		{AlertRuleInput{Moeda: "USD", Tipo: AlertBelow}, ErrAlertValue},
+		{AlertRuleInput{Moeda: "USD", Tipo: AlertMove, Valor: -2}, ErrAlertWindow},
+		{AlertRuleInput{Moeda: "USD", Tipo: AlertLow, Dias: 400}, ErrAlertDays},
+		{AlertRuleInput{Moeda: "USD", Tipo: AlertAbove, Valor: 6, CooldownMinutos: cooldown(-1)}, ErrAlertCooldown},
+		{AlertRuleInput{Moeda: "USD", Tipo: AlertAbove, Valor: 6, Canais: []string{"sms"}}, ErrAlertChannel},
+		// Sem servidor SMTP o canal email fica desligado
+		{AlertRuleInput{Moeda: "USD", Tipo: AlertAbove, Valor: 6, Canais: []string{AlertChannelEmail}, Email: "compras@exemplo.com"}, ErrAlertEmail},
+	}
+	for _, c := range cases {
+		_, err := uc.Create(context.Background(), c.in)
+		assert.ErrorIs(t, err, c.err, "%+v", c.in)
+	}
+
+	uc.WithMailer(&alertMailerFake{})
+	_, err := uc.Create(context.Background(), AlertRuleInput{Moeda: "USD", Tipo: AlertAbove, Valor: 6, Canais: []string{AlertChannelEmail}, Email: "Compras <compras@exemplo.com>"})
+	assert.ErrorIs(t, err, ErrAlertEmail)
+
+	rule, err := uc.Create(context.Background(), AlertRuleInput{Moeda: "usd", Tipo: AlertBelow, Valor: 5})
+	require.NoError(t, err)
+	assert.Equal(t, "USD", rule.Moeda)
+	assert.Equal(t, 60, rule.CooldownMinutos)
+	assert.Equal(t, []string{AlertChannelWebhook}, rule.Canais)
+	assert.True(t, rule.Ativa)
+}
+
+func shouldFireBelowTargetOnceUntilItRearms(t *testing.T) {
+	uc, store, sink, _ := newAlertFixture(nil)
+	rule, err := uc.Create(keyContext("k1", ScopeHistoryRead), AlertRuleInput{Moeda: "USD", Tipo: AlertBelow, Valor: 5, CooldownMinutos: cooldown(0)})
+	require.NoError(t, err)
+
+	fired := 0
+	for _, cotacao := range []float64{5.2, 4.9, 4.8, 5.1, 4.95} {
+		n, err := uc.Evaluate(context.Background(), usd(cotacao))
+		require.NoError(t, err)
+		fired += n
+	}
+
+	assert.Equal(t, 2, fired)
+	require.Len(t, store.triggers, 2)
+	assert.Equal(t, 4.9, store.triggers[0].Cotacao)
+	assert.Equal(t, 4.95, store.triggers[1].Cotacao)
+	assert.Equal(t, []AlertNotification{{Canal: AlertChannelWebhook, Status: NotificationSent}}, store.triggers[0].Notificacoes)
+
+	require.Len(t, sink.published, 2)
+	event := sink.published[0]
+	assert.Equal(t, EventRateAlertTriggered, event.Tipo)
+	assert.Equal(t, "k1", event.APIKeyID)
+	assert.Equal(t, store.triggers[0].ID, event.ID)
+	var dados AlertTrigger
+	require.NoError(t, json.Unmarshal(event.Dados, &dados))
+	assert.Equal(t, rule.ID, dados.AlertaID)
+	assert.Contains(t, dados.Descricao, "USD abaixo de 5.0000")
+}
+
+func shouldHoldARearmedRuleUntilTheCooldownPasses(t *testing.T) {
+	uc, store, _, now := newAlertFixture(nil)
+	_, err := uc.Create(context.Background(), AlertRuleInput{Moeda: "USD", Tipo: AlertAbove, Valor: 5.5, CooldownMinutos: cooldown(60)})
+	require.NoError(t, err)
+
+	steps := []struct {
+		after   time.Duration
+		cotacao float64
+		fired   int
+	}{
+		{0, 5.6, 1},
+		{5 * time.Minute, 5.4, 0},
+		// Rearmada, mas ainda dentro do cooldown
+		{10 * time.Minute, 5.7, 0},
+		{61 * time.Minute, 5.8, 1},
+	}
+	start := *now
+	for _, s := range steps {
+		*now = start.Add(s.after)
+		n, err := uc.Evaluate(context.Background(), usd(s.cotacao))
+		require.NoError(t, err)
+		assert.Equal(t, s.fired, n, "%v", s.after)
+	}
+	assert.Len(t, store.triggers, 2)
+}
+
+func shouldFireOnPercentMoveWithinTheWindow(t *testing.T) {
+	base := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	uc, store, _, _ := newAlertFixture(seriesFake{
+		{Moeda: "USD", Cotacao: 5.30, Data: base.Add(-3 * time.Hour)},
+		{Moeda: "USD", Cotacao: 5.00, Data: base.Add(-50 * time.Minute)},
+		{Moeda: "USD", Cotacao: 4.95, Data: base.Add(-10 * time.Minute)},
+	})
+	_, err := uc.Create(context.Background(), AlertRuleInput{Moeda: "USD", Tipo: AlertMove, Valor: -2, JanelaMinutos: 60})
+	require.NoError(t, err)
+
+	n, err := uc.Evaluate(context.Background(), usd(4.92))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = uc.Evaluate(context.Background(), usd(4.85))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Len(t, store.triggers, 1)
+	assert.Equal(t, 5.0, store.triggers[0].Referencia)
+	assert.InDelta(t, -3.0, store.triggers[0].Variacao, 1e-9)
+}
+
+func shouldFireOnNewNDayLow(t *testing.T) {
+	base := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	uc, store, _, _ := newAlertFixture(seriesFake{
+		// Fora do período de 30 dias
+		{Moeda: "USD", Cotacao: 4.50, Data: base.AddDate(0, 0, -40)},
+		{Moeda: "USD", Cotacao: 4.90, Data: base.AddDate(0, 0, -20)},
+		{Moeda: "USD", Cotacao: 5.30, Data: base.AddDate(0, 0, -5)},
+		// A própria cotação já gravada pela coleta não conta
+		{Moeda: "USD", Cotacao: 4.85, Data: base},
+	})
+	_, err := uc.Create(context.Background(), AlertRuleInput{Moeda: "USD", Tipo: AlertLow, Dias: 30})
+	require.NoError(t, err)
+	_, err = uc.Create(context.Background(), AlertRuleInput{Moeda: "USD", Tipo: AlertHigh, Dias: 30})
+	require.NoError(t, err)
+
+	n, err := uc.Evaluate(context.Background(), usd(4.95))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = uc.Evaluate(context.Background(), usd(4.85))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Len(t, store.triggers, 1)
+	assert.Equal(t, AlertLow, store.triggers[0].Tipo)
+	assert.Equal(t, 4.90, store.triggers[0].Referencia)
+}
+
+func shouldSkipRepeatedQuoteUntilARuleChanges(t *testing.T) {
+	uc, store, _, _ := newAlertFixture(nil)
+
+	_, err := uc.Evaluate(context.Background(), usd(4.9))
+	require.NoError(t, err)
+	_, err = uc.Create(context.Background(), AlertRuleInput{Moeda: "USD", Tipo: AlertBelow, Valor: 5})
+	require.NoError(t, err)
+
+	// A regra nova esquece a última cotação: a repetida é avaliada
+	n, err := uc.Evaluate(context.Background(), usd(4.9))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// Aqui a repetida não chega a buscar as regras
+	rule := store.rules[store.triggers[0].AlertaID]
+	rule.Disparada = false
+	rule.UltimoDisparo = time.Time{}
+	store.rules[rule.ID] = rule
+	n, err = uc.Evaluate(context.Background(), usd(4.9))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func shouldRecordFailedEmailNotification(t *testing.T) {
+	uc, store, sink, _ := newAlertFixture(nil)
+	uc.WithMailer(&alertMailerFake{err: errors.New("connection refused")})
+	_, err := uc.Create(context.Background(), AlertRuleInput{
+		Moeda: "USD", Tipo: AlertBelow, Valor: 5,
+		Canais: []string{AlertChannelEmail, AlertChannelWebhook}, Email: "compras@exemplo.com",
+	})
+	require.NoError(t, err)
+
+	n, err := uc.Evaluate(context.Background(), usd(4.9))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, n)
+	require.Len(t, store.triggers, 1)
+	assert.Equal(t, []AlertNotification{
+		{Canal: AlertChannelEmail, Status: NotificationFailed, Erro: "connection refused"},
+		{Canal: AlertChannelWebhook, Status: NotificationSent},
+	}, store.triggers[0].Notificacoes)
+	assert.Len(t, sink.published, 1)
+}
+
+func shouldHideAlertsOfAnotherKey(t *testing.T) {
+	uc, store, _, _ := newAlertFixture(nil)
+	rule, err := uc.Create(keyContext("k1", ScopeHistoryRead), AlertRuleInput{Moeda: "USD", Tipo: AlertBelow, Valor: 5})
+	require.NoError(t, err)
+	_, err = uc.Evaluate(context.Background(), usd(4.9))
+	require.NoError(t, err)
+
+	other := keyContext("k2", ScopeHistoryRead)
+	_, err = uc.Get(other, rule.ID)
+	assert.ErrorIs(t, err, ErrAlertNotFound)
+	assert.ErrorIs(t, uc.Delete(other, rule.ID), ErrAlertNotFound)
+	_, err = uc.Update(other, rule.ID, AlertRuleInput{Moeda: "USD", Tipo: AlertBelow, Valor: 4})
+	assert.ErrorIs(t, err, ErrAlertNotFound)
+	history, err := uc.History(other, AlertTriggerFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	history, err = uc.History(keyContext("admin", ScopeAdmin), AlertTriggerFilter{})
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+	assert.Contains(t, store.rules, rule.ID)
+}
+
+type rateObserverFake struct {
+	quotes []RateQuote
+}
+
+func (o *rateObserverFake) Observe(q RateQuote) {
+	o.quotes = append(o.quotes, q)
+}
+
+func shouldObserveOnlySuccessfulQuotes(t *testing.T) {
+	provider := new(rateProviderMock)
+	provider.On("GetRate", "usd").Return(5.1, nil)
+	provider.On("GetRate", "EUR").Return(0.0, errors.New("timeout"))
+	observer := &rateObserverFake{}
+	watched := NewWatchedRateProvider(provider, observer)
+
+	watched.GetRate("usd")
+	watched.GetRate("EUR")
+	watched.GetQuote("usd")
+
+	require.Len(t, observer.quotes, 2)
+	assert.True(t, slices.ContainsFunc(observer.quotes, func(q RateQuote) bool { return q.Moeda == "USD" && q.Cotacao == 5.1 }))
+	assert.Equal(t, observer.quotes[0], observer.quotes[1])
+}
@@ -0,0 +1,358 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"go-frete/api/pkg/logger"
+)
+
+// Indicadores técnicos calculados sobre a série de cotações
+const (
+	IndicatorSMA        = "sma"
+	IndicatorEMA        = "ema"
+	IndicatorVolatility = "volatilidade"
+	IndicatorBollinger  = "bollinger"
+	IndicatorRSI        = "rsi"
+	IndicatorDrawdown   = "drawdown"
+	IndicatorReturn     = "retorno"
+)
+
+// Indicators são todos os indicadores, na ordem em que aparecem na resposta
+var Indicators = []string{IndicatorSMA, IndicatorEMA, IndicatorVolatility, IndicatorBollinger, IndicatorRSI, IndicatorDrawdown, IndicatorReturn}
+
+const (
+	DefaultAnalyticsWindow = 20
+	MaxAnalyticsWindow     = 500
+	DefaultBollingerK      = 2.0
+)
+
+var (
+	ErrInvalidIndicator = errors.New("indicador inválido: use " + strings.Join(Indicators, ", "))
+	ErrInvalidWindow    = fmt.Errorf("janela deve ficar entre 2 e %d pontos", MaxAnalyticsWindow)
+	ErrInvalidBollinger = errors.New("k das bandas de Bollinger deve ser maior que zero")
+)
+
+// AnalyticsRequest escolhe o período, os indicadores e a janela em pontos da série
+type AnalyticsRequest struct {
+	Moeda string
+	// Sem To usa agora; sem From, os DefaultSeriesWindow anteriores a To
+	From, To time.Time
+	// Vazio calcula todos
+	Indicadores []string
+	// Pontos usados por média móvel, volatilidade, Bollinger e RSI (padrão 20)
+	Janela int
+	// Desvios padrão entre a média e cada banda de Bollinger (padrão 2)
+	K float64
+}
+
+// IndicatorPoint é um ponto da série com os indicadores pedidos. Um indicador
+// fica de fora enquanto a série ainda não tem pontos suficientes para a janela.
+type IndicatorPoint struct {
+	Data    time.Time `json:"data"`
+	Cotacao float64   `json:"cotacao"`
+	SMA     *float64  `json:"sma,omitempty"`
+	EMA     *float64  `json:"ema,omitempty"`
+	// Desvio padrão dos retornos logarítmicos da janela, em %
+	Volatilidade      *float64 `json:"volatilidade,omitempty"`
+	BollingerSuperior *float64 `json:"bollinger_superior,omitempty"`
+	BollingerInferior *float64 `json:"bollinger_inferior,omitempty"`
+	RSI               *float64 `json:"rsi,omitempty"`
+	// Queda em % desde a maior cotação do período até o ponto
+	Drawdown *float64 `json:"drawdown,omitempty"`
+	// Variação em % desde a primeira cotação do período
+	RetornoAcumulado *float64 `json:"retorno_acumulado,omitempty"`
+}
+
+// IndicatorSummary traz o último valor de cada indicador e o pior drawdown
+type IndicatorSummary struct {
+	SMA              *float64 `json:"sma,omitempty"`
+	EMA              *float64 `json:"ema,omitempty"`
+	Volatilidade     *float64 `json:"volatilidade,omitempty"`
+	RSI              *float64 `json:"rsi,omitempty"`
+	DrawdownMaximo   *float64 `json:"drawdown_maximo,omitempty"`
+	RetornoAcumulado *float64 `json:"retorno_acumulado,omitempty"`
+}
+
+type RateAnalytics struct {
+	Moeda       string           `json:"moeda"`
+	De          time.Time        `json:"de"`
+	Ate         time.Time        `json:"ate"`
+	Indicadores []string         `json:"indicadores"`
+	Janela      int              `json:"janela"`
+	K           float64          `json:"k,omitempty"`
+	Amostras    int              `json:"amostras"`
+	Resumo      IndicatorSummary `json:"resumo"`
+	Pontos      []IndicatorPoint `json:"pontos"`
+}
+
+// AnalyticsUseCase calcula indicadores técnicos sobre a série coletada, com
+// a mesma série (só dias úteis) da variação e das estatísticas
+type AnalyticsUseCase struct {
+	repo      RateSeriesReader
+	calendars CurrencyCalendars
+	log       logger.Logger
+}
+
+func NewAnalyticsUseCase(r RateSeriesReader, l logger.Logger) *AnalyticsUseCase {
+	return &AnalyticsUseCase{repo: r, log: l}
+}
+
+// WithCalendars descarta da série as cotações de fins de semana e feriados
+func (uc *AnalyticsUseCase) WithCalendars(c CurrencyCalendars) *AnalyticsUseCase {
+	uc.calendars = c
+	return uc
+}
+
+func (uc *AnalyticsUseCase) Execute(ctx context.Context, req AnalyticsRequest) (RateAnalytics, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	if req.To.IsZero() {
+		req.To = time.Now()
+	}
+	if req.From.IsZero() {
+		req.From = req.To.Add(-DefaultSeriesWindow)
+	}
+	if req.From.After(req.To) {
+		return RateAnalytics{}, ErrInvalidPeriod
+	}
+	if req.Janela == 0 {
+		req.Janela = DefaultAnalyticsWindow
+	}
+	if req.Janela < 2 || req.Janela > MaxAnalyticsWindow {
+		return RateAnalytics{}, ErrInvalidWindow
+	}
+	if req.K == 0 {
+		req.K = DefaultBollingerK
+	}
+	if req.K < 0 {
+		return RateAnalytics{}, ErrInvalidBollinger
+	}
+	indicadores, err := normalizeIndicators(req.Indicadores)
+	if err != nil {
+		return RateAnalytics{}, err
+	}
+
+	moeda := strings.ToUpper(req.Moeda)
+	series, err := businessSeries(uc.repo, uc.calendars, moeda, req.From, req.To)
+	if err != nil {
+		log.Error("Falha ao buscar série de cotações", "erro", err.Error(), "moeda", moeda)
+		return RateAnalytics{}, err
+	}
+	if len(series) == 0 {
+		return RateAnalytics{}, ErrNoRates
+	}
+
+	out := RateAnalytics{
+		Moeda:       moeda,
+		De:          req.From,
+		Ate:         req.To,
+		Indicadores: indicadores,
+		Janela:      req.Janela,
+		Amostras:    len(series),
+		Pontos:      make([]IndicatorPoint, len(series)),
+	}
+	cotacoes := make([]float64, len(series))
+	for i, s := range series {
+		cotacoes[i] = s.Cotacao
+		out.Pontos[i] = IndicatorPoint{Data: s.Data, Cotacao: s.Cotacao}
+	}
+
+	n := req.Janela
+	for _, ind := range indicadores {
+		switch ind {
+		case IndicatorSMA:
+			fill(out.Pontos, SimpleMovingAverage(cotacoes, n), func(p *IndicatorPoint, v *float64) { p.SMA = v })
+			out.Resumo.SMA = out.Pontos[len(series)-1].SMA
+		case IndicatorEMA:
+			fill(out.Pontos, ExponentialMovingAverage(cotacoes, n), func(p *IndicatorPoint, v *float64) { p.EMA = v })
+			out.Resumo.EMA = out.Pontos[len(series)-1].EMA
+		case IndicatorVolatility:
+			fill(out.Pontos, RollingVolatility(cotacoes, n), func(p *IndicatorPoint, v *float64) { p.Volatilidade = v })
+			out.Resumo.Volatilidade = out.Pontos[len(series)-1].Volatilidade
+		case IndicatorBollinger:
+			out.K = req.K
+			upper, lower := BollingerBands(cotacoes, n, req.K)
+			fill(out.Pontos, upper, func(p *IndicatorPoint, v *float64) { p.BollingerSuperior = v })
+			fill(out.Pontos, lower, func(p *IndicatorPoint, v *float64) { p.BollingerInferior = v })
+		case IndicatorRSI:
+			fill(out.Pontos, RelativeStrengthIndex(cotacoes, n), func(p *IndicatorPoint, v *float64) { p.RSI = v })
+			out.Resumo.RSI = out.Pontos[len(series)-1].RSI
+		case IndicatorDrawdown:
+			drawdown := Drawdown(cotacoes)
+			fill(out.Pontos, drawdown, func(p *IndicatorPoint, v *float64) { p.Drawdown = v })
+			worst := slices.Min(drawdown)
+			out.Resumo.DrawdownMaximo = &worst
+		case IndicatorReturn:
+			fill(out.Pontos, CumulativeReturn(cotacoes), func(p *IndicatorPoint, v *float64) { p.RetornoAcumulado = v })
+			out.Resumo.RetornoAcumulado = out.Pontos[len(series)-1].RetornoAcumulado
+		}
+	}
+
+	log.Info("Indicadores calculados", "moeda", moeda, "amostras", out.Amostras, "indicadores", indicadores, "janela", n)
+	return out, nil
+}
+
+// normalizeIndicators valida a lista e a devolve sem repetições, na ordem de Indicators
+func normalizeIndicators(pedidos []string) ([]string, error) {
+	if len(pedidos) == 0 {
+		return slices.Clone(Indicators), nil
+	}
+	for _, p := range pedidos {
+		if !slices.Contains(Indicators, p) {
+			return nil, ErrInvalidIndicator
+		}
+	}
+	var out []string
+	for _, ind := range Indicators {
+		if slices.Contains(pedidos, ind) {
+			out = append(out, ind)
+		}
+	}
+	return out, nil
+}
+
+// fill grava nos pontos os valores definidos (não NaN) da série do indicador
+func fill(pontos []IndicatorPoint, values []float64, set func(*IndicatorPoint, *float64)) {
+	for i, v := range values {
+		if !math.IsNaN(v) {
+			set(&pontos[i], &v)
+		}
+	}
+}
+
+// As funções abaixo devolvem uma série do mesmo tamanho da entrada, com NaN
+// nos pontos em que a janela ainda não está completa.
+
+func nanSeries(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
+
+// SimpleMovingAverage é a média das últimas n cotações
+func SimpleMovingAverage(values []float64, n int) []float64 {
+	out := nanSeries(len(values))
+	var soma float64
+	for i, v := range values {
+		soma += v
+		if i >= n {
+			soma -= values[i-n]
+		}
+		if i >= n-1 {
+			out[i] = soma / float64(n)
+		}
+	}
+	return out
+}
+
+// ExponentialMovingAverage usa alfa 2/(n+1), começando pela média simples dos
+// primeiros n pontos
+func ExponentialMovingAverage(values []float64, n int) []float64 {
+	out := nanSeries(len(values))
+	if len(values) < n {
+		return out
+	}
+	alpha := 2 / float64(n+1)
+	out[n-1] = SimpleMovingAverage(values[:n], n)[n-1]
+	for i := n; i < len(values); i++ {
+		out[i] = alpha*values[i] + (1-alpha)*out[i-1]
+	}
+	return out
+}
+
+// RollingVolatility é o desvio padrão amostral, em %, dos n últimos retornos
+// logarítmicos. Não é anualizada: o intervalo entre os pontos depende da coleta.
+func RollingVolatility(values []float64, n int) []float64 {
+	out := nanSeries(len(values))
+	returns := logReturns(values)
+	for i := n; i < len(values); i++ {
+		out[i] = sampleStdDev(returns[i-n:i]) * 100
+	}
+	return out
+}
+
+// BollingerBands são a média simples mais e menos k desvios padrão
+// populacionais das mesmas n cotações
+func BollingerBands(values []float64, n int, k float64) (upper, lower []float64) {
+	upper, lower = nanSeries(len(values)), nanSeries(len(values))
+	sma := SimpleMovingAverage(values, n)
+	for i := n - 1; i < len(values); i++ {
+		var quadrados float64
+		for _, v := range values[i-n+1 : i+1] {
+			quadrados += (v - sma[i]) * (v - sma[i])
+		}
+		desvio := math.Sqrt(quadrados / float64(n))
+		upper[i], lower[i] = sma[i]+k*desvio, sma[i]-k*desvio
+	}
+	return upper, lower
+}
+
+// RelativeStrengthIndex é o RSI de Wilder: médias de altas e quedas das n
+// primeiras variações e, depois, suavizadas por (média*(n-1)+atual)/n
+func RelativeStrengthIndex(values []float64, n int) []float64 {
+	out := nanSeries(len(values))
+	if len(values) <= n {
+		return out
+	}
+	var alta, queda float64
+	for i := 1; i <= n; i++ {
+		d := values[i] - values[i-1]
+		alta += max(d, 0)
+		queda += max(-d, 0)
+	}
+	alta, queda = alta/float64(n), queda/float64(n)
+	out[n] = rsi(alta, queda)
+	for i := n + 1; i < len(values); i++ {
+		d := values[i] - values[i-1]
+		alta = (alta*float64(n-1) + max(d, 0)) / float64(n)
+		queda = (queda*float64(n-1) + max(-d, 0)) / float64(n)
+		out[i] = rsi(alta, queda)
+	}
+	return out
+}
+
+func rsi(alta, queda float64) float64 {
+	if queda == 0 {
+		if alta == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+alta/queda)
+}
+
+// Drawdown é a distância em % (zero ou negativa) até a maior cotação anterior
+func Drawdown(values []float64) []float64 {
+	out := make([]float64, len(values))
+	pico := math.Inf(-1)
+	for i, v := range values {
+		pico = max(pico, v)
+		out[i] = (v - pico) / pico * 100
+	}
+	return out
+}
+
+// CumulativeReturn é a variação em % desde o primeiro ponto
+func CumulativeReturn(values []float64) []float64 {
+	out := make([]float64, len(values))
+	for i, v := range values {
+		out[i] = (v/values[0] - 1) * 100
+	}
+	return out
+}
+
+func sum(values []float64) float64 {
+	var s float64
+	for _, v := range values {
+		s += v
+	}
+	return s
+}
@@ -0,0 +1,222 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"go-frete/api/pkg/logger"
+)
+
+const (
+	MaxCompareCurrencies = 10
+	// O coletor grava todas as moedas com o mesmo instante; minuto a minuto
+	// basta para alinhá-las. Séries importadas pelo backfill pedem 24h.
+	DefaultCompareInterval = time.Minute
+	MaxCompareInterval     = 7 * 24 * time.Hour
+)
+
+var (
+	ErrCompareCurrencies = fmt.Errorf("informe de 2 a %d moedas", MaxCompareCurrencies)
+	ErrInvalidInterval   = errors.New("intervalo de alinhamento deve ficar entre 1 minuto e 7 dias")
+	ErrNoCommonRates     = errors.New("as moedas não têm cotações em instantes comuns no período")
+)
+
+type CompareRequest struct {
+	Moedas []string
+	// Sem To usa agora; sem From, os DefaultSeriesWindow anteriores a To
+	From, To time.Time
+	// Largura dos intervalos em que as séries são alinhadas (padrão 1 minuto)
+	Intervalo time.Duration
+}
+
+// ComparisonPoint é um instante em que todas as moedas têm cotação
+type ComparisonPoint struct {
+	Data     time.Time          `json:"data"`
+	Cotacoes map[string]float64 `json:"cotacoes"`
+	// Cotação rebaseada: 100 no primeiro instante comum
+	Desempenho map[string]float64 `json:"desempenho"`
+}
+
+type CurrencyPerformance struct {
+	Moeda string `json:"moeda"`
+	// Variação em % entre o primeiro e o último instante comum
+	RetornoPercentual float64 `json:"retorno_percentual"`
+	// Desvio padrão amostral, em %, dos retornos logarítmicos entre instantes comuns
+	Volatilidade *float64 `json:"volatilidade,omitempty"`
+	// Valorização em % frente a cada uma das outras moedas (variação da cotação cruzada)
+	Relativo map[string]float64 `json:"relativo"`
+}
+
+type RateComparison struct {
+	Moedas           []string  `json:"moedas"`
+	De               time.Time `json:"de"`
+	Ate              time.Time `json:"ate"`
+	IntervaloMinutos int       `json:"intervalo_minutos"`
+	Amostras         int       `json:"amostras"`
+	// Correlação de Pearson dos retornos logarítmicos; null quando uma das
+	// séries não varia ou há menos de três instantes comuns
+	Correlacao map[string]map[string]*float64 `json:"correlacao"`
+	Retornos   []CurrencyPerformance          `json:"retornos"`
+	Pontos     []ComparisonPoint              `json:"pontos"`
+}
+
+// Compare alinha as séries das moedas nos instantes em que todas têm cotação e
+// compara desempenho, retornos e correlação
+func (uc *AnalyticsUseCase) Compare(ctx context.Context, req CompareRequest) (RateComparison, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	if req.To.IsZero() {
+		req.To = time.Now()
+	}
+	if req.From.IsZero() {
+		req.From = req.To.Add(-DefaultSeriesWindow)
+	}
+	if req.From.After(req.To) {
+		return RateComparison{}, ErrInvalidPeriod
+	}
+	if req.Intervalo == 0 {
+		req.Intervalo = DefaultCompareInterval
+	}
+	if req.Intervalo < time.Minute || req.Intervalo > MaxCompareInterval {
+		return RateComparison{}, ErrInvalidInterval
+	}
+	var moedas []string
+	for _, m := range req.Moedas {
+		if m = strings.ToUpper(m); !slices.Contains(moedas, m) {
+			moedas = append(moedas, m)
+		}
+	}
+	if len(moedas) < 2 || len(moedas) > MaxCompareCurrencies {
+		return RateComparison{}, ErrCompareCurrencies
+	}
+
+	// Última cotação de cada moeda em cada intervalo
+	buckets := make([]map[time.Time]float64, len(moedas))
+	for i, moeda := range moedas {
+		series, err := businessSeries(uc.repo, uc.calendars, moeda, req.From, req.To)
+		if err != nil {
+			log.Error("Falha ao buscar série de cotações", "erro", err.Error(), "moeda", moeda)
+			return RateComparison{}, err
+		}
+		if len(series) == 0 {
+			return RateComparison{}, fmt.Errorf("%w: %s", ErrNoRates, moeda)
+		}
+		buckets[i] = make(map[time.Time]float64, len(series))
+		for _, s := range series {
+			buckets[i][s.Data.Truncate(req.Intervalo)] = s.Cotacao
+		}
+	}
+
+	var common []time.Time
+	for t := range buckets[0] {
+		if !slices.ContainsFunc(buckets[1:], func(b map[time.Time]float64) bool { _, ok := b[t]; return !ok }) {
+			common = append(common, t)
+		}
+	}
+	if len(common) == 0 {
+		return RateComparison{}, ErrNoCommonRates
+	}
+	slices.SortFunc(common, time.Time.Compare)
+
+	// aligned[i] é a série da moeda i nos instantes comuns
+	aligned := make([][]float64, len(moedas))
+	for i := range moedas {
+		aligned[i] = make([]float64, len(common))
+		for j, t := range common {
+			aligned[i][j] = buckets[i][t]
+		}
+	}
+
+	out := RateComparison{
+		Moedas:           moedas,
+		De:               req.From,
+		Ate:              req.To,
+		IntervaloMinutos: int(req.Intervalo / time.Minute),
+		Amostras:         len(common),
+		Correlacao:       make(map[string]map[string]*float64, len(moedas)),
+		Retornos:         make([]CurrencyPerformance, len(moedas)),
+		Pontos:           make([]ComparisonPoint, len(common)),
+	}
+	for j, t := range common {
+		p := ComparisonPoint{Data: t, Cotacoes: make(map[string]float64, len(moedas)), Desempenho: make(map[string]float64, len(moedas))}
+		for i, moeda := range moedas {
+			p.Cotacoes[moeda] = aligned[i][j]
+			p.Desempenho[moeda] = aligned[i][j] / aligned[i][0] * 100
+		}
+		out.Pontos[j] = p
+	}
+
+	growth := make([]float64, len(moedas))
+	returns := make([][]float64, len(moedas))
+	for i, serie := range aligned {
+		growth[i] = serie[len(serie)-1] / serie[0]
+		returns[i] = logReturns(serie)
+	}
+	for i, moeda := range moedas {
+		perf := CurrencyPerformance{Moeda: moeda, RetornoPercentual: (growth[i] - 1) * 100, Relativo: make(map[string]float64, len(moedas)-1)}
+		if len(returns[i]) >= 2 {
+			vol := sampleStdDev(returns[i]) * 100
+			perf.Volatilidade = &vol
+		}
+		out.Correlacao[moeda] = make(map[string]*float64, len(moedas))
+		for k, outra := range moedas {
+			if k != i {
+				// A cotação cruzada moeda/outra é a razão das cotações em BRL
+				perf.Relativo[outra] = (growth[i]/growth[k] - 1) * 100
+			}
+			if c := pearson(returns[i], returns[k]); !math.IsNaN(c) {
+				out.Correlacao[moeda][outra] = &c
+			} else {
+				out.Correlacao[moeda][outra] = nil
+			}
+		}
+		out.Retornos[i] = perf
+	}
+
+	log.Info("Moedas comparadas", "moedas", moedas, "amostras", out.Amostras, "intervalo", req.Intervalo.String())
+	return out, nil
+}
+
+// logReturns devolve ln(v[i]/v[i-1]) de cada par consecutivo
+func logReturns(values []float64) []float64 {
+	if len(values) < 2 {
+		return nil
+	}
+	out := make([]float64, len(values)-1)
+	for i := 1; i < len(values); i++ {
+		out[i-1] = math.Log(values[i] / values[i-1])
+	}
+	return out
+}
+
+func sampleStdDev(values []float64) float64 {
+	mean := sum(values) / float64(len(values))
+	var quadrados float64
+	for _, v := range values {
+		quadrados += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(quadrados / float64(len(values)-1))
+}
+
+// pearson é NaN com menos de dois pares ou quando uma das séries é constante
+func pearson(x, y []float64) float64 {
+	if len(x) < 2 {
+		return math.NaN()
+	}
+	mx, my := sum(x)/float64(len(x)), sum(y)/float64(len(y))
+	var cov, vx, vy float64
+	for i := range x {
+		cov += (x[i] - mx) * (y[i] - my)
+		vx += (x[i] - mx) * (x[i] - mx)
+		vy += (y[i] - my) * (y[i] - my)
+	}
+	if vx == 0 || vy == 0 {
+		return math.NaN()
+	}
+	return max(-1, min(1, cov/math.Sqrt(vx*vy)))
+}
@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyticsUseCase_Compare(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should align series on common timestamps",
+			run:  shouldAlignSeriesOnCommonTimestamps,
+		},
+		{
+			name: "should reject invalid compare request",
+			run:  shouldRejectInvalidCompareRequest,
+		},
+		{
+			name: "should return no common rates error when series do not overlap",
+			run:  shouldReturnNoCommonRatesErrorWhenSeriesDoNotOverlap,
+		},
+		{
+			name: "should return no rates error for currency without series",
+			run:  shouldReturnNoRatesErrorForCurrencyWithoutSeries,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldAlignSeriesOnCommonTimestamps(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+
+	t0 := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	searcherMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return([]RateSnapshot{
+		{Moeda: "USD", Cotacao: 5, Data: t0},
+		{Moeda: "USD", Cotacao: 9, Data: t0.Add(15 * time.Minute)},
+		{Moeda: "USD", Cotacao: 5.25, Data: t0.Add(30 * time.Minute)},
+		{Moeda: "USD", Cotacao: 5.5, Data: t0.Add(45 * time.Minute)},
+	}, nil)
+	// Gravadas alguns segundos depois, no mesmo minuto
+	searcherMock.On("GetRateSeries", "EUR", mock.Anything, mock.Anything).Return([]RateSnapshot{
+		{Moeda: "EUR", Cotacao: 6, Data: t0.Add(2 * time.Second)},
+		{Moeda: "EUR", Cotacao: 6.6, Data: t0.Add(30*time.Minute + 2*time.Second)},
+		{Moeda: "EUR", Cotacao: 6.3, Data: t0.Add(45*time.Minute + 2*time.Second)},
+	}, nil)
+
+	uc := NewAnalyticsUseCase(searcherMock, loggerMock)
+	out, err := uc.Compare(context.Background(), CompareRequest{Moedas: []string{"usd", "EUR", "USD"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"USD", "EUR"}, out.Moedas)
+	assert.Equal(t, 1, out.IntervaloMinutos)
+	assert.Equal(t, 3, out.Amostras)
+	require.Len(t, out.Pontos, 3)
+	assert.Equal(t, t0, out.Pontos[0].Data)
+	assert.Equal(t, map[string]float64{"USD": 100, "EUR": 100}, out.Pontos[0].Desempenho)
+	assert.Equal(t, 5.25, out.Pontos[1].Cotacoes["USD"])
+	assert.InDelta(t, 105, out.Pontos[2].Desempenho["EUR"], 0.0001)
+
+	usd, eur := out.Retornos[0], out.Retornos[1]
+	assert.InDelta(t, 10, usd.RetornoPercentual, 0.0001)
+	assert.InDelta(t, 5, eur.RetornoPercentual, 0.0001)
+	assert.InDelta(t, 4.7619, usd.Relativo["EUR"], 0.0001)
+	assert.InDelta(t, -4.5455, eur.Relativo["USD"], 0.0001)
+	assert.NotContains(t, usd.Relativo, "USD")
+	require.NotNil(t, usd.Volatilidade)
+
+	require.NotNil(t, out.Correlacao["USD"]["EUR"])
+	assert.InDelta(t, 1, *out.Correlacao["USD"]["EUR"], 0.0001)
+	assert.Equal(t, out.Correlacao["USD"]["EUR"], out.Correlacao["EUR"]["USD"])
+	assert.InDelta(t, 1, *out.Correlacao["EUR"]["EUR"], 0.0001)
+}
+
+func shouldRejectInvalidCompareRequest(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+	uc := NewAnalyticsUseCase(searcherMock, loggerMock)
+
+	many := []string{"USD", "EUR", "GBP", "CNY", "JPY", "CHF", "CAD", "AUD", "ARS", "MXN", "CLP"}
+	for _, tc := range []struct {
+		req CompareRequest
+		err error
+	}{
+		{CompareRequest{Moedas: []string{"USD"}}, ErrCompareCurrencies},
+		{CompareRequest{Moedas: []string{"usd", "USD"}}, ErrCompareCurrencies},
+		{CompareRequest{Moedas: many}, ErrCompareCurrencies},
+		{CompareRequest{Moedas: []string{"USD", "EUR"}, Intervalo: 30 * time.Second}, ErrInvalidInterval},
+		{CompareRequest{Moedas: []string{"USD", "EUR"}, Intervalo: 8 * 24 * time.Hour}, ErrInvalidInterval},
+		{CompareRequest{Moedas: []string{"USD", "EUR"}, From: time.Now(), To: time.Now().Add(-time.Hour)}, ErrInvalidPeriod},
+	} {
+		_, err := uc.Compare(context.Background(), tc.req)
+		assert.ErrorIs(t, err, tc.err)
+	}
+	searcherMock.AssertNotCalled(t, "GetRateSeries", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func shouldReturnNoCommonRatesErrorWhenSeriesDoNotOverlap(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	day := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	searcherMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return([]RateSnapshot{{Moeda: "USD", Cotacao: 5, Data: day.Add(18 * time.Hour)}}, nil)
+	searcherMock.On("GetRateSeries", "EUR", mock.Anything, mock.Anything).Return([]RateSnapshot{{Moeda: "EUR", Cotacao: 6, Data: day.Add(17 * time.Hour)}}, nil)
+
+	uc := NewAnalyticsUseCase(searcherMock, loggerMock)
+	_, err := uc.Compare(context.Background(), CompareRequest{Moedas: []string{"USD", "EUR"}})
+	assert.ErrorIs(t, err, ErrNoCommonRates)
+
+	// Fechamentos diários em horários diferentes se alinham com intervalo de um dia
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	out, err := uc.Compare(context.Background(), CompareRequest{Moedas: []string{"USD", "EUR"}, Intervalo: 24 * time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, 1, out.Amostras)
+	assert.Equal(t, day, out.Pontos[0].Data)
+	assert.Nil(t, out.Correlacao["USD"]["EUR"])
+}
+
+func shouldReturnNoRatesErrorForCurrencyWithoutSeries(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	searcherMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return([]RateSnapshot{{Moeda: "USD", Cotacao: 5}}, nil)
+	searcherMock.On("GetRateSeries", "CNY", mock.Anything, mock.Anything).Return([]RateSnapshot{}, nil)
+
+	uc := NewAnalyticsUseCase(searcherMock, loggerMock)
+	_, err := uc.Compare(context.Background(), CompareRequest{Moedas: []string{"USD", "CNY"}})
+
+	assert.ErrorIs(t, err, ErrNoRates)
+	assert.ErrorContains(t, err, "CNY")
+}
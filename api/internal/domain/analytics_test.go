@@ -0,0 +1,204 @@
+package domain
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndicators(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should calculate moving averages from full windows",
+			run:  shouldCalculateMovingAveragesFromFullWindows,
+		},
+		{
+			name: "should calculate wilder rsi",
+			run:  shouldCalculateWilderRSI,
+		},
+		{
+			name: "should calculate volatility and bollinger bands",
+			run:  shouldCalculateVolatilityAndBollingerBands,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldCalculateMovingAveragesFromFullWindows(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5}
+
+	sma := SimpleMovingAverage(values, 3)
+	ema := ExponentialMovingAverage(values, 3)
+
+	assert.True(t, math.IsNaN(sma[1]))
+	assert.Equal(t, []float64{2, 3, 4}, sma[2:])
+	assert.True(t, math.IsNaN(ema[1]))
+	assert.Equal(t, []float64{2, 3, 4}, ema[2:])
+	assert.Equal(t, []float64{100, 100}, RelativeStrengthIndex(values, 3)[3:])
+}
+
+func shouldCalculateWilderRSI(t *testing.T) {
+	rsi := RelativeStrengthIndex([]float64{10, 11, 10, 12, 11}, 2)
+
+	assert.True(t, math.IsNaN(rsi[1]))
+	assert.InDelta(t, 50, rsi[2], 0.0001)
+	assert.InDelta(t, 83.3333, rsi[3], 0.0001)
+	assert.InDelta(t, 50, rsi[4], 0.0001)
+	assert.Equal(t, 50.0, RelativeStrengthIndex([]float64{5, 5, 5}, 2)[2])
+}
+
+func shouldCalculateVolatilityAndBollingerBands(t *testing.T) {
+	values := []float64{10, 11, 10, 12, 11}
+
+	vol := RollingVolatility(values, 2)
+	upper, lower := BollingerBands(values, 2, 2)
+
+	assert.True(t, math.IsNaN(vol[1]))
+	assert.InDelta(t, 13.4789, vol[2], 0.0001)
+	assert.InDelta(t, 11.5, upper[1], 0.0001)
+	assert.InDelta(t, 9.5, lower[1], 0.0001)
+	assert.InDelta(t, -9.0909, Drawdown(values)[2], 0.0001)
+	assert.InDelta(t, 10, CumulativeReturn(values)[4], 0.0001)
+}
+
+func TestAnalyticsUseCase_Execute(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should calculate all indicators for series",
+			run:  shouldCalculateAllIndicatorsForSeries,
+		},
+		{
+			name: "should calculate only requested indicators",
+			run:  shouldCalculateOnlyRequestedIndicators,
+		},
+		{
+			name: "should reject invalid analytics request",
+			run:  shouldRejectInvalidAnalyticsRequest,
+		},
+		{
+			name: "should return no rates error for empty analytics period",
+			run:  shouldReturnNoRatesErrorForEmptyAnalyticsPeriod,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func analyticsSeries(from time.Time, cotacoes ...float64) []RateSnapshot {
+	series := make([]RateSnapshot, len(cotacoes))
+	for i, c := range cotacoes {
+		series[i] = RateSnapshot{Moeda: "USD", Cotacao: c, Data: from.Add(time.Duration(i) * time.Hour)}
+	}
+	return series
+}
+
+func shouldCalculateAllIndicatorsForSeries(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+
+	from := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	searcherMock.On("GetRateSeries", "USD", from, to).Return(analyticsSeries(from, 10, 11, 10, 12, 11), nil)
+
+	uc := NewAnalyticsUseCase(searcherMock, loggerMock)
+	out, err := uc.Execute(context.Background(), AnalyticsRequest{Moeda: "usd", From: from, To: to, Janela: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, "USD", out.Moeda)
+	assert.Equal(t, Indicators, out.Indicadores)
+	assert.Equal(t, 5, out.Amostras)
+	assert.Equal(t, DefaultBollingerK, out.K)
+	require.Len(t, out.Pontos, 5)
+
+	first := out.Pontos[0]
+	assert.Nil(t, first.SMA)
+	assert.Nil(t, first.RSI)
+	assert.Equal(t, 0.0, *first.Drawdown)
+	assert.Equal(t, 0.0, *first.RetornoAcumulado)
+
+	last := out.Pontos[4]
+	assert.Equal(t, 11.5, *last.SMA)
+	assert.InDelta(t, 50, *last.RSI, 0.0001)
+	assert.InDelta(t, -8.3333, *last.Drawdown, 0.0001)
+	assert.NotNil(t, last.EMA)
+	assert.NotNil(t, last.Volatilidade)
+	assert.NotNil(t, last.BollingerSuperior)
+
+	assert.Equal(t, last.SMA, out.Resumo.SMA)
+	assert.InDelta(t, -9.0909, *out.Resumo.DrawdownMaximo, 0.0001)
+	assert.InDelta(t, 10, *out.Resumo.RetornoAcumulado, 0.0001)
+	searcherMock.AssertExpectations(t)
+}
+
+func shouldCalculateOnlyRequestedIndicators(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+
+	from := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	searcherMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return(analyticsSeries(from, 1, 2, 3, 4, 5), nil)
+
+	uc := NewAnalyticsUseCase(searcherMock, loggerMock)
+	out, err := uc.Execute(context.Background(), AnalyticsRequest{Moeda: "USD", Indicadores: []string{"rsi", "sma", "rsi"}, Janela: 3})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{IndicatorSMA, IndicatorRSI}, out.Indicadores)
+	assert.Equal(t, DefaultSeriesWindow, out.Ate.Sub(out.De))
+	assert.Zero(t, out.K)
+	assert.Equal(t, 4.0, *out.Pontos[4].SMA)
+	assert.Equal(t, 100.0, *out.Resumo.RSI)
+	assert.Nil(t, out.Pontos[4].EMA)
+	assert.Nil(t, out.Pontos[4].Drawdown)
+	assert.Nil(t, out.Resumo.DrawdownMaximo)
+}
+
+func shouldRejectInvalidAnalyticsRequest(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+	uc := NewAnalyticsUseCase(searcherMock, loggerMock)
+
+	for _, tc := range []struct {
+		req AnalyticsRequest
+		err error
+	}{
+		{AnalyticsRequest{Moeda: "USD", Indicadores: []string{"macd"}}, ErrInvalidIndicator},
+		{AnalyticsRequest{Moeda: "USD", Janela: 1}, ErrInvalidWindow},
+		{AnalyticsRequest{Moeda: "USD", Janela: MaxAnalyticsWindow + 1}, ErrInvalidWindow},
+		{AnalyticsRequest{Moeda: "USD", K: -1}, ErrInvalidBollinger},
+		{AnalyticsRequest{Moeda: "USD", From: time.Now(), To: time.Now().Add(-time.Hour)}, ErrInvalidPeriod},
+	} {
+		_, err := uc.Execute(context.Background(), tc.req)
+		assert.ErrorIs(t, err, tc.err)
+	}
+	searcherMock.AssertNotCalled(t, "GetRateSeries", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func shouldReturnNoRatesErrorForEmptyAnalyticsPeriod(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+	searcherMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return([]RateSnapshot{}, nil)
+
+	uc := NewAnalyticsUseCase(searcherMock, loggerMock)
+	_, err := uc.Execute(context.Background(), AnalyticsRequest{Moeda: "USD"})
+
+	assert.ErrorIs(t, err, ErrNoRates)
+}
@@ -0,0 +1,260 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"go-frete/api/pkg/logger"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Escopos que uma chave de API pode receber
+const (
+	ScopeConvertWrite = "convert:write"
+	ScopeHistoryRead  = "history:read"
+	ScopeAdmin        = "admin"
+)
+
+// Prefixo das chaves geradas, facilita identificá-las em logs e varreduras de segredo
+const apiKeyPrefix = "gf_"
+
+var (
+	ErrAPIKeyNotFound = errors.New("chave de API não encontrada")
+	ErrInvalidAPIKey  = errors.New("chave de API inválida ou revogada")
+	ErrInvalidScope   = errors.New("escopo inválido")
+	ErrAPIKeyName     = errors.New("nome da chave deve ser informado")
+)
+
+var validScopes = []string{ScopeConvertWrite, ScopeHistoryRead, ScopeAdmin}
+
+// APIKey é a credencial de um cliente interno. Só o hash da chave é persistido.
+type APIKey struct {
+	ID        string     `bson:"_id" json:"id"`
+	Name      string     `bson:"name" json:"name"`
+	KeyHash   string     `bson:"key_hash" json:"-"`
+	Scopes    []string   `bson:"scopes" json:"scopes"`
+	Plan      string     `bson:"plan,omitempty" json:"plan,omitempty"`
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+	RevokedAt *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
+
+// HasScope considera que o escopo admin concede todos os outros
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope) || slices.Contains(k.Scopes, ScopeAdmin)
+}
+
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+type APIKeyRepository interface {
+	SaveAPIKey(key APIKey) error
+	GetAPIKeyByHash(hash string) (*APIKey, error)
+	GetAPIKeyByID(id string) (*APIKey, error)
+	ListAPIKeys() ([]APIKey, error)
+	RevokeAPIKey(id string, at time.Time) error
+}
+
+type APIKeyUseCase struct {
+	repo APIKeyRepository
+	log  logger.Logger
+}
+
+func NewAPIKeyUseCase(r APIKeyRepository, l logger.Logger) *APIKeyUseCase {
+	return &APIKeyUseCase{repo: r, log: l}
+}
+
+// HashAPIKey é o que fica salvo no banco; a chave em texto puro só é mostrada na criação
+func HashAPIKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create gera uma nova chave e devolve o texto puro junto, que não poderá ser recuperado depois
+func (uc *APIKeyUseCase) Create(ctx context.Context, name string, scopes []string, plan string) (APIKey, string, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return APIKey{}, "", ErrAPIKeyName
+	}
+	for _, s := range scopes {
+		if !slices.Contains(validScopes, s) {
+			log.Warn("Escopo de chave de API inválido", "escopo", s)
+			return APIKey{}, "", ErrInvalidScope
+		}
+	}
+	if len(scopes) == 0 {
+		return APIKey{}, "", ErrInvalidScope
+	}
+
+	key, plaintext, err := newAPIKey(name, scopes, plan)
+	if err != nil {
+		log.Error("Falha ao gerar chave de API", "erro", err.Error())
+		return APIKey{}, "", err
+	}
+
+	if err := uc.repo.SaveAPIKey(key); err != nil {
+		log.Error("Falha ao salvar chave de API", "erro", err.Error())
+		return APIKey{}, "", err
+	}
+
+	log.Info("Chave de API criada", "api_key_id", key.ID, "escopos", key.Scopes)
+	return key, plaintext, nil
+}
+
+// EnsureBootstrapKey garante que a chave informada exista com escopo admin,
+// permitindo criar as demais chaves em uma instalação nova
+func (uc *APIKeyUseCase) EnsureBootstrapKey(ctx context.Context, plaintext string) error {
+	log := logger.FromContext(ctx, uc.log)
+
+	hash := HashAPIKey(plaintext)
+	existing, err := uc.repo.GetAPIKeyByHash(hash)
+	if err != nil && !errors.Is(err, ErrAPIKeyNotFound) {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+
+	id, err := randomHex(12)
+	if err != nil {
+		return err
+	}
+
+	key := APIKey{
+		ID:        id,
+		Name:      "bootstrap",
+		KeyHash:   hash,
+		Scopes:    []string{ScopeAdmin},
+		CreatedAt: time.Now(),
+	}
+	if err := uc.repo.SaveAPIKey(key); err != nil {
+		return err
+	}
+
+	log.Info("Chave de API de bootstrap registrada", "api_key_id", key.ID)
+	return nil
+}
+
+func (uc *APIKeyUseCase) List(ctx context.Context) ([]APIKey, error) {
+	keys, err := uc.repo.ListAPIKeys()
+	if err != nil {
+		logger.FromContext(ctx, uc.log).Error("Falha ao listar chaves de API", "erro", err.Error())
+		return nil, err
+	}
+	if keys == nil {
+		keys = []APIKey{}
+	}
+	return keys, nil
+}
+
+func (uc *APIKeyUseCase) Revoke(ctx context.Context, id string) error {
+	log := logger.FromContext(ctx, uc.log)
+
+	if _, err := uc.repo.GetAPIKeyByID(id); err != nil {
+		return err
+	}
+
+	if err := uc.repo.RevokeAPIKey(id, time.Now()); err != nil {
+		log.Error("Falha ao revogar chave de API", "erro", err.Error(), "api_key_id", id)
+		return err
+	}
+
+	log.Info("Chave de API revogada", "api_key_id", id)
+	return nil
+}
+
+// Rotate emite uma chave nova com o mesmo nome e escopos e revoga a antiga
+func (uc *APIKeyUseCase) Rotate(ctx context.Context, id string) (APIKey, string, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	old, err := uc.repo.GetAPIKeyByID(id)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	if old.Revoked() {
+		return APIKey{}, "", ErrInvalidAPIKey
+	}
+
+	key, plaintext, err := newAPIKey(old.Name, old.Scopes, old.Plan)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	if err := uc.repo.SaveAPIKey(key); err != nil {
+		log.Error("Falha ao salvar chave de API rotacionada", "erro", err.Error())
+		return APIKey{}, "", err
+	}
+	if err := uc.repo.RevokeAPIKey(old.ID, time.Now()); err != nil {
+		log.Error("Falha ao revogar chave de API antiga", "erro", err.Error(), "api_key_id", old.ID)
+		return APIKey{}, "", err
+	}
+
+	log.Info("Chave de API rotacionada", "api_key_id_antiga", old.ID, "api_key_id", key.ID)
+	return key, plaintext, nil
+}
+
+// Authenticate resolve a chave em texto puro recebida na requisição
+func (uc *APIKeyUseCase) Authenticate(ctx context.Context, plaintext string) (*APIKey, error) {
+	key, err := uc.repo.GetAPIKeyByHash(HashAPIKey(plaintext))
+	if err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		logger.FromContext(ctx, uc.log).Error("Falha ao consultar chave de API", "erro", err.Error())
+		return nil, err
+	}
+	if key.Revoked() {
+		return nil, ErrInvalidAPIKey
+	}
+	return key, nil
+}
+
+func newAPIKey(name string, scopes []string, plan string) (APIKey, string, error) {
+	id, err := randomHex(12)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return APIKey{}, "", err
+	}
+	plaintext := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	return APIKey{
+		ID:        id,
+		Name:      name,
+		KeyHash:   HashAPIKey(plaintext),
+		Scopes:    slices.Clone(scopes),
+		Plan:      plan,
+		CreatedAt: time.Now(),
+	}, plaintext, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+type apiKeyContextKey struct{}
+
+// ContextWithAPIKey associa ao contexto a chave que autenticou a requisição
+func ContextWithAPIKey(ctx context.Context, key *APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKeyFromContext devolve a chave que autenticou a requisição, se houver
+func APIKeyFromContext(ctx context.Context) (*APIKey, bool) {
+	key, ok := ctx.Value(apiKeyContextKey{}).(*APIKey)
+	return key, ok && key != nil
+}
@@ -0,0 +1,216 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type apiKeyRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *apiKeyRepositoryMock) SaveAPIKey(key APIKey) error {
+	return m.Called(key).Error(0)
+}
+
+func (m *apiKeyRepositoryMock) GetAPIKeyByHash(hash string) (*APIKey, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*APIKey), args.Error(1)
+}
+
+func (m *apiKeyRepositoryMock) GetAPIKeyByID(id string) (*APIKey, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*APIKey), args.Error(1)
+}
+
+func (m *apiKeyRepositoryMock) ListAPIKeys() ([]APIKey, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]APIKey), args.Error(1)
+}
+
+func (m *apiKeyRepositoryMock) RevokeAPIKey(id string, at time.Time) error {
+	return m.Called(id, at).Error(0)
+}
+
+func TestAPIKeyUseCase(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should create key storing only its hash",
+			run:  shouldCreateKeyStoringOnlyItsHash,
+		},
+		{
+			name: "should reject unknown scope",
+			run:  shouldRejectUnknownScope,
+		},
+		{
+			name: "should authenticate valid key",
+			run:  shouldAuthenticateValidKey,
+		},
+		{
+			name: "should reject revoked key",
+			run:  shouldRejectRevokedKey,
+		},
+		{
+			name: "should reject unknown key",
+			run:  shouldRejectUnknownKey,
+		},
+		{
+			name: "should rotate key revoking the old one",
+			run:  shouldRotateKeyRevokingTheOldOne,
+		},
+		{
+			name: "should return not found when revoking unknown key",
+			run:  shouldReturnNotFoundWhenRevokingUnknownKey,
+		},
+		{
+			name: "should treat admin scope as superset",
+			run:  shouldTreatAdminScopeAsSuperset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldCreateKeyStoringOnlyItsHash(t *testing.T) {
+	repoMock := new(apiKeyRepositoryMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+
+	var saved APIKey
+	repoMock.On("SaveAPIKey", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(APIKey)
+	}).Return(nil)
+
+	uc := NewAPIKeyUseCase(repoMock, loggerMock)
+	key, plaintext, err := uc.Create(context.Background(), "frete-batch", []string{ScopeConvertWrite}, "batch")
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(plaintext, "gf_"))
+	assert.Equal(t, HashAPIKey(plaintext), saved.KeyHash)
+	assert.NotContains(t, saved.KeyHash, plaintext)
+	assert.Equal(t, key.ID, saved.ID)
+	assert.Equal(t, []string{ScopeConvertWrite}, saved.Scopes)
+	assert.Equal(t, "batch", saved.Plan)
+}
+
+func shouldRejectUnknownScope(t *testing.T) {
+	repoMock := new(apiKeyRepositoryMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+
+	uc := NewAPIKeyUseCase(repoMock, loggerMock)
+	_, _, err := uc.Create(context.Background(), "frete-batch", []string{"root"}, "")
+
+	assert.ErrorIs(t, err, ErrInvalidScope)
+	repoMock.AssertNotCalled(t, "SaveAPIKey", mock.Anything)
+}
+
+func shouldAuthenticateValidKey(t *testing.T) {
+	repoMock := new(apiKeyRepositoryMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	stored := &APIKey{ID: "k1", KeyHash: HashAPIKey("gf_abc"), Scopes: []string{ScopeHistoryRead}}
+	repoMock.On("GetAPIKeyByHash", HashAPIKey("gf_abc")).Return(stored, nil)
+
+	uc := NewAPIKeyUseCase(repoMock, loggerMock)
+	key, err := uc.Authenticate(context.Background(), "gf_abc")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "k1", key.ID)
+}
+
+func shouldRejectRevokedKey(t *testing.T) {
+	repoMock := new(apiKeyRepositoryMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	revokedAt := time.Now()
+	stored := &APIKey{ID: "k1", KeyHash: HashAPIKey("gf_abc"), RevokedAt: &revokedAt}
+	repoMock.On("GetAPIKeyByHash", HashAPIKey("gf_abc")).Return(stored, nil)
+
+	uc := NewAPIKeyUseCase(repoMock, loggerMock)
+	key, err := uc.Authenticate(context.Background(), "gf_abc")
+
+	assert.Nil(t, key)
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+}
+
+func shouldRejectUnknownKey(t *testing.T) {
+	repoMock := new(apiKeyRepositoryMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	repoMock.On("GetAPIKeyByHash", mock.Anything).Return(nil, ErrAPIKeyNotFound)
+
+	uc := NewAPIKeyUseCase(repoMock, loggerMock)
+	_, err := uc.Authenticate(context.Background(), "gf_nope")
+
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+}
+
+func shouldRotateKeyRevokingTheOldOne(t *testing.T) {
+	repoMock := new(apiKeyRepositoryMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+
+	old := &APIKey{ID: "old", Name: "frete-batch", Scopes: []string{ScopeConvertWrite}}
+	repoMock.On("GetAPIKeyByID", "old").Return(old, nil)
+	repoMock.On("SaveAPIKey", mock.MatchedBy(func(k APIKey) bool {
+		return k.ID != "old" && k.Name == "frete-batch"
+	})).Return(nil)
+	repoMock.On("RevokeAPIKey", "old", mock.Anything).Return(nil)
+
+	uc := NewAPIKeyUseCase(repoMock, loggerMock)
+	key, plaintext, err := uc.Rotate(context.Background(), "old")
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, "old", key.ID)
+	assert.Equal(t, []string{ScopeConvertWrite}, key.Scopes)
+	assert.NotEmpty(t, plaintext)
+	repoMock.AssertExpectations(t)
+}
+
+func shouldReturnNotFoundWhenRevokingUnknownKey(t *testing.T) {
+	repoMock := new(apiKeyRepositoryMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	repoMock.On("GetAPIKeyByID", "nope").Return(nil, ErrAPIKeyNotFound)
+
+	uc := NewAPIKeyUseCase(repoMock, loggerMock)
+	err := uc.Revoke(context.Background(), "nope")
+
+	assert.True(t, errors.Is(err, ErrAPIKeyNotFound))
+	repoMock.AssertNotCalled(t, "RevokeAPIKey", mock.Anything, mock.Anything)
+}
+
+func shouldTreatAdminScopeAsSuperset(t *testing.T) {
+	admin := &APIKey{Scopes: []string{ScopeAdmin}}
+	reader := &APIKey{Scopes: []string{ScopeHistoryRead}}
+
+	assert.True(t, admin.HasScope(ScopeConvertWrite))
+	assert.True(t, reader.HasScope(ScopeHistoryRead))
+	assert.False(t, reader.HasScope(ScopeConvertWrite))
+}
@@ -0,0 +1,302 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-frete/api/pkg/logger"
+)
+
+// Dias pedidos ao provedor por chamada quando o lote não é informado
+const DefaultBackfillChunkDays = 30
+
+var ErrInvalidBackfill = errors.New("informe ao menos uma moeda e um número de dias positivo")
+
+// HistoricalRateProvider devolve o fechamento diário da moeda entre from e to, inclusive
+type HistoricalRateProvider interface {
+	GetDailyRates(moeda string, from, to time.Time) ([]RateSnapshot, error)
+}
+
+// BackfillCoverage é o intervalo contínuo de dias já importado para a moeda.
+// É o que permite retomar uma importação interrompida sem consultar de novo o provedor.
+type BackfillCoverage struct {
+	Moeda     string    `bson:"_id"`
+	From      time.Time `bson:"from"`
+	Through   time.Time `bson:"through"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+type BackfillRepository interface {
+	RateSnapshotSaver
+	RateSeriesReader
+	// GetBackfillCoverage devolve nil quando a moeda nunca foi importada
+	GetBackfillCoverage(moeda string) (*BackfillCoverage, error)
+	SaveBackfillCoverage(coverage BackfillCoverage) error
+}
+
+type BackfillRequest struct {
+	Currencies []string
+	Days       int
+	// Último dia importado; zero importa até ontem (UTC), o último fechamento completo
+	Until     time.Time
+	ChunkDays int
+}
+
+// BackfillProgress é enviado ao fim de cada lote
+type BackfillProgress struct {
+	Moeda     string
+	From      time.Time
+	To        time.Time
+	DaysDone  int
+	DaysTotal int
+	Imported  int
+	Skipped   int
+}
+
+type BackfillResult struct {
+	Moeda string
+	// Dias gravados nesta execução
+	Imported int
+	// Dias que já tinham cotação salva (coletor ou importação anterior)
+	Skipped int
+	// Dias dentro da cobertura de uma importação anterior, nem consultados
+	Resumed int
+	Err     error
+}
+
+type backfillChunk struct {
+	from, to time.Time
+	backward bool
+}
+
+// BackfillUseCase importa a série diária do provedor para a coleção de cotações
+type BackfillUseCase struct {
+	provider HistoricalRateProvider
+	repo     BackfillRepository
+	log      logger.Logger
+	now      func() time.Time
+}
+
+func NewBackfillUseCase(p HistoricalRateProvider, r BackfillRepository, l logger.Logger) *BackfillUseCase {
+	return &BackfillUseCase{provider: p, repo: r, log: l, now: time.Now}
+}
+
+// Execute importa os req.Days dias até req.Until para cada moeda. É idempotente:
+// dias que já têm cotação são pulados, e o intervalo já coberto por uma execução
+// anterior nem é consultado. Falha numa moeda não impede as demais; o erro
+// devolvido junta as falhas. Com o contexto cancelado para entre lotes, e a
+// próxima execução continua de onde parou.
+func (uc *BackfillUseCase) Execute(ctx context.Context, req BackfillRequest, progress func(BackfillProgress)) ([]BackfillResult, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	if len(req.Currencies) == 0 || req.Days <= 0 {
+		return nil, ErrInvalidBackfill
+	}
+	if req.ChunkDays <= 0 {
+		req.ChunkDays = DefaultBackfillChunkDays
+	}
+	if req.Until.IsZero() {
+		req.Until = uc.now().UTC().AddDate(0, 0, -1)
+	}
+	if progress == nil {
+		progress = func(BackfillProgress) {}
+	}
+
+	until := startOfDay(req.Until)
+	from := until.AddDate(0, 0, -(req.Days - 1))
+	log.Info("Iniciando importação de histórico", "moedas", req.Currencies, "de", from.Format(time.DateOnly), "ate", until.Format(time.DateOnly))
+
+	var (
+		results []BackfillResult
+		errs    []error
+	)
+	for _, moeda := range req.Currencies {
+		moeda = strings.ToUpper(strings.TrimSpace(moeda))
+		result := uc.backfill(ctx, moeda, from, until, req, progress)
+		results = append(results, result)
+
+		if result.Err != nil {
+			if ctx.Err() != nil {
+				return results, ctx.Err()
+			}
+			log.Error("Falha ao importar histórico", "moeda", moeda, "erro", result.Err.Error())
+			errs = append(errs, fmt.Errorf("%s: %w", moeda, result.Err))
+			continue
+		}
+		log.Info("Histórico importado", "moeda", moeda, "importados", result.Imported, "existentes", result.Skipped, "retomados", result.Resumed)
+	}
+
+	return results, errors.Join(errs...)
+}
+
+func (uc *BackfillUseCase) backfill(ctx context.Context, moeda string, from, until time.Time, req BackfillRequest, progress func(BackfillProgress)) BackfillResult {
+	result := BackfillResult{Moeda: moeda}
+
+	coverage, err := uc.repo.GetBackfillCoverage(moeda)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	chunks, cov, resumed := planBackfill(moeda, coverage, from, until, req.ChunkDays)
+	result.Resumed = resumed
+	done := resumed
+
+	for _, c := range chunks {
+		if err := ctx.Err(); err != nil {
+			result.Err = err
+			return result
+		}
+
+		imported, skipped, err := uc.importChunk(moeda, c.from, c.to)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		result.Imported += imported
+		result.Skipped += skipped
+
+		// Estende a cobertura só depois de gravar: uma queda no meio refaz o lote
+		if c.backward {
+			cov.From = c.from
+		} else {
+			cov.Through = c.to
+		}
+		cov.UpdatedAt = uc.now()
+		if err := uc.repo.SaveBackfillCoverage(cov); err != nil {
+			result.Err = err
+			return result
+		}
+
+		done += daysBetween(c.from, c.to)
+		progress(BackfillProgress{
+			Moeda:     moeda,
+			From:      c.from,
+			To:        c.to,
+			DaysDone:  done,
+			DaysTotal: req.Days,
+			Imported:  result.Imported,
+			Skipped:   result.Skipped,
+		})
+	}
+
+	return result
+}
+
+// planBackfill divide [from, until] em lotes, pulando a parte já coberta. Os
+// lotes antes da cobertura vão do mais novo para o mais antigo e os depois dela
+// do mais antigo para o mais novo, assim a cobertura segue contínua a cada lote.
+func planBackfill(moeda string, coverage *BackfillCoverage, from, until time.Time, chunkDays int) ([]backfillChunk, BackfillCoverage, int) {
+	// Sem cobertura, ou cobertura que não encosta no período: começa uma nova
+	if coverage == nil || coverage.From.After(until.AddDate(0, 0, 1)) || coverage.Through.Before(from.AddDate(0, 0, -1)) {
+		cov := BackfillCoverage{Moeda: moeda, From: from, Through: from.AddDate(0, 0, -1)}
+		return splitDays(from, until, chunkDays, false), cov, 0
+	}
+
+	cov := *coverage
+	var chunks []backfillChunk
+	if from.Before(cov.From) {
+		chunks = append(chunks, splitDays(from, cov.From.AddDate(0, 0, -1), chunkDays, true)...)
+	}
+	if until.After(cov.Through) {
+		chunks = append(chunks, splitDays(cov.Through.AddDate(0, 0, 1), until, chunkDays, false)...)
+	}
+
+	overlapFrom, overlapTo := maxTime(from, cov.From), minTime(until, cov.Through)
+	resumed := 0
+	if !overlapFrom.After(overlapTo) {
+		resumed = daysBetween(overlapFrom, overlapTo)
+	}
+	return chunks, cov, resumed
+}
+
+func splitDays(from, to time.Time, size int, backward bool) []backfillChunk {
+	var chunks []backfillChunk
+	if backward {
+		for end := to; !end.Before(from); end = end.AddDate(0, 0, -size) {
+			start := maxTime(from, end.AddDate(0, 0, -(size-1)))
+			chunks = append(chunks, backfillChunk{from: start, to: end, backward: true})
+		}
+		return chunks
+	}
+	for start := from; !start.After(to); start = start.AddDate(0, 0, size) {
+		end := minTime(to, start.AddDate(0, 0, size-1))
+		chunks = append(chunks, backfillChunk{from: start, to: end})
+	}
+	return chunks
+}
+
+// importChunk grava o fechamento de cada dia do lote que ainda não tem cotação
+func (uc *BackfillUseCase) importChunk(moeda string, from, to time.Time) (imported, skipped int, err error) {
+	existing, err := uc.repo.GetRateSeries(moeda, from, to.AddDate(0, 0, 1).Add(-time.Nanosecond))
+	if err != nil {
+		return 0, 0, err
+	}
+	have := make(map[string]bool, len(existing))
+	for _, s := range existing {
+		have[s.Data.UTC().Format(time.DateOnly)] = true
+	}
+
+	fetched, err := uc.provider.GetDailyRates(moeda, from, to)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Um fechamento por dia: se o provedor mandar mais de um, fica o mais recente
+	latest := make(map[string]RateSnapshot)
+	var days []string
+	for _, s := range fetched {
+		day := s.Data.UTC().Format(time.DateOnly)
+		if s.Data.Before(from) || !s.Data.Before(to.AddDate(0, 0, 1)) || s.Cotacao == 0 {
+			continue
+		}
+		if prev, ok := latest[day]; !ok {
+			days = append(days, day)
+		} else if prev.Data.After(s.Data) {
+			continue
+		}
+		latest[day] = s
+	}
+
+	var snapshots []RateSnapshot
+	for _, day := range days {
+		if have[day] {
+			skipped++
+			continue
+		}
+		snapshots = append(snapshots, latest[day])
+	}
+	if len(snapshots) == 0 {
+		return 0, skipped, nil
+	}
+	if err := uc.repo.SaveSnapshots(snapshots); err != nil {
+		return 0, skipped, ErrSaveSnapshots
+	}
+	return len(snapshots), skipped, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours()/24) + 1
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
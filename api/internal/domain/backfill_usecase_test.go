@@ -0,0 +1,273 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// historicalProviderFake devolve uma cotação às 18h de cada dia pedido e
+// registra os intervalos consultados
+type historicalProviderFake struct {
+	calls  [][2]string
+	failOn map[string]error
+}
+
+func (p *historicalProviderFake) GetDailyRates(moeda string, from, to time.Time) ([]RateSnapshot, error) {
+	p.calls = append(p.calls, [2]string{from.Format(time.DateOnly), to.Format(time.DateOnly)})
+	if err := p.failOn[moeda+" "+from.Format(time.DateOnly)]; err != nil {
+		return nil, err
+	}
+	if err := p.failOn[moeda]; err != nil {
+		return nil, err
+	}
+	var out []RateSnapshot
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		out = append(out, RateSnapshot{Moeda: moeda, Cotacao: 5 + float64(d.Day())/100, Fonte: "awesomeapi", Data: d.Add(18 * time.Hour)})
+	}
+	return out, nil
+}
+
+// backfillRepositoryFake guarda cotações e coberturas em memória
+type backfillRepositoryFake struct {
+	snapshots []RateSnapshot
+	coverage  map[string]BackfillCoverage
+}
+
+func newBackfillRepositoryFake(existing ...RateSnapshot) *backfillRepositoryFake {
+	return &backfillRepositoryFake{snapshots: existing, coverage: map[string]BackfillCoverage{}}
+}
+
+func (r *backfillRepositoryFake) SaveSnapshots(snapshots []RateSnapshot) error {
+	r.snapshots = append(r.snapshots, snapshots...)
+	return nil
+}
+
+func (r *backfillRepositoryFake) GetRateSeries(moeda string, from, to time.Time) ([]RateSnapshot, error) {
+	var out []RateSnapshot
+	for _, s := range r.snapshots {
+		if s.Moeda == moeda && !s.Data.Before(from) && !s.Data.After(to) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (r *backfillRepositoryFake) GetBackfillCoverage(moeda string) (*BackfillCoverage, error) {
+	c, ok := r.coverage[moeda]
+	if !ok {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+func (r *backfillRepositoryFake) SaveBackfillCoverage(c BackfillCoverage) error {
+	r.coverage[c.Moeda] = c
+	return nil
+}
+
+func (r *backfillRepositoryFake) days(moeda string) []string {
+	var out []string
+	for _, s := range r.snapshots {
+		if s.Moeda == moeda {
+			out = append(out, s.Data.Format(time.DateOnly))
+		}
+	}
+	return out
+}
+
+func day(s string) time.Time {
+	t, _ := time.Parse(time.DateOnly, s)
+	return t
+}
+
+func newBackfillLogger() *loggermock.LoggerMock {
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Error", mock.Anything, mock.Anything).Return()
+	return loggerMock
+}
+
+func TestBackfillUseCase_Execute(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should import requested days in chunks reporting progress",
+			run:  shouldImportRequestedDaysInChunksReportingProgress,
+		},
+		{
+			name: "should skip days that already have a snapshot",
+			run:  shouldSkipDaysThatAlreadyHaveASnapshot,
+		},
+		{
+			name: "should resume from coverage after a failed chunk",
+			run:  shouldResumeFromCoverageAfterAFailedChunk,
+		},
+		{
+			name: "should extend coverage backwards and forwards",
+			run:  shouldExtendCoverageBackwardsAndForwards,
+		},
+		{
+			name: "should continue other currencies when one fails",
+			run:  shouldContinueOtherCurrenciesWhenOneFails,
+		},
+		{
+			name: "should stop between chunks when context is canceled",
+			run:  shouldStopBetweenChunksWhenContextIsCanceled,
+		},
+		{
+			name: "should default until to yesterday",
+			run:  shouldDefaultUntilToYesterday,
+		},
+		{
+			name: "should reject request without currencies or days",
+			run:  shouldRejectRequestWithoutCurrenciesOrDays,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldImportRequestedDaysInChunksReportingProgress(t *testing.T) {
+	provider := &historicalProviderFake{}
+	repo := newBackfillRepositoryFake()
+	uc := NewBackfillUseCase(provider, repo, newBackfillLogger())
+
+	var progress []BackfillProgress
+	results, err := uc.Execute(context.Background(), BackfillRequest{
+		Currencies: []string{"usd"}, Days: 10, Until: day("2026-01-10"), ChunkDays: 4,
+	}, func(p BackfillProgress) { progress = append(progress, p) })
+
+	require.NoError(t, err)
+	assert.Equal(t, [][2]string{{"2026-01-01", "2026-01-04"}, {"2026-01-05", "2026-01-08"}, {"2026-01-09", "2026-01-10"}}, provider.calls)
+	assert.Equal(t, []BackfillResult{{Moeda: "USD", Imported: 10}}, results)
+	assert.Len(t, repo.days("USD"), 10)
+
+	require.Len(t, progress, 3)
+	assert.Equal(t, 4, progress[0].DaysDone)
+	assert.Equal(t, 10, progress[2].DaysDone)
+	assert.Equal(t, 10, progress[2].DaysTotal)
+	assert.Equal(t, BackfillCoverage{Moeda: "USD", From: day("2026-01-01"), Through: day("2026-01-10"), UpdatedAt: repo.coverage["USD"].UpdatedAt}, repo.coverage["USD"])
+}
+
+func shouldSkipDaysThatAlreadyHaveASnapshot(t *testing.T) {
+	provider := &historicalProviderFake{}
+	// Dia 2 já tem cotação do coletor, em outro horário
+	repo := newBackfillRepositoryFake(RateSnapshot{Moeda: "USD", Cotacao: 5.5, Data: day("2026-01-02").Add(9 * time.Hour)})
+	uc := NewBackfillUseCase(provider, repo, newBackfillLogger())
+
+	results, err := uc.Execute(context.Background(), BackfillRequest{
+		Currencies: []string{"USD"}, Days: 3, Until: day("2026-01-03"),
+	}, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, results[0].Imported)
+	assert.Equal(t, 1, results[0].Skipped)
+	assert.ElementsMatch(t, []string{"2026-01-01", "2026-01-02", "2026-01-03"}, repo.days("USD"))
+}
+
+func shouldResumeFromCoverageAfterAFailedChunk(t *testing.T) {
+	provider := &historicalProviderFake{failOn: map[string]error{"USD 2026-01-05": errors.New("timeout")}}
+	repo := newBackfillRepositoryFake()
+	uc := NewBackfillUseCase(provider, repo, newBackfillLogger())
+	req := BackfillRequest{Currencies: []string{"USD"}, Days: 8, Until: day("2026-01-08"), ChunkDays: 4}
+
+	_, err := uc.Execute(context.Background(), req, nil)
+	require.Error(t, err)
+	assert.Equal(t, day("2026-01-04"), repo.coverage["USD"].Through)
+
+	provider.failOn = nil
+	provider.calls = nil
+	results, err := uc.Execute(context.Background(), req, nil)
+
+	require.NoError(t, err)
+	// Só o lote que faltou é consultado de novo
+	assert.Equal(t, [][2]string{{"2026-01-05", "2026-01-08"}}, provider.calls)
+	assert.Equal(t, BackfillResult{Moeda: "USD", Imported: 4, Resumed: 4}, results[0])
+	assert.Len(t, repo.days("USD"), 8)
+}
+
+func shouldExtendCoverageBackwardsAndForwards(t *testing.T) {
+	provider := &historicalProviderFake{}
+	repo := newBackfillRepositoryFake()
+	repo.coverage["USD"] = BackfillCoverage{Moeda: "USD", From: day("2026-01-05"), Through: day("2026-01-06")}
+	uc := NewBackfillUseCase(provider, repo, newBackfillLogger())
+
+	results, err := uc.Execute(context.Background(), BackfillRequest{
+		Currencies: []string{"USD"}, Days: 8, Until: day("2026-01-08"), ChunkDays: 2,
+	}, nil)
+
+	require.NoError(t, err)
+	// Antes da cobertura do mais novo para o mais antigo, depois dela em ordem
+	assert.Equal(t, [][2]string{{"2026-01-03", "2026-01-04"}, {"2026-01-01", "2026-01-02"}, {"2026-01-07", "2026-01-08"}}, provider.calls)
+	assert.Equal(t, 2, results[0].Resumed)
+	assert.Equal(t, day("2026-01-01"), repo.coverage["USD"].From)
+	assert.Equal(t, day("2026-01-08"), repo.coverage["USD"].Through)
+}
+
+func shouldContinueOtherCurrenciesWhenOneFails(t *testing.T) {
+	provider := &historicalProviderFake{failOn: map[string]error{"XYZ": ErrCurrencyNotFound}}
+	repo := newBackfillRepositoryFake()
+	uc := NewBackfillUseCase(provider, repo, newBackfillLogger())
+
+	results, err := uc.Execute(context.Background(), BackfillRequest{
+		Currencies: []string{"XYZ", "EUR"}, Days: 2, Until: day("2026-01-02"),
+	}, nil)
+
+	assert.ErrorIs(t, err, ErrCurrencyNotFound)
+	assert.ErrorContains(t, err, "XYZ")
+	require.Len(t, results, 2)
+	assert.ErrorIs(t, results[0].Err, ErrCurrencyNotFound)
+	assert.Equal(t, 2, results[1].Imported)
+	_, covered := repo.coverage["XYZ"]
+	assert.False(t, covered)
+}
+
+func shouldStopBetweenChunksWhenContextIsCanceled(t *testing.T) {
+	provider := &historicalProviderFake{}
+	repo := newBackfillRepositoryFake()
+	uc := NewBackfillUseCase(provider, repo, newBackfillLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results, err := uc.Execute(ctx, BackfillRequest{
+		Currencies: []string{"USD", "EUR"}, Days: 4, Until: day("2026-01-04"), ChunkDays: 2,
+	}, func(p BackfillProgress) { cancel() })
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, provider.calls, 1)
+	assert.Len(t, results, 1)
+	assert.Equal(t, day("2026-01-02"), repo.coverage["USD"].Through)
+}
+
+func shouldDefaultUntilToYesterday(t *testing.T) {
+	provider := &historicalProviderFake{}
+	repo := newBackfillRepositoryFake()
+	uc := NewBackfillUseCase(provider, repo, newBackfillLogger())
+	uc.now = func() time.Time { return time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC) }
+
+	_, err := uc.Execute(context.Background(), BackfillRequest{Currencies: []string{"USD"}, Days: 1}, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, [][2]string{{"2026-03-09", "2026-03-09"}}, provider.calls)
+}
+
+func shouldRejectRequestWithoutCurrenciesOrDays(t *testing.T) {
+	uc := NewBackfillUseCase(&historicalProviderFake{}, newBackfillRepositoryFake(), newBackfillLogger())
+
+	_, err := uc.Execute(context.Background(), BackfillRequest{Days: 10}, nil)
+	assert.ErrorIs(t, err, ErrInvalidBackfill)
+
+	_, err = uc.Execute(context.Background(), BackfillRequest{Currencies: []string{"USD"}}, nil)
+	assert.ErrorIs(t, err, ErrInvalidBackfill)
+}
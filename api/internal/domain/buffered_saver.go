@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"sync"
+	"time"
+
+	"go-frete/api/pkg/logger"
+)
+
+// ConversionBatchSaver é implementado pelos repositórios que gravam vários
+// registros numa única operação
+type ConversionBatchSaver interface {
+	SaveHistoryBatch(records []ConversionRecord) error
+}
+
+// saveBatch grava o lote de uma vez quando o repositório suporta e, nos
+// demais, registro a registro, parando na primeira falha
+func saveBatch(s ConversionSaver, records []ConversionRecord) error {
+	if b, ok := s.(ConversionBatchSaver); ok {
+		return b.SaveHistoryBatch(records)
+	}
+	for _, record := range records {
+		if err := s.SaveHistory(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BufferedSaver tira a gravação do histórico do caminho da requisição: os
+// registros entram numa fila limitada e um gravador em segundo plano os envia
+// em lotes, ao juntar batchSize registros ou a cada intervalo. Com a fila
+// cheia, SaveHistory espera abrir espaço.
+//
+// A conversão responde antes de o registro chegar ao banco: falhas da gravação
+// em lote só aparecem no log (ou no diário, se next for um ResilientSaver
+// com a política journaled).
+type BufferedSaver struct {
+	next      ConversionSaver
+	records   chan ConversionRecord
+	batchSize int
+	interval  time.Duration
+	log       logger.Logger
+
+	// SaveHistory segura a leitura enquanto enfileira; Close, a escrita
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// NewBufferedSaver inicia o gravador em segundo plano; Close o encerra
+func NewBufferedSaver(next ConversionSaver, capacity, batchSize int, interval time.Duration, l logger.Logger) *BufferedSaver {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	b := &BufferedSaver{
+		next:      next,
+		records:   make(chan ConversionRecord, capacity),
+		batchSize: batchSize,
+		interval:  interval,
+		log:       l,
+		done:      make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// SaveHistory implementa ConversionSaver. Depois do Close a gravação volta a
+// ser síncrona, para não perder as requisições que ainda estão terminando.
+func (b *BufferedSaver) SaveHistory(record ConversionRecord) error {
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return b.next.SaveHistory(record)
+	}
+	b.records <- record
+	b.mu.RUnlock()
+	return nil
+}
+
+// Len é a quantidade de registros esperando na fila
+func (b *BufferedSaver) Len() int {
+	return len(b.records)
+}
+
+// Close para de aceitar registros na fila e espera o gravador enviar o que restou
+func (b *BufferedSaver) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	close(b.records)
+	b.mu.Unlock()
+
+	<-b.done
+}
+
+func (b *BufferedSaver) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	batch := make([]ConversionRecord, 0, b.batchSize)
+	for {
+		select {
+		case record, ok := <-b.records:
+			if !ok {
+				b.flush(batch)
+				return
+			}
+			batch = append(batch, record)
+			if len(batch) < b.batchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+		b.flush(batch)
+		batch = make([]ConversionRecord, 0, b.batchSize)
+	}
+}
+
+func (b *BufferedSaver) flush(batch []ConversionRecord) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := saveBatch(b.next, batch); err != nil {
+		b.log.Error("Falha ao gravar lote do histórico", "erro", err.Error(), "registros", len(batch))
+	}
+}
@@ -0,0 +1,218 @@
+package domain
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchSaverFake registra cada lote recebido; gate, se informado, segura a gravação
+type batchSaverFake struct {
+	mu      sync.Mutex
+	batches [][]ConversionRecord
+	single  int
+	gate    chan struct{}
+}
+
+func (f *batchSaverFake) SaveHistory(record ConversionRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.single++
+	return nil
+}
+
+func (f *batchSaverFake) SaveHistoryBatch(records []ConversionRecord) error {
+	if f.gate != nil {
+		<-f.gate
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = append(f.batches, records)
+	return nil
+}
+
+func (f *batchSaverFake) saved() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []string
+	for _, b := range f.batches {
+		out = append(out, currencies(b)...)
+	}
+	return out
+}
+
+func (f *batchSaverFake) batchCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.batches)
+}
+
+func TestBufferedSaver(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should flush when batch is full",
+			run:  shouldFlushWhenBatchIsFull,
+		},
+		{
+			name: "should flush partial batch on interval",
+			run:  shouldFlushPartialBatchOnInterval,
+		},
+		{
+			name: "should block callers while buffer is full",
+			run:  shouldBlockCallersWhileBufferIsFull,
+		},
+		{
+			name: "should flush pending records on close",
+			run:  shouldFlushPendingRecordsOnClose,
+		},
+		{
+			name: "should save synchronously after close",
+			run:  shouldSaveSynchronouslyAfterClose,
+		},
+		{
+			name: "should journal failed batch through resilient saver",
+			run:  shouldJournalFailedBatchThroughResilientSaver,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldFlushWhenBatchIsFull(t *testing.T) {
+	repo := &batchSaverFake{}
+	saver := NewBufferedSaver(repo, 10, 2, time.Hour, newPersistenceLogger())
+	defer saver.Close()
+
+	require.NoError(t, saver.SaveHistory(conversion("USD")))
+	require.NoError(t, saver.SaveHistory(conversion("EUR")))
+
+	assert.Eventually(t, func() bool { return repo.batchCount() == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"USD", "EUR"}, repo.saved())
+}
+
+func shouldFlushPartialBatchOnInterval(t *testing.T) {
+	repo := &batchSaverFake{}
+	saver := NewBufferedSaver(repo, 10, 100, 10*time.Millisecond, newPersistenceLogger())
+	defer saver.Close()
+
+	require.NoError(t, saver.SaveHistory(conversion("USD")))
+
+	assert.Eventually(t, func() bool { return repo.batchCount() == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"USD"}, repo.saved())
+}
+
+func shouldBlockCallersWhileBufferIsFull(t *testing.T) {
+	repo := &batchSaverFake{gate: make(chan struct{})}
+	saver := NewBufferedSaver(repo, 1, 1, time.Hour, newPersistenceLogger())
+
+	// O primeiro fica preso no gravador, o segundo ocupa a fila
+	require.NoError(t, saver.SaveHistory(conversion("USD")))
+	assert.Eventually(t, func() bool { return saver.Len() == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, saver.SaveHistory(conversion("EUR")))
+
+	third := make(chan struct{})
+	go func() {
+		saver.SaveHistory(conversion("GBP"))
+		close(third)
+	}()
+	select {
+	case <-third:
+		t.Fatal("SaveHistory deveria esperar espaço na fila")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(repo.gate)
+	<-third
+	saver.Close()
+	assert.Equal(t, []string{"USD", "EUR", "GBP"}, repo.saved())
+}
+
+func shouldFlushPendingRecordsOnClose(t *testing.T) {
+	repo := &batchSaverFake{}
+	saver := NewBufferedSaver(repo, 10, 100, time.Hour, newPersistenceLogger())
+
+	for _, moeda := range []string{"USD", "EUR", "GBP"} {
+		require.NoError(t, saver.SaveHistory(conversion(moeda)))
+	}
+	saver.Close()
+
+	assert.Equal(t, []string{"USD", "EUR", "GBP"}, repo.saved())
+}
+
+func shouldSaveSynchronouslyAfterClose(t *testing.T) {
+	repo := &batchSaverFake{}
+	saver := NewBufferedSaver(repo, 10, 100, time.Hour, newPersistenceLogger())
+	saver.Close()
+
+	require.NoError(t, saver.SaveHistory(conversion("USD")))
+
+	assert.Equal(t, 1, repo.single)
+	assert.Equal(t, 0, repo.batchCount())
+}
+
+func shouldJournalFailedBatchThroughResilientSaver(t *testing.T) {
+	repo := &outageSaverFake{down: true}
+	journal := &memoryJournalFake{}
+	resilient := NewResilientSaver(repo, PersistJournaled, newPersistenceLogger()).WithJournal(journal, time.Second)
+	saver := NewBufferedSaver(resilient, 10, 100, time.Hour, newPersistenceLogger())
+
+	require.NoError(t, saver.SaveHistory(conversion("USD")))
+	require.NoError(t, saver.SaveHistory(conversion("EUR")))
+	saver.Close()
+
+	assert.Equal(t, []string{"USD", "EUR"}, currencies(journal.records))
+}
+
+// latencySaverFake imita o custo de uma ida ao banco: cada operação paga a
+// latência de rede e cada documento, um custo pequeno de escrita
+type latencySaverFake struct {
+	roundTrip time.Duration
+	perRecord time.Duration
+}
+
+func (f latencySaverFake) SaveHistory(record ConversionRecord) error {
+	time.Sleep(f.roundTrip + f.perRecord)
+	return nil
+}
+
+func (f latencySaverFake) SaveHistoryBatch(records []ConversionRecord) error {
+	time.Sleep(f.roundTrip + time.Duration(len(records))*f.perRecord)
+	return nil
+}
+
+var benchmarkRepo = latencySaverFake{roundTrip: 500 * time.Microsecond, perRecord: 5 * time.Microsecond}
+
+// Compara o tempo que cada conversão espera pela gravação do histórico,
+// com requisições concorrentes como num servidor sob carga
+func BenchmarkConversionSaver(b *testing.B) {
+	b.Run("sync InsertOne", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				benchmarkRepo.SaveHistory(conversion("USD"))
+			}
+		})
+	})
+
+	for _, batchSize := range []int{50, 200} {
+		b.Run(fmt.Sprintf("buffered InsertMany batch=%d", batchSize), func(b *testing.B) {
+			saver := NewBufferedSaver(benchmarkRepo, 4*batchSize, batchSize, 10*time.Millisecond, newPersistenceLogger())
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					saver.SaveHistory(conversion("USD"))
+				}
+			})
+			// Inclui o esvaziamento da fila: o que importa é a vazão de ponta a ponta
+			saver.Close()
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	// Os fusos dos calendários não dependem do zoneinfo da imagem
+	_ "time/tzdata"
+)
+
+var ErrUnknownCalendar = errors.New("calendário não encontrado")
+
+// BusinessCalendar diz se um instante cai num dia útil
+type BusinessCalendar interface {
+	IsBusinessDay(t time.Time) bool
+}
+
+// CurrencyCalendars escolhe o calendário de dias úteis das cotações de uma moeda
+type CurrencyCalendars interface {
+	ForCurrency(moeda string) BusinessCalendar
+}
+
+// Holiday é um feriado; Data é a meia-noite do dia no fuso do calendário
+type Holiday struct {
+	Data time.Time `json:"data"`
+	Nome string    `json:"nome"`
+}
+
+// Calendar é o calendário bancário de um país (ou sistema de pagamentos):
+// sábados, domingos e feriados não são dias úteis
+type Calendar struct {
+	Code     string
+	Name     string
+	Location *time.Location
+
+	rules  func(year int) []Holiday
+	custom []Holiday
+
+	mu    sync.Mutex
+	years map[int]map[string]Holiday
+}
+
+func newCalendar(code, name, tz string, rules func(year int) []Holiday, custom []Holiday) *Calendar {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		loc = time.UTC
+	}
+	return &Calendar{Code: code, Name: name, Location: loc, rules: rules, custom: custom, years: map[int]map[string]Holiday{}}
+}
+
+// Holidays devolve os feriados do ano em ordem, incluindo os configurados
+func (c *Calendar) Holidays(year int) []Holiday {
+	byDay := c.year(year)
+	out := make([]Holiday, 0, len(byDay))
+	for _, h := range byDay {
+		out = append(out, h)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Data.Before(out[j].Data) })
+	return out
+}
+
+// IsHoliday diz se o dia de t, no fuso do calendário, é feriado
+func (c *Calendar) IsHoliday(t time.Time) (Holiday, bool) {
+	local := t.In(c.Location)
+	h, ok := c.year(local.Year())[local.Format(time.DateOnly)]
+	return h, ok
+}
+
+// IsBusinessDay implementa BusinessCalendar
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	switch t.In(c.Location).Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+	_, holiday := c.IsHoliday(t)
+	return !holiday
+}
+
+func (c *Calendar) year(year int) map[string]Holiday {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if byDay, ok := c.years[year]; ok {
+		return byDay
+	}
+	byDay := map[string]Holiday{}
+	for _, h := range c.rules(year) {
+		h.Data = time.Date(h.Data.Year(), h.Data.Month(), h.Data.Day(), 0, 0, 0, 0, c.Location)
+		byDay[h.Data.Format(time.DateOnly)] = h
+	}
+	for _, h := range c.custom {
+		if h.Data.Year() != year {
+			continue
+		}
+		h.Data = time.Date(h.Data.Year(), h.Data.Month(), h.Data.Day(), 0, 0, 0, 0, c.Location)
+		byDay[h.Data.Format(time.DateOnly)] = h
+	}
+	c.years[year] = byDay
+	return byDay
+}
+
+// jointCalendar só considera útil o dia que é útil em todos os calendários
+type jointCalendar []BusinessCalendar
+
+func (j jointCalendar) IsBusinessDay(t time.Time) bool {
+	for _, c := range j {
+		if !c.IsBusinessDay(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// Calendário da praça de cada moeda; as demais seguem só o calendário brasileiro
+var currencyCalendar = map[string]string{
+	"USD": "US",
+	"EUR": "EU",
+}
+
+// Calendars reúne os calendários suportados: BR (feriados bancários nacionais),
+// US (Federal Reserve) e EU (TARGET2)
+type Calendars struct {
+	byCode map[string]*Calendar
+}
+
+// NewCalendars monta os calendários com os feriados extras de cada código (ex: "BR")
+func NewCalendars(custom map[string][]Holiday) *Calendars {
+	upper := map[string][]Holiday{}
+	for code, holidays := range custom {
+		upper[strings.ToUpper(code)] = append(upper[strings.ToUpper(code)], holidays...)
+	}
+	return &Calendars{byCode: map[string]*Calendar{
+		"BR": newCalendar("BR", "Feriados bancários nacionais do Brasil", "America/Sao_Paulo", brazilHolidays, upper["BR"]),
+		"US": newCalendar("US", "Feriados do Federal Reserve (EUA)", "America/New_York", usHolidays, upper["US"]),
+		"EU": newCalendar("EU", "Feriados do TARGET2 (zona do euro)", "Europe/Berlin", target2Holidays, append(upper["EU"], upper["TARGET2"]...)),
+	}}
+}
+
+// Get devolve o calendário pelo código (BR, US, EU ou TARGET2)
+func (c *Calendars) Get(code string) (*Calendar, error) {
+	code = strings.ToUpper(code)
+	if code == "TARGET2" {
+		code = "EU"
+	}
+	cal, ok := c.byCode[code]
+	if !ok {
+		return nil, ErrUnknownCalendar
+	}
+	return cal, nil
+}
+
+// ForCurrency implementa CurrencyCalendars: a cotação contra o real só é
+// publicada nos dias úteis no Brasil e na praça da moeda
+func (c *Calendars) ForCurrency(moeda string) BusinessCalendar {
+	joint := jointCalendar{c.byCode["BR"]}
+	if code, ok := currencyCalendar[strings.ToUpper(moeda)]; ok {
+		joint = append(joint, c.byCode[code])
+	}
+	return joint
+}
+
+// onlyBusinessDays descarta as cotações que caem fora dos dias úteis
+func onlyBusinessDays(series []RateSnapshot, cal BusinessCalendar) []RateSnapshot {
+	if cal == nil {
+		return series
+	}
+	out := series[:0:0]
+	for _, s := range series {
+		if cal.IsBusinessDay(s.Data) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func brazilHolidays(year int) []Holiday {
+	easter := easterSunday(year)
+	holidays := []Holiday{
+		{ymd(year, time.January, 1), "Confraternização Universal"},
+		{easter.AddDate(0, 0, -48), "Carnaval"},
+		{easter.AddDate(0, 0, -47), "Carnaval"},
+		{easter.AddDate(0, 0, -2), "Sexta-feira Santa"},
+		{ymd(year, time.April, 21), "Tiradentes"},
+		{ymd(year, time.May, 1), "Dia do Trabalho"},
+		{easter.AddDate(0, 0, 60), "Corpus Christi"},
+		{ymd(year, time.September, 7), "Independência do Brasil"},
+		{ymd(year, time.October, 12), "Nossa Senhora Aparecida"},
+		{ymd(year, time.November, 2), "Finados"},
+		{ymd(year, time.November, 15), "Proclamação da República"},
+		{ymd(year, time.December, 25), "Natal"},
+	}
+	// Feriado nacional desde a Lei 14.759/2023
+	if year >= 2024 {
+		holidays = append(holidays, Holiday{ymd(year, time.November, 20), "Dia Nacional de Zumbi e da Consciência Negra"})
+	}
+	return holidays
+}
+
+// usHolidays segue o Federal Reserve: feriado no domingo é observado na
+// segunda; no sábado não há folga
+func usHolidays(year int) []Holiday {
+	observed := func(d time.Time) time.Time {
+		if d.Weekday() == time.Sunday {
+			return d.AddDate(0, 0, 1)
+		}
+		return d
+	}
+	holidays := []Holiday{
+		{observed(ymd(year, time.January, 1)), "New Year's Day"},
+		{nthWeekday(year, time.January, time.Monday, 3), "Birthday of Martin Luther King, Jr."},
+		{nthWeekday(year, time.February, time.Monday, 3), "Washington's Birthday"},
+		{nthWeekday(year, time.May, time.Monday, -1), "Memorial Day"},
+		{observed(ymd(year, time.July, 4)), "Independence Day"},
+		{nthWeekday(year, time.September, time.Monday, 1), "Labor Day"},
+		{nthWeekday(year, time.October, time.Monday, 2), "Columbus Day"},
+		{observed(ymd(year, time.November, 11)), "Veterans Day"},
+		{nthWeekday(year, time.November, time.Thursday, 4), "Thanksgiving Day"},
+		{observed(ymd(year, time.December, 25)), "Christmas Day"},
+	}
+	if year >= 2022 {
+		holidays = append(holidays, Holiday{observed(ymd(year, time.June, 19)), "Juneteenth National Independence Day"})
+	}
+	return holidays
+}
+
+func target2Holidays(year int) []Holiday {
+	easter := easterSunday(year)
+	return []Holiday{
+		{ymd(year, time.January, 1), "New Year's Day"},
+		{easter.AddDate(0, 0, -2), "Good Friday"},
+		{easter.AddDate(0, 0, 1), "Easter Monday"},
+		{ymd(year, time.May, 1), "Labour Day"},
+		{ymd(year, time.December, 25), "Christmas Day"},
+		{ymd(year, time.December, 26), "Christmas Holiday"},
+	}
+}
+
+// easterSunday calcula o domingo de Páscoa no calendário gregoriano (algoritmo de Meeus/Jones/Butcher)
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return ymd(year, time.Month(month), day)
+}
+
+// nthWeekday devolve o n-ésimo dia da semana do mês; n = -1 é o último
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	if n < 0 {
+		last := ymd(year, month+1, 0)
+		return last.AddDate(0, 0, -((int(last.Weekday()) - int(weekday) + 7) % 7))
+	}
+	first := ymd(year, month, 1)
+	offset := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, offset+7*(n-1))
+}
+
+func ymd(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendars(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should compute easter based brazilian holidays",
+			run:  shouldComputeEasterBasedBrazilianHolidays,
+		},
+		{
+			name: "should observe us holiday on monday when it falls on sunday",
+			run:  shouldObserveUSHolidayOnMondayWhenItFallsOnSunday,
+		},
+		{
+			name: "should close target2 on easter monday and boxing day",
+			run:  shouldCloseTarget2OnEasterMondayAndBoxingDay,
+		},
+		{
+			name: "should judge business day in calendar time zone",
+			run:  shouldJudgeBusinessDayInCalendarTimeZone,
+		},
+		{
+			name: "should include custom holidays",
+			run:  shouldIncludeCustomHolidays,
+		},
+		{
+			name: "should combine brazil and currency home calendar",
+			run:  shouldCombineBrazilAndCurrencyHomeCalendar,
+		},
+		{
+			name: "should reject unknown calendar",
+			run:  shouldRejectUnknownCalendar,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func holidayDates(holidays []Holiday) []string {
+	var out []string
+	for _, h := range holidays {
+		out = append(out, h.Data.Format(time.DateOnly))
+	}
+	return out
+}
+
+func shouldComputeEasterBasedBrazilianHolidays(t *testing.T) {
+	assert.Equal(t, "2024-03-31", easterSunday(2024).Format(time.DateOnly))
+	assert.Equal(t, "2025-04-20", easterSunday(2025).Format(time.DateOnly))
+
+	br, err := NewCalendars(nil).Get("br")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"2026-01-01", "2026-02-16", "2026-02-17", "2026-04-03", "2026-04-21", "2026-05-01", "2026-06-04",
+		"2026-09-07", "2026-10-12", "2026-11-02", "2026-11-15", "2026-11-20", "2026-12-25",
+	}, holidayDates(br.Holidays(2026)))
+	// Consciência Negra só vira feriado nacional em 2024
+	assert.NotContains(t, holidayDates(br.Holidays(2023)), "2023-11-20")
+}
+
+func shouldObserveUSHolidayOnMondayWhenItFallsOnSunday(t *testing.T) {
+	us, _ := NewCalendars(nil).Get("US")
+
+	dates := holidayDates(us.Holidays(2027))
+	// 4 de julho de 2027 é domingo
+	assert.Contains(t, dates, "2027-07-05")
+	assert.Contains(t, dates, "2027-11-25")
+	assert.Contains(t, dates, "2027-05-31")
+	assert.Contains(t, dates, "2027-01-18")
+}
+
+func shouldCloseTarget2OnEasterMondayAndBoxingDay(t *testing.T) {
+	eu, err := NewCalendars(nil).Get("TARGET2")
+	require.NoError(t, err)
+
+	assert.Equal(t, "EU", eu.Code)
+	assert.Equal(t, []string{"2026-01-01", "2026-04-03", "2026-04-06", "2026-05-01", "2026-12-25", "2026-12-26"}, holidayDates(eu.Holidays(2026)))
+	assert.False(t, eu.IsBusinessDay(time.Date(2026, 4, 6, 12, 0, 0, 0, time.UTC)))
+}
+
+func shouldJudgeBusinessDayInCalendarTimeZone(t *testing.T) {
+	br, _ := NewCalendars(nil).Get("BR")
+
+	// Sábado 02:30 UTC ainda é sexta-feira 23:30 em São Paulo
+	assert.True(t, br.IsBusinessDay(time.Date(2026, 1, 10, 2, 30, 0, 0, time.UTC)))
+	assert.False(t, br.IsBusinessDay(time.Date(2026, 1, 10, 3, 30, 0, 0, time.UTC)))
+}
+
+func shouldIncludeCustomHolidays(t *testing.T) {
+	br, _ := NewCalendars(map[string][]Holiday{"br": {{Data: day("2026-12-24"), Nome: "Véspera de Natal"}}}).Get("BR")
+
+	holiday, ok := br.IsHoliday(time.Date(2026, 12, 24, 15, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, "Véspera de Natal", holiday.Nome)
+	assert.NotContains(t, holidayDates(br.Holidays(2027)), "2027-12-24")
+}
+
+func shouldCombineBrazilAndCurrencyHomeCalendar(t *testing.T) {
+	calendars := NewCalendars(nil)
+	thanksgiving := time.Date(2026, 11, 26, 15, 0, 0, 0, time.UTC)
+	carnival := time.Date(2026, 2, 16, 15, 0, 0, 0, time.UTC)
+
+	assert.False(t, calendars.ForCurrency("usd").IsBusinessDay(thanksgiving))
+	assert.False(t, calendars.ForCurrency("USD").IsBusinessDay(carnival))
+	// Libra não tem calendário próprio: só o brasileiro vale
+	assert.True(t, calendars.ForCurrency("GBP").IsBusinessDay(thanksgiving))
+	assert.False(t, calendars.ForCurrency("GBP").IsBusinessDay(carnival))
+}
+
+func shouldRejectUnknownCalendar(t *testing.T) {
+	_, err := NewCalendars(nil).Get("JP")
+
+	assert.ErrorIs(t, err, ErrUnknownCalendar)
+}
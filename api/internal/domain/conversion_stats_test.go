@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversionStatsUseCase(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should merge archived and live days without double counting",
+			run:  shouldMergeArchivedAndLiveDaysWithoutDoubleCounting,
+		},
+		{
+			name: "should keep totals after conversions are deleted",
+			run:  shouldKeepTotalsAfterConversionsAreDeleted,
+		},
+		{
+			name: "should use only live conversions without retention",
+			run:  shouldUseOnlyLiveConversionsWithoutRetention,
+		},
+		{
+			name: "should reject inverted period",
+			run:  shouldRejectInvertedPeriod,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+// archivedHistory arquiva tudo antes de 21/01 e devolve o repositório
+func archivedHistory(t *testing.T, records ...ConversionRecord) *memoryHistoryFake {
+	repo := newMemoryHistoryFake(records...)
+	_, err := newRetention(repo, &memoryArchiveFake{files: map[string][]ConversionRecord{}}, RetentionPolicy{MaxAge: 10 * 24 * time.Hour}).Archive(context.Background())
+	require.NoError(t, err)
+	return repo
+}
+
+func shouldMergeArchivedAndLiveDaysWithoutDoubleCounting(t *testing.T) {
+	// Arquivadas, mas ainda no banco até o TTL: não podem entrar duas vezes
+	repo := archivedHistory(t,
+		conversionAt("USD", 5, jan(2, 10)),
+		conversionAt("USD", 6, jan(5, 10)),
+		conversionAt("EUR", 6, jan(5, 11)),
+		conversionAt("USD", 4, jan(25, 10)),
+	)
+
+	stats, err := NewConversionStatsUseCase(repo, newPersistenceLogger()).Execute(context.Background(), "usd", jan(1, 0), jan(31, 23))
+
+	require.NoError(t, err)
+	assert.Equal(t, "USD", stats.Moeda)
+	assert.Equal(t, 3, stats.Conversoes)
+	assert.Equal(t, 300.0, stats.ValorEntrada)
+	assert.InDelta(t, 5.0, stats.CotacaoMedia, 1e-9)
+	require.Len(t, stats.Dias, 3)
+	assert.True(t, stats.Dias[0].Arquivado)
+	assert.True(t, stats.Dias[1].Arquivado)
+	assert.False(t, stats.Dias[2].Arquivado)
+}
+
+func shouldKeepTotalsAfterConversionsAreDeleted(t *testing.T) {
+	repo := archivedHistory(t,
+		conversionAt("USD", 5, jan(2, 10)),
+		conversionAt("USD", 5, jan(25, 10)),
+	)
+	// O índice TTL apagou as conversões arquivadas
+	repo.records = repo.records[1:]
+
+	stats, err := NewConversionStatsUseCase(repo, newPersistenceLogger()).Execute(context.Background(), "", jan(1, 0), jan(31, 23))
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Conversoes)
+	assert.Zero(t, stats.CotacaoMedia)
+}
+
+func shouldUseOnlyLiveConversionsWithoutRetention(t *testing.T) {
+	repo := newMemoryHistoryFake(
+		conversionAt("USD", 5, jan(2, 10)),
+		conversionAt("USD", 5, jan(25, 10)),
+	)
+
+	stats, err := NewConversionStatsUseCase(repo, newPersistenceLogger()).Execute(context.Background(), "USD", jan(1, 0), jan(31, 23))
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Conversoes)
+	for _, d := range stats.Dias {
+		assert.False(t, d.Arquivado)
+	}
+}
+
+func shouldRejectInvertedPeriod(t *testing.T) {
+	_, err := NewConversionStatsUseCase(newMemoryHistoryFake(), newPersistenceLogger()).Execute(context.Background(), "USD", jan(10, 0), jan(1, 0))
+
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+}
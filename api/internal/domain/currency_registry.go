@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+)
+
+// defaultCurrencies são as moedas cotadas em reais pelos provedores da API
+var defaultCurrencies = []string{
+	"AED", "ARS", "AUD", "BOB", "CAD", "CHF", "CLP", "CNY", "COP", "CZK",
+	"DKK", "EUR", "GBP", "HKD", "ILS", "INR", "JPY", "KRW", "MXN", "NOK",
+	"NZD", "PEN", "PLN", "PYG", "RUB", "SAR", "SEK", "SGD", "THB", "TRY",
+	"TWD", "USD", "UYU", "ZAR",
+}
+
+// CurrencyRegistry é a lista de moedas que a API aceita fora da conversão ao
+// vivo, onde quem valida a moeda é o provedor de cotações
+type CurrencyRegistry struct {
+	codes map[string]bool
+}
+
+// NewCurrencyRegistry traz as moedas padrão mais as extras informadas
+func NewCurrencyRegistry(extra []string) *CurrencyRegistry {
+	r := &CurrencyRegistry{codes: map[string]bool{}}
+	for _, code := range append(append([]string{}, defaultCurrencies...), extra...) {
+		if code = strings.ToUpper(strings.TrimSpace(code)); code != "" {
+			r.codes[code] = true
+		}
+	}
+	return r
+}
+
+func (r *CurrencyRegistry) Known(moeda string) bool {
+	return r.codes[strings.ToUpper(moeda)]
+}
+
+// Codes devolve as moedas em ordem alfabética
+func (r *CurrencyRegistry) Codes() []string {
+	codes := make([]string, 0, len(r.codes))
+	for code := range r.codes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
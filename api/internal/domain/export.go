@@ -0,0 +1,256 @@
+package domain
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-frete/api/pkg/logger"
+	"go-frete/api/pkg/xlsx"
+)
+
+var (
+	ErrInvalidExportFormat = errors.New("formato de exportação inválido: use csv, ndjson ou xlsx")
+	ErrInvalidDelimiter    = errors.New("delimitador inválido: use um único caractere diferente de aspas, quebra de linha e do separador decimal")
+)
+
+// ExportFormat é o formato do arquivo exportado
+type ExportFormat string
+
+const (
+	ExportCSV    ExportFormat = "csv"
+	ExportNDJSON ExportFormat = "ndjson"
+	ExportXLSX   ExportFormat = "xlsx"
+)
+
+// ParseExportFormat aceita csv (padrão, quando vazio), ndjson e xlsx
+func ParseExportFormat(raw string) (ExportFormat, error) {
+	switch f := ExportFormat(strings.ToLower(raw)); f {
+	case "":
+		return ExportCSV, nil
+	case ExportCSV, ExportNDJSON, ExportXLSX:
+		return f, nil
+	}
+	return "", ErrInvalidExportFormat
+}
+
+// ContentType devolve o tipo MIME do formato
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportNDJSON:
+		return "application/x-ndjson"
+	case ExportXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// ExportOptions ajusta o CSV às planilhas de cada região
+type ExportOptions struct {
+	Format ExportFormat
+	// Separador de colunas do CSV; zero usa vírgula, ou ponto e vírgula com DecimalComma
+	Delimiter rune
+	// DecimalComma escreve os números do CSV com vírgula decimal (1234,56), como o Excel em pt-BR espera
+	DecimalComma bool
+}
+
+// ConversionStreamer percorre o histórico filtrado sem carregá-lo em memória,
+// da conversão mais antiga para a mais nova. Limit zero traz todas; um erro
+// devolvido por fn ou o cancelamento de ctx interrompem a leitura.
+type ConversionStreamer interface {
+	StreamConversions(ctx context.Context, filter ConversionFilter, fn func(ConversionRecord) error) error
+}
+
+// exportColumns são as colunas do CSV e do XLSX, na ordem
+var exportColumns = []string{"data", "currency", "valor_entrada", "cotacao", "valor_convertido", "fonte", "api_key_id", "retroativa", "data_referencia", "data_cotacao"}
+
+type ExportUseCase struct {
+	repo ConversionStreamer
+	log  logger.Logger
+}
+
+func NewExportUseCase(s ConversionStreamer, l logger.Logger) *ExportUseCase {
+	return &ExportUseCase{repo: s, log: l}
+}
+
+// Validate confere o filtro e as opções antes de a exportação começar, para
+// que o erro ainda possa ser respondido como erro
+func (uc *ExportUseCase) Validate(filter ConversionFilter, opts ExportOptions) error {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.From.After(filter.To) {
+		return ErrInvalidPeriod
+	}
+	format, err := ParseExportFormat(string(opts.Format))
+	if err != nil {
+		return err
+	}
+	if format == ExportCSV {
+		if d := opts.delimiter(); !validDelimiter(d) || (opts.DecimalComma && d == ',') {
+			return ErrInvalidDelimiter
+		}
+	}
+	return nil
+}
+
+// Execute grava em w as conversões do filtro no formato pedido e devolve quantas
+// foram exportadas. O cancelamento do contexto interrompe a leitura do banco.
+func (uc *ExportUseCase) Execute(ctx context.Context, filter ConversionFilter, opts ExportOptions, w io.Writer) (int, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	if err := uc.Validate(filter, opts); err != nil {
+		return 0, err
+	}
+	opts.Format, _ = ParseExportFormat(string(opts.Format))
+	if filter.Limit < 0 {
+		filter.Limit = 0
+	}
+	filter.Offset = 0
+
+	enc, err := newConversionEncoder(opts, w)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = uc.repo.StreamConversions(ctx, filter, func(r ConversionRecord) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		count++
+		return enc.Encode(r)
+	})
+	if err == nil {
+		err = enc.Close()
+	}
+	if err != nil {
+		log.Error("Falha ao exportar histórico", "formato", string(opts.Format), "exportadas", count, "erro", err.Error())
+		return count, err
+	}
+
+	log.Info("Histórico exportado", "formato", string(opts.Format), "exportadas", count)
+	return count, nil
+}
+
+func (o ExportOptions) delimiter() rune {
+	switch {
+	case o.Delimiter != 0:
+		return o.Delimiter
+	case o.DecimalComma:
+		return ';'
+	}
+	return ','
+}
+
+// Mesmas regras do encoding/csv, que só descobre o problema na primeira linha
+func validDelimiter(r rune) bool {
+	return r != 0 && r != utf8.RuneError && utf8.ValidRune(r) && !strings.ContainsRune("\r\n\"", r)
+}
+
+// conversionEncoder grava as conversões num formato; Close completa o arquivo
+type conversionEncoder interface {
+	Encode(r ConversionRecord) error
+	Close() error
+}
+
+func newConversionEncoder(opts ExportOptions, w io.Writer) (conversionEncoder, error) {
+	switch opts.Format {
+	case ExportNDJSON:
+		buf := bufio.NewWriter(w)
+		return &ndjsonEncoder{buf: buf, enc: json.NewEncoder(buf)}, nil
+	case ExportXLSX:
+		sheet := xlsx.NewWriter(w, "Conversões")
+		return &xlsxEncoder{sheet: sheet}, sheet.WriteHeader(exportColumns...)
+	}
+	out := csv.NewWriter(w)
+	out.Comma = opts.delimiter()
+	return &csvEncoder{out: out, decimalComma: opts.DecimalComma}, out.Write(exportColumns)
+}
+
+type csvEncoder struct {
+	out          *csv.Writer
+	decimalComma bool
+}
+
+func (e *csvEncoder) Encode(r ConversionRecord) error {
+	return e.out.Write([]string{
+		r.Data.UTC().Format(time.RFC3339),
+		r.MoedaDestino,
+		e.number(r.ValorEntrada),
+		e.number(r.Cotacao),
+		e.number(r.ValorConvertido),
+		r.Fonte,
+		r.APIKeyID,
+		strconv.FormatBool(r.Retroativa),
+		formatOptionalTime(r.DataReferencia),
+		formatOptionalTime(r.DataCotacao),
+	})
+}
+
+func (e *csvEncoder) number(v float64) string {
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	if e.decimalComma {
+		return strings.Replace(s, ".", ",", 1)
+	}
+	return s
+}
+
+func (e *csvEncoder) Close() error {
+	e.out.Flush()
+	return e.out.Error()
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+type ndjsonEncoder struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder) Encode(r ConversionRecord) error {
+	return e.enc.Encode(r)
+}
+
+func (e *ndjsonEncoder) Close() error {
+	return e.buf.Flush()
+}
+
+type xlsxEncoder struct {
+	sheet *xlsx.Writer
+}
+
+func (e *xlsxEncoder) Encode(r ConversionRecord) error {
+	return e.sheet.WriteRow(
+		r.Data,
+		r.MoedaDestino,
+		r.ValorEntrada,
+		r.Cotacao,
+		r.ValorConvertido,
+		r.Fonte,
+		r.APIKeyID,
+		r.Retroativa,
+		optionalTime(r.DataReferencia),
+		optionalTime(r.DataCotacao),
+	)
+}
+
+func (e *xlsxEncoder) Close() error {
+	return e.sheet.Close()
+}
+
+func optionalTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
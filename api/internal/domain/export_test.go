@@ -0,0 +1,212 @@
+package domain
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sliceStreamerFake entrega os registros um a um, como o cursor do Mongo
+type sliceStreamerFake struct {
+	records []ConversionRecord
+	filter  ConversionFilter
+	ctx     context.Context
+	err     error
+}
+
+func (f *sliceStreamerFake) StreamConversions(ctx context.Context, filter ConversionFilter, fn func(ConversionRecord) error) error {
+	f.filter = filter
+	f.ctx = ctx
+	for _, r := range f.records {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return f.err
+}
+
+func exportSample() []ConversionRecord {
+	cotacaoEm := time.Date(2026, 1, 2, 18, 0, 0, 0, time.UTC)
+	return []ConversionRecord{
+		{MoedaDestino: "USD", Cotacao: 5.4321, ValorEntrada: 1234.5, ValorConvertido: 227.26, Data: time.Date(2026, 1, 5, 12, 30, 0, 0, time.UTC), Fonte: "awesomeapi", APIKeyID: "k1"},
+		{MoedaDestino: "EUR", Cotacao: 6.1, ValorEntrada: 100, ValorConvertido: 16.39, Data: time.Date(2026, 1, 6, 9, 0, 0, 0, time.UTC), Fonte: "frankfurter, \"ecb\"", Retroativa: true, DataCotacao: &cotacaoEm},
+	}
+}
+
+func TestExportUseCase(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should export csv with header and quoted fields",
+			run:  shouldExportCSVWithHeaderAndQuotedFields,
+		},
+		{
+			name: "should export csv with decimal comma and semicolon",
+			run:  shouldExportCSVWithDecimalCommaAndSemicolon,
+		},
+		{
+			name: "should export one json object per line",
+			run:  shouldExportOneJSONObjectPerLine,
+		},
+		{
+			name: "should export readable xlsx workbook",
+			run:  shouldExportReadableXLSXWorkbook,
+		},
+		{
+			name: "should stream whole filter without pagination",
+			run:  shouldStreamWholeFilterWithoutPagination,
+		},
+		{
+			name: "should reject invalid options before streaming",
+			run:  shouldRejectInvalidOptionsBeforeStreaming,
+		},
+		{
+			name: "should stop streaming when context is canceled",
+			run:  shouldStopStreamingWhenContextIsCanceled,
+		},
+		{
+			name: "should return cursor error",
+			run:  shouldReturnCursorError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldExportCSVWithHeaderAndQuotedFields(t *testing.T) {
+	var out bytes.Buffer
+	n, err := NewExportUseCase(&sliceStreamerFake{records: exportSample()}, newPersistenceLogger()).
+		Execute(context.Background(), ConversionFilter{}, ExportOptions{}, &out)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "data,currency,valor_entrada,cotacao,valor_convertido,fonte,api_key_id,retroativa,data_referencia,data_cotacao", lines[0])
+	assert.Equal(t, "2026-01-05T12:30:00Z,USD,1234.5,5.4321,227.26,awesomeapi,k1,false,,", lines[1])
+	assert.Equal(t, `2026-01-06T09:00:00Z,EUR,100,6.1,16.39,"frankfurter, ""ecb""",,true,,2026-01-02T18:00:00Z`, lines[2])
+}
+
+func shouldExportCSVWithDecimalCommaAndSemicolon(t *testing.T) {
+	var out bytes.Buffer
+	_, err := NewExportUseCase(&sliceStreamerFake{records: exportSample()[:1]}, newPersistenceLogger()).
+		Execute(context.Background(), ConversionFilter{}, ExportOptions{Format: ExportCSV, DecimalComma: true}, &out)
+
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, "2026-01-05T12:30:00Z;USD;1234,5;5,4321;227,26;awesomeapi;k1;false;;", lines[1])
+
+	out.Reset()
+	_, err = NewExportUseCase(&sliceStreamerFake{records: exportSample()[:1]}, newPersistenceLogger()).
+		Execute(context.Background(), ConversionFilter{}, ExportOptions{Delimiter: '\t', DecimalComma: true}, &out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "USD\t1234,5\t")
+}
+
+func shouldExportOneJSONObjectPerLine(t *testing.T) {
+	var out bytes.Buffer
+	_, err := NewExportUseCase(&sliceStreamerFake{records: exportSample()}, newPersistenceLogger()).
+		Execute(context.Background(), ConversionFilter{}, ExportOptions{Format: ExportNDJSON}, &out)
+
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	var record ConversionRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, "EUR", record.MoedaDestino)
+	assert.True(t, record.Retroativa)
+}
+
+func shouldExportReadableXLSXWorkbook(t *testing.T) {
+	var out bytes.Buffer
+	_, err := NewExportUseCase(&sliceStreamerFake{records: exportSample()}, newPersistenceLogger()).
+		Execute(context.Background(), ConversionFilter{}, ExportOptions{Format: "XLSX"}, &out)
+	require.NoError(t, err)
+
+	book, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	require.NoError(t, err)
+	parts := map[string]string{}
+	for _, f := range book.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		body, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		parts[f.Name] = string(body)
+	}
+
+	require.Contains(t, parts, "[Content_Types].xml")
+	require.Contains(t, parts, "xl/styles.xml")
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Conversões" sheetId="1" r:id="rId1"/>`)
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<c r="A1" t="inlineStr" s="2"><is><t xml:space="preserve">data</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="C2"><v>1234.5</v></c>`)
+	// 05/01/2026 12:30 UTC no calendário do Excel
+	assert.Contains(t, sheet, `<c r="A2" s="1"><v>46027.520833333336</v></c>`)
+	assert.Contains(t, sheet, `<c r="H3" t="b"><v>1</v></c>`)
+	assert.Contains(t, sheet, "frankfurter, &#34;ecb&#34;")
+}
+
+func shouldStreamWholeFilterWithoutPagination(t *testing.T) {
+	streamer := &sliceStreamerFake{}
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := NewExportUseCase(streamer, newPersistenceLogger()).
+		Execute(context.Background(), ConversionFilter{Currency: "USD", From: from, Offset: 20}, ExportOptions{}, io.Discard)
+
+	require.NoError(t, err)
+	assert.Equal(t, ConversionFilter{Currency: "USD", From: from}, streamer.filter)
+}
+
+func shouldRejectInvalidOptionsBeforeStreaming(t *testing.T) {
+	uc := NewExportUseCase(&sliceStreamerFake{}, newPersistenceLogger())
+	from := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.ErrorIs(t, uc.Validate(ConversionFilter{}, ExportOptions{Format: "pdf"}), ErrInvalidExportFormat)
+	assert.ErrorIs(t, uc.Validate(ConversionFilter{}, ExportOptions{Delimiter: '"'}), ErrInvalidDelimiter)
+	assert.ErrorIs(t, uc.Validate(ConversionFilter{}, ExportOptions{Delimiter: ',', DecimalComma: true}), ErrInvalidDelimiter)
+	assert.ErrorIs(t, uc.Validate(ConversionFilter{From: from, To: from.AddDate(0, 0, -1)}, ExportOptions{}), ErrInvalidPeriod)
+	assert.NoError(t, uc.Validate(ConversionFilter{}, ExportOptions{Format: ExportNDJSON, Delimiter: '"'}))
+
+	var out bytes.Buffer
+	_, err := uc.Execute(context.Background(), ConversionFilter{}, ExportOptions{Format: "pdf"}, &out)
+	assert.ErrorIs(t, err, ErrInvalidExportFormat)
+	assert.Zero(t, out.Len())
+}
+
+func shouldStopStreamingWhenContextIsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	streamer := &sliceStreamerFake{records: exportSample()}
+	n, err := NewExportUseCase(streamer, newPersistenceLogger()).
+		Execute(ctx, ConversionFilter{}, ExportOptions{}, io.Discard)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, n)
+	// O repositório recebe o mesmo contexto e pode fechar o cursor no banco
+	assert.Equal(t, ctx, streamer.ctx)
+}
+
+func shouldReturnCursorError(t *testing.T) {
+	cursorErr := errors.New("cursor perdido")
+
+	n, err := NewExportUseCase(&sliceStreamerFake{records: exportSample(), err: cursorErr}, newPersistenceLogger()).
+		Execute(context.Background(), ConversionFilter{}, ExportOptions{Format: ExportNDJSON}, io.Discard)
+
+	assert.ErrorIs(t, err, cursorErr)
+	assert.Equal(t, 2, n)
+}
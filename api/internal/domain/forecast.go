@@ -0,0 +1,351 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"go-frete/api/pkg/logger"
+)
+
+// Modelos de previsão
+const (
+	// A próxima cotação é a última; o erro cresce com a raiz do horizonte
+	ModelRandomWalk = "passeio_aleatorio"
+	// Passeio aleatório com a tendência média do histórico
+	ModelDrift = "drift"
+	// Suavização exponencial simples, com alfa escolhido pelo menor erro no histórico
+	ModelSmoothing = "suavizacao_exponencial"
+)
+
+// ForecastModels são todos os modelos, na ordem em que aparecem na resposta
+var ForecastModels = []string{ModelRandomWalk, ModelDrift, ModelSmoothing}
+
+const (
+	DefaultForecastHorizon = 30
+	MaxForecastHorizon     = 180
+	// Fechamentos diários usados para ajustar os modelos quando From não é informado
+	DefaultForecastHistory = 180 * 24 * time.Hour
+	DefaultForecastLevel   = 95
+	// Mínimo de fechamentos diários para ajustar os modelos e para cada origem do backtest
+	MinForecastSamples = 10
+)
+
+// forecastZ é o quantil da normal de cada nível de confiança aceito
+var forecastZ = map[int]float64{80: 1.2816, 90: 1.6449, 95: 1.9600, 99: 2.5758}
+
+// forecastLocation define o dia de cada fechamento: o da praça do real
+var forecastLocation = func() *time.Location {
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}()
+
+var (
+	ErrInvalidHorizon    = fmt.Errorf("horizonte deve ficar entre 1 e %d dias", MaxForecastHorizon)
+	ErrInvalidModel      = errors.New("modelo inválido: use " + strings.Join(ForecastModels, ", "))
+	ErrInvalidConfidence = errors.New("nível de confiança deve ser 80, 90, 95 ou 99")
+	ErrNotEnoughRates    = fmt.Errorf("são necessários ao menos %d fechamentos diários no período", MinForecastSamples)
+)
+
+type ForecastRequest struct {
+	Moeda string
+	// Período do histórico; sem To usa agora e sem From os DefaultForecastHistory anteriores
+	From, To time.Time
+	// Dias corridos após o último fechamento (padrão 30); só os dias úteis são previstos
+	HorizonteDias int
+	// Vazio usa todos
+	Modelos []string
+	// Nível de confiança do intervalo, em % (padrão 95)
+	Nivel int
+}
+
+// ForecastPoint é a previsão de um dia útil com o intervalo de confiança
+type ForecastPoint struct {
+	Data     time.Time `json:"data"`
+	Previsao float64   `json:"previsao"`
+	Inferior float64   `json:"inferior"`
+	Superior float64   `json:"superior"`
+}
+
+// BacktestReport compara as previsões feitas com parte do histórico às cotações
+// que vieram depois, para cada origem a partir da metade da série
+type BacktestReport struct {
+	Origens  int     `json:"origens"`
+	Amostras int     `json:"amostras"`
+	MAE      float64 `json:"mae"`
+	RMSE     float64 `json:"rmse"`
+	// Erro percentual absoluto médio
+	MAPE float64 `json:"mape"`
+	// % das cotações que caíram dentro do intervalo de confiança
+	Cobertura float64 `json:"cobertura"`
+}
+
+type ModelForecast struct {
+	Modelo string `json:"modelo"`
+	// Só na suavização exponencial
+	Alpha     *float64        `json:"alpha,omitempty"`
+	Previsoes []ForecastPoint `json:"previsoes"`
+	// Ausente quando o histórico é curto demais para testar
+	Backtest *BacktestReport `json:"backtest,omitempty"`
+}
+
+type RateForecast struct {
+	Moeda string    `json:"moeda"`
+	De    time.Time `json:"de"`
+	Ate   time.Time `json:"ate"`
+	// Dia e valor do último fechamento, ponto de partida das previsões
+	Base          time.Time `json:"base"`
+	UltimaCotacao float64   `json:"ultima_cotacao"`
+	Amostras      int       `json:"amostras"`
+	HorizonteDias int       `json:"horizonte_dias"`
+	Nivel         int       `json:"nivel"`
+	// Modelo com o menor RMSE no backtest; vazio sem backtest
+	Recomendado string          `json:"recomendado,omitempty"`
+	Modelos     []ModelForecast `json:"modelos"`
+}
+
+// ForecastUseCase prevê a cotação dos próximos dias úteis a partir dos
+// fechamentos diários da série coletada
+type ForecastUseCase struct {
+	repo      RateSeriesReader
+	calendars CurrencyCalendars
+	log       logger.Logger
+}
+
+func NewForecastUseCase(r RateSeriesReader, l logger.Logger) *ForecastUseCase {
+	return &ForecastUseCase{repo: r, log: l}
+}
+
+// WithCalendars descarta do histórico e das previsões os fins de semana e feriados
+func (uc *ForecastUseCase) WithCalendars(c CurrencyCalendars) *ForecastUseCase {
+	uc.calendars = c
+	return uc
+}
+
+func (uc *ForecastUseCase) Execute(ctx context.Context, req ForecastRequest) (RateForecast, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	if req.To.IsZero() {
+		req.To = time.Now()
+	}
+	if req.From.IsZero() {
+		req.From = req.To.Add(-DefaultForecastHistory)
+	}
+	if req.From.After(req.To) {
+		return RateForecast{}, ErrInvalidPeriod
+	}
+	if req.HorizonteDias == 0 {
+		req.HorizonteDias = DefaultForecastHorizon
+	}
+	if req.HorizonteDias < 1 || req.HorizonteDias > MaxForecastHorizon {
+		return RateForecast{}, ErrInvalidHorizon
+	}
+	if req.Nivel == 0 {
+		req.Nivel = DefaultForecastLevel
+	}
+	z, ok := forecastZ[req.Nivel]
+	if !ok {
+		return RateForecast{}, ErrInvalidConfidence
+	}
+	modelos := ForecastModels
+	if len(req.Modelos) > 0 {
+		for _, m := range req.Modelos {
+			if !slices.Contains(ForecastModels, m) {
+				return RateForecast{}, ErrInvalidModel
+			}
+		}
+		modelos = slices.DeleteFunc(slices.Clone(ForecastModels), func(m string) bool { return !slices.Contains(req.Modelos, m) })
+	}
+
+	moeda := strings.ToUpper(req.Moeda)
+	series, err := businessSeries(uc.repo, uc.calendars, moeda, req.From, req.To)
+	if err != nil {
+		log.Error("Falha ao buscar série de cotações", "erro", err.Error(), "moeda", moeda)
+		return RateForecast{}, err
+	}
+	if len(series) == 0 {
+		return RateForecast{}, ErrNoRates
+	}
+	closes := dailyCloses(series)
+	if len(closes) < MinForecastSamples {
+		return RateForecast{}, ErrNotEnoughRates
+	}
+
+	values := make([]float64, len(closes))
+	for i, c := range closes {
+		values[i] = c.Cotacao
+	}
+	base := closes[len(closes)-1].Data
+	days := uc.businessDaysAfter(moeda, base, req.HorizonteDias)
+
+	out := RateForecast{
+		Moeda:         moeda,
+		De:            req.From,
+		Ate:           req.To,
+		Base:          base,
+		UltimaCotacao: values[len(values)-1],
+		Amostras:      len(values),
+		HorizonteDias: req.HorizonteDias,
+		Nivel:         req.Nivel,
+	}
+	bestRMSE := math.Inf(1)
+	for _, modelo := range modelos {
+		fit := fitForecast(modelo, values)
+		mf := ModelForecast{Modelo: modelo, Previsoes: make([]ForecastPoint, len(days))}
+		if modelo == ModelSmoothing {
+			alpha := fit.alpha
+			mf.Alpha = &alpha
+		}
+		for h, day := range days {
+			p := fit.point(h + 1)
+			margem := z * fit.sigma(h+1)
+			mf.Previsoes[h] = ForecastPoint{Data: day, Previsao: p, Inferior: max(0, p-margem), Superior: p + margem}
+		}
+		if report, ok := backtest(modelo, values, max(len(days), 1), z); ok {
+			mf.Backtest = &report
+			if report.RMSE < bestRMSE {
+				bestRMSE, out.Recomendado = report.RMSE, modelo
+			}
+		}
+		out.Modelos = append(out.Modelos, mf)
+	}
+
+	log.Info("Previsão calculada", "moeda", moeda, "amostras", out.Amostras, "horizonte_dias", req.HorizonteDias, "recomendado", out.Recomendado)
+	return out, nil
+}
+
+// businessDaysAfter lista a meia-noite de cada dia útil nos dias corridos após base
+func (uc *ForecastUseCase) businessDaysAfter(moeda string, base time.Time, dias int) []time.Time {
+	var cal BusinessCalendar
+	if uc.calendars != nil {
+		cal = uc.calendars.ForCurrency(moeda)
+	}
+	var out []time.Time
+	for d := 1; d <= dias; d++ {
+		day := base.AddDate(0, 0, d)
+		// O meio-dia cai no mesmo dia nos fusos de todos os calendários
+		if cal == nil || cal.IsBusinessDay(day.Add(12*time.Hour)) {
+			out = append(out, day)
+		}
+	}
+	return out
+}
+
+// dailyCloses fica com a última cotação de cada dia, datada da meia-noite
+func dailyCloses(series []RateSnapshot) []RateSnapshot {
+	var out []RateSnapshot
+	for _, s := range series {
+		y, m, d := s.Data.In(forecastLocation).Date()
+		day := time.Date(y, m, d, 0, 0, 0, 0, forecastLocation)
+		if n := len(out); n > 0 && out[n-1].Data.Equal(day) {
+			out[n-1].Cotacao = s.Cotacao
+			continue
+		}
+		out = append(out, RateSnapshot{Moeda: s.Moeda, Cotacao: s.Cotacao, Fonte: s.Fonte, Data: day})
+	}
+	return out
+}
+
+// fittedForecast é um modelo ajustado: a previsão e o desvio padrão do erro h
+// passos à frente
+type fittedForecast struct {
+	point func(h int) float64
+	sigma func(h int) float64
+	alpha float64
+}
+
+func fitForecast(modelo string, y []float64) fittedForecast {
+	n := len(y)
+	last := y[n-1]
+	diffs := make([]float64, n-1)
+	for i := 1; i < n; i++ {
+		diffs[i-1] = y[i] - y[i-1]
+	}
+
+	switch modelo {
+	case ModelDrift:
+		drift := (last - y[0]) / float64(n-1)
+		var quadrados float64
+		for _, d := range diffs {
+			quadrados += (d - drift) * (d - drift)
+		}
+		sigma := math.Sqrt(quadrados / float64(max(n-2, 1)))
+		return fittedForecast{
+			point: func(h int) float64 { return last + float64(h)*drift },
+			// Inclui a incerteza da própria tendência estimada
+			sigma: func(h int) float64 { return sigma * math.Sqrt(float64(h)*(1+float64(h)/float64(n-1))) },
+		}
+	case ModelSmoothing:
+		alpha, level, sse := 0.0, 0.0, math.Inf(1)
+		for step := 1; step <= 20; step++ {
+			a := float64(step) / 20
+			l, s := y[0], 0.0
+			for _, v := range y[1:] {
+				e := v - l
+				s += e * e
+				l += a * e
+			}
+			if s < sse {
+				alpha, level, sse = a, l, s
+			}
+		}
+		sigma := math.Sqrt(sse / float64(n-1))
+		return fittedForecast{
+			point: func(int) float64 { return level },
+			sigma: func(h int) float64 { return sigma * math.Sqrt(1+float64(h-1)*alpha*alpha) },
+			alpha: alpha,
+		}
+	default:
+		var quadrados float64
+		for _, d := range diffs {
+			quadrados += d * d
+		}
+		sigma := math.Sqrt(quadrados / float64(n-1))
+		return fittedForecast{
+			point: func(int) float64 { return last },
+			sigma: func(h int) float64 { return sigma * math.Sqrt(float64(h)) },
+		}
+	}
+}
+
+// backtest ajusta o modelo com os fechamentos até cada origem, da metade da
+// série em diante, e mede o erro das previsões de até horizon passos contra o
+// que aconteceu depois
+func backtest(modelo string, y []float64, horizon int, z float64) (BacktestReport, bool) {
+	first := max(MinForecastSamples, len(y)/2)
+	if first >= len(y) {
+		return BacktestReport{}, false
+	}
+	var report BacktestReport
+	var abs, quadrados, pct float64
+	var dentro int
+	for origin := first; origin < len(y); origin++ {
+		fit := fitForecast(modelo, y[:origin])
+		report.Origens++
+		for h := 1; h <= horizon && origin+h-1 < len(y); h++ {
+			actual := y[origin+h-1]
+			p := fit.point(h)
+			e := actual - p
+			abs += math.Abs(e)
+			quadrados += e * e
+			pct += math.Abs(e) / actual * 100
+			if margem := z * fit.sigma(h); math.Abs(e) <= margem {
+				dentro++
+			}
+			report.Amostras++
+		}
+	}
+	n := float64(report.Amostras)
+	report.MAE = abs / n
+	report.RMSE = math.Sqrt(quadrados / n)
+	report.MAPE = pct / n
+	report.Cobertura = float64(dentro) / n * 100
+	return report, true
+}
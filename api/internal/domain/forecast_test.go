@@ -0,0 +1,201 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForecastUseCase_Execute(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should forecast with all models and recommend the best",
+			run:  shouldForecastWithAllModelsAndRecommendTheBest,
+		},
+		{
+			name: "should fit models on daily closes",
+			run:  shouldFitModelsOnDailyCloses,
+		},
+		{
+			name: "should forecast only business days with calendar",
+			run:  shouldForecastOnlyBusinessDaysWithCalendar,
+		},
+		{
+			name: "should report backtest errors",
+			run:  shouldReportBacktestErrors,
+		},
+		{
+			name: "should reject invalid forecast request",
+			run:  shouldRejectInvalidForecastRequest,
+		},
+		{
+			name: "should require minimum daily closes",
+			run:  shouldRequireMinimumDailyCloses,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+// dailySeries cria um fechamento por dia, às 15h UTC, a partir de start
+func dailySeries(start time.Time, cotacoes ...float64) []RateSnapshot {
+	series := make([]RateSnapshot, len(cotacoes))
+	for i, c := range cotacoes {
+		series[i] = RateSnapshot{Moeda: "USD", Cotacao: c, Data: start.AddDate(0, 0, i).Add(15 * time.Hour)}
+	}
+	return series
+}
+
+func newForecastFake(series []RateSnapshot) *ForecastUseCase {
+	searcherMock := new(rateSeriesReaderMock)
+	searcherMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return(series, nil)
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	return NewForecastUseCase(searcherMock, loggerMock)
+}
+
+func shouldForecastWithAllModelsAndRecommendTheBest(t *testing.T) {
+	cotacoes := make([]float64, 20)
+	for i := range cotacoes {
+		cotacoes[i] = 5 + float64(i)/100
+	}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	uc := newForecastFake(dailySeries(start, cotacoes...))
+
+	out, err := uc.Execute(context.Background(), ForecastRequest{Moeda: "usd", HorizonteDias: 7})
+
+	require.NoError(t, err)
+	assert.Equal(t, "USD", out.Moeda)
+	assert.Equal(t, 20, out.Amostras)
+	assert.InDelta(t, 5.19, out.UltimaCotacao, 0.0001)
+	assert.Equal(t, DefaultForecastLevel, out.Nivel)
+	assert.Equal(t, DefaultForecastHistory, out.Ate.Sub(out.De))
+	require.Len(t, out.Modelos, 3)
+
+	walk, drift, smoothing := out.Modelos[0], out.Modelos[1], out.Modelos[2]
+	assert.Equal(t, ModelRandomWalk, walk.Modelo)
+	require.Len(t, walk.Previsoes, 7)
+	assert.InDelta(t, 5.19, walk.Previsoes[6].Previsao, 0.0001)
+	assert.Less(t, walk.Previsoes[0].Superior-walk.Previsoes[0].Inferior, walk.Previsoes[6].Superior-walk.Previsoes[6].Inferior)
+	assert.Equal(t, out.Base.AddDate(0, 0, 7), walk.Previsoes[6].Data)
+	assert.Nil(t, walk.Alpha)
+
+	// Série perfeitamente linear: o drift acerta e não tem incerteza
+	assert.InDelta(t, 5.26, drift.Previsoes[6].Previsao, 0.0001)
+	assert.InDelta(t, 0, drift.Previsoes[6].Superior-drift.Previsoes[6].Inferior, 0.0001)
+	assert.InDelta(t, 0, drift.Backtest.RMSE, 0.0001)
+	assert.Equal(t, ModelDrift, out.Recomendado)
+
+	require.NotNil(t, smoothing.Alpha)
+	assert.Equal(t, 1.0, *smoothing.Alpha)
+	require.NotNil(t, smoothing.Backtest)
+	assert.Equal(t, 10, smoothing.Backtest.Origens)
+}
+
+func shouldFitModelsOnDailyCloses(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	series := dailySeries(start, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5)
+	// Mais cotações no último dia: vale a última. Às 2h UTC ainda é o dia anterior em Brasília.
+	last := series[len(series)-1].Data
+	series = append(series,
+		RateSnapshot{Moeda: "USD", Cotacao: 5.2, Data: last.Add(2 * time.Hour)},
+		RateSnapshot{Moeda: "USD", Cotacao: 5.4, Data: last.Add(11 * time.Hour)},
+	)
+	uc := newForecastFake(series)
+
+	out, err := uc.Execute(context.Background(), ForecastRequest{Moeda: "USD", HorizonteDias: 1, Modelos: []string{ModelRandomWalk}})
+
+	require.NoError(t, err)
+	assert.Equal(t, 10, out.Amostras)
+	assert.Equal(t, 5.4, out.UltimaCotacao)
+	assert.Equal(t, time.Date(2026, 1, 10, 0, 0, 0, 0, forecastLocation), out.Base)
+	require.Len(t, out.Modelos, 1)
+	assert.Nil(t, out.Modelos[0].Backtest)
+	assert.Empty(t, out.Recomendado)
+}
+
+func shouldForecastOnlyBusinessDaysWithCalendar(t *testing.T) {
+	// De 2025-12-22 a 2026-01-09 (sexta), com Natal e Ano Novo no meio
+	start := time.Date(2025, 12, 22, 0, 0, 0, 0, time.UTC)
+	cotacoes := make([]float64, 19)
+	for i := range cotacoes {
+		cotacoes[i] = 5 + float64(i%4)/10
+	}
+	uc := newForecastFake(dailySeries(start, cotacoes...)).WithCalendars(NewCalendars(nil))
+
+	out, err := uc.Execute(context.Background(), ForecastRequest{Moeda: "USD", HorizonteDias: 4})
+
+	require.NoError(t, err)
+	assert.Equal(t, 13, out.Amostras)
+	assert.Equal(t, time.Date(2026, 1, 9, 0, 0, 0, 0, forecastLocation), out.Base)
+	require.Len(t, out.Modelos[0].Previsoes, 2)
+	assert.Equal(t, time.Date(2026, 1, 12, 0, 0, 0, 0, forecastLocation), out.Modelos[0].Previsoes[0].Data)
+	assert.Equal(t, time.Date(2026, 1, 13, 0, 0, 0, 0, forecastLocation), out.Modelos[0].Previsoes[1].Data)
+}
+
+func shouldReportBacktestErrors(t *testing.T) {
+	cotacoes := make([]float64, 20)
+	for i := range cotacoes {
+		cotacoes[i] = 5 + float64(i%2)
+	}
+	uc := newForecastFake(dailySeries(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), cotacoes...))
+
+	out, err := uc.Execute(context.Background(), ForecastRequest{Moeda: "USD", HorizonteDias: 1, Modelos: []string{ModelRandomWalk}, Nivel: 99})
+
+	require.NoError(t, err)
+	report := out.Modelos[0].Backtest
+	require.NotNil(t, report)
+	// Cada dia repete o anterior e erra por 1
+	assert.Equal(t, 10, report.Origens)
+	assert.Equal(t, 10, report.Amostras)
+	assert.InDelta(t, 1, report.MAE, 0.0001)
+	assert.InDelta(t, 1, report.RMSE, 0.0001)
+	assert.InDelta(t, 18.3333, report.MAPE, 0.0001)
+	assert.Equal(t, 100.0, report.Cobertura)
+}
+
+func shouldRejectInvalidForecastRequest(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	uc := NewForecastUseCase(searcherMock, new(loggermock.LoggerMock))
+
+	for _, tc := range []struct {
+		req ForecastRequest
+		err error
+	}{
+		{ForecastRequest{Moeda: "USD", HorizonteDias: MaxForecastHorizon + 1}, ErrInvalidHorizon},
+		{ForecastRequest{Moeda: "USD", HorizonteDias: -1}, ErrInvalidHorizon},
+		{ForecastRequest{Moeda: "USD", Modelos: []string{"arima"}}, ErrInvalidModel},
+		{ForecastRequest{Moeda: "USD", Nivel: 85}, ErrInvalidConfidence},
+		{ForecastRequest{Moeda: "USD", From: time.Now(), To: time.Now().Add(-time.Hour)}, ErrInvalidPeriod},
+	} {
+		_, err := uc.Execute(context.Background(), tc.req)
+		assert.ErrorIs(t, err, tc.err)
+	}
+	searcherMock.AssertNotCalled(t, "GetRateSeries", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func shouldRequireMinimumDailyCloses(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Muitas cotações, mas em poucos dias
+	var series []RateSnapshot
+	for h := range 5 * 24 {
+		series = append(series, RateSnapshot{Moeda: "USD", Cotacao: 5, Data: start.Add(time.Duration(h) * time.Hour)})
+	}
+
+	_, err := newForecastFake(series).Execute(context.Background(), ForecastRequest{Moeda: "USD"})
+	assert.ErrorIs(t, err, ErrNotEnoughRates)
+
+	_, err = newForecastFake([]RateSnapshot{}).Execute(context.Background(), ForecastRequest{Moeda: "USD"})
+	assert.ErrorIs(t, err, ErrNoRates)
+}
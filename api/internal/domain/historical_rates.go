@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Quanto voltar no tempo procurando a cotação vigente: cobre fins de semana e feriados
+const AsOfLookback = 7 * 24 * time.Hour
+
+var (
+	ErrRateNotAvailable = errors.New("sem cotação disponível para a data informada")
+	ErrAsOfInFuture     = errors.New("a data da conversão não pode estar no futuro")
+)
+
+// RateHistory descobre a cotação vigente num instante passado
+type RateHistory interface {
+	RateAt(moeda string, at time.Time) (RateSnapshot, error)
+}
+
+// HistoricalRateResolver procura a cotação vigente primeiro nas cotações
+// gravadas e, se não houver, na série diária do provedor (quando informado)
+type HistoricalRateResolver struct {
+	series    RateSeriesReader
+	provider  HistoricalRateProvider
+	calendars CurrencyCalendars
+}
+
+func NewHistoricalRateResolver(r RateSeriesReader, p HistoricalRateProvider) *HistoricalRateResolver {
+	return &HistoricalRateResolver{series: r, provider: p}
+}
+
+// WithCalendars faz datas em fins de semana e feriados usarem a cotação do dia útil anterior
+func (h *HistoricalRateResolver) WithCalendars(c CurrencyCalendars) *HistoricalRateResolver {
+	h.calendars = c
+	return h
+}
+
+// RateAt devolve a última cotação com data até at, dentro de AsOfLookback
+func (h *HistoricalRateResolver) RateAt(moeda string, at time.Time) (RateSnapshot, error) {
+	moeda = strings.ToUpper(moeda)
+	var cal BusinessCalendar
+	if h.calendars != nil {
+		cal = h.calendars.ForCurrency(moeda)
+	}
+
+	stored, err := h.series.GetRateSeries(moeda, at.Add(-AsOfLookback), at)
+	if err != nil {
+		return RateSnapshot{}, err
+	}
+	if stored = onlyBusinessDays(stored, cal); len(stored) > 0 {
+		return stored[len(stored)-1], nil
+	}
+
+	if h.provider == nil {
+		return RateSnapshot{}, ErrRateNotAvailable
+	}
+	fetched, err := h.provider.GetDailyRates(moeda, startOfDay(at.Add(-AsOfLookback)), startOfDay(at))
+	if err != nil {
+		return RateSnapshot{}, err
+	}
+	// A série vem da mais antiga para a mais nova; o fechamento do dia pode ser depois de at
+	fetched = onlyBusinessDays(fetched, cal)
+	for i := len(fetched) - 1; i >= 0; i-- {
+		if !fetched[i].Data.After(at) && fetched[i].Cotacao != 0 {
+			return fetched[i], nil
+		}
+	}
+	return RateSnapshot{}, ErrRateNotAvailable
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoricalRateResolver_RateAt(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should use last stored snapshot before instant",
+			run:  shouldUseLastStoredSnapshotBeforeInstant,
+		},
+		{
+			name: "should fall back to previous business day",
+			run:  shouldFallBackToPreviousBusinessDay,
+		},
+		{
+			name: "should fall back to provider daily series",
+			run:  shouldFallBackToProviderDailySeries,
+		},
+		{
+			name: "should return rate not available without provider",
+			run:  shouldReturnRateNotAvailableWithoutProvider,
+		},
+		{
+			name: "should propagate provider error",
+			run:  shouldPropagateProviderError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldUseLastStoredSnapshotBeforeInstant(t *testing.T) {
+	repo := newBackfillRepositoryFake(
+		RateSnapshot{Moeda: "USD", Cotacao: 5.1, Data: day("2026-01-05").Add(9 * time.Hour)},
+		RateSnapshot{Moeda: "USD", Cotacao: 5.2, Data: day("2026-01-05").Add(12 * time.Hour)},
+		RateSnapshot{Moeda: "USD", Cotacao: 5.3, Data: day("2026-01-05").Add(16 * time.Hour)},
+	)
+	provider := &historicalProviderFake{}
+
+	rate, err := NewHistoricalRateResolver(repo, provider).RateAt("usd", day("2026-01-05").Add(13*time.Hour))
+
+	require.NoError(t, err)
+	assert.Equal(t, 5.2, rate.Cotacao)
+	assert.Empty(t, provider.calls)
+}
+
+func shouldFallBackToPreviousBusinessDay(t *testing.T) {
+	// O coletor grava no fim de semana a mesma cotação parada
+	repo := newBackfillRepositoryFake(
+		RateSnapshot{Moeda: "USD", Cotacao: 5.2, Data: day("2026-01-09").Add(20 * time.Hour)},
+		RateSnapshot{Moeda: "USD", Cotacao: 5.25, Data: day("2026-01-10").Add(12 * time.Hour)},
+	)
+
+	rate, err := NewHistoricalRateResolver(repo, nil).WithCalendars(NewCalendars(nil)).RateAt("USD", day("2026-01-11").Add(15*time.Hour))
+
+	require.NoError(t, err)
+	assert.Equal(t, 5.2, rate.Cotacao)
+}
+
+func shouldFallBackToProviderDailySeries(t *testing.T) {
+	provider := &historicalProviderFake{}
+
+	// O fake fecha cada dia às 18h: às 10h do dia 5 vale o fechamento do dia 4
+	rate, err := NewHistoricalRateResolver(newBackfillRepositoryFake(), provider).RateAt("USD", day("2026-01-05").Add(10*time.Hour))
+
+	require.NoError(t, err)
+	assert.Equal(t, day("2026-01-04").Add(18*time.Hour), rate.Data)
+	assert.Equal(t, [][2]string{{"2025-12-29", "2026-01-05"}}, provider.calls)
+}
+
+func shouldReturnRateNotAvailableWithoutProvider(t *testing.T) {
+	_, err := NewHistoricalRateResolver(newBackfillRepositoryFake(), nil).RateAt("USD", day("2026-01-05"))
+
+	assert.ErrorIs(t, err, ErrRateNotAvailable)
+}
+
+func shouldPropagateProviderError(t *testing.T) {
+	provider := &historicalProviderFake{failOn: map[string]error{"USD": errors.New("timeout")}}
+
+	_, err := NewHistoricalRateResolver(newBackfillRepositoryFake(), provider).RateAt("USD", day("2026-01-05"))
+
+	assert.EqualError(t, err, "timeout")
+}
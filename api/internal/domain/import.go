@@ -0,0 +1,568 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-frete/api/pkg/logger"
+)
+
+var (
+	ErrInvalidImportFormat  = errors.New("formato de importação inválido: use csv ou ndjson")
+	ErrInvalidColumnMapping = errors.New("mapeamento de colunas inválido: use campo=coluna separados por vírgula")
+	ErrMissingColumns       = errors.New("colunas obrigatórias ausentes no arquivo")
+)
+
+const (
+	// ImportBatchSize é quantas linhas válidas vão juntas para a checagem de
+	// duplicatas e para a gravação
+	ImportBatchSize = 500
+	// MaxImportErrors limita os erros de linha devolvidos no relatório
+	MaxImportErrors = 1000
+	// ImportSource é a fonte das conversões importadas sem a coluna fonte
+	ImportSource = "importacao"
+)
+
+// Campos obrigatórios em cada linha; os demais campos de exportColumns são opcionais
+var requiredImportFields = []string{"data", "currency", "valor_entrada", "cotacao"}
+
+// ImportOptions descreve o arquivo importado
+type ImportOptions struct {
+	// csv (padrão) ou ndjson
+	Format       ExportFormat
+	Delimiter    rune
+	DecimalComma bool
+	// Columns liga o campo do histórico ao nome da coluna (ou chave JSON) no
+	// arquivo; campos fora do mapa usam o próprio nome, como na exportação
+	Columns map[string]string
+	// Fuso das datas sem fuso no arquivo (nil = UTC)
+	Location *time.Location
+	// DryRun valida e conta as duplicatas sem gravar
+	DryRun bool
+}
+
+// ParseImportFormat aceita csv (padrão, quando vazio) e ndjson
+func ParseImportFormat(raw string) (ExportFormat, error) {
+	format, err := ParseExportFormat(raw)
+	if err != nil || format == ExportXLSX {
+		return "", ErrInvalidImportFormat
+	}
+	return format, nil
+}
+
+// ParseColumnMapping lê "currency=Moeda,data=Data da conversão" no mapa campo → coluna
+func ParseColumnMapping(raw string) (map[string]string, error) {
+	columns := map[string]string{}
+	if strings.TrimSpace(raw) == "" {
+		return columns, nil
+	}
+	for _, pair := range strings.Split(raw, ",") {
+		field, column, ok := strings.Cut(pair, "=")
+		field, column = strings.TrimSpace(field), strings.TrimSpace(column)
+		if !ok || column == "" || !isImportField(field) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidColumnMapping, pair)
+		}
+		columns[field] = column
+	}
+	return columns, nil
+}
+
+func isImportField(field string) bool {
+	for _, f := range exportColumns {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportRowError aponta o problema de uma linha do arquivo (a primeira linha é 1)
+type ImportRowError struct {
+	Linha    int    `json:"linha"`
+	Campo    string `json:"campo,omitempty"`
+	Mensagem string `json:"mensagem"`
+}
+
+func (e *ImportRowError) Error() string {
+	return fmt.Sprintf("linha %d: %s", e.Linha, e.Mensagem)
+}
+
+// ImportReport resume a importação. Importadas conta as gravadas ou, na
+// simulação, as que seriam gravadas.
+type ImportReport struct {
+	Linhas        int              `json:"linhas"`
+	Importadas    int              `json:"importadas"`
+	Duplicadas    int              `json:"duplicadas"`
+	Rejeitadas    int              `json:"rejeitadas"`
+	Erros         []ImportRowError `json:"erros"`
+	ErrosOmitidos int              `json:"erros_omitidos,omitempty"`
+	Simulacao     bool             `json:"simulacao,omitempty"`
+}
+
+func (r *ImportReport) reject(e ImportRowError) {
+	r.Rejeitadas++
+	if len(r.Erros) < MaxImportErrors {
+		r.Erros = append(r.Erros, e)
+		return
+	}
+	r.ErrosOmitidos++
+}
+
+// ConversionKey é a chave natural de uma conversão: a mesma moeda, no mesmo
+// instante (em milissegundos, a precisão do banco), com o mesmo valor e a
+// mesma cotação é a mesma conversão
+type ConversionKey struct {
+	Moeda        string
+	Data         int64
+	ValorEntrada float64
+	Cotacao      float64
+}
+
+func KeyOf(r ConversionRecord) ConversionKey {
+	return ConversionKey{Moeda: r.MoedaDestino, Data: r.Data.UnixMilli(), ValorEntrada: r.ValorEntrada, Cotacao: r.Cotacao}
+}
+
+// String é a chave gravada em ConversionRecord.ChaveImportacao
+func (k ConversionKey) String() string {
+	return k.Moeda + "|" + strconv.FormatInt(k.Data, 10) + "|" +
+		strconv.FormatFloat(k.ValorEntrada, 'g', -1, 64) + "|" +
+		strconv.FormatFloat(k.Cotacao, 'g', -1, 64)
+}
+
+// ConversionKeyReader diz quais das chaves já estão no histórico
+type ConversionKeyReader interface {
+	ExistingConversionKeys(keys []ConversionKey) ([]ConversionKey, error)
+}
+
+// ImportRepository é o que a importação consulta e grava além do histórico:
+// as chaves já gravadas, a série de cotações e o primeiro dia não arquivado
+type ImportRepository interface {
+	ConversionKeyReader
+	RateSnapshotSaver
+	RetentionWatermark() (time.Time, error)
+}
+
+// ImportUseCase grava no histórico conversões vindas de planilhas e de outros
+// sistemas. Cada linha é validada; as repetidas (no arquivo ou no histórico)
+// são contadas e puladas, então reimportar o mesmo arquivo não duplica nada.
+// A cotação de cada conversão importada também entra na série de cotações,
+// que é de onde variação, indicadores e previsão leem.
+type ImportUseCase struct {
+	saver      ConversionSaver
+	repo       ImportRepository
+	currencies *CurrencyRegistry
+	log        logger.Logger
+	now        func() time.Time
+}
+
+func NewImportUseCase(s ConversionSaver, r ImportRepository, c *CurrencyRegistry, l logger.Logger) *ImportUseCase {
+	return &ImportUseCase{saver: s, repo: r, currencies: c, log: l, now: time.Now}
+}
+
+// Execute lê o arquivo inteiro e grava as linhas válidas em lotes. Erros de
+// linha vão para o relatório; o erro devolvido é de arquivo (formato, colunas)
+// ou de gravação, e nesse caso o relatório conta o que já foi gravado.
+func (uc *ImportUseCase) Execute(ctx context.Context, r io.Reader, opts ImportOptions) (ImportReport, error) {
+	log := logger.FromContext(ctx, uc.log)
+	report := ImportReport{Erros: []ImportRowError{}, Simulacao: opts.DryRun}
+
+	format, err := ParseImportFormat(string(opts.Format))
+	if err != nil {
+		return report, err
+	}
+	for field := range opts.Columns {
+		if !isImportField(field) {
+			return report, fmt.Errorf("%w: %q", ErrInvalidColumnMapping, field)
+		}
+	}
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+
+	// Os dias antes da marca já foram resumidos e arquivados: uma conversão
+	// nova ali não entraria no resumo diário que as estatísticas leem
+	watermark, err := uc.repo.RetentionWatermark()
+	if err != nil {
+		log.Error("Falha ao ler a marca de retenção", "erro", err.Error())
+		return report, err
+	}
+
+	var rows importRowReader
+	if format == ExportNDJSON {
+		rows = newNDJSONRowReader(r, opts.Columns)
+	} else if rows, err = newCSVRowReader(r, opts); err != nil {
+		return report, err
+	}
+
+	var batch []ConversionRecord
+	flush := func() error {
+		err := uc.saveBatch(batch, opts.DryRun, &report)
+		batch = batch[:0]
+		return err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		line, fields, err := rows.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var rowErr *ImportRowError
+		if errors.As(err, &rowErr) {
+			report.Linhas++
+			report.reject(*rowErr)
+			continue
+		}
+		if err != nil {
+			log.Error("Falha ao ler arquivo importado", "linhas", report.Linhas, "erro", err.Error())
+			return report, err
+		}
+		report.Linhas++
+		record, rowErr := uc.parseRow(fields, opts)
+		if rowErr == nil && record.Data.Before(watermark) {
+			rowErr = &ImportRowError{Campo: "data", Mensagem: "dia já arquivado: importe conversões a partir de " + watermark.Format(time.DateOnly)}
+		}
+		if rowErr != nil {
+			rowErr.Linha = line
+			report.reject(*rowErr)
+			continue
+		}
+
+		batch = append(batch, record)
+		if len(batch) == ImportBatchSize {
+			if err := flush(); err != nil {
+				log.Error("Falha ao gravar lote importado", "importadas", report.Importadas, "erro", err.Error())
+				return report, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		log.Error("Falha ao gravar lote importado", "importadas", report.Importadas, "erro", err.Error())
+		return report, err
+	}
+
+	log.Info("Importação do histórico concluída",
+		"linhas", report.Linhas,
+		"importadas", report.Importadas,
+		"duplicadas", report.Duplicadas,
+		"rejeitadas", report.Rejeitadas,
+		"simulacao", opts.DryRun,
+	)
+	return report, nil
+}
+
+// saveBatch tira do lote as repetidas e grava o resto. Duplicatas entre lotes
+// diferentes do arquivo são achadas no histórico, onde o lote anterior já está;
+// na simulação, que não grava, só as do mesmo lote são achadas. Duas
+// importações simultâneas do mesmo arquivo podem passar juntas pela checagem:
+// cada conversão importada leva a chave em ChaveImportacao, e o índice único
+// dela no histórico segura a segunda.
+//
+// As cotações vão antes do histórico: se a gravação falhar no meio, reimportar
+// o arquivo as grava de novo, em vez de deixar conversões sem cotação na série.
+func (uc *ImportUseCase) saveBatch(batch []ConversionRecord, dryRun bool, report *ImportReport) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	keys := make([]ConversionKey, len(batch))
+	for i, r := range batch {
+		keys[i] = KeyOf(r)
+	}
+	existing, err := uc.repo.ExistingConversionKeys(keys)
+	if err != nil {
+		return err
+	}
+	seen := make(map[ConversionKey]bool, len(batch))
+	for _, k := range existing {
+		seen[k] = true
+	}
+
+	fresh := make([]ConversionRecord, 0, len(batch))
+	for i, r := range batch {
+		if seen[keys[i]] {
+			report.Duplicadas++
+			continue
+		}
+		seen[keys[i]] = true
+		r.ChaveImportacao = keys[i].String()
+		fresh = append(fresh, r)
+	}
+	if len(fresh) == 0 {
+		return nil
+	}
+	if !dryRun {
+		if err := uc.repo.SaveSnapshots(importedRates(fresh)); err != nil {
+			return err
+		}
+		if err := saveBatch(uc.saver, fresh); err != nil {
+			return err
+		}
+	}
+	report.Importadas += len(fresh)
+	return nil
+}
+
+// importedRates devolve a cotação usada em cada conversão, no instante da
+// cotação (a conversão retroativa usa a cotação de outra data)
+func importedRates(records []ConversionRecord) []RateSnapshot {
+	snapshots := make([]RateSnapshot, len(records))
+	for i, r := range records {
+		at := r.Data
+		if r.DataCotacao != nil {
+			at = *r.DataCotacao
+		}
+		snapshots[i] = RateSnapshot{Moeda: r.MoedaDestino, Cotacao: r.Cotacao, Fonte: r.Fonte, Data: at}
+	}
+	return snapshots
+}
+
+// parseRow valida a linha e monta o registro; o erro aponta o primeiro campo com problema
+func (uc *ImportUseCase) parseRow(fields map[string]string, opts ImportOptions) (ConversionRecord, *ImportRowError) {
+	for _, f := range requiredImportFields {
+		if fields[f] == "" {
+			return ConversionRecord{}, &ImportRowError{Campo: f, Mensagem: "campo obrigatório vazio"}
+		}
+	}
+
+	record := ConversionRecord{
+		MoedaDestino: strings.ToUpper(fields["currency"]),
+		Fonte:        fields["fonte"],
+		APIKeyID:     fields["api_key_id"],
+	}
+	if !uc.currencies.Known(record.MoedaDestino) {
+		return record, &ImportRowError{Campo: "currency", Mensagem: "moeda desconhecida: " + record.MoedaDestino}
+	}
+	if record.Fonte == "" {
+		record.Fonte = ImportSource
+	}
+
+	var err error
+	if record.Data, err = parseImportTime(fields["data"], opts.Location); err != nil {
+		return record, &ImportRowError{Campo: "data", Mensagem: "data inválida: " + fields["data"]}
+	}
+	if record.Data.After(uc.now()) {
+		return record, &ImportRowError{Campo: "data", Mensagem: "data no futuro"}
+	}
+
+	for _, n := range []struct {
+		field    string
+		dst      *float64
+		optional bool
+	}{
+		{"valor_entrada", &record.ValorEntrada, false},
+		{"cotacao", &record.Cotacao, false},
+		{"valor_convertido", &record.ValorConvertido, true},
+	} {
+		raw := fields[n.field]
+		if raw == "" && n.optional {
+			continue
+		}
+		v, err := parseImportNumber(raw, opts.DecimalComma)
+		if err != nil {
+			return record, &ImportRowError{Campo: n.field, Mensagem: "número inválido: " + raw}
+		}
+		if v <= 0 {
+			return record, &ImportRowError{Campo: n.field, Mensagem: "deve ser maior que zero"}
+		}
+		*n.dst = v
+	}
+	if record.ValorConvertido == 0 {
+		record.ValorConvertido = record.ValorEntrada / record.Cotacao
+	}
+
+	if raw := fields["retroativa"]; raw != "" {
+		if record.Retroativa, err = strconv.ParseBool(raw); err != nil {
+			return record, &ImportRowError{Campo: "retroativa", Mensagem: "use true ou false"}
+		}
+	}
+	for _, t := range []struct {
+		field string
+		dst   **time.Time
+	}{{"data_referencia", &record.DataReferencia}, {"data_cotacao", &record.DataCotacao}} {
+		raw := fields[t.field]
+		if raw == "" {
+			continue
+		}
+		v, err := parseImportTime(raw, opts.Location)
+		if err != nil {
+			return record, &ImportRowError{Campo: t.field, Mensagem: "data inválida: " + raw}
+		}
+		*t.dst = &v
+	}
+	return record, nil
+}
+
+// Formatos de data aceitos, além de RFC 3339: os da exportação e os das planilhas em pt-BR
+var importTimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	time.DateOnly,
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"02/01/2006",
+}
+
+func parseImportTime(raw string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+		return t.UTC().Truncate(time.Millisecond), nil
+	}
+	for _, layout := range importTimeLayouts {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return t.UTC().Truncate(time.Millisecond), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("data inválida: %s", raw)
+}
+
+// parseImportNumber aceita "1234.56" e, com vírgula decimal, "1.234,56" e "R$ 1.234,56"
+func parseImportNumber(raw string, decimalComma bool) (float64, error) {
+	s := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(raw), "R$"))
+	if decimalComma {
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.Replace(s, ",", ".", 1)
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// importRowReader entrega as linhas do arquivo como campo → valor; io.EOF no
+// fim. Uma linha mal formada vem como *ImportRowError; outros erros encerram a leitura.
+type importRowReader interface {
+	Next() (line int, fields map[string]string, err error)
+}
+
+type csvRowReader struct {
+	in      *csv.Reader
+	columns map[string]int
+}
+
+// newCSVRowReader lê o cabeçalho e localiza a coluna de cada campo; faltar
+// uma coluna obrigatória é erro do arquivo inteiro
+func newCSVRowReader(r io.Reader, opts ImportOptions) (*csvRowReader, error) {
+	in := csv.NewReader(r)
+	in.Comma = ExportOptions{Delimiter: opts.Delimiter, DecimalComma: opts.DecimalComma}.delimiter()
+	in.FieldsPerRecord = -1
+	in.TrimLeadingSpace = true
+	in.ReuseRecord = true
+	if !validDelimiter(in.Comma) {
+		return nil, ErrInvalidDelimiter
+	}
+
+	header, err := in.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %s", ErrMissingColumns, strings.Join(requiredImportFields, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		// O Excel grava o CSV em UTF-8 com BOM
+		name = strings.TrimPrefix(name, "\ufeff")
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	columns := map[string]int{}
+	var missing []string
+	for _, field := range exportColumns {
+		name := field
+		if mapped, ok := opts.Columns[field]; ok {
+			name = mapped
+		}
+		if i, ok := index[strings.ToLower(name)]; ok {
+			columns[field] = i
+		}
+	}
+	for _, field := range requiredImportFields {
+		if _, ok := columns[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingColumns, strings.Join(missing, ", "))
+	}
+	return &csvRowReader{in: in, columns: columns}, nil
+}
+
+func (c *csvRowReader) Next() (int, map[string]string, error) {
+	record, err := c.in.Read()
+	if errors.Is(err, io.EOF) {
+		return 0, nil, err
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr.StartLine, nil, &ImportRowError{Linha: parseErr.StartLine, Mensagem: parseErr.Err.Error()}
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	line, _ := c.in.FieldPos(0)
+
+	fields := make(map[string]string, len(c.columns))
+	for field, i := range c.columns {
+		if i < len(record) {
+			fields[field] = strings.TrimSpace(record[i])
+		}
+	}
+	return line, fields, nil
+}
+
+type ndjsonRowReader struct {
+	in      *bufio.Scanner
+	columns map[string]string
+	line    int
+}
+
+func newNDJSONRowReader(r io.Reader, columns map[string]string) *ndjsonRowReader {
+	in := bufio.NewScanner(r)
+	in.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &ndjsonRowReader{in: in, columns: columns}
+}
+
+func (n *ndjsonRowReader) Next() (int, map[string]string, error) {
+	for n.in.Scan() {
+		n.line++
+		raw := bytes.TrimSpace(n.in.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			return n.line, nil, &ImportRowError{Linha: n.line, Mensagem: "JSON inválido: " + err.Error()}
+		}
+
+		fields := make(map[string]string, len(exportColumns))
+		for _, field := range exportColumns {
+			key := field
+			if mapped, ok := n.columns[field]; ok {
+				key = mapped
+			}
+			switch v := obj[key].(type) {
+			case nil:
+			case string:
+				fields[field] = strings.TrimSpace(v)
+			default:
+				fields[field] = fmt.Sprint(v)
+			}
+		}
+		return n.line, fields, nil
+	}
+	if err := n.in.Err(); err != nil {
+		return n.line + 1, nil, fmt.Errorf("linha %d: %w", n.line+1, err)
+	}
+	return 0, nil, io.EOF
+}
@@ -0,0 +1,394 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// importStoreFake é o histórico em memória: grava em lotes, responde as chaves
+// já gravadas e guarda as cotações da série
+type importStoreFake struct {
+	records   []ConversionRecord
+	snapshots []RateSnapshot
+	batches   int
+	keys      map[ConversionKey]bool
+	failAt    int
+	watermark time.Time
+}
+
+func newImportStoreFake(existing ...ConversionRecord) *importStoreFake {
+	f := &importStoreFake{keys: map[ConversionKey]bool{}}
+	for _, r := range existing {
+		f.keys[KeyOf(r)] = true
+	}
+	return f
+}
+
+func (f *importStoreFake) SaveHistory(record ConversionRecord) error {
+	return f.SaveHistoryBatch([]ConversionRecord{record})
+}
+
+func (f *importStoreFake) SaveHistoryBatch(records []ConversionRecord) error {
+	f.batches++
+	if f.failAt > 0 && f.batches >= f.failAt {
+		return errors.New("mongo fora do ar")
+	}
+	for _, r := range records {
+		f.records = append(f.records, r)
+		f.keys[KeyOf(r)] = true
+	}
+	return nil
+}
+
+func (f *importStoreFake) ExistingConversionKeys(keys []ConversionKey) ([]ConversionKey, error) {
+	var out []ConversionKey
+	for _, k := range keys {
+		if f.keys[k] {
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
+
+func (f *importStoreFake) SaveSnapshots(snapshots []RateSnapshot) error {
+	f.snapshots = append(f.snapshots, snapshots...)
+	return nil
+}
+
+func (f *importStoreFake) RetentionWatermark() (time.Time, error) {
+	return f.watermark, nil
+}
+
+func newImportUseCaseFake(store *importStoreFake, extra ...string) *ImportUseCase {
+	uc := NewImportUseCase(store, store, NewCurrencyRegistry(extra), newPersistenceLogger())
+	uc.now = func() time.Time { return time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC) }
+	return uc
+}
+
+func TestImportUseCase(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should import csv with export columns",
+			run:  shouldImportCSVWithExportColumns,
+		},
+		{
+			name: "should import pt-BR spreadsheet with mapped columns",
+			run:  shouldImportPtBRSpreadsheetWithMappedColumns,
+		},
+		{
+			name: "should report invalid rows and keep the valid ones",
+			run:  shouldReportInvalidRowsAndKeepTheValidOnes,
+		},
+		{
+			name: "should accept extra currencies from the registry",
+			run:  shouldAcceptExtraCurrenciesFromTheRegistry,
+		},
+		{
+			name: "should skip duplicates in the file and in the history",
+			run:  shouldSkipDuplicatesInTheFileAndInTheHistory,
+		},
+		{
+			name: "should not save on dry run",
+			run:  shouldNotSaveOnDryRun,
+		},
+		{
+			name: "should fail when required columns are missing",
+			run:  shouldFailWhenRequiredColumnsAreMissing,
+		},
+		{
+			name: "should import ndjson with mapped keys",
+			run:  shouldImportNDJSONWithMappedKeys,
+		},
+		{
+			name: "should save in batches and report what was saved before a failure",
+			run:  shouldSaveInBatchesAndReportWhatWasSavedBeforeAFailure,
+		},
+		{
+			name: "should add imported rates to the rate series",
+			run:  shouldAddImportedRatesToTheRateSeries,
+		},
+		{
+			name: "should reject rows from archived days",
+			run:  shouldRejectRowsFromArchivedDays,
+		},
+		{
+			name: "should cap row errors in the report",
+			run:  shouldCapRowErrorsInTheReport,
+		},
+		{
+			name: "should parse column mapping",
+			run:  shouldParseColumnMapping,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldImportCSVWithExportColumns(t *testing.T) {
+	store := newImportStoreFake()
+	uc := newImportUseCaseFake(store)
+
+	// O mesmo formato que a exportação gera
+	var out strings.Builder
+	_, err := NewExportUseCase(&sliceStreamerFake{records: exportSample()}, newPersistenceLogger()).
+		Execute(context.Background(), ConversionFilter{}, ExportOptions{Format: ExportCSV}, &out)
+	require.NoError(t, err)
+
+	report, err := uc.Execute(context.Background(), strings.NewReader(out.String()), ImportOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, ImportReport{Linhas: 2, Importadas: 2, Erros: []ImportRowError{}}, report)
+	require.Len(t, store.records, 2)
+	for i, want := range exportSample() {
+		// Só as conversões importadas levam a chave do índice único parcial
+		want.ChaveImportacao = KeyOf(want).String()
+		assert.Equal(t, want, store.records[i])
+	}
+	assert.Equal(t, "USD|1767616200000|1234.5|5.4321", store.records[0].ChaveImportacao)
+}
+
+func shouldImportPtBRSpreadsheetWithMappedColumns(t *testing.T) {
+	store := newImportStoreFake()
+	uc := newImportUseCaseFake(store)
+	file := "\ufeffData;Moeda;Valor (R$);Cotação\n" +
+		"05/01/2026 09:30;usd;R$ 1.234,50;5,4321\n"
+
+	report, err := uc.Execute(context.Background(), strings.NewReader(file), ImportOptions{
+		DecimalComma: true,
+		Columns:      map[string]string{"data": "Data", "currency": "moeda", "valor_entrada": "Valor (R$)", "cotacao": "Cotação"},
+		Location:     time.FixedZone("BRT", -3*3600),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Importadas)
+	require.Len(t, store.records, 1)
+	r := store.records[0]
+	assert.Equal(t, "USD", r.MoedaDestino)
+	assert.Equal(t, time.Date(2026, 1, 5, 12, 30, 0, 0, time.UTC), r.Data)
+	assert.Equal(t, 1234.5, r.ValorEntrada)
+	assert.Equal(t, 5.4321, r.Cotacao)
+	assert.InDelta(t, 1234.5/5.4321, r.ValorConvertido, 1e-9)
+	assert.Equal(t, ImportSource, r.Fonte)
+}
+
+func shouldReportInvalidRowsAndKeepTheValidOnes(t *testing.T) {
+	store := newImportStoreFake()
+	uc := newImportUseCaseFake(store)
+	file := "data,currency,valor_entrada,cotacao,retroativa\n" +
+		"2026-01-05,USD,100,5,\n" +
+		"2026-01-05,XYZ,100,5,\n" +
+		"ontem,USD,100,5,\n" +
+		"2026-01-05,EUR,-1,5,\n" +
+		"2026-01-05,EUR,100,,\n" +
+		"2026-12-25,EUR,100,6,\n" +
+		"2026-01-05,EUR,100,6,talvez\n" +
+		"2026-01-06,GBP,100,7,true\n"
+
+	report, err := uc.Execute(context.Background(), strings.NewReader(file), ImportOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 8, report.Linhas)
+	assert.Equal(t, 2, report.Importadas)
+	assert.Equal(t, 6, report.Rejeitadas)
+	assert.Equal(t, []ImportRowError{
+		{Linha: 3, Campo: "currency", Mensagem: "moeda desconhecida: XYZ"},
+		{Linha: 4, Campo: "data", Mensagem: "data inválida: ontem"},
+		{Linha: 5, Campo: "valor_entrada", Mensagem: "deve ser maior que zero"},
+		{Linha: 6, Campo: "cotacao", Mensagem: "campo obrigatório vazio"},
+		{Linha: 7, Campo: "data", Mensagem: "data no futuro"},
+		{Linha: 8, Campo: "retroativa", Mensagem: "use true ou false"},
+	}, report.Erros)
+	assert.Equal(t, []string{"USD", "GBP"}, currencies(store.records))
+	assert.True(t, store.records[1].Retroativa)
+}
+
+func shouldAcceptExtraCurrenciesFromTheRegistry(t *testing.T) {
+	store := newImportStoreFake()
+	uc := newImportUseCaseFake(store, " btc ")
+	file := "data,currency,valor_entrada,cotacao\n2026-01-05,BTC,100,350000\n"
+
+	report, err := uc.Execute(context.Background(), strings.NewReader(file), ImportOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Importadas)
+	assert.Contains(t, NewCurrencyRegistry([]string{"btc"}).Codes(), "BTC")
+	assert.False(t, NewCurrencyRegistry(nil).Known("BTC"))
+}
+
+func shouldSkipDuplicatesInTheFileAndInTheHistory(t *testing.T) {
+	existing := ConversionRecord{MoedaDestino: "USD", Data: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), ValorEntrada: 100, Cotacao: 5}
+	store := newImportStoreFake(existing)
+	uc := newImportUseCaseFake(store)
+	file := "data,currency,valor_entrada,cotacao\n" +
+		"2026-01-05T00:00:00Z,USD,100,5\n" +
+		"2026-01-05,EUR,100,6\n" +
+		"2026-01-05 00:00:00,EUR,100,6\n" +
+		"2026-01-05,EUR,100,6.1\n"
+
+	report, err := uc.Execute(context.Background(), strings.NewReader(file), ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Importadas)
+	assert.Equal(t, 2, report.Duplicadas)
+	assert.Equal(t, []string{"EUR", "EUR"}, currencies(store.records))
+
+	// Reimportar o mesmo arquivo não grava nada
+	report, err = uc.Execute(context.Background(), strings.NewReader(file), ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Importadas)
+	assert.Equal(t, 4, report.Duplicadas)
+	assert.Len(t, store.records, 2)
+}
+
+func shouldNotSaveOnDryRun(t *testing.T) {
+	store := newImportStoreFake()
+	uc := newImportUseCaseFake(store)
+	file := "data,currency,valor_entrada,cotacao\n2026-01-05,USD,100,5\n2026-01-05,USD,100,5\n2026-01-05,XYZ,100,5\n"
+
+	report, err := uc.Execute(context.Background(), strings.NewReader(file), ImportOptions{DryRun: true})
+	require.NoError(t, err)
+
+	assert.True(t, report.Simulacao)
+	assert.Equal(t, 1, report.Importadas)
+	assert.Equal(t, 1, report.Duplicadas)
+	assert.Equal(t, 1, report.Rejeitadas)
+	assert.Empty(t, store.records)
+	assert.Empty(t, store.snapshots)
+	assert.Zero(t, store.batches)
+}
+
+func shouldFailWhenRequiredColumnsAreMissing(t *testing.T) {
+	uc := newImportUseCaseFake(newImportStoreFake())
+
+	_, err := uc.Execute(context.Background(), strings.NewReader("data,moeda,valor_entrada\n"), ImportOptions{})
+	require.ErrorIs(t, err, ErrMissingColumns)
+	assert.Contains(t, err.Error(), "currency, cotacao")
+
+	_, err = uc.Execute(context.Background(), strings.NewReader(""), ImportOptions{})
+	assert.ErrorIs(t, err, ErrMissingColumns)
+
+	_, err = uc.Execute(context.Background(), strings.NewReader(""), ImportOptions{Format: ExportXLSX})
+	assert.ErrorIs(t, err, ErrInvalidImportFormat)
+
+	_, err = uc.Execute(context.Background(), strings.NewReader(""), ImportOptions{Columns: map[string]string{"moeda": "Moeda"}})
+	assert.ErrorIs(t, err, ErrInvalidColumnMapping)
+}
+
+func shouldImportNDJSONWithMappedKeys(t *testing.T) {
+	store := newImportStoreFake()
+	uc := newImportUseCaseFake(store)
+	file := `{"when":"2026-01-05T12:30:00-03:00","currency":"USD","valor_entrada":100,"cotacao":5.25,"fonte":"erp"}` + "\n" +
+		"\n" +
+		`{"when":"2026-01-05T12:30:00Z",` + "\n" +
+		`{"when":"2026-01-06T08:00:00Z","currency":"EUR","valor_entrada":"100","cotacao":6}` + "\n"
+
+	report, err := uc.Execute(context.Background(), strings.NewReader(file), ImportOptions{
+		Format:  ExportNDJSON,
+		Columns: map[string]string{"data": "when"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.Linhas)
+	assert.Equal(t, 2, report.Importadas)
+	require.Len(t, report.Erros, 1)
+	assert.Equal(t, 3, report.Erros[0].Linha)
+	assert.Equal(t, time.Date(2026, 1, 5, 15, 30, 0, 0, time.UTC), store.records[0].Data)
+	assert.Equal(t, 5.25, store.records[0].Cotacao)
+	assert.Equal(t, "erp", store.records[0].Fonte)
+}
+
+func shouldSaveInBatchesAndReportWhatWasSavedBeforeAFailure(t *testing.T) {
+	store := newImportStoreFake()
+	store.failAt = 3
+	uc := newImportUseCaseFake(store)
+
+	var file strings.Builder
+	file.WriteString("data,currency,valor_entrada,cotacao\n")
+	for i := 0; i < 3*ImportBatchSize; i++ {
+		fmt.Fprintf(&file, "2026-01-05,USD,%d,5\n", i+1)
+	}
+
+	report, err := uc.Execute(context.Background(), strings.NewReader(file.String()), ImportOptions{})
+	require.Error(t, err)
+
+	assert.Equal(t, 3, store.batches)
+	assert.Equal(t, 2*ImportBatchSize, report.Importadas)
+	assert.Len(t, store.records, 2*ImportBatchSize)
+}
+
+func shouldCapRowErrorsInTheReport(t *testing.T) {
+	uc := newImportUseCaseFake(newImportStoreFake())
+
+	var file strings.Builder
+	file.WriteString("data,currency,valor_entrada,cotacao\n")
+	for i := 0; i < MaxImportErrors+5; i++ {
+		file.WriteString("2026-01-05,XYZ,100,5\n")
+	}
+
+	report, err := uc.Execute(context.Background(), strings.NewReader(file.String()), ImportOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, MaxImportErrors+5, report.Rejeitadas)
+	assert.Len(t, report.Erros, MaxImportErrors)
+	assert.Equal(t, 5, report.ErrosOmitidos)
+}
+
+func shouldParseColumnMapping(t *testing.T) {
+	columns, err := ParseColumnMapping("data = Data da conversão, currency=Moeda")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"data": "Data da conversão", "currency": "Moeda"}, columns)
+
+	_, err = ParseColumnMapping("moeda=Moeda")
+	assert.ErrorIs(t, err, ErrInvalidColumnMapping)
+	_, err = ParseColumnMapping("currency")
+	assert.ErrorIs(t, err, ErrInvalidColumnMapping)
+}
+
+func shouldAddImportedRatesToTheRateSeries(t *testing.T) {
+	store := newImportStoreFake()
+	uc := newImportUseCaseFake(store)
+	file := "data,currency,valor_entrada,cotacao,fonte,retroativa,data_cotacao\n" +
+		"2026-01-05T12:00:00Z,USD,100,5,planilha,,\n" +
+		"2026-01-05T12:00:00Z,USD,100,5,planilha,,\n" +
+		"2026-01-06T12:00:00Z,EUR,100,6,,true,2025-12-30T15:00:00Z\n"
+
+	report, err := uc.Execute(context.Background(), strings.NewReader(file), ImportOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.Importadas)
+	// A repetida não entra de novo; a retroativa vale no instante da cotação usada
+	assert.Equal(t, []RateSnapshot{
+		{Moeda: "USD", Cotacao: 5, Fonte: "planilha", Data: time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)},
+		{Moeda: "EUR", Cotacao: 6, Fonte: ImportSource, Data: time.Date(2025, 12, 30, 15, 0, 0, 0, time.UTC)},
+	}, store.snapshots)
+}
+
+func shouldRejectRowsFromArchivedDays(t *testing.T) {
+	store := newImportStoreFake()
+	store.watermark = time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC)
+	uc := newImportUseCaseFake(store)
+	file := "data,currency,valor_entrada,cotacao\n" +
+		"2026-01-05T23:59:59Z,USD,100,5\n" +
+		"2026-01-06T00:00:00Z,USD,100,5\n"
+
+	report, err := uc.Execute(context.Background(), strings.NewReader(file), ImportOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Importadas)
+	assert.Equal(t, []ImportRowError{
+		{Linha: 2, Campo: "data", Mensagem: "dia já arquivado: importe conversões a partir de 2026-01-06"},
+	}, report.Erros)
+	require.Len(t, store.records, 1)
+	assert.Equal(t, store.watermark, store.records[0].Data)
+}
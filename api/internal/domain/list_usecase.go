@@ -1,14 +1,43 @@
 package domain
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"go-frete/api/pkg/logger"
 )
 
 // Limitar a busca
 const SearchLimit = 10
 
+// Maior página aceita na busca paginada
+const MaxPageSize = 100
+
+var ErrInvalidPeriod = errors.New("período inválido: início depois do fim")
+
 type ConversionReader interface {
 	GetLastConversions(limit int) ([]ConversionRecord, error)
+	FindConversions(filter ConversionFilter) ([]ConversionRecord, error)
+}
+
+// ConversionFilter descreve uma busca no histórico; campos zerados não filtram
+type ConversionFilter struct {
+	Currency string
+	From     time.Time
+	To       time.Time
+	Limit    int
+	Offset   int
+	// true traz só as conversões retroativas, false só as feitas com a cotação do dia
+	Retroativa *bool
+}
+
+// ConversionPage é uma página do histórico, da conversão mais nova para a mais antiga
+type ConversionPage struct {
+	Records []ConversionRecord
+	Limit   int
+	Offset  int
+	HasMore bool
 }
 
 type ListConversionsUseCase struct {
@@ -20,14 +49,15 @@ func NewListConversionsUseCase(r ConversionReader, l logger.Logger) *ListConvers
 	return &ListConversionsUseCase{repo: r, log: l}
 }
 
-func (uc *ListConversionsUseCase) Execute() ([]ConversionRecord, error) {
-	uc.log.Info("Iniciando busca do histórico de conversões")
+func (uc *ListConversionsUseCase) Execute(ctx context.Context) ([]ConversionRecord, error) {
+	log := logger.FromContext(ctx, uc.log)
+	log.Info("Iniciando busca do histórico de conversões")
 
 	limit := SearchLimit
 
 	records, err := uc.repo.GetLastConversions(limit)
 	if err != nil {
-		uc.log.Error("Falha ao buscar últimas conversões no banco de dados", "erro", err.Error())
+		log.Error("Falha ao buscar últimas conversões no banco de dados", "erro", err.Error())
 		return nil, err
 	}
 
@@ -36,6 +66,46 @@ func (uc *ListConversionsUseCase) Execute() ([]ConversionRecord, error) {
 		records = []ConversionRecord{}
 	}
 
-	uc.log.Info("Busca de histórico finalizada com sucesso", "quantidade_encontrada", len(records))
+	log.Info("Busca de histórico finalizada com sucesso", "quantidade_encontrada", len(records))
 	return records, nil
 }
+
+// Search busca uma página do histórico aplicando os filtros informados
+func (uc *ListConversionsUseCase) Search(ctx context.Context, filter ConversionFilter) (ConversionPage, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.From.After(filter.To) {
+		return ConversionPage{}, ErrInvalidPeriod
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = SearchLimit
+	}
+	if filter.Limit > MaxPageSize {
+		filter.Limit = MaxPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	// Busca um registro a mais só para saber se existe próxima página
+	query := filter
+	query.Limit++
+
+	records, err := uc.repo.FindConversions(query)
+	if err != nil {
+		log.Error("Falha ao buscar conversões no banco de dados", "erro", err.Error())
+		return ConversionPage{}, err
+	}
+
+	page := ConversionPage{Records: records, Limit: filter.Limit, Offset: filter.Offset}
+	if len(records) > filter.Limit {
+		page.Records = records[:filter.Limit]
+		page.HasMore = true
+	}
+	if page.Records == nil {
+		page.Records = []ConversionRecord{}
+	}
+
+	log.Info("Busca paginada finalizada com sucesso", "quantidade_encontrada", len(page.Records), "offset", filter.Offset)
+	return page, nil
+}
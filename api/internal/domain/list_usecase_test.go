@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -23,6 +24,14 @@ func (m *conversionReaderMock) GetLastConversions(limit int) ([]ConversionRecord
 	return args.Get(0).([]ConversionRecord), args.Error(1)
 }
 
+func (m *conversionReaderMock) FindConversions(filter ConversionFilter) ([]ConversionRecord, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ConversionRecord), args.Error(1)
+}
+
 func TestListConversionsUseCase_Execute(t *testing.T) {
 	tests := []struct {
 		name string
@@ -61,7 +70,7 @@ func shouldReturnListOfConversionsSuccessfully(t *testing.T) {
 	readerMock.On("GetLastConversions", 10).Return(mockData, nil)
 
 	uc := NewListConversionsUseCase(readerMock, loggerMock)
-	result, err := uc.Execute()
+	result, err := uc.Execute(context.Background())
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 2)
@@ -78,7 +87,7 @@ func shouldHandleEmptyDatabaseGracefully(t *testing.T) {
 	readerMock.On("GetLastConversions", 10).Return(nil, nil)
 
 	uc := NewListConversionsUseCase(readerMock, loggerMock)
-	result, err := uc.Execute()
+	result, err := uc.Execute(context.Background())
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -97,10 +106,84 @@ func shouldReturnErrorWhenRepositoryFailsToRead(t *testing.T) {
 	readerMock.On("GetLastConversions", 10).Return(nil, expectedErr)
 
 	uc := NewListConversionsUseCase(readerMock, loggerMock)
-	result, err := uc.Execute()
+	result, err := uc.Execute(context.Background())
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Equal(t, expectedErr, err)
 	readerMock.AssertExpectations(t)
 }
+
+func TestListConversionsUseCase_Search(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should paginate and detect next page",
+			run:  shouldPaginateAndDetectNextPage,
+		},
+		{
+			name: "should clamp page size and offset",
+			run:  shouldClampPageSizeAndOffset,
+		},
+		{
+			name: "should reject period with start after end",
+			run:  shouldRejectPeriodWithStartAfterEnd,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldPaginateAndDetectNextPage(t *testing.T) {
+	readerMock := new(conversionReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+
+	// O use case pede um registro a mais que o limite
+	readerMock.On("FindConversions", ConversionFilter{Currency: "USD", Limit: 3, Offset: 2}).Return([]ConversionRecord{
+		{MoedaDestino: "USD"}, {MoedaDestino: "USD"}, {MoedaDestino: "USD"},
+	}, nil)
+
+	uc := NewListConversionsUseCase(readerMock, loggerMock)
+	page, err := uc.Search(context.Background(), ConversionFilter{Currency: "USD", Limit: 2, Offset: 2})
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Records, 2)
+	assert.True(t, page.HasMore)
+	assert.Equal(t, 2, page.Limit)
+	assert.Equal(t, 2, page.Offset)
+	readerMock.AssertExpectations(t)
+}
+
+func shouldClampPageSizeAndOffset(t *testing.T) {
+	readerMock := new(conversionReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	readerMock.On("FindConversions", ConversionFilter{Limit: MaxPageSize + 1}).Return(nil, nil)
+
+	uc := NewListConversionsUseCase(readerMock, loggerMock)
+	page, err := uc.Search(context.Background(), ConversionFilter{Limit: 5000, Offset: -3})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, page.Records)
+	assert.False(t, page.HasMore)
+	assert.Equal(t, MaxPageSize, page.Limit)
+	assert.Equal(t, 0, page.Offset)
+	readerMock.AssertExpectations(t)
+}
+
+func shouldRejectPeriodWithStartAfterEnd(t *testing.T) {
+	readerMock := new(conversionReaderMock)
+
+	uc := NewListConversionsUseCase(readerMock, new(loggermock.LoggerMock))
+	_, err := uc.Search(context.Background(), ConversionFilter{From: time.Now(), To: time.Now().Add(-time.Hour)})
+
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+	readerMock.AssertNotCalled(t, "FindConversions", mock.Anything)
+}
@@ -0,0 +1,269 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go-frete/api/pkg/logger"
+)
+
+// EventConversionCreated é publicado a cada conversão gravada no histórico
+const EventConversionCreated = "conversion.created"
+
+// DomainEvent é um fato do domínio entregue a outros serviços pelo outbox
+type DomainEvent struct {
+	// ID único: quem consome descarta as repetições por ele (entrega de pelo menos uma vez)
+	ID   string `json:"id"`
+	Tipo string `json:"tipo"`
+	// Chave agrupa os eventos que saem em ordem (a moeda, nas conversões)
+	Chave string `json:"chave"`
+	// Sequencia é atribuída ao gravar o evento e cresce na ordem das gravações
+	Sequencia int64           `json:"sequencia"`
+	CriadoEm  time.Time       `json:"criado_em"`
+	Dados     json.RawMessage `json:"dados"`
+	// APIKeyID restringe a entrega por webhook aos da chave (nos alertas, a dona da regra)
+	APIKeyID string `json:"api_key_id,omitempty"`
+}
+
+// NewConversionCreated monta o evento da conversão, com o registro salvo como dados
+func NewConversionCreated(record ConversionRecord) (DomainEvent, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return DomainEvent{}, err
+	}
+	dados, err := json.Marshal(record)
+	if err != nil {
+		return DomainEvent{}, err
+	}
+	return DomainEvent{
+		ID:       id,
+		Tipo:     EventConversionCreated,
+		Chave:    record.MoedaDestino,
+		CriadoEm: record.Data,
+		Dados:    dados,
+	}, nil
+}
+
+// ConversionOutbox grava as conversões e os eventos delas numa única transação:
+// ou entram os dois, ou nenhum. A Sequencia dos eventos é atribuída aqui.
+type ConversionOutbox interface {
+	SaveHistoryWithEvents(records []ConversionRecord, events []DomainEvent) error
+}
+
+// OutboxSaver é o fim da cadeia de gravação das conversões quando o outbox
+// está ligado: cada conversão do ConverterUseCase sai com seu ConversionCreated.
+// As conversões importadas ou restauradas de arquivos não passam por aqui.
+type OutboxSaver struct {
+	outbox ConversionOutbox
+}
+
+func NewOutboxSaver(o ConversionOutbox) *OutboxSaver {
+	return &OutboxSaver{outbox: o}
+}
+
+// SaveHistory implementa ConversionSaver
+func (s *OutboxSaver) SaveHistory(record ConversionRecord) error {
+	return s.SaveHistoryBatch([]ConversionRecord{record})
+}
+
+// SaveHistoryBatch implementa ConversionBatchSaver
+func (s *OutboxSaver) SaveHistoryBatch(records []ConversionRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	events := make([]DomainEvent, len(records))
+	for i, record := range records {
+		event, err := NewConversionCreated(record)
+		if err != nil {
+			return err
+		}
+		events[i] = event
+	}
+	return s.outbox.SaveHistoryWithEvents(records, events)
+}
+
+// OutboxEntry é um evento ainda não publicado, com as tentativas já feitas
+type OutboxEntry struct {
+	Event      DomainEvent
+	Tentativas int
+	// Antes deste instante o evento (e os seguintes da mesma chave) esperam
+	ProximaTentativa time.Time
+}
+
+// OutboxStore é a fila de eventos gravados junto com as conversões
+type OutboxStore interface {
+	// PendingEvents devolve os eventos não publicados em ordem de Sequencia,
+	// sem os das chaves em skipKeys
+	PendingEvents(limit int, skipKeys []string) ([]OutboxEntry, error)
+	MarkPublished(ids []string, at time.Time) error
+	MarkFailed(id string, tentativas int, motivo string, retryAt time.Time) error
+}
+
+// EventSink entrega eventos a outro sistema (HTTP, NATS, saída padrão)
+type EventSink interface {
+	Name() string
+	Publish(event DomainEvent) error
+}
+
+// OutboxRelayConfig ajusta o relay; campos zerados usam os padrões
+type OutboxRelayConfig struct {
+	// Eventos lidos do outbox por rodada (padrão 100)
+	BatchSize int
+	// Espera depois da primeira falha de um evento; dobra a cada nova falha
+	// até MaxBackoff (padrões 1s e 5m)
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// OutboxRelay publica nos sinks os eventos pendentes do outbox. A entrega é de
+// pelo menos uma vez: um evento só é marcado como publicado depois que todos
+// os sinks o aceitam, e uma falha entre a publicação e a marcação o repete.
+// Eventos da mesma chave saem na ordem em que foram gravados: enquanto um
+// deles falha, os seguintes da mesma chave esperam; as outras chaves seguem.
+type OutboxRelay struct {
+	store OutboxStore
+	sinks []EventSink
+	cfg   OutboxRelayConfig
+	log   logger.Logger
+	now   func() time.Time
+
+	// Chaves com um evento esperando nova tentativa, até quando. Ficam fora da
+	// leitura para que uma chave travada não ocupe a rodada inteira.
+	waiting map[string]time.Time
+}
+
+func NewOutboxRelay(store OutboxStore, sinks []EventSink, cfg OutboxRelayConfig, l logger.Logger) *OutboxRelay {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = time.Second
+	}
+	if cfg.MaxBackoff < cfg.Backoff {
+		cfg.MaxBackoff = max(5*time.Minute, cfg.Backoff)
+	}
+	return &OutboxRelay{store: store, sinks: sinks, cfg: cfg, log: l, now: time.Now, waiting: map[string]time.Time{}}
+}
+
+// RelayOnce publica uma rodada de eventos pendentes e devolve quantos foram publicados
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	log := logger.FromContext(ctx, r.log)
+
+	now := r.now()
+	skip := make([]string, 0, len(r.waiting))
+	for chave, until := range r.waiting {
+		if until.After(now) {
+			skip = append(skip, chave)
+		} else {
+			delete(r.waiting, chave)
+		}
+	}
+
+	pending, err := r.store.PendingEvents(r.cfg.BatchSize, skip)
+	if err != nil {
+		log.Error("Falha ao ler eventos pendentes do outbox", "erro", err.Error())
+		return 0, err
+	}
+
+	blocked := map[string]bool{}
+	published := make([]string, 0, len(pending))
+	for _, entry := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+		event := entry.Event
+		if blocked[event.Chave] {
+			continue
+		}
+		if entry.ProximaTentativa.After(now) {
+			blocked[event.Chave] = true
+			r.waiting[event.Chave] = entry.ProximaTentativa
+			continue
+		}
+
+		if err := r.publish(event); err != nil {
+			blocked[event.Chave] = true
+			tentativas := entry.Tentativas + 1
+			retryAt := now.Add(r.backoff(tentativas))
+			r.waiting[event.Chave] = retryAt
+			log.Warn("Falha ao publicar evento do outbox",
+				"erro", err.Error(),
+				"evento", event.ID,
+				"tipo", event.Tipo,
+				"chave", event.Chave,
+				"tentativas", tentativas,
+				"proxima_tentativa", retryAt,
+			)
+			if err := r.store.MarkFailed(event.ID, tentativas, err.Error(), retryAt); err != nil {
+				log.Error("Falha ao registrar tentativa no outbox", "erro", err.Error(), "evento", event.ID)
+			}
+			continue
+		}
+		published = append(published, event.ID)
+	}
+
+	if len(published) == 0 {
+		return 0, nil
+	}
+	// Se a marcação falhar, os eventos voltam na próxima rodada e são publicados de novo
+	if err := r.store.MarkPublished(published, r.now()); err != nil {
+		log.Error("Falha ao marcar eventos publicados no outbox", "erro", err.Error(), "eventos", len(published))
+		return 0, err
+	}
+	log.Debug("Eventos do outbox publicados", "eventos", len(published))
+	return len(published), nil
+}
+
+func (r *OutboxRelay) publish(event DomainEvent) error {
+	for _, sink := range r.sinks {
+		if err := sink.Publish(event); err != nil {
+			return &SinkError{Sink: sink.Name(), Err: err}
+		}
+	}
+	return nil
+}
+
+func (r *OutboxRelay) backoff(tentativas int) time.Duration {
+	d := r.cfg.Backoff
+	for i := 1; i < tentativas && d < r.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, r.cfg.MaxBackoff)
+}
+
+// Run publica os pendentes a cada intervalo até o contexto ser cancelado. Uma
+// rodada cheia emenda na seguinte, para esvaziar o acúmulo sem esperar o intervalo.
+func (r *OutboxRelay) Run(ctx context.Context, every time.Duration) {
+	r.log.Info("Relay do outbox iniciado", "intervalo", every.String(), "sinks", len(r.sinks))
+
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		n, err := r.RelayOnce(ctx)
+		if err == nil && n == r.cfg.BatchSize && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			r.log.Info("Relay do outbox encerrado")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SinkError identifica o sink que recusou o evento
+type SinkError struct {
+	Sink string
+	Err  error
+}
+
+func (e *SinkError) Error() string {
+	return e.Sink + ": " + e.Err.Error()
+}
+
+func (e *SinkError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,290 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryOutboxFake guarda conversões e eventos juntos, como a transação do Mongo
+type memoryOutboxFake struct {
+	mu         sync.Mutex
+	records    []ConversionRecord
+	entries    []OutboxEntry
+	published  map[string]bool
+	seq        int64
+	saveErr    error
+	markErr    error
+	lastSkip   []string
+	markedFail []string
+}
+
+func newMemoryOutboxFake() *memoryOutboxFake {
+	return &memoryOutboxFake{published: map[string]bool{}}
+}
+
+func (f *memoryOutboxFake) SaveHistoryWithEvents(records []ConversionRecord, events []DomainEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.saveErr != nil {
+		return f.saveErr
+	}
+	f.records = append(f.records, records...)
+	for _, e := range events {
+		f.seq++
+		e.Sequencia = f.seq
+		f.entries = append(f.entries, OutboxEntry{Event: e})
+	}
+	return nil
+}
+
+func (f *memoryOutboxFake) PendingEvents(limit int, skipKeys []string) ([]OutboxEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastSkip = append([]string{}, skipKeys...)
+	sort.Strings(f.lastSkip)
+
+	skip := map[string]bool{}
+	for _, k := range skipKeys {
+		skip[k] = true
+	}
+	var out []OutboxEntry
+	for _, e := range f.entries {
+		if f.published[e.Event.ID] || skip[e.Event.Chave] {
+			continue
+		}
+		if out = append(out, e); len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (f *memoryOutboxFake) MarkPublished(ids []string, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.markErr != nil {
+		return f.markErr
+	}
+	for _, id := range ids {
+		f.published[id] = true
+	}
+	return nil
+}
+
+func (f *memoryOutboxFake) MarkFailed(id string, tentativas int, motivo string, retryAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.markedFail = append(f.markedFail, id)
+	for i := range f.entries {
+		if f.entries[i].Event.ID == id {
+			f.entries[i].Tentativas = tentativas
+			f.entries[i].ProximaTentativa = retryAt
+		}
+	}
+	return nil
+}
+
+// recordingSinkFake guarda as chaves publicadas, na ordem; fail decide quais recusar
+type recordingSinkFake struct {
+	name      string
+	published []DomainEvent
+	fail      func(DomainEvent) bool
+}
+
+func (s *recordingSinkFake) Name() string { return s.name }
+
+func (s *recordingSinkFake) Publish(event DomainEvent) error {
+	if s.fail != nil && s.fail(event) {
+		return errors.New("destino fora do ar")
+	}
+	s.published = append(s.published, event)
+	return nil
+}
+
+func (s *recordingSinkFake) sequences() []int64 {
+	out := make([]int64, len(s.published))
+	for i, e := range s.published {
+		out[i] = e.Sequencia
+	}
+	return out
+}
+
+func newOutboxLogger() *loggermock.LoggerMock {
+	loggerMock := newPersistenceLogger()
+	loggerMock.On("Debug", mock.Anything, mock.Anything).Return()
+	return loggerMock
+}
+
+// seedOutbox grava uma conversão por moeda, na ordem dada
+func seedOutbox(t *testing.T, store *memoryOutboxFake, moedas ...string) {
+	t.Helper()
+	saver := NewOutboxSaver(store)
+	for _, moeda := range moedas {
+		require.NoError(t, saver.SaveHistory(conversion(moeda)))
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should emit conversion created with the saved conversion",
+			run:  shouldEmitConversionCreatedWithTheSavedConversion,
+		},
+		{
+			name: "should fail conversion when outbox transaction fails",
+			run:  shouldFailConversionWhenOutboxTransactionFails,
+		},
+		{
+			name: "should publish pending events in order to every sink",
+			run:  shouldPublishPendingEventsInOrderToEverySink,
+		},
+		{
+			name: "should hold later events of the same key while one fails",
+			run:  shouldHoldLaterEventsOfTheSameKeyWhileOneFails,
+		},
+		{
+			name: "should back off exponentially up to the limit",
+			run:  shouldBackOffExponentiallyUpToTheLimit,
+		},
+		{
+			name: "should publish again when marking fails",
+			run:  shouldPublishAgainWhenMarkingFails,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldEmitConversionCreatedWithTheSavedConversion(t *testing.T) {
+	store := newMemoryOutboxFake()
+	provider := new(rateProviderMock)
+	provider.On("GetRate", "USD").Return(5.0, nil)
+	uc := NewConverterUseCase(provider, NewOutboxSaver(store), newPersistenceLogger())
+
+	ctx := ContextWithAPIKey(context.Background(), &APIKey{ID: "k1"})
+	record, err := uc.Convert(ctx, "USD", 100)
+	require.NoError(t, err)
+
+	require.Len(t, store.records, 1)
+	require.Len(t, store.entries, 1)
+	event := store.entries[0].Event
+	assert.Len(t, event.ID, 32)
+	assert.Equal(t, EventConversionCreated, event.Tipo)
+	assert.Equal(t, "USD", event.Chave)
+	assert.Equal(t, int64(1), event.Sequencia)
+	assert.Equal(t, record.Data, event.CriadoEm)
+
+	var dados ConversionRecord
+	require.NoError(t, json.Unmarshal(event.Dados, &dados))
+	assert.Equal(t, "k1", dados.APIKeyID)
+	assert.Equal(t, 20.0, dados.ValorConvertido)
+}
+
+func shouldFailConversionWhenOutboxTransactionFails(t *testing.T) {
+	store := newMemoryOutboxFake()
+	store.saveErr = errors.New("transação abortada")
+	provider := new(rateProviderMock)
+	provider.On("GetRate", "USD").Return(5.0, nil)
+	uc := NewConverterUseCase(provider, NewOutboxSaver(store), newPersistenceLogger())
+
+	_, err := uc.Convert(context.Background(), "USD", 100)
+
+	assert.ErrorIs(t, err, ErrSaveConversion)
+	assert.Empty(t, store.records)
+	assert.Empty(t, store.entries)
+}
+
+func shouldPublishPendingEventsInOrderToEverySink(t *testing.T) {
+	store := newMemoryOutboxFake()
+	seedOutbox(t, store, "USD", "EUR", "USD")
+	stdout, http := &recordingSinkFake{name: "stdout"}, &recordingSinkFake{name: "http"}
+	relay := NewOutboxRelay(store, []EventSink{stdout, http}, OutboxRelayConfig{}, newOutboxLogger())
+
+	n, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []int64{1, 2, 3}, stdout.sequences())
+	assert.Equal(t, []int64{1, 2, 3}, http.sequences())
+
+	// Nada mais pendente
+	n, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Len(t, stdout.published, 3)
+}
+
+func shouldHoldLaterEventsOfTheSameKeyWhileOneFails(t *testing.T) {
+	store := newMemoryOutboxFake()
+	seedOutbox(t, store, "USD", "USD", "EUR", "USD", "EUR")
+	down := true
+	sink := &recordingSinkFake{name: "http", fail: func(e DomainEvent) bool { return down && e.Sequencia == 2 }}
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	relay := NewOutboxRelay(store, []EventSink{sink}, OutboxRelayConfig{Backoff: time.Minute}, newOutboxLogger())
+	relay.now = func() time.Time { return now }
+
+	_, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	// O 1 sai, o 2 falha e segura o 4; o EUR segue
+	assert.Equal(t, []int64{1, 3, 5}, sink.sequences())
+	assert.Equal(t, 1, store.entries[1].Tentativas)
+	assert.Equal(t, now.Add(time.Minute), store.entries[1].ProximaTentativa)
+
+	// Antes do prazo o USD nem é lido
+	down = false
+	_, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"USD"}, store.lastSkip)
+	assert.Equal(t, []int64{1, 3, 5}, sink.sequences())
+
+	now = now.Add(time.Minute)
+	_, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 3, 5, 2, 4}, sink.sequences())
+}
+
+func shouldBackOffExponentiallyUpToTheLimit(t *testing.T) {
+	relay := NewOutboxRelay(newMemoryOutboxFake(), nil, OutboxRelayConfig{Backoff: time.Second, MaxBackoff: 10 * time.Second}, newOutboxLogger())
+
+	assert.Equal(t, time.Second, relay.backoff(1))
+	assert.Equal(t, 2*time.Second, relay.backoff(2))
+	assert.Equal(t, 8*time.Second, relay.backoff(4))
+	assert.Equal(t, 10*time.Second, relay.backoff(5))
+	assert.Equal(t, 10*time.Second, relay.backoff(50))
+}
+
+func shouldPublishAgainWhenMarkingFails(t *testing.T) {
+	store := newMemoryOutboxFake()
+	seedOutbox(t, store, "USD")
+	store.markErr = errors.New("mongo fora do ar")
+	sink := &recordingSinkFake{name: "nats"}
+	relay := NewOutboxRelay(store, []EventSink{sink}, OutboxRelayConfig{}, newOutboxLogger())
+
+	_, err := relay.RelayOnce(context.Background())
+	require.Error(t, err)
+
+	store.markErr = nil
+	n, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+
+	// Entrega de pelo menos uma vez: o mesmo evento, com o mesmo id, sai duas vezes
+	assert.Equal(t, 1, n)
+	require.Len(t, sink.published, 2)
+	assert.Equal(t, sink.published[0].ID, sink.published[1].ID)
+}
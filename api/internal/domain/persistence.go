@@ -0,0 +1,268 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"go-frete/api/pkg/logger"
+)
+
+var ErrUnknownPersistencePolicy = errors.New("política de persistência desconhecida (use strict, best-effort ou journaled)")
+
+// PersistencePolicy define o que acontece com a conversão quando o banco não grava o histórico
+type PersistencePolicy string
+
+const (
+	// A conversão falha junto com a gravação (comportamento original)
+	PersistStrict PersistencePolicy = "strict"
+	// A falha só é registrada no log: o cliente recebe a conversão e o registro se perde
+	PersistBestEffort PersistencePolicy = "best-effort"
+	// O registro vai para o diário local e é regravado no banco quando ele voltar
+	PersistJournaled PersistencePolicy = "journaled"
+)
+
+func ParsePersistencePolicy(raw string) (PersistencePolicy, error) {
+	switch p := PersistencePolicy(strings.ToLower(strings.TrimSpace(raw))); p {
+	case PersistStrict, PersistBestEffort, PersistJournaled:
+		return p, nil
+	case "":
+		return PersistStrict, nil
+	}
+	return "", ErrUnknownPersistencePolicy
+}
+
+// ConversionJournal é o diário local das conversões que o banco não aceitou.
+// Os registros saem na mesma ordem em que entraram.
+type ConversionJournal interface {
+	Append(record ConversionRecord) error
+	// Pending devolve os registros pendentes, do mais antigo ao mais novo
+	Pending() ([]ConversionRecord, error)
+	// Ack descarta os n primeiros registros pendentes, já gravados no banco
+	Ack(n int) error
+	// Len é a quantidade de registros pendentes
+	Len() int
+}
+
+// PersistenceStats resume o estado da gravação do histórico
+type PersistenceStats struct {
+	Politica PersistencePolicy `json:"politica"`
+	// Registros no diário esperando o banco voltar
+	Pendentes int `json:"pendentes"`
+	// Falhas de gravação no banco desde a subida
+	Falhas uint64 `json:"falhas"`
+	// Registros que foram para o diário, que já foram regravados e que se perderam (best-effort)
+	NoDiario    uint64     `json:"no_diario"`
+	Regravados  uint64     `json:"regravados"`
+	Descartados uint64     `json:"descartados"`
+	UltimaFalha *time.Time `json:"ultima_falha,omitempty"`
+}
+
+// ResilientSaver aplica a política de persistência sobre o repositório do histórico
+type ResilientSaver struct {
+	repo        ConversionSaver
+	journal     ConversionJournal
+	policy      PersistencePolicy
+	replayEvery time.Duration
+	log         logger.Logger
+	now         func() time.Time
+
+	// Uma regravação por vez, para não repetir registros
+	replaying sync.Mutex
+
+	mu    sync.Mutex
+	stats PersistenceStats
+}
+
+func NewResilientSaver(repo ConversionSaver, policy PersistencePolicy, l logger.Logger) *ResilientSaver {
+	return &ResilientSaver{repo: repo, policy: policy, log: l, now: time.Now}
+}
+
+// WithJournal define o diário da política journaled e de quanto em quanto
+// tempo Run tenta regravá-lo no banco
+func (s *ResilientSaver) WithJournal(j ConversionJournal, replayEvery time.Duration) *ResilientSaver {
+	s.journal = j
+	s.replayEvery = replayEvery
+	return s
+}
+
+// SaveHistory implementa ConversionSaver. Na política journaled, enquanto houver
+// registros pendentes os novos entram no diário atrás deles, mantendo a ordem.
+func (s *ResilientSaver) SaveHistory(record ConversionRecord) error {
+	if err := assignRecordID(&record); err != nil {
+		return err
+	}
+	journaled := s.policy == PersistJournaled && s.journal != nil
+	if journaled && s.journal.Len() > 0 {
+		return s.append(record, nil)
+	}
+
+	err := s.repo.SaveHistory(record)
+	if err == nil {
+		return nil
+	}
+	s.mu.Lock()
+	s.stats.Falhas++
+	failedAt := s.now()
+	s.stats.UltimaFalha = &failedAt
+	s.mu.Unlock()
+
+	switch {
+	case journaled:
+		return s.append(record, err)
+	case s.policy == PersistBestEffort:
+		s.mu.Lock()
+		s.stats.Descartados++
+		s.mu.Unlock()
+		s.log.Error("Conversão não gravada no histórico (best-effort)", "erro", err.Error(), "moeda", record.MoedaDestino)
+		return nil
+	}
+	return err
+}
+
+// SaveHistoryBatch implementa ConversionBatchSaver. Se o lote falhar, cada
+// registro é gravado de novo individualmente, passando pela política. Os IDs
+// são gerados antes da primeira tentativa: os registros que o banco já gravou
+// antes da falha voltam como duplicados e o repositório os ignora.
+func (s *ResilientSaver) SaveHistoryBatch(records []ConversionRecord) error {
+	records = append([]ConversionRecord(nil), records...)
+	for i := range records {
+		if err := assignRecordID(&records[i]); err != nil {
+			return err
+		}
+	}
+	if s.policy != PersistJournaled || s.journal == nil || s.journal.Len() == 0 {
+		if err := saveBatch(s.repo, records); err == nil {
+			return nil
+		}
+	}
+
+	var firstErr error
+	for _, record := range records {
+		if err := s.SaveHistory(record); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func assignRecordID(record *ConversionRecord) error {
+	if record.ID != "" {
+		return nil
+	}
+	id, err := randomHex(12)
+	if err != nil {
+		return err
+	}
+	record.ID = id
+	return nil
+}
+
+func (s *ResilientSaver) append(record ConversionRecord, cause error) error {
+	if err := s.journal.Append(record); err != nil {
+		s.log.Error("Falha ao gravar conversão no diário local", "erro", err.Error())
+		if cause != nil {
+			return cause
+		}
+		return err
+	}
+	s.mu.Lock()
+	s.stats.NoDiario++
+	s.mu.Unlock()
+	if cause != nil {
+		s.log.Warn("Banco indisponível: conversão gravada no diário local",
+			"erro", cause.Error(),
+			"pendentes", s.journal.Len(),
+		)
+	}
+	return nil
+}
+
+// Replay regrava no banco, em ordem, os registros pendentes do diário. Para na
+// primeira falha; os que faltarem ficam para a próxima rodada.
+func (s *ResilientSaver) Replay(ctx context.Context) (int, error) {
+	if s.journal == nil {
+		return 0, nil
+	}
+	log := logger.FromContext(ctx, s.log)
+
+	s.replaying.Lock()
+	defer s.replaying.Unlock()
+
+	pending, err := s.journal.Pending()
+	if err != nil {
+		log.Error("Falha ao ler o diário local", "erro", err.Error())
+		return 0, err
+	}
+
+	done := 0
+	var saveErr error
+	for _, record := range pending {
+		if ctx.Err() != nil {
+			saveErr = ctx.Err()
+			break
+		}
+		if saveErr = s.repo.SaveHistory(record); saveErr != nil {
+			break
+		}
+		done++
+	}
+
+	if done > 0 {
+		// Se o Ack falhar, os registros já gravados voltam na próxima rodada:
+		// a entrega é de pelo menos uma vez
+		if err := s.journal.Ack(done); err != nil {
+			log.Error("Falha ao descartar registros regravados do diário", "erro", err.Error(), "regravados", done)
+			return done, err
+		}
+		s.mu.Lock()
+		s.stats.Regravados += uint64(done)
+		s.mu.Unlock()
+		log.Info("Conversões do diário regravadas no banco", "regravados", done, "pendentes", s.journal.Len())
+	}
+	if saveErr != nil {
+		log.Warn("Banco ainda indisponível para regravar o diário", "erro", saveErr.Error(), "pendentes", s.journal.Len())
+		return done, saveErr
+	}
+	return done, nil
+}
+
+// Run regrava o diário na subida e depois a cada intervalo, até o contexto ser cancelado
+func (s *ResilientSaver) Run(ctx context.Context) {
+	if s.journal == nil || s.replayEvery <= 0 {
+		return
+	}
+	s.log.Info("Regravação do diário local iniciada", "pendentes", s.journal.Len(), "intervalo", s.replayEvery.String())
+
+	ticker := time.NewTicker(s.replayEvery)
+	defer ticker.Stop()
+
+	for {
+		if s.journal.Len() > 0 {
+			// Falhas já foram registradas; a próxima rodada tenta de novo
+			s.Replay(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			s.log.Info("Regravação do diário local encerrada", "pendentes", s.journal.Len())
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stats devolve a política e os contadores de gravação
+func (s *ResilientSaver) Stats() PersistenceStats {
+	s.mu.Lock()
+	stats := s.stats
+	s.mu.Unlock()
+
+	stats.Politica = s.policy
+	if s.journal != nil {
+		stats.Pendentes = s.journal.Len()
+	}
+	return stats
+}
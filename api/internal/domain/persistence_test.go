@@ -0,0 +1,337 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var errMongoDown = errors.New("server selection timeout")
+
+// outageSaverFake simula o MongoDB: enquanto down, toda gravação falha
+type outageSaverFake struct {
+	down  bool
+	saved []ConversionRecord
+}
+
+func (f *outageSaverFake) SaveHistory(record ConversionRecord) error {
+	if f.down {
+		return errMongoDown
+	}
+	f.saved = append(f.saved, record)
+	return nil
+}
+
+// memoryJournalFake é o diário em memória
+type memoryJournalFake struct {
+	records   []ConversionRecord
+	appendErr error
+}
+
+func (f *memoryJournalFake) Append(record ConversionRecord) error {
+	if f.appendErr != nil {
+		return f.appendErr
+	}
+	f.records = append(f.records, record)
+	return nil
+}
+
+func (f *memoryJournalFake) Pending() ([]ConversionRecord, error) {
+	return append([]ConversionRecord(nil), f.records...), nil
+}
+
+func (f *memoryJournalFake) Ack(n int) error {
+	f.records = f.records[n:]
+	return nil
+}
+
+func (f *memoryJournalFake) Len() int {
+	return len(f.records)
+}
+
+// partialBatchSaverFake imita o InsertMany que grava os primeiros k registros
+// do lote e falha; como o banco, recusa um _id repetido e o repositório trata
+// isso como sucesso
+type partialBatchSaverFake struct {
+	k     int
+	down  bool
+	saved []ConversionRecord
+}
+
+func (f *partialBatchSaverFake) SaveHistory(record ConversionRecord) error {
+	if f.down {
+		return errMongoDown
+	}
+	f.insert(record)
+	return nil
+}
+
+func (f *partialBatchSaverFake) SaveHistoryBatch(records []ConversionRecord) error {
+	for i, record := range records {
+		if i == f.k {
+			return errMongoDown
+		}
+		f.insert(record)
+	}
+	return nil
+}
+
+func (f *partialBatchSaverFake) insert(record ConversionRecord) {
+	for _, s := range f.saved {
+		if s.ID == record.ID {
+			return
+		}
+	}
+	f.saved = append(f.saved, record)
+}
+
+func newPersistenceLogger() *loggermock.LoggerMock {
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Error", mock.Anything, mock.Anything).Return()
+	return loggerMock
+}
+
+func conversion(moeda string) ConversionRecord {
+	return ConversionRecord{MoedaDestino: moeda, Cotacao: 5, ValorEntrada: 100, ValorConvertido: 20}
+}
+
+func currencies(records []ConversionRecord) []string {
+	out := make([]string, 0, len(records))
+	for _, r := range records {
+		out = append(out, r.MoedaDestino)
+	}
+	return out
+}
+
+func TestParsePersistencePolicy(t *testing.T) {
+	for raw, want := range map[string]PersistencePolicy{
+		"":            PersistStrict,
+		"strict":      PersistStrict,
+		"Best-Effort": PersistBestEffort,
+		" journaled ": PersistJournaled,
+	} {
+		got, err := ParsePersistencePolicy(raw)
+		assert.NoError(t, err, raw)
+		assert.Equal(t, want, got, raw)
+	}
+
+	_, err := ParsePersistencePolicy("sometimes")
+	assert.ErrorIs(t, err, ErrUnknownPersistencePolicy)
+}
+
+func TestResilientSaver_Outage(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should fail conversion on strict policy",
+			run:  shouldFailConversionOnStrictPolicy,
+		},
+		{
+			name: "should keep conversion and count loss on best effort policy",
+			run:  shouldKeepConversionAndCountLossOnBestEffortPolicy,
+		},
+		{
+			name: "should journal during outage and replay in order after recovery",
+			run:  shouldJournalDuringOutageAndReplayInOrderAfterRecovery,
+		},
+		{
+			name: "should queue new records behind pending ones",
+			run:  shouldQueueNewRecordsBehindPendingOnes,
+		},
+		{
+			name: "should keep remaining records when outage returns mid replay",
+			run:  shouldKeepRemainingRecordsWhenOutageReturnsMidReplay,
+		},
+		{
+			name: "should fail conversion when journal also fails",
+			run:  shouldFailConversionWhenJournalAlsoFails,
+		},
+		{
+			name: "should return saved record through converter during outage",
+			run:  shouldReturnSavedRecordThroughConverterDuringOutage,
+		},
+		{
+			name: "should not duplicate records written before batch failure",
+			run:  shouldNotDuplicateRecordsWrittenBeforeBatchFailure,
+		},
+		{
+			name: "should not duplicate partial batch replayed from journal",
+			run:  shouldNotDuplicatePartialBatchReplayedFromJournal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldFailConversionOnStrictPolicy(t *testing.T) {
+	repo := &outageSaverFake{down: true}
+	saver := NewResilientSaver(repo, PersistStrict, newPersistenceLogger())
+
+	err := saver.SaveHistory(conversion("USD"))
+
+	assert.ErrorIs(t, err, errMongoDown)
+	assert.Equal(t, uint64(1), saver.Stats().Falhas)
+}
+
+func shouldKeepConversionAndCountLossOnBestEffortPolicy(t *testing.T) {
+	repo := &outageSaverFake{down: true}
+	saver := NewResilientSaver(repo, PersistBestEffort, newPersistenceLogger())
+
+	err := saver.SaveHistory(conversion("USD"))
+
+	assert.NoError(t, err)
+	stats := saver.Stats()
+	assert.Equal(t, PersistBestEffort, stats.Politica)
+	assert.Equal(t, uint64(1), stats.Descartados)
+	assert.NotNil(t, stats.UltimaFalha)
+}
+
+func shouldJournalDuringOutageAndReplayInOrderAfterRecovery(t *testing.T) {
+	repo := &outageSaverFake{down: true}
+	journal := &memoryJournalFake{}
+	saver := NewResilientSaver(repo, PersistJournaled, newPersistenceLogger()).WithJournal(journal, time.Second)
+
+	for _, moeda := range []string{"USD", "EUR", "GBP"} {
+		assert.NoError(t, saver.SaveHistory(conversion(moeda)))
+	}
+	assert.Equal(t, 3, saver.Stats().Pendentes)
+
+	_, err := saver.Replay(context.Background())
+	assert.ErrorIs(t, err, errMongoDown)
+	assert.Equal(t, 3, saver.Stats().Pendentes)
+
+	repo.down = false
+	done, err := saver.Replay(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, done)
+	assert.Equal(t, []string{"USD", "EUR", "GBP"}, currencies(repo.saved))
+	stats := saver.Stats()
+	assert.Equal(t, 0, stats.Pendentes)
+	assert.Equal(t, uint64(3), stats.NoDiario)
+	assert.Equal(t, uint64(3), stats.Regravados)
+}
+
+func shouldQueueNewRecordsBehindPendingOnes(t *testing.T) {
+	repo := &outageSaverFake{down: true}
+	journal := &memoryJournalFake{}
+	saver := NewResilientSaver(repo, PersistJournaled, newPersistenceLogger()).WithJournal(journal, time.Second)
+
+	assert.NoError(t, saver.SaveHistory(conversion("USD")))
+	// O banco voltou, mas ainda há pendência: o novo registro não pode passar na frente
+	repo.down = false
+	assert.NoError(t, saver.SaveHistory(conversion("EUR")))
+	assert.Empty(t, repo.saved)
+
+	_, err := saver.Replay(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"USD", "EUR"}, currencies(repo.saved))
+	// Sem pendência, volta a gravar direto no banco
+	assert.NoError(t, saver.SaveHistory(conversion("GBP")))
+	assert.Equal(t, []string{"USD", "EUR", "GBP"}, currencies(repo.saved))
+	assert.Equal(t, 0, journal.Len())
+}
+
+// flappingSaverFake aceita um número fixo de gravações e depois cai de novo
+type flappingSaverFake struct {
+	outageSaverFake
+	budget int
+}
+
+func (f *flappingSaverFake) SaveHistory(record ConversionRecord) error {
+	if f.budget == 0 {
+		return errMongoDown
+	}
+	f.budget--
+	return f.outageSaverFake.SaveHistory(record)
+}
+
+func shouldKeepRemainingRecordsWhenOutageReturnsMidReplay(t *testing.T) {
+	repo := &flappingSaverFake{budget: 0}
+	journal := &memoryJournalFake{}
+	saver := NewResilientSaver(repo, PersistJournaled, newPersistenceLogger()).WithJournal(journal, time.Second)
+	for _, moeda := range []string{"USD", "EUR", "GBP"} {
+		assert.NoError(t, saver.SaveHistory(conversion(moeda)))
+	}
+
+	repo.budget = 1
+	done, err := saver.Replay(context.Background())
+
+	assert.ErrorIs(t, err, errMongoDown)
+	assert.Equal(t, 1, done)
+	assert.Equal(t, []string{"USD"}, currencies(repo.saved))
+	assert.Equal(t, []string{"EUR", "GBP"}, currencies(journal.records))
+}
+
+func shouldFailConversionWhenJournalAlsoFails(t *testing.T) {
+	repo := &outageSaverFake{down: true}
+	journal := &memoryJournalFake{appendErr: errors.New("no space left on device")}
+	saver := NewResilientSaver(repo, PersistJournaled, newPersistenceLogger()).WithJournal(journal, time.Second)
+
+	err := saver.SaveHistory(conversion("USD"))
+
+	assert.ErrorIs(t, err, errMongoDown)
+	assert.Equal(t, uint64(0), saver.Stats().NoDiario)
+}
+
+func shouldReturnSavedRecordThroughConverterDuringOutage(t *testing.T) {
+	providerMock := new(namedProviderStub)
+	providerMock.On("GetRate", "USD").Return(5.0, nil)
+	repo := &outageSaverFake{down: true}
+	journal := &memoryJournalFake{}
+	loggerMock := newPersistenceLogger()
+	saver := NewResilientSaver(repo, PersistJournaled, loggerMock).WithJournal(journal, time.Second)
+	uc := NewConverterUseCase(providerMock, saver, loggerMock)
+
+	record, err := uc.Convert(context.Background(), "USD", 100)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 20.0, record.ValorConvertido)
+	assert.Len(t, journal.records, 1)
+}
+
+func shouldNotDuplicateRecordsWrittenBeforeBatchFailure(t *testing.T) {
+	repo := &partialBatchSaverFake{k: 2}
+	saver := NewResilientSaver(repo, PersistStrict, newPersistenceLogger())
+
+	err := saver.SaveHistoryBatch([]ConversionRecord{conversion("USD"), conversion("EUR"), conversion("GBP")})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"USD", "EUR", "GBP"}, currencies(repo.saved))
+	for _, record := range repo.saved {
+		assert.Len(t, record.ID, 24)
+	}
+}
+
+func shouldNotDuplicatePartialBatchReplayedFromJournal(t *testing.T) {
+	repo := &partialBatchSaverFake{k: 1, down: true}
+	journal := &memoryJournalFake{}
+	saver := NewResilientSaver(repo, PersistJournaled, newPersistenceLogger()).WithJournal(journal, time.Second)
+	records := []ConversionRecord{conversion("USD"), conversion("EUR"), conversion("GBP")}
+
+	assert.NoError(t, saver.SaveHistoryBatch(records))
+	// O USD chegou ao banco antes da falha, mas o diário também o guardou
+	assert.Equal(t, []string{"USD"}, currencies(repo.saved))
+	assert.Equal(t, []string{"USD", "EUR", "GBP"}, currencies(journal.records))
+	assert.Empty(t, records[0].ID, "o lote do chamador não deve ser alterado")
+
+	repo.down = false
+	done, err := saver.Replay(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, done)
+	assert.Equal(t, []string{"USD", "EUR", "GBP"}, currencies(repo.saved))
+}
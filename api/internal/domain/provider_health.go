@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"go-frete/api/pkg/logger"
+)
+
+// ProviderDown são os dados do evento EventProviderDown
+type ProviderDown struct {
+	Provedor       string    `json:"provedor"`
+	FalhasSeguidas int       `json:"falhas_seguidas"`
+	UltimoErro     string    `json:"ultimo_erro"`
+	DesdeEm        time.Time `json:"desde_em"`
+}
+
+// ProviderHealth conta as falhas seguidas do provedor de cotações e publica
+// EventProviderDown uma vez quando elas chegam ao limite. A primeira resposta
+// boa rearma o aviso. Moeda inexistente é resposta do provedor, não falha.
+type ProviderHealth struct {
+	next      RateProvider
+	threshold int
+	events    EventSink
+	log       logger.Logger
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	since    time.Time
+	down     bool
+}
+
+func NewProviderHealth(next RateProvider, threshold int, events EventSink, l logger.Logger) *ProviderHealth {
+	if threshold <= 0 {
+		threshold = 5
+	}
+	return &ProviderHealth{next: next, threshold: threshold, events: events, log: l, now: time.Now}
+}
+
+func (p *ProviderHealth) GetRate(moeda string) (float64, error) {
+	cotacao, err := p.next.GetRate(moeda)
+	p.observe(err)
+	return cotacao, err
+}
+
+// GetQuote repassa o horário da cotação, se o provedor decorado o informar
+func (p *ProviderHealth) GetQuote(moeda string) (RateQuote, error) {
+	quote, err := quoteOf(p.next, moeda)
+	p.observe(err)
+	return quote, err
+}
+
+// Source repassa a identificação do provedor decorado
+func (p *ProviderHealth) Source() string {
+	return sourceOf(p.next)
+}
+
+// GetDailyRates repassa a série diária, se o provedor decorado a oferecer, e
+// conta as falhas junto com as da cotação atual
+func (p *ProviderHealth) GetDailyRates(moeda string, from, to time.Time) ([]RateSnapshot, error) {
+	h, ok := p.next.(HistoricalRateProvider)
+	if !ok {
+		return nil, ErrRateNotAvailable
+	}
+	rates, err := h.GetDailyRates(moeda, from, to)
+	p.observe(err)
+	return rates, err
+}
+
+func (p *ProviderHealth) observe(err error) {
+	if err == nil || errors.Is(err, ErrCurrencyNotFound) {
+		p.mu.Lock()
+		recovered := p.down
+		p.failures, p.down = 0, false
+		p.mu.Unlock()
+		if recovered {
+			p.log.Info("Provedor de cotações voltou a responder", "provedor", p.Source())
+		}
+		return
+	}
+
+	p.mu.Lock()
+	p.failures++
+	if p.failures == 1 {
+		p.since = p.now().UTC()
+	}
+	if p.down || p.failures < p.threshold {
+		p.mu.Unlock()
+		return
+	}
+	p.down = true
+	dados := ProviderDown{Provedor: p.Source(), FalhasSeguidas: p.failures, UltimoErro: err.Error(), DesdeEm: p.since}
+	p.mu.Unlock()
+
+	p.log.Warn("Provedor de cotações fora do ar", "provedor", dados.Provedor, "falhas_seguidas", dados.FalhasSeguidas, "erro", dados.UltimoErro)
+	if err := p.publish(dados); err != nil {
+		p.log.Error("Falha ao publicar queda do provedor", "erro", err.Error())
+	}
+}
+
+func (p *ProviderHealth) publish(dados ProviderDown) error {
+	id, err := randomHex(16)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(dados)
+	if err != nil {
+		return err
+	}
+	return p.events.Publish(DomainEvent{
+		ID:       id,
+		Tipo:     EventProviderDown,
+		Chave:    dados.Provedor,
+		CriadoEm: p.now().UTC(),
+		Dados:    raw,
+	})
+}
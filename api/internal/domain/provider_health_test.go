@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderHealth(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should publish provider down once at the threshold",
+			run:  shouldPublishProviderDownOnceAtTheThreshold,
+		},
+		{
+			name: "should not count unknown currency as failure",
+			run:  shouldNotCountUnknownCurrencyAsFailure,
+		},
+		{
+			name: "should warn again after the provider recovers",
+			run:  shouldWarnAgainAfterTheProviderRecovers,
+		},
+		{
+			name: "should count daily rate failures",
+			run:  shouldCountDailyRateFailures,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldPublishProviderDownOnceAtTheThreshold(t *testing.T) {
+	provider := new(rateProviderMock)
+	provider.On("GetRate", "USD").Return(0.0, errors.New("timeout"))
+	sink := &recordingSinkFake{name: "webhooks"}
+	health := NewProviderHealth(provider, 3, sink, newPersistenceLogger())
+
+	for range 5 {
+		_, err := health.GetRate("USD")
+		require.Error(t, err)
+	}
+
+	require.Len(t, sink.published, 1)
+	event := sink.published[0]
+	assert.Equal(t, EventProviderDown, event.Tipo)
+	var dados ProviderDown
+	require.NoError(t, json.Unmarshal(event.Dados, &dados))
+	assert.Equal(t, 3, dados.FalhasSeguidas)
+	assert.Equal(t, "timeout", dados.UltimoErro)
+}
+
+func shouldNotCountUnknownCurrencyAsFailure(t *testing.T) {
+	provider := new(rateProviderMock)
+	provider.On("GetRate", "USD").Return(0.0, errors.New("timeout"))
+	provider.On("GetRate", "XYZ").Return(0.0, ErrCurrencyNotFound)
+	sink := &recordingSinkFake{name: "webhooks"}
+	health := NewProviderHealth(provider, 2, sink, newPersistenceLogger())
+
+	for _, moeda := range []string{"USD", "XYZ", "USD", "XYZ"} {
+		health.GetRate(moeda)
+	}
+
+	assert.Empty(t, sink.published)
+}
+
+func shouldWarnAgainAfterTheProviderRecovers(t *testing.T) {
+	provider := new(rateProviderMock)
+	provider.On("GetRate", "USD").Return(0.0, errors.New("timeout")).Twice()
+	provider.On("GetRate", "USD").Return(5.0, nil).Once()
+	provider.On("GetRate", "USD").Return(0.0, errors.New("502")).Twice()
+	sink := &recordingSinkFake{name: "webhooks"}
+	health := NewProviderHealth(provider, 2, sink, newPersistenceLogger())
+
+	for range 5 {
+		health.GetRate("USD")
+	}
+
+	assert.Len(t, sink.published, 2)
+}
+
+func shouldCountDailyRateFailures(t *testing.T) {
+	provider := &dailySeriesProviderFake{quoteProviderFake: quoteProviderFake{err: errors.New("timeout")}}
+	sink := &recordingSinkFake{name: "webhooks"}
+	health := NewProviderHealth(provider, 2, sink, newPersistenceLogger())
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	_, err := health.GetRate("USD")
+	require.Error(t, err)
+	_, err = health.GetDailyRates("USD", day, day)
+	require.Error(t, err)
+
+	assert.Len(t, sink.published, 1)
+
+	_, err = NewProviderHealth(new(rateProviderMock), 2, sink, newPersistenceLogger()).GetDailyRates("USD", day, day)
+	assert.ErrorIs(t, err, ErrRateNotAvailable)
+}
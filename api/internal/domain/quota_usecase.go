@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"go-frete/api/pkg/logger"
+	"time"
+)
+
+// Planos padrão quando a chave não informa um plano conhecido
+const (
+	DefaultPlan   = "default"
+	AnonymousPlan = "anonymous"
+)
+
+var ErrQuotaExceeded = errors.New("cota diária de conversões excedida")
+
+// Plan define os limites de uso de um cliente
+type Plan struct {
+	Name              string
+	RequestsPerMinute int
+	Burst             int
+	// Zero ou negativo significa sem limite diário
+	DailyConversions int
+}
+
+// QuotaStatus é o retrato da cota diária após um consumo
+type QuotaStatus struct {
+	Limit int
+	Used  int
+	Reset time.Time
+}
+
+func (s QuotaStatus) Remaining() int {
+	if s.Used >= s.Limit {
+		return 0
+	}
+	return s.Limit - s.Used
+}
+
+type UsageCounter interface {
+	// IncrementDailyUsage soma 1 ao uso do cliente no dia e devolve o total atualizado
+	IncrementDailyUsage(clientID string, day time.Time) (int, error)
+	// DecrementDailyUsage devolve 1 ao uso do cliente no dia, sem ficar negativo
+	DecrementDailyUsage(clientID string, day time.Time) error
+}
+
+type QuotaUseCase struct {
+	repo  UsageCounter
+	plans map[string]Plan
+	log   logger.Logger
+}
+
+func NewQuotaUseCase(r UsageCounter, plans []Plan, l logger.Logger) *QuotaUseCase {
+	byName := make(map[string]Plan, len(plans))
+	for _, p := range plans {
+		byName[p.Name] = p
+	}
+	return &QuotaUseCase{repo: r, plans: byName, log: l}
+}
+
+// PlanFor resolve o plano da chave; sem chave vale o plano anônimo
+func (uc *QuotaUseCase) PlanFor(key *APIKey) Plan {
+	name := AnonymousPlan
+	if key != nil {
+		name = key.Plan
+		if name == "" {
+			name = DefaultPlan
+		}
+	}
+
+	if p, ok := uc.plans[name]; ok {
+		return p
+	}
+	return uc.plans[DefaultPlan]
+}
+
+// Consume registra uma conversão na cota diária da chave (dia em UTC)
+func (uc *QuotaUseCase) Consume(ctx context.Context, key *APIKey) (QuotaStatus, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	plan := uc.PlanFor(key)
+	if key == nil || plan.DailyConversions <= 0 {
+		return QuotaStatus{}, nil
+	}
+
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	used, err := uc.repo.IncrementDailyUsage(key.ID, day)
+	if err != nil {
+		log.Error("Falha ao registrar uso da cota diária", "erro", err.Error())
+		return QuotaStatus{}, err
+	}
+
+	status := QuotaStatus{Limit: plan.DailyConversions, Used: used, Reset: day.AddDate(0, 0, 1)}
+	if used > plan.DailyConversions {
+		log.Warn("Cota diária excedida", "plano", plan.Name, "usado", used, "limite", plan.DailyConversions)
+		// A chamada recusada não conta no uso do dia
+		uc.Refund(ctx, key, status)
+		return status, ErrQuotaExceeded
+	}
+	return status, nil
+}
+
+// Refund devolve à cota a conversão consumida por uma chamada que falhou.
+// Uma falha ao devolver só é registrada: a chamada já foi respondida
+func (uc *QuotaUseCase) Refund(ctx context.Context, key *APIKey, consumed QuotaStatus) {
+	if key == nil || consumed.Limit <= 0 {
+		return
+	}
+
+	day := consumed.Reset.AddDate(0, 0, -1)
+	if err := uc.repo.DecrementDailyUsage(key.ID, day); err != nil {
+		logger.FromContext(ctx, uc.log).Error("Falha ao devolver uso da cota diária", "erro", err.Error())
+	}
+}
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type usageCounterMock struct {
+	mock.Mock
+}
+
+func (m *usageCounterMock) IncrementDailyUsage(clientID string, day time.Time) (int, error) {
+	args := m.Called(clientID, day)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *usageCounterMock) DecrementDailyUsage(clientID string, day time.Time) error {
+	return m.Called(clientID, day).Error(0)
+}
+
+var testPlans = []Plan{
+	{Name: AnonymousPlan, RequestsPerMinute: 10, Burst: 5},
+	{Name: DefaultPlan, RequestsPerMinute: 60, Burst: 20, DailyConversions: 2},
+	{Name: "batch", RequestsPerMinute: 600, Burst: 100, DailyConversions: 0},
+}
+
+func TestQuotaUseCase(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should resolve plan from key with default fallback",
+			run:  shouldResolvePlanFromKeyWithDefaultFallback,
+		},
+		{
+			name: "should allow conversions within daily quota",
+			run:  shouldAllowConversionsWithinDailyQuota,
+		},
+		{
+			name: "should reject conversion beyond daily quota",
+			run:  shouldRejectConversionBeyondDailyQuota,
+		},
+		{
+			name: "should skip counting for unlimited plans",
+			run:  shouldSkipCountingForUnlimitedPlans,
+		},
+		{
+			name: "should return error when usage counter fails",
+			run:  shouldReturnErrorWhenUsageCounterFails,
+		},
+		{
+			name: "should refund only consumed quota",
+			run:  shouldRefundOnlyConsumedQuota,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldResolvePlanFromKeyWithDefaultFallback(t *testing.T) {
+	uc := NewQuotaUseCase(new(usageCounterMock), testPlans, new(loggermock.LoggerMock))
+
+	assert.Equal(t, AnonymousPlan, uc.PlanFor(nil).Name)
+	assert.Equal(t, DefaultPlan, uc.PlanFor(&APIKey{}).Name)
+	assert.Equal(t, "batch", uc.PlanFor(&APIKey{Plan: "batch"}).Name)
+	assert.Equal(t, DefaultPlan, uc.PlanFor(&APIKey{Plan: "desconhecido"}).Name)
+}
+
+func shouldAllowConversionsWithinDailyQuota(t *testing.T) {
+	counterMock := new(usageCounterMock)
+	counterMock.On("IncrementDailyUsage", "k1", mock.Anything).Return(2, nil)
+
+	uc := NewQuotaUseCase(counterMock, testPlans, new(loggermock.LoggerMock))
+	status, err := uc.Consume(context.Background(), &APIKey{ID: "k1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, status.Limit)
+	assert.Equal(t, 0, status.Remaining())
+	assert.True(t, status.Reset.After(time.Now()))
+}
+
+func shouldRejectConversionBeyondDailyQuota(t *testing.T) {
+	counterMock := new(usageCounterMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+	counterMock.On("IncrementDailyUsage", "k1", mock.Anything).Return(3, nil)
+	counterMock.On("DecrementDailyUsage", "k1", mock.Anything).Return(nil)
+
+	uc := NewQuotaUseCase(counterMock, testPlans, loggerMock)
+	_, err := uc.Consume(context.Background(), &APIKey{ID: "k1"})
+
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	// A chamada recusada é devolvida ao contador no mesmo dia
+	day := counterMock.Calls[0].Arguments.Get(1)
+	counterMock.AssertCalled(t, "DecrementDailyUsage", "k1", day)
+}
+
+func shouldSkipCountingForUnlimitedPlans(t *testing.T) {
+	counterMock := new(usageCounterMock)
+
+	uc := NewQuotaUseCase(counterMock, testPlans, new(loggermock.LoggerMock))
+	_, err := uc.Consume(context.Background(), &APIKey{ID: "k1", Plan: "batch"})
+
+	assert.NoError(t, err)
+	counterMock.AssertNotCalled(t, "IncrementDailyUsage", mock.Anything, mock.Anything)
+}
+
+func shouldReturnErrorWhenUsageCounterFails(t *testing.T) {
+	counterMock := new(usageCounterMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	loggerMock.On("Error", mock.Anything, mock.Anything).Return()
+	counterMock.On("IncrementDailyUsage", "k1", mock.Anything).Return(0, errors.New("mongo timeout"))
+
+	uc := NewQuotaUseCase(counterMock, testPlans, loggerMock)
+	_, err := uc.Consume(context.Background(), &APIKey{ID: "k1"})
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrQuotaExceeded)
+}
+
+func shouldRefundOnlyConsumedQuota(t *testing.T) {
+	counterMock := new(usageCounterMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	loggerMock.On("Error", mock.Anything, mock.Anything).Return()
+	counterMock.On("DecrementDailyUsage", "k1", time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)).Return(errors.New("mongo timeout"))
+
+	uc := NewQuotaUseCase(counterMock, testPlans, loggerMock)
+	consumed := QuotaStatus{Limit: 2, Used: 1, Reset: time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC)}
+	uc.Refund(context.Background(), nil, consumed)
+	uc.Refund(context.Background(), &APIKey{ID: "k1", Plan: "batch"}, QuotaStatus{})
+	uc.Refund(context.Background(), &APIKey{ID: "k1"}, consumed)
+
+	counterMock.AssertNumberOfCalls(t, "DecrementDailyUsage", 1)
+	// A falha ao devolver só é registrada
+	loggerMock.AssertCalled(t, "Error", "Falha ao devolver uso da cota diária", mock.Anything)
+}
@@ -0,0 +1,308 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-frete/api/pkg/logger"
+)
+
+var (
+	ErrSlowConsumer       = errors.New("assinante não acompanhou o ritmo das cotações")
+	ErrCurrencyNotWatched = errors.New("moeda fora da lista de cotações transmitidas")
+	ErrBroadcasterStopped = errors.New("transmissão de cotações encerrada")
+)
+
+// RateEvent é uma mudança de cotação; o ID cresce a cada evento e permite retomar
+// a transmissão de onde o cliente parou (Last-Event-ID). A contagem parte do
+// instante da subida em microssegundos, então continua crescendo depois de um
+// reinício (e cabe num número do JavaScript)
+type RateEvent struct {
+	ID      uint64    `json:"id"`
+	Moeda   string    `json:"moeda"`
+	Cotacao float64   `json:"cotacao"`
+	Fonte   string    `json:"fonte,omitempty"`
+	Data    time.Time `json:"data"`
+}
+
+// RateBroadcaster consulta as moedas configuradas em intervalos e repassa as
+// mudanças de cotação para todos os assinantes
+type RateBroadcaster struct {
+	provider   RateProvider
+	currencies []string
+	interval   time.Duration
+	log        logger.Logger
+
+	mu          sync.Mutex
+	lastID      uint64
+	history     []RateEvent
+	historySize int
+	latest      map[string]RateEvent
+	subs        map[*RateSubscription]struct{}
+	stopped     bool
+}
+
+// NewRateBroadcaster guarda os últimos historySize eventos para quem reconectar
+func NewRateBroadcaster(p RateProvider, currencies []string, interval time.Duration, historySize int, l logger.Logger) *RateBroadcaster {
+	normalized := make([]string, 0, len(currencies))
+	for _, c := range currencies {
+		normalized = append(normalized, strings.ToUpper(c))
+	}
+	return &RateBroadcaster{
+		provider:    p,
+		currencies:  normalized,
+		interval:    interval,
+		log:         l,
+		historySize: historySize,
+		lastID:      uint64(time.Now().UnixMicro()),
+		latest:      make(map[string]RateEvent),
+		subs:        make(map[*RateSubscription]struct{}),
+	}
+}
+
+// Currencies devolve as moedas transmitidas
+func (b *RateBroadcaster) Currencies() []string {
+	return slices.Clone(b.currencies)
+}
+
+// Run consulta o provedor até o contexto ser cancelado e então encerra os assinantes
+func (b *RateBroadcaster) Run(ctx context.Context) {
+	b.log.Info("Transmissão de cotações iniciada", "moedas", b.currencies, "intervalo", b.interval.String())
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		b.poll()
+
+		select {
+		case <-ctx.Done():
+			b.stop()
+			b.log.Info("Transmissão de cotações encerrada")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *RateBroadcaster) poll() {
+	source := sourceOf(b.provider)
+	for _, moeda := range b.currencies {
+		cotacao, err := b.provider.GetRate(moeda)
+		if err != nil {
+			// Falha momentânea: a próxima rodada tenta de novo
+			b.log.Warn("Falha ao consultar cotação para transmissão", "moeda", moeda, "erro", err.Error())
+			continue
+		}
+		if cotacao == 0 {
+			continue
+		}
+		b.Publish(RateQuote{Moeda: moeda, Cotacao: cotacao, Fonte: source, Data: time.Now()})
+	}
+}
+
+// Publish registra a cotação e a envia aos assinantes se ela mudou
+func (b *RateBroadcaster) Publish(q RateQuote) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stopped {
+		return
+	}
+	if prev, ok := b.latest[q.Moeda]; ok && prev.Cotacao == q.Cotacao {
+		return
+	}
+
+	b.lastID++
+	ev := RateEvent{ID: b.lastID, Moeda: q.Moeda, Cotacao: q.Cotacao, Fonte: q.Fonte, Data: q.Data}
+	b.latest[q.Moeda] = ev
+	b.history = append(b.history, ev)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for sub := range b.subs {
+		if !sub.deliver(ev) {
+			// Backpressure: quem não consome a tempo é desconectado e pode
+			// retomar pelo último ID recebido sem travar os demais
+			delete(b.subs, sub)
+			sub.finish(ErrSlowConsumer)
+			b.log.Warn("Assinante de cotações desconectado por lentidão", "ultimo_evento", ev.ID)
+		}
+	}
+}
+
+// Subscribe registra um assinante das moedas informadas (vazio = todas).
+// Com lastEventID os eventos perdidos ainda em memória são reenviados; sem ele,
+// ou se o histórico já não cobre o intervalo, o assinante recebe a cotação atual
+// de cada moeda. buffer é quantos eventos podem ficar pendentes antes de o
+// assinante ser considerado lento.
+func (b *RateBroadcaster) Subscribe(currencies []string, lastEventID uint64, buffer int) (*RateSubscription, error) {
+	filter, err := b.filter(currencies)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stopped {
+		return nil, ErrBroadcasterStopped
+	}
+
+	backlog := b.backlog(filter, lastEventID)
+	sub := &RateSubscription{
+		broadcaster: b,
+		events:      make(chan RateEvent, buffer+len(backlog)),
+		done:        make(chan struct{}),
+		filter:      filter,
+	}
+	for _, ev := range backlog {
+		sub.events <- ev
+	}
+	b.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+func (b *RateBroadcaster) backlog(filter map[string]bool, lastEventID uint64) []RateEvent {
+	var out []RateEvent
+
+	// Um ID à frente do último emitido não saiu desta instância (veio de outra
+	// réplica ou de antes de um reinício com o relógio atrasado): o cliente
+	// recebe o estado atual, como numa conexão nova
+	if lastEventID > b.lastID {
+		lastEventID = 0
+	}
+
+	// Retomada: o histórico ainda contém o evento seguinte ao último recebido
+	if lastEventID > 0 && len(b.history) > 0 && b.history[0].ID <= lastEventID+1 {
+		for _, ev := range b.history {
+			if ev.ID > lastEventID && matches(filter, ev.Moeda) {
+				out = append(out, ev)
+			}
+		}
+		return out
+	}
+
+	for moeda, ev := range b.latest {
+		if matches(filter, moeda) && ev.ID > lastEventID {
+			out = append(out, ev)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func (b *RateBroadcaster) filter(currencies []string) (map[string]bool, error) {
+	filter := make(map[string]bool, len(currencies))
+	for _, c := range currencies {
+		c = strings.ToUpper(strings.TrimSpace(c))
+		if c == "" {
+			continue
+		}
+		if !slices.Contains(b.currencies, c) {
+			return nil, ErrCurrencyNotWatched
+		}
+		filter[c] = true
+	}
+	return filter, nil
+}
+
+func (b *RateBroadcaster) unsubscribe(sub *RateSubscription) {
+	b.mu.Lock()
+	delete(b.subs, sub)
+	b.mu.Unlock()
+	sub.finish(nil)
+}
+
+func (b *RateBroadcaster) stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stopped = true
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		sub.finish(ErrBroadcasterStopped)
+	}
+}
+
+func matches(filter map[string]bool, moeda string) bool {
+	return len(filter) == 0 || filter[moeda]
+}
+
+// RateSubscription entrega os eventos de um assinante até Done ser fechado
+type RateSubscription struct {
+	broadcaster *RateBroadcaster
+	events      chan RateEvent
+	done        chan struct{}
+
+	mu     sync.Mutex
+	filter map[string]bool
+	err    error
+	once   sync.Once
+}
+
+// Events entrega os eventos em ordem de ID; nunca é fechado, use Done
+func (s *RateSubscription) Events() <-chan RateEvent {
+	return s.events
+}
+
+// Done é fechado quando a assinatura termina; Err diz o motivo
+func (s *RateSubscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err devolve ErrSlowConsumer, ErrBroadcasterStopped ou nil se fechada pelo cliente
+func (s *RateSubscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// SetCurrencies troca as moedas acompanhadas sem perder a conexão
+func (s *RateSubscription) SetCurrencies(currencies []string) error {
+	filter, err := s.broadcaster.filter(currencies)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.filter = filter
+	s.mu.Unlock()
+	return nil
+}
+
+// Close cancela a assinatura
+func (s *RateSubscription) Close() {
+	s.broadcaster.unsubscribe(s)
+}
+
+// deliver tenta enfileirar o evento sem bloquear; false indica fila cheia
+func (s *RateSubscription) deliver(ev RateEvent) bool {
+	s.mu.Lock()
+	wanted := matches(s.filter, ev.Moeda)
+	s.mu.Unlock()
+	if !wanted {
+		return true
+	}
+
+	select {
+	case s.events <- ev:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *RateSubscription) finish(err error) {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+		close(s.done)
+	})
+}
@@ -0,0 +1,332 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// sequenceProvider devolve as cotações da fila de cada moeda, repetindo a última
+type sequenceProvider struct {
+	mu     sync.Mutex
+	rates  map[string][]float64
+	errors map[string]error
+}
+
+func (p *sequenceProvider) GetRate(moeda string) (float64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.errors[moeda]; err != nil {
+		return 0, err
+	}
+	queue := p.rates[moeda]
+	if len(queue) == 0 {
+		return 0, ErrCurrencyNotFound
+	}
+	rate := queue[0]
+	if len(queue) > 1 {
+		p.rates[moeda] = queue[1:]
+	}
+	return rate, nil
+}
+
+func TestRateBroadcaster(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should publish only when rate changes",
+			run:  shouldPublishOnlyWhenRateChanges,
+		},
+		{
+			name: "should send latest snapshot to new subscriber",
+			run:  shouldSendLatestSnapshotToNewSubscriber,
+		},
+		{
+			name: "should filter events by subscribed currencies",
+			run:  shouldFilterEventsBySubscribedCurrencies,
+		},
+		{
+			name: "should change currencies of active subscription",
+			run:  shouldChangeCurrenciesOfActiveSubscription,
+		},
+		{
+			name: "should replay missed events after last event id",
+			run:  shouldReplayMissedEventsAfterLastEventID,
+		},
+		{
+			name: "should fall back to snapshot when history no longer covers last event id",
+			run:  shouldFallBackToSnapshotWhenHistoryIsGone,
+		},
+		{
+			name: "should send snapshot when last event id is ahead of this instance",
+			run:  shouldSendSnapshotWhenLastEventIDIsAhead,
+		},
+		{
+			name: "should keep event ids growing across restarts",
+			run:  shouldKeepEventIDsGrowingAcrossRestarts,
+		},
+		{
+			name: "should drop slow subscriber without blocking others",
+			run:  shouldDropSlowSubscriberWithoutBlockingOthers,
+		},
+		{
+			name: "should reject currency not broadcast",
+			run:  shouldRejectCurrencyNotBroadcast,
+		},
+		{
+			name: "should poll provider and stop subscribers when context ends",
+			run:  shouldPollProviderAndStopSubscribersWhenContextEnds,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func newTestBroadcaster(historySize int) *RateBroadcaster {
+	b := newSeededBroadcaster(historySize)
+	// IDs a partir de 1 deixam as asserções legíveis
+	b.lastID = 0
+	return b
+}
+
+// newSeededBroadcaster mantém a semente de IDs do construtor
+func newSeededBroadcaster(historySize int) *RateBroadcaster {
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+	return NewRateBroadcaster(&sequenceProvider{}, []string{"usd", "EUR"}, time.Minute, historySize, loggerMock)
+}
+
+func quote(moeda string, cotacao float64) RateQuote {
+	return RateQuote{Moeda: moeda, Cotacao: cotacao, Fonte: "awesomeapi", Data: time.Now()}
+}
+
+// drain lê os eventos já enfileirados sem esperar por novos
+func drain(sub *RateSubscription) []RateEvent {
+	var out []RateEvent
+	for {
+		select {
+		case ev := <-sub.Events():
+			out = append(out, ev)
+		default:
+			return out
+		}
+	}
+}
+
+func ids(events []RateEvent) []uint64 {
+	out := make([]uint64, 0, len(events))
+	for _, ev := range events {
+		out = append(out, ev.ID)
+	}
+	return out
+}
+
+func shouldPublishOnlyWhenRateChanges(t *testing.T) {
+	b := newTestBroadcaster(10)
+	sub, err := b.Subscribe(nil, 0, 10)
+	require.NoError(t, err)
+
+	b.Publish(quote("USD", 5.0))
+	b.Publish(quote("USD", 5.0))
+	b.Publish(quote("USD", 5.1))
+
+	events := drain(sub)
+	assert.Equal(t, []uint64{1, 2}, ids(events))
+	assert.Equal(t, 5.1, events[1].Cotacao)
+	assert.Equal(t, "awesomeapi", events[1].Fonte)
+}
+
+func shouldSendLatestSnapshotToNewSubscriber(t *testing.T) {
+	b := newTestBroadcaster(10)
+	b.Publish(quote("USD", 5.0))
+	b.Publish(quote("EUR", 6.0))
+	b.Publish(quote("USD", 5.2))
+
+	sub, err := b.Subscribe(nil, 0, 10)
+	require.NoError(t, err)
+
+	events := drain(sub)
+	// Apenas a cotação atual de cada moeda, em ordem de ID
+	assert.Equal(t, []uint64{2, 3}, ids(events))
+	assert.Equal(t, "EUR", events[0].Moeda)
+	assert.Equal(t, 5.2, events[1].Cotacao)
+}
+
+func shouldFilterEventsBySubscribedCurrencies(t *testing.T) {
+	b := newTestBroadcaster(10)
+	sub, err := b.Subscribe([]string{"eur"}, 0, 10)
+	require.NoError(t, err)
+
+	b.Publish(quote("USD", 5.0))
+	b.Publish(quote("EUR", 6.0))
+
+	events := drain(sub)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "EUR", events[0].Moeda)
+}
+
+func shouldChangeCurrenciesOfActiveSubscription(t *testing.T) {
+	b := newTestBroadcaster(10)
+	sub, err := b.Subscribe([]string{"EUR"}, 0, 10)
+	require.NoError(t, err)
+
+	require.NoError(t, sub.SetCurrencies([]string{"USD"}))
+	b.Publish(quote("USD", 5.0))
+	b.Publish(quote("EUR", 6.0))
+
+	events := drain(sub)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "USD", events[0].Moeda)
+	assert.ErrorIs(t, sub.SetCurrencies([]string{"JPY"}), ErrCurrencyNotWatched)
+}
+
+func shouldReplayMissedEventsAfterLastEventID(t *testing.T) {
+	b := newTestBroadcaster(10)
+	b.Publish(quote("USD", 5.0))
+	b.Publish(quote("USD", 5.1))
+	b.Publish(quote("EUR", 6.0))
+	b.Publish(quote("USD", 5.2))
+
+	sub, err := b.Subscribe([]string{"USD"}, 1, 10)
+	require.NoError(t, err)
+
+	// Reenvia tudo o que veio depois do ID 1, inclusive cotações já superadas
+	assert.Equal(t, []uint64{2, 4}, ids(drain(sub)))
+}
+
+func shouldFallBackToSnapshotWhenHistoryIsGone(t *testing.T) {
+	b := newTestBroadcaster(2)
+	b.Publish(quote("USD", 5.0))
+	b.Publish(quote("EUR", 6.0))
+	b.Publish(quote("USD", 5.1))
+	b.Publish(quote("USD", 5.2))
+
+	sub, err := b.Subscribe(nil, 1, 10)
+	require.NoError(t, err)
+
+	// Os eventos 2 e 3 saíram do histórico: o cliente recebe o estado atual
+	assert.Equal(t, []uint64{2, 4}, ids(drain(sub)))
+}
+
+func shouldSendSnapshotWhenLastEventIDIsAhead(t *testing.T) {
+	b := newTestBroadcaster(10)
+	b.Publish(quote("USD", 5.0))
+	b.Publish(quote("EUR", 6.0))
+	b.Publish(quote("USD", 5.1))
+
+	// ID de antes de um reinício (ou de outra réplica) maior que o último emitido:
+	// sem o estado atual o cliente ficaria sem cotação até a próxima mudança
+	sub, err := b.Subscribe(nil, 5000, 10)
+	require.NoError(t, err)
+
+	assert.Equal(t, []uint64{2, 3}, ids(drain(sub)))
+}
+
+func shouldKeepEventIDsGrowingAcrossRestarts(t *testing.T) {
+	before := newSeededBroadcaster(10)
+	for i := 0; i < 100; i++ {
+		before.Publish(quote("USD", 5.0+float64(i)/100))
+	}
+	lastSeen := before.lastID
+
+	time.Sleep(time.Millisecond)
+	restarted := newSeededBroadcaster(10)
+	restarted.Publish(quote("USD", 6.0))
+
+	sub, err := restarted.Subscribe(nil, lastSeen, 10)
+	require.NoError(t, err)
+
+	// O ID anterior ao reinício continua válido: o cliente só recebe o que veio depois
+	events := drain(sub)
+	require.Len(t, events, 1)
+	assert.Greater(t, events[0].ID, lastSeen)
+	assert.Equal(t, 6.0, events[0].Cotacao)
+}
+
+func shouldDropSlowSubscriberWithoutBlockingOthers(t *testing.T) {
+	b := newTestBroadcaster(10)
+	slow, err := b.Subscribe(nil, 0, 1)
+	require.NoError(t, err)
+	fast, err := b.Subscribe(nil, 0, 10)
+	require.NoError(t, err)
+
+	b.Publish(quote("USD", 5.0))
+	b.Publish(quote("USD", 5.1))
+	b.Publish(quote("USD", 5.2))
+
+	select {
+	case <-slow.Done():
+	default:
+		t.Fatal("assinante lento deveria ter sido desconectado")
+	}
+	assert.ErrorIs(t, slow.Err(), ErrSlowConsumer)
+	assert.Equal(t, []uint64{1}, ids(drain(slow)))
+	assert.Equal(t, []uint64{1, 2, 3}, ids(drain(fast)))
+
+	// Retoma do último evento recebido sem perder nada
+	resumed, err := b.Subscribe(nil, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{2, 3}, ids(drain(resumed)))
+}
+
+func shouldRejectCurrencyNotBroadcast(t *testing.T) {
+	b := newTestBroadcaster(10)
+
+	sub, err := b.Subscribe([]string{"USD", "JPY"}, 0, 10)
+
+	assert.Nil(t, sub)
+	assert.ErrorIs(t, err, ErrCurrencyNotWatched)
+}
+
+func shouldPollProviderAndStopSubscribersWhenContextEnds(t *testing.T) {
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+	provider := &sequenceProvider{
+		rates:  map[string][]float64{"USD": {5.0, 5.0, 5.3}},
+		errors: map[string]error{"EUR": errors.New("timeout")},
+	}
+	b := NewRateBroadcaster(provider, []string{"USD", "EUR"}, 10*time.Millisecond, 10, loggerMock)
+	sub, err := b.Subscribe(nil, 0, 10)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		b.Run(ctx)
+		close(done)
+	}()
+
+	var got []float64
+	for len(got) < 2 {
+		select {
+		case ev := <-sub.Events():
+			got = append(got, ev.Cotacao)
+		case <-time.After(time.Second):
+			t.Fatal("cotações não foram publicadas")
+		}
+	}
+	cancel()
+	<-done
+
+	assert.Equal(t, []float64{5.0, 5.3}, got)
+	<-sub.Done()
+	assert.ErrorIs(t, sub.Err(), ErrBroadcasterStopped)
+	loggerMock.AssertCalled(t, "Warn", "Falha ao consultar cotação para transmissão", mock.Anything)
+
+	_, err = b.Subscribe(nil, 0, 10)
+	assert.ErrorIs(t, err, ErrBroadcasterStopped)
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go-frete/api/pkg/ratelimit"
+)
+
+var ErrUpstreamBudgetExhausted = errors.New("orçamento de chamadas à API de cotação esgotado")
+
+// RetryAfterError indica que a operação pode ser repetida depois do intervalo
+type RetryAfterError struct {
+	Err   error
+	After time.Duration
+}
+
+func (e *RetryAfterError) Error() string { return e.Err.Error() }
+func (e *RetryAfterError) Unwrap() error { return e.Err }
+
+// BudgetedRateProvider protege a cota do provedor externo com um orçamento
+// global compartilhado por todos os clientes
+type BudgetedRateProvider struct {
+	next   RateProvider
+	bucket *ratelimit.Bucket
+}
+
+func NewBudgetedRateProvider(next RateProvider, perMinute, burst int) *BudgetedRateProvider {
+	return &BudgetedRateProvider{
+		next:   next,
+		bucket: ratelimit.NewBucket(float64(perMinute)/60, burst),
+	}
+}
+
+func (p *BudgetedRateProvider) GetRate(moeda string) (float64, error) {
+	if err := p.take(); err != nil {
+		return 0, err
+	}
+	return p.next.GetRate(moeda)
+}
+
+// GetQuote consome o mesmo orçamento e repassa o horário da cotação, se o provedor decorado o informar
+func (p *BudgetedRateProvider) GetQuote(moeda string) (RateQuote, error) {
+	if err := p.take(); err != nil {
+		return RateQuote{}, err
+	}
+	return quoteOf(p.next, moeda)
+}
+
+// GetDailyRates consome o mesmo orçamento para buscar a série diária, se o
+// provedor decorado a oferecer
+func (p *BudgetedRateProvider) GetDailyRates(moeda string, from, to time.Time) ([]RateSnapshot, error) {
+	h, ok := p.next.(HistoricalRateProvider)
+	if !ok {
+		return nil, ErrRateNotAvailable
+	}
+	if err := p.take(); err != nil {
+		return nil, err
+	}
+	return h.GetDailyRates(moeda, from, to)
+}
+
+func (p *BudgetedRateProvider) take() error {
+	res := p.bucket.Take(time.Now())
+	if !res.Allowed {
+		return &RetryAfterError{Err: ErrUpstreamBudgetExhausted, After: res.RetryAfter}
+	}
+	return nil
+}
+
+// Source repassa a identificação do provedor decorado
+func (p *BudgetedRateProvider) Source() string {
+	return sourceOf(p.next)
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type rateProviderStub struct {
+	calls int
+}
+
+func (s *rateProviderStub) GetRate(moeda string) (float64, error) {
+	s.calls++
+	return 5.0, nil
+}
+
+func TestBudgetedRateProvider(t *testing.T) {
+	stub := &rateProviderStub{}
+	provider := NewBudgetedRateProvider(stub, 1, 2)
+
+	_, err1 := provider.GetRate("USD")
+	_, err2 := provider.GetRate("USD")
+	_, err3 := provider.GetRate("USD")
+
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.ErrorIs(t, err3, ErrUpstreamBudgetExhausted)
+
+	var ra *RetryAfterError
+	assert.True(t, errors.As(err3, &ra))
+	assert.Greater(t, ra.After, time.Duration(0))
+	assert.Equal(t, 2, stub.calls)
+}
+
+// dailyRateProviderStub é o provedor com cotação atual e série diária
+type dailyRateProviderStub struct {
+	rateProviderStub
+	historicalProviderFake
+}
+
+func TestBudgetedRateProvider_GetDailyRates(t *testing.T) {
+	stub := &dailyRateProviderStub{}
+	provider := NewBudgetedRateProvider(stub, 1, 2)
+	day := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	_, err1 := provider.GetRate("USD")
+	rates, err2 := provider.GetDailyRates("USD", day, day)
+	_, err3 := provider.GetDailyRates("USD", day, day)
+
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Len(t, rates, 1)
+	// A série diária gasta o mesmo orçamento da cotação atual
+	assert.ErrorIs(t, err3, ErrUpstreamBudgetExhausted)
+	assert.Len(t, stub.historicalProviderFake.calls, 1)
+
+	_, err := NewBudgetedRateProvider(&rateProviderStub{}, 1, 2).GetDailyRates("USD", day, day)
+	assert.ErrorIs(t, err, ErrRateNotAvailable)
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go-frete/api/pkg/logger"
+)
+
+var ErrSaveSnapshots = errors.New("falha ao salvar cotações coletadas")
+
+// RateSnapshot é a cotação de uma moeda num instante, coletada independente das conversões
+type RateSnapshot struct {
+	Moeda   string    `bson:"moeda" json:"moeda"`
+	Cotacao float64   `bson:"cotacao" json:"cotacao"`
+	Fonte   string    `bson:"fonte,omitempty" json:"fonte,omitempty"`
+	Data    time.Time `bson:"data" json:"data"`
+}
+
+type RateSnapshotSaver interface {
+	SaveSnapshots(snapshots []RateSnapshot) error
+}
+
+// RateCollector grava em intervalos fixos a cotação das moedas configuradas,
+// formando uma série regular para variação e estatísticas
+type RateCollector struct {
+	provider   RateProvider
+	repo       RateSnapshotSaver
+	currencies []string
+	interval   time.Duration
+	log        logger.Logger
+}
+
+func NewRateCollector(p RateProvider, r RateSnapshotSaver, currencies []string, interval time.Duration, l logger.Logger) *RateCollector {
+	normalized := make([]string, 0, len(currencies))
+	for _, c := range currencies {
+		normalized = append(normalized, strings.ToUpper(c))
+	}
+	return &RateCollector{provider: p, repo: r, currencies: normalized, interval: interval, log: l}
+}
+
+// Run coleta na subida e depois a cada intervalo, até o contexto ser cancelado
+func (c *RateCollector) Run(ctx context.Context) {
+	c.log.Info("Coleta de cotações iniciada", "moedas", c.currencies, "intervalo", c.interval.String())
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		// Falhas já foram registradas; a próxima rodada tenta de novo
+		c.Collect(ctx)
+
+		select {
+		case <-ctx.Done():
+			c.log.Info("Coleta de cotações encerrada")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Collect consulta todas as moedas e grava as cotações obtidas num único lote.
+// Moedas que falharem ficam de fora desta rodada sem impedir as demais.
+func (c *RateCollector) Collect(ctx context.Context) (int, error) {
+	log := logger.FromContext(ctx, c.log)
+
+	source := sourceOf(c.provider)
+	// Mesmo instante para todo o lote: facilita comparar moedas na mesma rodada
+	now := time.Now().UTC().Truncate(time.Second)
+
+	snapshots := make([]RateSnapshot, 0, len(c.currencies))
+	for _, moeda := range c.currencies {
+		cotacao, err := c.provider.GetRate(moeda)
+		if err != nil {
+			log.Warn("Falha ao coletar cotação", "moeda", moeda, "erro", err.Error())
+			continue
+		}
+		if cotacao == 0 {
+			log.Warn("Cotação zerada ignorada na coleta", "moeda", moeda)
+			continue
+		}
+		snapshots = append(snapshots, RateSnapshot{Moeda: moeda, Cotacao: cotacao, Fonte: source, Data: now})
+	}
+
+	if len(snapshots) == 0 {
+		return 0, nil
+	}
+	if err := c.repo.SaveSnapshots(snapshots); err != nil {
+		log.Error("Falha ao salvar cotações coletadas", "erro", err.Error())
+		return 0, ErrSaveSnapshots
+	}
+
+	log.Info("Cotações coletadas", "total", len(snapshots))
+	return len(snapshots), nil
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type snapshotSaverMock struct {
+	mock.Mock
+}
+
+func (m *snapshotSaverMock) SaveSnapshots(snapshots []RateSnapshot) error {
+	args := m.Called(snapshots)
+	return args.Error(0)
+}
+
+func TestRateCollector_Collect(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should save one batch with every collected currency",
+			run:  shouldSaveOneBatchWithEveryCollectedCurrency,
+		},
+		{
+			name: "should skip currency when provider fails",
+			run:  shouldSkipCurrencyWhenProviderFails,
+		},
+		{
+			name: "should not save when nothing was collected",
+			run:  shouldNotSaveWhenNothingWasCollected,
+		},
+		{
+			name: "should return error when saving snapshots fails",
+			run:  shouldReturnErrorWhenSavingSnapshotsFails,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldSaveOneBatchWithEveryCollectedCurrency(t *testing.T) {
+	providerMock := new(namedProviderStub)
+	repoMock := new(snapshotSaverMock)
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+
+	providerMock.On("GetRate", "USD").Return(5.0, nil)
+	providerMock.On("GetRate", "EUR").Return(6.0, nil)
+	repoMock.On("SaveSnapshots", mock.MatchedBy(func(s []RateSnapshot) bool {
+		return len(s) == 2 &&
+			s[0].Moeda == "USD" && s[0].Cotacao == 5.0 && s[0].Fonte == "awesomeapi" &&
+			s[1].Moeda == "EUR" && s[1].Cotacao == 6.0 &&
+			s[0].Data.Equal(s[1].Data) && !s[0].Data.IsZero()
+	})).Return(nil)
+
+	collector := NewRateCollector(providerMock, repoMock, []string{"usd", "EUR"}, 0, loggerMock)
+	total, err := collector.Collect(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	repoMock.AssertExpectations(t)
+}
+
+func shouldSkipCurrencyWhenProviderFails(t *testing.T) {
+	providerMock := new(rateProviderMock)
+	repoMock := new(snapshotSaverMock)
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+
+	providerMock.On("GetRate", "USD").Return(0.0, errors.New("timeout"))
+	providerMock.On("GetRate", "EUR").Return(6.0, nil)
+	repoMock.On("SaveSnapshots", mock.MatchedBy(func(s []RateSnapshot) bool {
+		return len(s) == 1 && s[0].Moeda == "EUR"
+	})).Return(nil)
+
+	collector := NewRateCollector(providerMock, repoMock, []string{"USD", "EUR"}, 0, loggerMock)
+	total, err := collector.Collect(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	loggerMock.AssertCalled(t, "Warn", "Falha ao coletar cotação", mock.Anything)
+}
+
+func shouldNotSaveWhenNothingWasCollected(t *testing.T) {
+	providerMock := new(rateProviderMock)
+	repoMock := new(snapshotSaverMock)
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+
+	providerMock.On("GetRate", "USD").Return(0.0, nil)
+
+	collector := NewRateCollector(providerMock, repoMock, []string{"USD"}, 0, loggerMock)
+	total, err := collector.Collect(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total)
+	repoMock.AssertNotCalled(t, "SaveSnapshots", mock.Anything)
+}
+
+func shouldReturnErrorWhenSavingSnapshotsFails(t *testing.T) {
+	providerMock := new(rateProviderMock)
+	repoMock := new(snapshotSaverMock)
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Error", mock.Anything, mock.Anything).Return()
+
+	providerMock.On("GetRate", "USD").Return(5.0, nil)
+	repoMock.On("SaveSnapshots", mock.Anything).Return(errors.New("disk full"))
+
+	collector := NewRateCollector(providerMock, repoMock, []string{"USD"}, 0, loggerMock)
+	_, err := collector.Collect(context.Background())
+
+	assert.ErrorIs(t, err, ErrSaveSnapshots)
+}
@@ -0,0 +1,235 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"go-frete/api/pkg/logger"
+)
+
+var (
+	ErrRateOutlier  = errors.New("cotação recusada: variação acima do limite em relação à última aceita")
+	ErrStaleRate    = errors.New("cotação recusada: horário da cotação na origem é antigo demais")
+	ErrRateMismatch = errors.New("cotação recusada: diverge do provedor de conferência")
+)
+
+// RateGuardConfig define os limites da validação; valores zero desligam a regra
+type RateGuardConfig struct {
+	// Variação máxima, em %, em relação à última cotação aceita da moeda
+	MaxDeviationPercent float64
+	// Até quanto tempo a última cotação aceita serve de referência; depois
+	// dele o mercado pode ter andado de verdade e a comparação é pulada.
+	// Zero também desliga a comparação com a última aceita
+	BaselineWindow time.Duration
+	// Idade máxima da cotação na origem (só para provedores que informam o horário)
+	MaxAge time.Duration
+	// Diferença máxima, em %, para o provedor de conferência
+	CrossCheckPercent float64
+}
+
+// QuarantinedRate é uma cotação recusada, guardada para análise
+type QuarantinedRate struct {
+	Moeda   string    `bson:"moeda" json:"moeda"`
+	Cotacao float64   `bson:"cotacao" json:"cotacao"`
+	Fonte   string    `bson:"fonte,omitempty" json:"fonte,omitempty"`
+	Data    time.Time `bson:"data,omitempty" json:"data,omitempty"`
+	Motivo  string    `bson:"motivo" json:"motivo"`
+	// Valor com que foi comparada: a última aceita ou a do provedor de conferência
+	Referencia float64   `bson:"referencia,omitempty" json:"referencia,omitempty"`
+	RecebidaEm time.Time `bson:"recebida_em" json:"recebida_em"`
+}
+
+type RateQuarantine interface {
+	QuarantineRate(rate QuarantinedRate) error
+}
+
+type acceptedRate struct {
+	cotacao float64
+	at      time.Time
+}
+
+// RateGuard valida as cotações do provedor antes que sejam usadas em
+// conversões, streaming ou na série coletada
+type RateGuard struct {
+	next       RateProvider
+	crossCheck RateProvider
+	quarantine RateQuarantine
+	cfg        RateGuardConfig
+	log        logger.Logger
+	now        func() time.Time
+
+	mu       sync.Mutex
+	accepted map[string]acceptedRate
+	// Última cotação recusada por variação, de cada moeda
+	suspects map[string]acceptedRate
+}
+
+func NewRateGuard(next RateProvider, cfg RateGuardConfig, l logger.Logger) *RateGuard {
+	return &RateGuard{
+		next:     next,
+		cfg:      cfg,
+		log:      l,
+		now:      time.Now,
+		accepted: map[string]acceptedRate{},
+		suspects: map[string]acceptedRate{},
+	}
+}
+
+// WithCrossCheck confere cada cotação com um segundo provedor
+func (g *RateGuard) WithCrossCheck(p RateProvider) *RateGuard {
+	g.crossCheck = p
+	return g
+}
+
+// WithQuarantine guarda as cotações recusadas para análise
+func (g *RateGuard) WithQuarantine(q RateQuarantine) *RateGuard {
+	g.quarantine = q
+	return g
+}
+
+func (g *RateGuard) GetRate(moeda string) (float64, error) {
+	q, err := g.GetQuote(moeda)
+	return q.Cotacao, err
+}
+
+// Source repassa a identificação do provedor validado
+func (g *RateGuard) Source() string {
+	return sourceOf(g.next)
+}
+
+// GetQuote busca a cotação e a recusa se estiver zerada, antiga, fora da
+// variação aceita ou divergente do provedor de conferência
+func (g *RateGuard) GetQuote(moeda string) (RateQuote, error) {
+	q, err := quoteOf(g.next, moeda)
+	if err != nil {
+		return RateQuote{}, err
+	}
+	key := strings.ToUpper(moeda)
+	now := g.now()
+
+	if q.Cotacao <= 0 {
+		return RateQuote{}, g.reject(q, ErrZeroRate, 0)
+	}
+	if g.cfg.MaxAge > 0 && !q.Data.IsZero() && now.Sub(q.Data) > g.cfg.MaxAge {
+		return RateQuote{}, g.reject(q, ErrStaleRate, 0)
+	}
+
+	g.mu.Lock()
+	last, ok := g.accepted[key]
+	suspect, suspected := g.suspects[key]
+	g.mu.Unlock()
+	outlier := ok && g.isOutlier(q.Cotacao, now, last, suspect, suspected)
+
+	if g.crossCheck != nil && g.cfg.CrossCheckPercent > 0 {
+		reference, err := g.crossCheck.GetRate(moeda)
+		switch {
+		case err != nil:
+			// Conferência indisponível não derruba o provedor principal
+			g.log.Warn("Falha ao conferir cotação no segundo provedor", "moeda", key, "erro", err.Error())
+		case deviationPercent(q.Cotacao, reference) > g.cfg.CrossCheckPercent:
+			return RateQuote{}, g.reject(q, ErrRateMismatch, reference)
+		default:
+			// O segundo provedor confirma o salto: o mercado andou de verdade
+			outlier = false
+		}
+	}
+	if outlier {
+		g.mu.Lock()
+		g.suspects[key] = acceptedRate{cotacao: q.Cotacao, at: now}
+		g.mu.Unlock()
+		return RateQuote{}, g.reject(q, ErrRateOutlier, last.cotacao)
+	}
+
+	g.mu.Lock()
+	g.accepted[key] = acceptedRate{cotacao: q.Cotacao, at: now}
+	delete(g.suspects, key)
+	g.mu.Unlock()
+	return q, nil
+}
+
+// isOutlier diz se value foge da última cotação aceita, que só vale como
+// referência dentro de BaselineWindow. Duas cotações seguidas fora da faixa,
+// mas próximas entre si, confirmam um novo patamar: o mercado andou de
+// verdade, ou a referência é que estava errada. Assim nem um salto real nem
+// uma primeira cotação ruim travam a moeda até a janela vencer.
+func (g *RateGuard) isOutlier(value float64, at time.Time, last, suspect acceptedRate, suspected bool) bool {
+	if g.cfg.MaxDeviationPercent <= 0 || g.cfg.BaselineWindow <= 0 ||
+		at.Sub(last.at) > g.cfg.BaselineWindow ||
+		deviationPercent(value, last.cotacao) <= g.cfg.MaxDeviationPercent {
+		return false
+	}
+	confirmed := suspected && at.Sub(suspect.at) <= g.cfg.BaselineWindow &&
+		deviationPercent(value, suspect.cotacao) <= g.cfg.MaxDeviationPercent
+	return !confirmed
+}
+
+// GetDailyRates busca a série diária do provedor validado e tira dela, para a
+// quarentena, os fechamentos zerados e os que saltam mais que o limite em
+// relação ao fechamento aceito anterior dentro da janela de referência, com a
+// mesma confirmação de novo patamar de GetQuote. Idade
+// e conferência não se aplicam: a série é do passado e o segundo provedor só
+// tem a cotação atual.
+func (g *RateGuard) GetDailyRates(moeda string, from, to time.Time) ([]RateSnapshot, error) {
+	h, ok := g.next.(HistoricalRateProvider)
+	if !ok {
+		return nil, ErrRateNotAvailable
+	}
+	rates, err := h.GetDailyRates(moeda, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	accepted := make([]RateSnapshot, 0, len(rates))
+	var last, suspect acceptedRate
+	var suspected bool
+	for _, s := range rates {
+		q := RateQuote{Moeda: s.Moeda, Cotacao: s.Cotacao, Fonte: s.Fonte, Data: s.Data}
+		if s.Cotacao <= 0 {
+			g.reject(q, ErrZeroRate, 0)
+			continue
+		}
+		if len(accepted) > 0 && g.isOutlier(s.Cotacao, s.Data, last, suspect, suspected) {
+			suspect, suspected = acceptedRate{cotacao: s.Cotacao, at: s.Data}, true
+			g.reject(q, ErrRateOutlier, last.cotacao)
+			continue
+		}
+		accepted = append(accepted, s)
+		last, suspected = acceptedRate{cotacao: s.Cotacao, at: s.Data}, false
+	}
+	return accepted, nil
+}
+
+func (g *RateGuard) reject(q RateQuote, reason error, reference float64) error {
+	g.log.Warn("Cotação recusada pela validação",
+		"moeda", q.Moeda,
+		"cotacao", q.Cotacao,
+		"referencia", reference,
+		"motivo", reason.Error(),
+	)
+	if g.quarantine != nil {
+		err := g.quarantine.QuarantineRate(QuarantinedRate{
+			Moeda:      strings.ToUpper(q.Moeda),
+			Cotacao:    q.Cotacao,
+			Fonte:      q.Fonte,
+			Data:       q.Data,
+			Motivo:     reason.Error(),
+			Referencia: reference,
+			RecebidaEm: g.now(),
+		})
+		if err != nil {
+			g.log.Error("Falha ao guardar cotação recusada", "moeda", q.Moeda, "erro", err.Error())
+		}
+	}
+	if reference != 0 {
+		return fmt.Errorf("%w (recebida %.4f, referência %.4f)", reason, q.Cotacao, reference)
+	}
+	return reason
+}
+
+func deviationPercent(value, reference float64) float64 {
+	return math.Abs(value-reference) / reference * 100
+}
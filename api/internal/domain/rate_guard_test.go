@@ -0,0 +1,343 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// quoteProviderFake devolve as cotações na ordem em que foram enfileiradas
+type quoteProviderFake struct {
+	quotes []RateQuote
+	err    error
+}
+
+func (f *quoteProviderFake) GetRate(moeda string) (float64, error) {
+	q, err := f.GetQuote(moeda)
+	return q.Cotacao, err
+}
+
+func (f *quoteProviderFake) GetQuote(moeda string) (RateQuote, error) {
+	if f.err != nil {
+		return RateQuote{}, f.err
+	}
+	q := f.quotes[0]
+	if len(f.quotes) > 1 {
+		f.quotes = f.quotes[1:]
+	}
+	return q, nil
+}
+
+type quarantineFake struct {
+	rates []QuarantinedRate
+}
+
+func (f *quarantineFake) QuarantineRate(rate QuarantinedRate) error {
+	f.rates = append(f.rates, rate)
+	return nil
+}
+
+var guardNow = time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+
+var guardConfig = RateGuardConfig{
+	MaxDeviationPercent: 5,
+	BaselineWindow:      time.Hour,
+	MaxAge:              2 * time.Hour,
+	CrossCheckPercent:   3,
+}
+
+func newGuardForTest(provider RateProvider) (*RateGuard, *quarantineFake, *loggermock.LoggerMock) {
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+	quarantine := &quarantineFake{}
+	g := NewRateGuard(provider, guardConfig, loggerMock).WithQuarantine(quarantine)
+	g.now = func() time.Time { return guardNow }
+	return g, quarantine, loggerMock
+}
+
+func usdQuote(cotacao float64) RateQuote {
+	return RateQuote{Moeda: "USD", Cotacao: cotacao, Fonte: "awesomeapi", Data: guardNow.Add(-time.Minute)}
+}
+
+func TestRateGuard_GetQuote(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should accept rates within the deviation limit",
+			run:  shouldAcceptRatesWithinTheDeviationLimit,
+		},
+		{
+			name: "should reject and quarantine outlier",
+			run:  shouldRejectAndQuarantineOutlier,
+		},
+		{
+			name: "should accept jump after baseline window",
+			run:  shouldAcceptJumpAfterBaselineWindow,
+		},
+		{
+			name: "should recover after real market move",
+			run:  shouldRecoverAfterRealMarketMove,
+		},
+		{
+			name: "should replace bad first baseline",
+			run:  shouldReplaceBadFirstBaseline,
+		},
+		{
+			name: "should skip deviation check when baseline window is zero",
+			run:  shouldSkipDeviationCheckWhenBaselineWindowIsZero,
+		},
+		{
+			name: "should reject stale rate",
+			run:  shouldRejectStaleRate,
+		},
+		{
+			name: "should reject zero rate",
+			run:  shouldRejectZeroRate,
+		},
+		{
+			name: "should reject rate that diverges from cross check",
+			run:  shouldRejectRateThatDivergesFromCrossCheck,
+		},
+		{
+			name: "should accept jump confirmed by cross check",
+			run:  shouldAcceptJumpConfirmedByCrossCheck,
+		},
+		{
+			name: "should tolerate cross check failure",
+			run:  shouldTolerateCrossCheckFailure,
+		},
+		{
+			name: "should return provider error without quarantine",
+			run:  shouldReturnProviderErrorWithoutQuarantine,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldAcceptRatesWithinTheDeviationLimit(t *testing.T) {
+	provider := &quoteProviderFake{quotes: []RateQuote{usdQuote(5.00), usdQuote(5.20)}}
+	g, quarantine, _ := newGuardForTest(provider)
+
+	first, err1 := g.GetQuote("USD")
+	second, err2 := g.GetQuote("USD")
+
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, 5.00, first.Cotacao)
+	assert.Equal(t, 5.20, second.Cotacao)
+	assert.Empty(t, quarantine.rates)
+}
+
+func shouldRejectAndQuarantineOutlier(t *testing.T) {
+	provider := &quoteProviderFake{quotes: []RateQuote{usdQuote(5.00), usdQuote(50.00), usdQuote(5.10)}}
+	g, quarantine, loggerMock := newGuardForTest(provider)
+
+	_, _ = g.GetQuote("USD")
+	_, err := g.GetQuote("USD")
+	next, errNext := g.GetQuote("usd")
+
+	assert.ErrorIs(t, err, ErrRateOutlier)
+	assert.Contains(t, err.Error(), "50.0000")
+	// A recusada não vira referência: a próxima é comparada com 5.00
+	assert.NoError(t, errNext)
+	assert.Equal(t, 5.10, next.Cotacao)
+	if assert.Len(t, quarantine.rates, 1) {
+		assert.Equal(t, "USD", quarantine.rates[0].Moeda)
+		assert.Equal(t, 50.00, quarantine.rates[0].Cotacao)
+		assert.Equal(t, 5.00, quarantine.rates[0].Referencia)
+		assert.Equal(t, ErrRateOutlier.Error(), quarantine.rates[0].Motivo)
+		assert.Equal(t, guardNow, quarantine.rates[0].RecebidaEm)
+	}
+	loggerMock.AssertCalled(t, "Warn", "Cotação recusada pela validação", mock.Anything)
+}
+
+func shouldAcceptJumpAfterBaselineWindow(t *testing.T) {
+	provider := &quoteProviderFake{quotes: []RateQuote{usdQuote(5.00), {Moeda: "USD", Cotacao: 6.00}}}
+	g, quarantine, _ := newGuardForTest(provider)
+
+	_, _ = g.GetQuote("USD")
+	guardLater := guardNow.Add(2 * time.Hour)
+	g.now = func() time.Time { return guardLater }
+	q, err := g.GetQuote("USD")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 6.00, q.Cotacao)
+	assert.Empty(t, quarantine.rates)
+}
+
+// guardQuotes consulta a guarda uma vez por cotação enfileirada e devolve os erros
+func guardQuotes(g *RateGuard, n int) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		_, errs[i] = g.GetQuote("USD")
+	}
+	return errs
+}
+
+func shouldRecoverAfterRealMarketMove(t *testing.T) {
+	// Sem conferência: o salto de 5.00 para 6.00 é real e o mercado fica lá
+	provider := &quoteProviderFake{quotes: []RateQuote{usdQuote(5.00), usdQuote(6.00), usdQuote(6.05), usdQuote(6.10)}}
+	g, quarantine, _ := newGuardForTest(provider)
+
+	errs := guardQuotes(g, 4)
+
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], ErrRateOutlier)
+	// A segunda cotação no novo patamar confirma a primeira e vira referência
+	assert.NoError(t, errs[2])
+	assert.NoError(t, errs[3])
+	assert.Len(t, quarantine.rates, 1)
+}
+
+func shouldReplaceBadFirstBaseline(t *testing.T) {
+	// A primeira cotação depois da subida não tem com o que ser comparada
+	provider := &quoteProviderFake{quotes: []RateQuote{usdQuote(50.00), usdQuote(5.00), usdQuote(5.01), usdQuote(50.00)}}
+	g, _, _ := newGuardForTest(provider)
+
+	errs := guardQuotes(g, 4)
+
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], ErrRateOutlier)
+	assert.NoError(t, errs[2])
+	// A recusa anterior não confirma um salto depois de uma cotação aceita
+	assert.ErrorIs(t, errs[3], ErrRateOutlier)
+}
+
+func shouldSkipDeviationCheckWhenBaselineWindowIsZero(t *testing.T) {
+	provider := &quoteProviderFake{quotes: []RateQuote{usdQuote(5.00), usdQuote(50.00)}}
+	loggerMock := new(loggermock.LoggerMock)
+	cfg := guardConfig
+	cfg.BaselineWindow = 0
+	g := NewRateGuard(provider, cfg, loggerMock)
+	g.now = func() time.Time { return guardNow }
+
+	errs := guardQuotes(g, 2)
+
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+}
+
+func shouldRejectStaleRate(t *testing.T) {
+	stale := usdQuote(5.00)
+	stale.Data = guardNow.Add(-3 * time.Hour)
+	g, quarantine, _ := newGuardForTest(&quoteProviderFake{quotes: []RateQuote{stale}})
+
+	_, err := g.GetQuote("USD")
+
+	assert.ErrorIs(t, err, ErrStaleRate)
+	assert.Len(t, quarantine.rates, 1)
+}
+
+func shouldRejectZeroRate(t *testing.T) {
+	g, quarantine, _ := newGuardForTest(&quoteProviderFake{quotes: []RateQuote{usdQuote(0)}})
+
+	_, err := g.GetQuote("USD")
+
+	assert.ErrorIs(t, err, ErrZeroRate)
+	assert.Len(t, quarantine.rates, 1)
+}
+
+func shouldRejectRateThatDivergesFromCrossCheck(t *testing.T) {
+	g, quarantine, _ := newGuardForTest(&quoteProviderFake{quotes: []RateQuote{usdQuote(5.50)}})
+	g.WithCrossCheck(&quoteProviderFake{quotes: []RateQuote{{Cotacao: 5.00}}})
+
+	_, err := g.GetQuote("USD")
+
+	assert.ErrorIs(t, err, ErrRateMismatch)
+	if assert.Len(t, quarantine.rates, 1) {
+		assert.Equal(t, 5.00, quarantine.rates[0].Referencia)
+	}
+}
+
+func shouldAcceptJumpConfirmedByCrossCheck(t *testing.T) {
+	g, quarantine, _ := newGuardForTest(&quoteProviderFake{quotes: []RateQuote{usdQuote(5.00), usdQuote(5.60)}})
+	g.WithCrossCheck(&quoteProviderFake{quotes: []RateQuote{{Cotacao: 5.00}, {Cotacao: 5.58}}})
+
+	_, _ = g.GetQuote("USD")
+	q, err := g.GetQuote("USD")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5.60, q.Cotacao)
+	assert.Empty(t, quarantine.rates)
+}
+
+func shouldTolerateCrossCheckFailure(t *testing.T) {
+	g, quarantine, loggerMock := newGuardForTest(&quoteProviderFake{quotes: []RateQuote{usdQuote(5.00)}})
+	g.WithCrossCheck(&quoteProviderFake{err: errors.New("timeout")})
+
+	q, err := g.GetQuote("USD")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5.00, q.Cotacao)
+	assert.Empty(t, quarantine.rates)
+	loggerMock.AssertCalled(t, "Warn", "Falha ao conferir cotação no segundo provedor", mock.Anything)
+}
+
+func shouldReturnProviderErrorWithoutQuarantine(t *testing.T) {
+	g, quarantine, _ := newGuardForTest(&quoteProviderFake{err: ErrCurrencyNotFound})
+
+	_, err := g.GetRate("XYZ")
+
+	assert.ErrorIs(t, err, ErrCurrencyNotFound)
+	assert.Empty(t, quarantine.rates)
+}
+
+// dailySeriesProviderFake devolve sempre a mesma série diária
+type dailySeriesProviderFake struct {
+	quoteProviderFake
+	series []RateSnapshot
+}
+
+func (f *dailySeriesProviderFake) GetDailyRates(moeda string, from, to time.Time) ([]RateSnapshot, error) {
+	return f.series, f.err
+}
+
+func TestRateGuard_GetDailyRates(t *testing.T) {
+	day := time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC)
+	provider := &dailySeriesProviderFake{series: []RateSnapshot{
+		{Moeda: "USD", Cotacao: 5.00, Data: day},
+		{Moeda: "USD", Cotacao: 0, Data: day.AddDate(0, 0, 1)},
+		// Salto de um dia para o outro: fora da janela de referência, vale
+		{Moeda: "USD", Cotacao: 5.60, Data: day.AddDate(0, 0, 2)},
+		// Mesmo salto meia hora depois: dentro da janela, vai para a quarentena
+		{Moeda: "USD", Cotacao: 6.30, Data: day.AddDate(0, 0, 2).Add(30 * time.Minute)},
+	}}
+	g, quarantine, _ := newGuardForTest(provider)
+
+	rates, err := g.GetDailyRates("USD", day, day.AddDate(0, 0, 2))
+
+	assert.NoError(t, err)
+	require.Len(t, rates, 2)
+	assert.Equal(t, []float64{5.00, 5.60}, []float64{rates[0].Cotacao, rates[1].Cotacao})
+	assert.Len(t, quarantine.rates, 2)
+	assert.Equal(t, ErrZeroRate.Error(), quarantine.rates[0].Motivo)
+	assert.Equal(t, ErrRateOutlier.Error(), quarantine.rates[1].Motivo)
+	assert.Equal(t, 5.60, quarantine.rates[1].Referencia)
+
+	provider.err = errors.New("timeout")
+	_, err = g.GetDailyRates("USD", day, day)
+	assert.EqualError(t, err, "timeout")
+
+	// Dois fechamentos seguidos no novo patamar confirmam o salto
+	provider.err = nil
+	provider.series = []RateSnapshot{
+		{Moeda: "USD", Cotacao: 5.00, Data: day},
+		{Moeda: "USD", Cotacao: 6.00, Data: day.Add(10 * time.Minute)},
+		{Moeda: "USD", Cotacao: 6.02, Data: day.Add(20 * time.Minute)},
+	}
+	rates, err = g.GetDailyRates("USD", day, day)
+	assert.NoError(t, err)
+	require.Len(t, rates, 2)
+	assert.Equal(t, 6.02, rates[1].Cotacao)
+}
@@ -0,0 +1,238 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"go-frete/api/pkg/logger"
+)
+
+var ErrEmptyArchive = errors.New("arquivo sem conversões")
+
+// ConversionDailyAggregate resume as conversões de uma moeda num dia (UTC)
+type ConversionDailyAggregate struct {
+	Dia             time.Time `bson:"dia" json:"dia"`
+	Moeda           string    `bson:"moeda" json:"moeda"`
+	Conversoes      int       `bson:"conversoes" json:"conversoes"`
+	ValorEntrada    float64   `bson:"valor_entrada" json:"valor_entrada"`
+	ValorConvertido float64   `bson:"valor_convertido" json:"valor_convertido"`
+	CotacaoMinima   float64   `bson:"cotacao_minima" json:"cotacao_minima"`
+	CotacaoMaxima   float64   `bson:"cotacao_maxima" json:"cotacao_maxima"`
+	CotacaoMedia    float64   `bson:"cotacao_media" json:"cotacao_media"`
+	// Arquivado indica que o dia veio do resumo diário, não das conversões no banco
+	Arquivado bool `bson:"-" json:"arquivado"`
+}
+
+// RetentionPolicy define o que sai do histórico e quando
+type RetentionPolicy struct {
+	// Conversões de dias mais antigos que isso são resumidas e arquivadas
+	MaxAge time.Duration
+	// Depois de arquivadas, ficam no banco por mais esse tempo (zero = não apaga)
+	DeleteAfter time.Duration
+}
+
+// ConversionRetentionRepository é o que a retenção precisa do banco
+type ConversionRetentionRepository interface {
+	// FirstConversionSince devolve o horário da conversão mais antiga a partir de t (nil se não houver)
+	FirstConversionSince(t time.Time) (*time.Time, error)
+	ConversionsBetween(from, to time.Time) ([]ConversionRecord, error)
+	SaveDailyAggregates(aggregates []ConversionDailyAggregate) error
+	// MarkArchived marca as conversões do período como arquivadas; com expireAt,
+	// o banco as apaga nesse instante (índice TTL)
+	MarkArchived(from, to, archivedAt time.Time, expireAt *time.Time) error
+	// ReplaceArchivedConversions troca as conversões arquivadas do período pelas informadas
+	ReplaceArchivedConversions(from, to time.Time, records []ConversionRecord) error
+	RetentionWatermark() (time.Time, error)
+	SaveRetentionWatermark(t time.Time) error
+}
+
+// ConversionArchive guarda as conversões de cada dia fora do banco
+type ConversionArchive interface {
+	WriteDay(day time.Time, records []ConversionRecord) (string, error)
+	ReadArchive(path string) ([]ConversionRecord, error)
+}
+
+// RetentionResult resume uma rodada de arquivamento
+type RetentionResult struct {
+	Dias      int
+	Registros int
+	Arquivos  []string
+}
+
+// RetentionUseCase arquiva, dia a dia, as conversões mais antigas que a
+// política: grava o arquivo do dia, o resumo diário e marca as conversões
+// como arquivadas. A marca d'água guarda o primeiro dia ainda não arquivado.
+type RetentionUseCase struct {
+	repo    ConversionRetentionRepository
+	archive ConversionArchive
+	policy  RetentionPolicy
+	log     logger.Logger
+	now     func() time.Time
+}
+
+func NewRetentionUseCase(r ConversionRetentionRepository, a ConversionArchive, p RetentionPolicy, l logger.Logger) *RetentionUseCase {
+	return &RetentionUseCase{repo: r, archive: a, policy: p, log: l, now: time.Now}
+}
+
+// Run arquiva na subida e depois a cada intervalo, até o contexto ser cancelado
+func (uc *RetentionUseCase) Run(ctx context.Context, interval time.Duration) {
+	uc.log.Info("Retenção do histórico iniciada", "idade_maxima", uc.policy.MaxAge.String(), "intervalo", interval.String())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Falhas já foram registradas; a próxima rodada continua do mesmo dia
+		uc.Archive(ctx)
+
+		select {
+		case <-ctx.Done():
+			uc.log.Info("Retenção do histórico encerrada")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Archive arquiva todos os dias completos anteriores ao corte da política.
+// Cada passo pode ser repetido: se a rodada cair no meio, o dia é refeito.
+func (uc *RetentionUseCase) Archive(ctx context.Context) (RetentionResult, error) {
+	log := logger.FromContext(ctx, uc.log)
+	var result RetentionResult
+
+	cutoff := startOfDay(uc.now().Add(-uc.policy.MaxAge))
+	from, err := uc.repo.RetentionWatermark()
+	if err != nil {
+		log.Error("Falha ao ler a marca d'água da retenção", "erro", err.Error())
+		return result, err
+	}
+
+	for ctx.Err() == nil {
+		first, err := uc.repo.FirstConversionSince(from)
+		if err != nil {
+			log.Error("Falha ao buscar conversões a arquivar", "erro", err.Error())
+			return result, err
+		}
+		if first == nil || !first.Before(cutoff) {
+			break
+		}
+
+		day := startOfDay(*first)
+		next := day.AddDate(0, 0, 1)
+		path, n, err := uc.archiveDay(day, next)
+		if err != nil {
+			log.Error("Falha ao arquivar dia do histórico", "dia", day.Format(time.DateOnly), "erro", err.Error())
+			return result, err
+		}
+		result.Dias++
+		result.Registros += n
+		result.Arquivos = append(result.Arquivos, path)
+		from = next
+	}
+
+	if result.Dias > 0 {
+		log.Info("Conversões antigas arquivadas", "dias", result.Dias, "registros", result.Registros)
+	}
+	return result, ctx.Err()
+}
+
+func (uc *RetentionUseCase) archiveDay(day, next time.Time) (string, int, error) {
+	records, err := uc.repo.ConversionsBetween(day, next)
+	if err != nil {
+		return "", 0, err
+	}
+	path, err := uc.archive.WriteDay(day, records)
+	if err != nil {
+		return "", 0, err
+	}
+	if err := uc.repo.SaveDailyAggregates(aggregateDaily(records)); err != nil {
+		return "", 0, err
+	}
+
+	now := uc.now()
+	var expireAt *time.Time
+	if uc.policy.DeleteAfter > 0 {
+		at := now.Add(uc.policy.DeleteAfter)
+		expireAt = &at
+	}
+	if err := uc.repo.MarkArchived(day, next, now, expireAt); err != nil {
+		return "", 0, err
+	}
+	return path, len(records), uc.repo.SaveRetentionWatermark(next)
+}
+
+// Restore devolve ao histórico as conversões de um arquivo, no lugar das que
+// foram marcadas como arquivadas no mesmo período. As restauradas não voltam
+// a ser apagadas pela retenção.
+func (uc *RetentionUseCase) Restore(ctx context.Context, path string) (int, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	records, err := uc.archive.ReadArchive(path)
+	if err != nil {
+		log.Error("Falha ao ler arquivo de conversões", "arquivo", path, "erro", err.Error())
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, ErrEmptyArchive
+	}
+
+	from, to := records[0].Data, records[0].Data
+	for _, r := range records {
+		if r.Data.Before(from) {
+			from = r.Data
+		}
+		if r.Data.After(to) {
+			to = r.Data
+		}
+	}
+	if err := uc.repo.ReplaceArchivedConversions(startOfDay(from), startOfDay(to).AddDate(0, 0, 1), records); err != nil {
+		log.Error("Falha ao restaurar conversões", "arquivo", path, "erro", err.Error())
+		return 0, err
+	}
+
+	log.Info("Conversões restauradas do arquivo", "arquivo", path, "registros", len(records))
+	return len(records), nil
+}
+
+// aggregateDaily resume as conversões por dia e moeda, em ordem de dia e moeda
+func aggregateDaily(records []ConversionRecord) []ConversionDailyAggregate {
+	type key struct {
+		dia   time.Time
+		moeda string
+	}
+	byKey := map[key]*ConversionDailyAggregate{}
+	for _, r := range records {
+		k := key{startOfDay(r.Data), strings.ToUpper(r.MoedaDestino)}
+		agg, ok := byKey[k]
+		if !ok {
+			agg = &ConversionDailyAggregate{Dia: k.dia, Moeda: k.moeda, CotacaoMinima: r.Cotacao, CotacaoMaxima: r.Cotacao}
+			byKey[k] = agg
+		}
+		agg.Conversoes++
+		agg.ValorEntrada += r.ValorEntrada
+		agg.ValorConvertido += r.ValorConvertido
+		agg.CotacaoMinima = min(agg.CotacaoMinima, r.Cotacao)
+		agg.CotacaoMaxima = max(agg.CotacaoMaxima, r.Cotacao)
+		// Média incremental, sem guardar a soma das cotações
+		agg.CotacaoMedia += (r.Cotacao - agg.CotacaoMedia) / float64(agg.Conversoes)
+	}
+
+	out := make([]ConversionDailyAggregate, 0, len(byKey))
+	for _, agg := range byKey {
+		out = append(out, *agg)
+	}
+	sortAggregates(out)
+	return out
+}
+
+func sortAggregates(aggs []ConversionDailyAggregate) {
+	sort.Slice(aggs, func(i, j int) bool {
+		if !aggs[i].Dia.Equal(aggs[j].Dia) {
+			return aggs[i].Dia.Before(aggs[j].Dia)
+		}
+		return aggs[i].Moeda < aggs[j].Moeda
+	})
+}
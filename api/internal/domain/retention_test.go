@@ -0,0 +1,288 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryHistoryFake guarda o histórico em memória com as marcas da retenção
+type memoryHistoryFake struct {
+	records    []ConversionRecord
+	archivedAt map[int]time.Time
+	expireAt   map[int]time.Time
+	daily      map[string]ConversionDailyAggregate
+	watermark  time.Time
+}
+
+func newMemoryHistoryFake(records ...ConversionRecord) *memoryHistoryFake {
+	sort.Slice(records, func(i, j int) bool { return records[i].Data.Before(records[j].Data) })
+	return &memoryHistoryFake{
+		records:    records,
+		archivedAt: map[int]time.Time{},
+		expireAt:   map[int]time.Time{},
+		daily:      map[string]ConversionDailyAggregate{},
+	}
+}
+
+func (f *memoryHistoryFake) FirstConversionSince(t time.Time) (*time.Time, error) {
+	for _, r := range f.records {
+		if !r.Data.Before(t) {
+			data := r.Data
+			return &data, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *memoryHistoryFake) ConversionsBetween(from, to time.Time) ([]ConversionRecord, error) {
+	var out []ConversionRecord
+	for _, r := range f.records {
+		if !r.Data.Before(from) && r.Data.Before(to) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (f *memoryHistoryFake) SaveDailyAggregates(aggregates []ConversionDailyAggregate) error {
+	for _, a := range aggregates {
+		f.daily[a.Moeda+":"+a.Dia.Format(time.DateOnly)] = a
+	}
+	return nil
+}
+
+func (f *memoryHistoryFake) MarkArchived(from, to, archivedAt time.Time, expireAt *time.Time) error {
+	for i, r := range f.records {
+		if !r.Data.Before(from) && r.Data.Before(to) {
+			f.archivedAt[i] = archivedAt
+			if expireAt != nil {
+				f.expireAt[i] = *expireAt
+			}
+		}
+	}
+	return nil
+}
+
+func (f *memoryHistoryFake) ReplaceArchivedConversions(from, to time.Time, records []ConversionRecord) error {
+	var kept []ConversionRecord
+	for i, r := range f.records {
+		if _, archived := f.archivedAt[i]; archived && !r.Data.Before(from) && r.Data.Before(to) {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	f.records = append(kept, records...)
+	f.archivedAt, f.expireAt = map[int]time.Time{}, map[int]time.Time{}
+	return nil
+}
+
+func (f *memoryHistoryFake) RetentionWatermark() (time.Time, error) {
+	return f.watermark, nil
+}
+
+func (f *memoryHistoryFake) SaveRetentionWatermark(t time.Time) error {
+	f.watermark = t
+	return nil
+}
+
+func (f *memoryHistoryFake) DailyAggregates(moeda string, from, to time.Time) ([]ConversionDailyAggregate, error) {
+	var out []ConversionDailyAggregate
+	for _, a := range f.daily {
+		if (moeda == "" || a.Moeda == moeda) && !a.Dia.Before(from) && !a.Dia.After(to) {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+// AggregateConversions resume o que ainda está no banco, como faria o pipeline do Mongo
+func (f *memoryHistoryFake) AggregateConversions(moeda string, from, to time.Time) ([]ConversionDailyAggregate, error) {
+	var live []ConversionRecord
+	for _, r := range f.records {
+		if (moeda == "" || r.MoedaDestino == moeda) && !r.Data.Before(from) && !r.Data.After(to) {
+			live = append(live, r)
+		}
+	}
+	return aggregateDaily(live), nil
+}
+
+// memoryArchiveFake guarda os arquivos por caminho
+type memoryArchiveFake struct {
+	files map[string][]ConversionRecord
+	fail  error
+}
+
+func (a *memoryArchiveFake) WriteDay(day time.Time, records []ConversionRecord) (string, error) {
+	if a.fail != nil {
+		return "", a.fail
+	}
+	path := fmt.Sprintf("conversions-%s.ndjson.gz", day.Format(time.DateOnly))
+	a.files[path] = records
+	return path, nil
+}
+
+func (a *memoryArchiveFake) ReadArchive(path string) ([]ConversionRecord, error) {
+	records, ok := a.files[path]
+	if !ok {
+		return nil, errors.New("arquivo inexistente")
+	}
+	return records, nil
+}
+
+func conversionAt(moeda string, cotacao float64, data time.Time) ConversionRecord {
+	return ConversionRecord{MoedaDestino: moeda, Cotacao: cotacao, ValorEntrada: 100, ValorConvertido: 100 / cotacao, Data: data}
+}
+
+func jan(d int, hour int) time.Time {
+	return time.Date(2026, 1, d, hour, 0, 0, 0, time.UTC)
+}
+
+func newRetention(repo *memoryHistoryFake, archive *memoryArchiveFake, policy RetentionPolicy) *RetentionUseCase {
+	uc := NewRetentionUseCase(repo, archive, policy, newPersistenceLogger())
+	uc.now = func() time.Time { return jan(31, 12) }
+	return uc
+}
+
+func TestRetentionUseCase(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should archive only days before the cutoff",
+			run:  shouldArchiveOnlyDaysBeforeTheCutoff,
+		},
+		{
+			name: "should aggregate archived days by currency",
+			run:  shouldAggregateArchivedDaysByCurrency,
+		},
+		{
+			name: "should set expiration only with delete after",
+			run:  shouldSetExpirationOnlyWithDeleteAfter,
+		},
+		{
+			name: "should resume from the failed day on next run",
+			run:  shouldResumeFromTheFailedDayOnNextRun,
+		},
+		{
+			name: "should restore archived conversions without duplicates",
+			run:  shouldRestoreArchivedConversionsWithoutDuplicates,
+		},
+		{
+			name: "should reject empty archive on restore",
+			run:  shouldRejectEmptyArchiveOnRestore,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldArchiveOnlyDaysBeforeTheCutoff(t *testing.T) {
+	repo := newMemoryHistoryFake(
+		conversionAt("USD", 5, jan(2, 10)),
+		conversionAt("USD", 5, jan(5, 23)),
+		conversionAt("USD", 5, jan(21, 0)),
+		conversionAt("USD", 5, jan(30, 9)),
+	)
+	archive := &memoryArchiveFake{files: map[string][]ConversionRecord{}}
+
+	// Corte em 21/01 00:00: o dia 21 ainda não está completo dentro da idade máxima
+	result, err := newRetention(repo, archive, RetentionPolicy{MaxAge: 10 * 24 * time.Hour}).Archive(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Dias)
+	assert.Equal(t, 2, result.Registros)
+	assert.Equal(t, []string{"conversions-2026-01-02.ndjson.gz", "conversions-2026-01-05.ndjson.gz"}, result.Arquivos)
+	assert.Equal(t, jan(6, 0), repo.watermark)
+	assert.Len(t, repo.archivedAt, 2)
+}
+
+func shouldAggregateArchivedDaysByCurrency(t *testing.T) {
+	repo := newMemoryHistoryFake(
+		conversionAt("USD", 5, jan(2, 10)),
+		conversionAt("USD", 6, jan(2, 11)),
+		conversionAt("EUR", 6.5, jan(2, 12)),
+	)
+	archive := &memoryArchiveFake{files: map[string][]ConversionRecord{}}
+
+	_, err := newRetention(repo, archive, RetentionPolicy{MaxAge: 24 * time.Hour}).Archive(context.Background())
+
+	require.NoError(t, err)
+	usd := repo.daily["USD:2026-01-02"]
+	assert.Equal(t, 2, usd.Conversoes)
+	assert.Equal(t, 200.0, usd.ValorEntrada)
+	assert.Equal(t, 5.0, usd.CotacaoMinima)
+	assert.Equal(t, 6.0, usd.CotacaoMaxima)
+	assert.InDelta(t, 5.5, usd.CotacaoMedia, 1e-9)
+	assert.Equal(t, 1, repo.daily["EUR:2026-01-02"].Conversoes)
+	assert.Len(t, archive.files["conversions-2026-01-02.ndjson.gz"], 3)
+}
+
+func shouldSetExpirationOnlyWithDeleteAfter(t *testing.T) {
+	keep := newMemoryHistoryFake(conversionAt("USD", 5, jan(2, 10)))
+	_, err := newRetention(keep, &memoryArchiveFake{files: map[string][]ConversionRecord{}}, RetentionPolicy{MaxAge: 24 * time.Hour}).Archive(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, keep.archivedAt, 1)
+	assert.Empty(t, keep.expireAt)
+
+	drop := newMemoryHistoryFake(conversionAt("USD", 5, jan(2, 10)))
+	_, err = newRetention(drop, &memoryArchiveFake{files: map[string][]ConversionRecord{}}, RetentionPolicy{MaxAge: 24 * time.Hour, DeleteAfter: 7 * 24 * time.Hour}).Archive(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, jan(31, 12).Add(7*24*time.Hour), drop.expireAt[0])
+}
+
+func shouldResumeFromTheFailedDayOnNextRun(t *testing.T) {
+	repo := newMemoryHistoryFake(
+		conversionAt("USD", 5, jan(2, 10)),
+		conversionAt("USD", 5, jan(3, 10)),
+	)
+	archive := &memoryArchiveFake{files: map[string][]ConversionRecord{}, fail: errors.New("disco cheio")}
+	retention := newRetention(repo, archive, RetentionPolicy{MaxAge: 24 * time.Hour})
+
+	_, err := retention.Archive(context.Background())
+	require.Error(t, err)
+	assert.True(t, repo.watermark.IsZero())
+	assert.Empty(t, repo.daily)
+
+	archive.fail = nil
+	result, err := retention.Archive(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Dias)
+	assert.Equal(t, jan(4, 0), repo.watermark)
+}
+
+func shouldRestoreArchivedConversionsWithoutDuplicates(t *testing.T) {
+	repo := newMemoryHistoryFake(
+		conversionAt("USD", 5, jan(2, 10)),
+		conversionAt("USD", 6, jan(2, 11)),
+		conversionAt("USD", 5, jan(30, 9)),
+	)
+	archive := &memoryArchiveFake{files: map[string][]ConversionRecord{}}
+	retention := newRetention(repo, archive, RetentionPolicy{MaxAge: 24 * time.Hour})
+	_, err := retention.Archive(context.Background())
+	require.NoError(t, err)
+
+	n, err := retention.Restore(context.Background(), "conversions-2026-01-02.ndjson.gz")
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Len(t, repo.records, 3)
+	assert.Empty(t, repo.archivedAt)
+}
+
+func shouldRejectEmptyArchiveOnRestore(t *testing.T) {
+	archive := &memoryArchiveFake{files: map[string][]ConversionRecord{"vazio.ndjson.gz": nil}}
+
+	_, err := newRetention(newMemoryHistoryFake(), archive, RetentionPolicy{MaxAge: 24 * time.Hour}).Restore(context.Background(), "vazio.ndjson.gz")
+
+	assert.ErrorIs(t, err, ErrEmptyArchive)
+}
@@ -1,29 +1,86 @@
 package domain
 
 import (
+	"context"
 	"errors"
 	"go-frete/api/pkg/logger"
 	"time"
 )
 
+var (
+	// Mantém o texto original para não quebrar quem ainda compara a mensagem
+	ErrCurrencyNotFound = errors.New("moeda_nao_encontrada")
+	ErrZeroRate         = errors.New("cotação não pode ser zero")
+	ErrSaveConversion   = errors.New("erro interno ao salvar conversão")
+)
+
 // O contrato que a regra de negócio exige.
 type RateProvider interface {
 	GetRate(moeda string) (float64, error)
 }
 
+// RateSource é implementado pelos provedores que sabem se identificar (ex: "awesomeapi")
+type RateSource interface {
+	Source() string
+}
+
+func sourceOf(p RateProvider) string {
+	if s, ok := p.(RateSource); ok {
+		return s.Source()
+	}
+	return ""
+}
+
+// QuoteProvider é implementado pelos provedores que informam o horário da cotação na origem
+type QuoteProvider interface {
+	GetQuote(moeda string) (RateQuote, error)
+}
+
+// quoteOf busca a cotação com o horário da origem quando o provedor o informa;
+// nos demais, Data fica zerada
+func quoteOf(p RateProvider, moeda string) (RateQuote, error) {
+	if q, ok := p.(QuoteProvider); ok {
+		return q.GetQuote(moeda)
+	}
+	cotacao, err := p.GetRate(moeda)
+	if err != nil {
+		return RateQuote{}, err
+	}
+	return RateQuote{Moeda: moeda, Cotacao: cotacao, Fonte: sourceOf(p)}, nil
+}
+
 // A estrutura do Caso de Uso
 type ConverterUseCase struct {
 	provider RateProvider
 	repo     ConversionSaver
+	history  RateHistory
 	log      logger.Logger
+	now      func() time.Time
 }
 
 type ConversionRecord struct {
+	// ID é gerado antes da primeira gravação, para que regravar o registro
+	// (lote parcial, diário local) não o duplique no histórico
+	ID              string    `bson:"_id,omitempty" json:"id,omitempty"`
 	MoedaDestino    string    `bson:"currency" json:"currency"`
 	Cotacao         float64   `bson:"cotacao" json:"cotacao"`
 	ValorEntrada    float64   `bson:"valor_entrada" json:"valor_entrada"`
 	ValorConvertido float64   `bson:"valor_convertido" json:"valor_convertido"`
 	Data            time.Time `bson:"data" json:"data"`
+	// Chave de API que originou a conversão (vazio para registros antigos)
+	APIKeyID string `bson:"api_key_id,omitempty" json:"api_key_id,omitempty"`
+	// Provedor de onde veio a cotação
+	Fonte string `bson:"fonte,omitempty" json:"fonte,omitempty"`
+	// Retroativa marca as conversões feitas com a cotação de uma data passada,
+	// para não se misturarem às conversões do dia nas consultas do histórico
+	Retroativa bool `bson:"retroativa,omitempty" json:"retroativa,omitempty"`
+	// Instante pedido pelo cliente na conversão retroativa
+	DataReferencia *time.Time `bson:"data_referencia,omitempty" json:"data_referencia,omitempty"`
+	// Horário da cotação efetivamente usada na conversão retroativa
+	DataCotacao *time.Time `bson:"data_cotacao,omitempty" json:"data_cotacao,omitempty"`
+	// ChaveImportacao é a chave natural das conversões importadas; só elas a
+	// têm, e o índice único dela não alcança as conversões feitas pela API
+	ChaveImportacao string `bson:"chave_importacao,omitempty" json:"chave_importacao,omitempty"`
 }
 
 type ConversionSaver interface {
@@ -31,44 +88,124 @@ type ConversionSaver interface {
 }
 
 func NewConverterUseCase(p RateProvider, r ConversionSaver, l logger.Logger) *ConverterUseCase {
-	return &ConverterUseCase{provider: p, repo: r, log: l}
+	return &ConverterUseCase{provider: p, repo: r, log: l, now: time.Now}
+}
+
+// WithRateHistory habilita as conversões retroativas (ConvertAsOf)
+func (uc *ConverterUseCase) WithRateHistory(h RateHistory) *ConverterUseCase {
+	uc.history = h
+	return uc
 }
 
 // A Regra de Negócio Pura
-func (uc *ConverterUseCase) Execute(moeda string, valorBRL float64) (float64, error) {
+func (uc *ConverterUseCase) Execute(ctx context.Context, moeda string, valorBRL float64) (float64, error) {
+	record, err := uc.Convert(ctx, moeda, valorBRL)
+	if err != nil {
+		return 0, err
+	}
+	return record.ValorConvertido, nil
+}
 
-	uc.log.Info("Iniciando cálculo de conversão",
+// Convert faz a conversão e devolve o registro completo salvo no histórico
+func (uc *ConverterUseCase) Convert(ctx context.Context, moeda string, valorBRL float64) (ConversionRecord, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	log.Info("Iniciando cálculo de conversão",
 		"moeda_alvo", moeda,
 		"valor_brl", valorBRL,
 	)
 	// 1. Pede a cotação pro "provedor"
 	cotacao, err := uc.provider.GetRate(moeda)
 	if err != nil {
-		uc.log.Error("Falha ao buscar cotação no provider", "erro", err.Error())
-		return 0, err
+		log.Error("Falha ao buscar cotação no provider", "erro", err.Error())
+		return ConversionRecord{}, err
 	}
 
-	if cotacao == 0 {
-		return 0, errors.New("cotação não pode ser zero")
+	record := ConversionRecord{
+		MoedaDestino: moeda,
+		Cotacao:      cotacao,
+		ValorEntrada: valorBRL,
+		Fonte:        sourceOf(uc.provider),
 	}
+	return uc.save(ctx, record)
+}
 
-	// 2. Faz a matemática
-	valorConvertido := valorBRL / cotacao
+// ConvertAsOf converte com a cotação vigente no instante at, em vez da atual.
+// O registro fica marcado como retroativo e traz o horário e a fonte da cotação usada.
+func (uc *ConverterUseCase) ConvertAsOf(ctx context.Context, moeda string, valorBRL float64, at time.Time) (ConversionRecord, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	log.Info("Iniciando cálculo de conversão retroativa",
+		"moeda_alvo", moeda,
+		"valor_brl", valorBRL,
+		"data_referencia", at,
+	)
+	if at.After(uc.now()) {
+		return ConversionRecord{}, ErrAsOfInFuture
+	}
+	if uc.history == nil {
+		return ConversionRecord{}, ErrRateNotAvailable
+	}
+
+	rate, err := uc.history.RateAt(moeda, at)
+	if err != nil {
+		log.Warn("Falha ao buscar cotação histórica", "erro", err.Error(), "moeda", moeda)
+		return ConversionRecord{}, err
+	}
 
-	//Monta o registro de conversão para salvar no histórico
 	record := ConversionRecord{
-		MoedaDestino:    moeda,
-		Cotacao:         cotacao,
-		ValorEntrada:    valorBRL,
-		ValorConvertido: valorConvertido,
-		Data:            time.Now(),
+		MoedaDestino:   moeda,
+		Cotacao:        rate.Cotacao,
+		ValorEntrada:   valorBRL,
+		Fonte:          rate.Fonte,
+		Retroativa:     true,
+		DataReferencia: &at,
+		DataCotacao:    &rate.Data,
+	}
+	return uc.save(ctx, record)
+}
+
+// save calcula o valor convertido e grava o registro no histórico
+func (uc *ConverterUseCase) save(ctx context.Context, record ConversionRecord) (ConversionRecord, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	if record.Cotacao == 0 {
+		return ConversionRecord{}, ErrZeroRate
+	}
+
+	// 2. Faz a matemática
+	record.ValorConvertido = record.ValorEntrada / record.Cotacao
+	record.Data = uc.now()
+	if key, ok := APIKeyFromContext(ctx); ok {
+		record.APIKeyID = key.ID
 	}
 
 	if err := uc.repo.SaveHistory(record); err != nil {
-		uc.log.Error("Falha ao salvar histórico no banco", "erro", err.Error())
-		return 0, errors.New("erro interno ao salvar conversão")
+		log.Error("Falha ao salvar histórico no banco", "erro", err.Error())
+		return ConversionRecord{}, ErrSaveConversion
 	}
 
-	uc.log.Info("Conversão finalizada com sucesso", "valor_convertido", valorConvertido)
-	return valorConvertido, nil
+	log.Info("Conversão finalizada com sucesso", "valor_convertido", record.ValorConvertido)
+	return record, nil
+}
+
+// RateQuote é a cotação atual de uma moeda, sem conversão nem histórico
+type RateQuote struct {
+	Moeda   string
+	Cotacao float64
+	Fonte   string
+	Data    time.Time
+}
+
+// Quote consulta a cotação da moeda no provedor sem salvar no histórico
+func (uc *ConverterUseCase) Quote(ctx context.Context, moeda string) (RateQuote, error) {
+	cotacao, err := uc.provider.GetRate(moeda)
+	if err != nil {
+		logger.FromContext(ctx, uc.log).Warn("Falha ao buscar cotação no provider", "erro", err.Error(), "moeda", moeda)
+		return RateQuote{}, err
+	}
+	if cotacao == 0 {
+		return RateQuote{}, ErrZeroRate
+	}
+	return RateQuote{Moeda: moeda, Cotacao: cotacao, Fonte: sourceOf(uc.provider), Data: time.Now()}, nil
 }
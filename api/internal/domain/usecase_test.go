@@ -1,13 +1,16 @@
 package domain
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"go-frete/api/tests/mocks/loggermock"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type rateProviderMock struct {
@@ -48,6 +51,14 @@ func TestConverterUseCase_Execute(t *testing.T) {
 			name: "should return error when repository fails to save",
 			run:  shouldReturnErrorWhenRepositoryFailsToSave,
 		},
+		{
+			name: "should record calling api key id on history",
+			run:  shouldRecordCallingAPIKeyIDOnHistory,
+		},
+		{
+			name: "should quote rate without saving history",
+			run:  shouldQuoteRateWithoutSavingHistory,
+		},
 	}
 
 	for _, tt := range tests {
@@ -66,7 +77,7 @@ func shouldCalculateConversionAndSaveSuccessfully(t *testing.T) {
 	repoMock.On("SaveHistory", mock.Anything).Return(nil)
 
 	uc := NewConverterUseCase(providerMock, repoMock, loggerMock)
-	result, err := uc.Execute("USD", 100.0)
+	result, err := uc.Execute(context.Background(), "USD", 100.0)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 20.0, result)
@@ -87,7 +98,7 @@ func shouldReturnErrorWhenProviderFails(t *testing.T) {
 	providerMock.On("GetRate", "EUR").Return(0.0, expectedErr)
 
 	uc := NewConverterUseCase(providerMock, repoMock, loggerMock)
-	result, err := uc.Execute("EUR", 100.0)
+	result, err := uc.Execute(context.Background(), "EUR", 100.0)
 
 	assert.Error(t, err)
 	assert.Equal(t, 0.0, result)
@@ -107,7 +118,7 @@ func shouldReturnErrorWhenRateIsZero(t *testing.T) {
 	providerMock.On("GetRate", "BTC").Return(0.0, nil)
 
 	uc := NewConverterUseCase(providerMock, repoMock, loggerMock)
-	_, err := uc.Execute("BTC", 100.0)
+	_, err := uc.Execute(context.Background(), "BTC", 100.0)
 
 	assert.Error(t, err)
 	assert.Equal(t, "cotação não pode ser zero", err.Error())
@@ -129,7 +140,7 @@ func shouldReturnErrorWhenRepositoryFailsToSave(t *testing.T) {
 	repoMock.On("SaveHistory", mock.Anything).Return(errors.New("mongo timeout"))
 
 	uc := NewConverterUseCase(providerMock, repoMock, loggerMock)
-	result, err := uc.Execute("USD", 100.0)
+	result, err := uc.Execute(context.Background(), "USD", 100.0)
 
 	assert.Error(t, err)
 	assert.Equal(t, 0.0, result)
@@ -138,3 +149,142 @@ func shouldReturnErrorWhenRepositoryFailsToSave(t *testing.T) {
 	providerMock.AssertExpectations(t)
 	repoMock.AssertExpectations(t)
 }
+
+func shouldRecordCallingAPIKeyIDOnHistory(t *testing.T) {
+	providerMock := new(rateProviderMock)
+	repoMock := new(repositoryMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	providerMock.On("GetRate", "USD").Return(5.0, nil)
+
+	repoMock.On("SaveHistory", mock.MatchedBy(func(r ConversionRecord) bool {
+		return r.APIKeyID == "key-1"
+	})).Return(nil)
+
+	ctx := ContextWithAPIKey(context.Background(), &APIKey{ID: "key-1", Scopes: []string{ScopeConvertWrite}})
+
+	uc := NewConverterUseCase(providerMock, repoMock, loggerMock)
+	_, err := uc.Execute(ctx, "USD", 100.0)
+
+	assert.NoError(t, err)
+	repoMock.AssertExpectations(t)
+}
+
+type namedProviderStub struct{ rateProviderMock }
+
+func (p *namedProviderStub) Source() string { return "awesomeapi" }
+
+func shouldQuoteRateWithoutSavingHistory(t *testing.T) {
+	providerMock := new(namedProviderStub)
+	repoMock := new(repositoryMock)
+
+	providerMock.On("GetRate", "USD").Return(5.0, nil)
+
+	uc := NewConverterUseCase(providerMock, repoMock, new(loggermock.LoggerMock))
+	quote, err := uc.Quote(context.Background(), "USD")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, quote.Cotacao)
+	assert.Equal(t, "awesomeapi", quote.Fonte)
+	repoMock.AssertNotCalled(t, "SaveHistory", mock.Anything)
+}
+
+type rateHistoryMock struct {
+	mock.Mock
+}
+
+func (m *rateHistoryMock) RateAt(moeda string, at time.Time) (RateSnapshot, error) {
+	args := m.Called(moeda, at)
+	return args.Get(0).(RateSnapshot), args.Error(1)
+}
+
+func TestConverterUseCase_ConvertAsOf(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should convert with rate in effect at date and flag record",
+			run:  shouldConvertWithRateInEffectAtDateAndFlagRecord,
+		},
+		{
+			name: "should reject date in the future",
+			run:  shouldRejectDateInTheFuture,
+		},
+		{
+			name: "should return rate not available without history",
+			run:  shouldReturnRateNotAvailableWithoutHistory,
+		},
+		{
+			name: "should propagate history error without saving",
+			run:  shouldPropagateHistoryErrorWithoutSaving,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldConvertWithRateInEffectAtDateAndFlagRecord(t *testing.T) {
+	at := time.Date(2026, 1, 5, 15, 0, 0, 0, time.UTC)
+	rateAt := time.Date(2026, 1, 5, 14, 45, 0, 0, time.UTC)
+	historyMock := new(rateHistoryMock)
+	historyMock.On("RateAt", "USD", at).Return(RateSnapshot{Moeda: "USD", Cotacao: 4.0, Fonte: "awesomeapi", Data: rateAt}, nil)
+	repoMock := new(repositoryMock)
+	repoMock.On("SaveHistory", mock.Anything).Return(nil)
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	providerMock := new(rateProviderMock)
+
+	uc := NewConverterUseCase(providerMock, repoMock, loggerMock).WithRateHistory(historyMock)
+	record, err := uc.ConvertAsOf(context.Background(), "USD", 100.0, at)
+
+	require.NoError(t, err)
+	assert.Equal(t, 25.0, record.ValorConvertido)
+	assert.True(t, record.Retroativa)
+	assert.Equal(t, at, *record.DataReferencia)
+	assert.Equal(t, rateAt, *record.DataCotacao)
+	assert.Equal(t, "awesomeapi", record.Fonte)
+	repoMock.AssertCalled(t, "SaveHistory", mock.MatchedBy(func(r ConversionRecord) bool { return r.Retroativa && r.Cotacao == 4.0 }))
+	// A cotação atual nem é consultada
+	providerMock.AssertNotCalled(t, "GetRate", mock.Anything)
+}
+
+func shouldRejectDateInTheFuture(t *testing.T) {
+	historyMock := new(rateHistoryMock)
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+
+	uc := NewConverterUseCase(new(rateProviderMock), new(repositoryMock), loggerMock).WithRateHistory(historyMock)
+	_, err := uc.ConvertAsOf(context.Background(), "USD", 100.0, time.Now().Add(time.Hour))
+
+	assert.ErrorIs(t, err, ErrAsOfInFuture)
+	historyMock.AssertNotCalled(t, "RateAt", mock.Anything, mock.Anything)
+}
+
+func shouldReturnRateNotAvailableWithoutHistory(t *testing.T) {
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+
+	uc := NewConverterUseCase(new(rateProviderMock), new(repositoryMock), loggerMock)
+	_, err := uc.ConvertAsOf(context.Background(), "USD", 100.0, time.Now().Add(-time.Hour))
+
+	assert.ErrorIs(t, err, ErrRateNotAvailable)
+}
+
+func shouldPropagateHistoryErrorWithoutSaving(t *testing.T) {
+	historyMock := new(rateHistoryMock)
+	historyMock.On("RateAt", "XYZ", mock.Anything).Return(RateSnapshot{}, ErrCurrencyNotFound)
+	repoMock := new(repositoryMock)
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+
+	uc := NewConverterUseCase(new(rateProviderMock), repoMock, loggerMock).WithRateHistory(historyMock)
+	_, err := uc.ConvertAsOf(context.Background(), "XYZ", 100.0, time.Now().Add(-time.Hour))
+
+	assert.ErrorIs(t, err, ErrCurrencyNotFound)
+	repoMock.AssertNotCalled(t, "SaveHistory", mock.Anything)
+}
@@ -1,10 +1,19 @@
 package domain
 
 import (
+	"context"
+	"errors"
 	"go-frete/api/pkg/logger"
+	"math"
+	"strings"
 	"time"
 )
 
+// Janela usada quando o período não é informado
+const DefaultSeriesWindow = 30 * 24 * time.Hour
+
+var ErrNoRates = errors.New("sem cotações coletadas no período")
+
 // O DTO de resposta
 type CurrencyVariation struct {
 	Data               time.Time `json:"data"`
@@ -13,51 +22,147 @@ type CurrencyVariation struct {
 	VariacaoPercentual float64   `json:"variacao_percentual"`
 }
 
-type ConversionSearcher interface {
-	GetConversionsByCurrency(currency string) ([]ConversionRecord, error)
+// RateStatistics resume a série de cotações de uma moeda num período
+type RateStatistics struct {
+	Moeda              string    `json:"moeda"`
+	De                 time.Time `json:"de"`
+	Ate                time.Time `json:"ate"`
+	Amostras           int       `json:"amostras"`
+	Minima             float64   `json:"minima"`
+	Maxima             float64   `json:"maxima"`
+	Media              float64   `json:"media"`
+	DesvioPadrao       float64   `json:"desvio_padrao"`
+	Primeira           float64   `json:"primeira"`
+	Ultima             float64   `json:"ultima"`
+	VariacaoPercentual float64   `json:"variacao_percentual"`
+}
+
+// RateSeriesReader lê a série de cotações coletadas, da mais antiga para a mais nova
+type RateSeriesReader interface {
+	GetRateSeries(moeda string, from, to time.Time) ([]RateSnapshot, error)
 }
 
 type VariationUseCase struct {
-	repo ConversionSearcher
-	log  logger.Logger
+	repo      RateSeriesReader
+	calendars CurrencyCalendars
+	log       logger.Logger
 }
 
-func NewVariationUseCase(r ConversionSearcher, l logger.Logger) *VariationUseCase {
+func NewVariationUseCase(r RateSeriesReader, l logger.Logger) *VariationUseCase {
 	return &VariationUseCase{repo: r, log: l}
 }
 
-func (uc *VariationUseCase) Execute(moeda string) ([]CurrencyVariation, error) {
-	uc.log.Info("Iniciando cálculo de variação", "moeda", moeda)
+// WithCalendars descarta da série as cotações de fins de semana e feriados,
+// que repetem o último fechamento e apareceriam como variação zero
+func (uc *VariationUseCase) WithCalendars(c CurrencyCalendars) *VariationUseCase {
+	uc.calendars = c
+	return uc
+}
+
+func (uc *VariationUseCase) series(moeda string, from, to time.Time) ([]RateSnapshot, error) {
+	return businessSeries(uc.repo, uc.calendars, moeda, from, to)
+}
 
-	// Busca os registros dessa moeda (ordenando da mais antiga para a mais nova)
-	records, err := uc.repo.GetConversionsByCurrency(moeda)
+// businessSeries busca a série da moeda no período, só com os dias úteis quando há calendário
+func businessSeries(repo RateSeriesReader, calendars CurrencyCalendars, moeda string, from, to time.Time) ([]RateSnapshot, error) {
+	series, err := repo.GetRateSeries(moeda, from, to)
+	if err != nil || calendars == nil {
+		return series, err
+	}
+	return onlyBusinessDays(series, calendars.ForCurrency(moeda)), nil
+}
+
+// Execute calcula a variação entre cotações consecutivas dos últimos DefaultSeriesWindow
+func (uc *VariationUseCase) Execute(ctx context.Context, moeda string) ([]CurrencyVariation, error) {
+	log := logger.FromContext(ctx, uc.log)
+	log.Info("Iniciando cálculo de variação", "moeda", moeda)
+
+	to := time.Now()
+	series, err := uc.series(strings.ToUpper(moeda), to.Add(-DefaultSeriesWindow), to)
 	if err != nil {
-		uc.log.Error("Falha ao buscar conversões por moeda", "erro", err.Error(), "moeda", moeda)
+		log.Error("Falha ao buscar série de cotações", "erro", err.Error(), "moeda", moeda)
 		return nil, err
 	}
 
-	var variations []CurrencyVariation
+	// Sem registros devolve lista vazia (e não null) no JSON
+	variations := make([]CurrencyVariation, 0, len(series))
 
 	// Regra de Negócio: Calcular a variação entre uma cotação e a anterior
-	for i, record := range records {
+	for i, snapshot := range series {
 		variacaoValor := 0.0
 		variacaoPerc := 0.0
 
 		// Se não for o primeiro registro, compara com o anterior
 		if i > 0 {
-			cotacaoAnterior := records[i-1].Cotacao
-			variacaoValor = record.Cotacao - cotacaoAnterior
+			cotacaoAnterior := series[i-1].Cotacao
+			variacaoValor = snapshot.Cotacao - cotacaoAnterior
 			variacaoPerc = (variacaoValor / cotacaoAnterior) * 100
 		}
 
 		variations = append(variations, CurrencyVariation{
-			Data:               record.Data,
-			Cotacao:            record.Cotacao,
+			Data:               snapshot.Data,
+			Cotacao:            snapshot.Cotacao,
 			VariacaoValor:      variacaoValor,
 			VariacaoPercentual: variacaoPerc,
 		})
 	}
 
-	uc.log.Info("Cálculo de variação finalizado com sucesso", "total_registros", len(variations))
+	log.Info("Cálculo de variação finalizado com sucesso", "total_registros", len(variations))
 	return variations, nil
 }
+
+// Statistics resume a série no período [from, to]. Sem to usa agora; sem from,
+// os DefaultSeriesWindow anteriores a to.
+func (uc *VariationUseCase) Statistics(ctx context.Context, moeda string, from, to time.Time) (RateStatistics, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-DefaultSeriesWindow)
+	}
+	if from.After(to) {
+		return RateStatistics{}, ErrInvalidPeriod
+	}
+
+	moeda = strings.ToUpper(moeda)
+	series, err := uc.series(moeda, from, to)
+	if err != nil {
+		log.Error("Falha ao buscar série de cotações", "erro", err.Error(), "moeda", moeda)
+		return RateStatistics{}, err
+	}
+	if len(series) == 0 {
+		return RateStatistics{}, ErrNoRates
+	}
+
+	stats := RateStatistics{
+		Moeda:    moeda,
+		De:       from,
+		Ate:      to,
+		Amostras: len(series),
+		Minima:   series[0].Cotacao,
+		Maxima:   series[0].Cotacao,
+		Primeira: series[0].Cotacao,
+		Ultima:   series[len(series)-1].Cotacao,
+	}
+
+	var soma float64
+	for _, s := range series {
+		soma += s.Cotacao
+		stats.Minima = math.Min(stats.Minima, s.Cotacao)
+		stats.Maxima = math.Max(stats.Maxima, s.Cotacao)
+	}
+	stats.Media = soma / float64(len(series))
+
+	// Desvio padrão populacional: a série é o período inteiro, não uma amostra dele
+	var quadrados float64
+	for _, s := range series {
+		quadrados += (s.Cotacao - stats.Media) * (s.Cotacao - stats.Media)
+	}
+	stats.DesvioPadrao = math.Sqrt(quadrados / float64(len(series)))
+	stats.VariacaoPercentual = (stats.Ultima - stats.Primeira) / stats.Primeira * 100
+
+	log.Info("Estatísticas calculadas", "moeda", moeda, "amostras", stats.Amostras)
+	return stats, nil
+}
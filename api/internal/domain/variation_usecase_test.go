@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -11,16 +12,16 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
-type conversionSearcherMock struct {
+type rateSeriesReaderMock struct {
 	mock.Mock
 }
 
-func (m *conversionSearcherMock) GetConversionsByCurrency(currency string) ([]ConversionRecord, error) {
-	args := m.Called(currency)
+func (m *rateSeriesReaderMock) GetRateSeries(moeda string, from, to time.Time) ([]RateSnapshot, error) {
+	args := m.Called(moeda, from, to)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]ConversionRecord), args.Error(1)
+	return args.Get(0).([]RateSnapshot), args.Error(1)
 }
 
 func TestVariationUseCase_Execute(t *testing.T) {
@@ -36,6 +37,10 @@ func TestVariationUseCase_Execute(t *testing.T) {
 			name: "should return error when repository fails",
 			run:  shouldReturnErrorWhenSearchFails,
 		},
+		{
+			name: "should skip weekends and holidays when calendar is set",
+			run:  shouldSkipWeekendsAndHolidaysWhenCalendarIsSet,
+		},
 	}
 
 	for _, tt := range tests {
@@ -44,20 +49,21 @@ func TestVariationUseCase_Execute(t *testing.T) {
 }
 
 func shouldCalculateVariationSuccessfully(t *testing.T) {
-	searcherMock := new(conversionSearcherMock)
+	searcherMock := new(rateSeriesReaderMock)
 	loggerMock := new(loggermock.LoggerMock)
 
 	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
 
-	mockData := []ConversionRecord{
-		{MoedaDestino: "JPY", Cotacao: 30.0, ValorEntrada: 100, ValorConvertido: 3000, Data: time.Now().Add(-1 * time.Hour)},
-		{MoedaDestino: "JPY", Cotacao: 33.0, ValorEntrada: 100, ValorConvertido: 3300, Data: time.Now()}, // Aumentou 3.0 (10%)
+	mockData := []RateSnapshot{
+		{Moeda: "JPY", Cotacao: 30.0, Data: time.Now().Add(-1 * time.Hour)},
+		{Moeda: "JPY", Cotacao: 33.0, Data: time.Now()}, // Aumentou 3.0 (10%)
 	}
 
-	searcherMock.On("GetConversionsByCurrency", "JPY").Return(mockData, nil)
+	// A moeda é normalizada e a janela termina agora
+	searcherMock.On("GetRateSeries", "JPY", mock.Anything, mock.Anything).Return(mockData, nil)
 
 	uc := NewVariationUseCase(searcherMock, loggerMock)
-	result, err := uc.Execute("JPY")
+	result, err := uc.Execute(context.Background(), "jpy")
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 2)
@@ -70,24 +76,148 @@ func shouldCalculateVariationSuccessfully(t *testing.T) {
 	assert.Equal(t, 3.0, result[1].VariacaoValor)       // 33 - 30 = 3
 	assert.Equal(t, 10.0, result[1].VariacaoPercentual) // (3 / 30) * 100 = 10%
 
+	from := searcherMock.Calls[0].Arguments.Get(1).(time.Time)
+	to := searcherMock.Calls[0].Arguments.Get(2).(time.Time)
+	assert.Equal(t, DefaultSeriesWindow, to.Sub(from))
 	searcherMock.AssertExpectations(t)
 }
 
 func shouldReturnErrorWhenSearchFails(t *testing.T) {
-	searcherMock := new(conversionSearcherMock)
+	searcherMock := new(rateSeriesReaderMock)
 	loggerMock := new(loggermock.LoggerMock)
 
 	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
 	loggerMock.On("Error", mock.Anything, mock.Anything).Return()
 
 	expectedErr := errors.New("db connection lost")
-	searcherMock.On("GetConversionsByCurrency", "USD").Return(nil, expectedErr)
+	searcherMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return(nil, expectedErr)
 
 	uc := NewVariationUseCase(searcherMock, loggerMock)
-	result, err := uc.Execute("USD")
+	result, err := uc.Execute(context.Background(), "USD")
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Equal(t, expectedErr, err)
 	searcherMock.AssertExpectations(t)
 }
+
+func shouldSkipWeekendsAndHolidaysWhenCalendarIsSet(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+
+	// Sexta, sábado e domingo repetindo o fechamento, segunda e terça de Carnaval, quarta
+	at := func(d string) time.Time { return day(d).Add(15 * time.Hour) }
+	searcherMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return([]RateSnapshot{
+		{Moeda: "USD", Cotacao: 5.0, Data: at("2026-02-13")},
+		{Moeda: "USD", Cotacao: 5.0, Data: at("2026-02-14")},
+		{Moeda: "USD", Cotacao: 5.0, Data: at("2026-02-15")},
+		{Moeda: "USD", Cotacao: 5.0, Data: at("2026-02-16")},
+		{Moeda: "USD", Cotacao: 5.0, Data: at("2026-02-17")},
+		{Moeda: "USD", Cotacao: 5.5, Data: at("2026-02-18")},
+	}, nil)
+
+	uc := NewVariationUseCase(searcherMock, loggerMock).WithCalendars(NewCalendars(nil))
+	result, err := uc.Execute(context.Background(), "USD")
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, at("2026-02-18"), result[1].Data)
+	assert.InDelta(t, 10.0, result[1].VariacaoPercentual, 0.0001)
+}
+
+func TestVariationUseCase_Statistics(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should summarize rate series in period",
+			run:  shouldSummarizeRateSeriesInPeriod,
+		},
+		{
+			name: "should default period to window ending now",
+			run:  shouldDefaultPeriodToWindowEndingNow,
+		},
+		{
+			name: "should return no rates error for empty period",
+			run:  shouldReturnNoRatesErrorForEmptyPeriod,
+		},
+		{
+			name: "should reject period with from after to",
+			run:  shouldRejectStatisticsPeriodWithFromAfterTo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldSummarizeRateSeriesInPeriod(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	searcherMock.On("GetRateSeries", "USD", from, to).Return([]RateSnapshot{
+		{Moeda: "USD", Cotacao: 4.0, Data: from},
+		{Moeda: "USD", Cotacao: 6.0, Data: from.Add(24 * time.Hour)},
+		{Moeda: "USD", Cotacao: 5.0, Data: from.Add(48 * time.Hour)},
+	}, nil)
+
+	uc := NewVariationUseCase(searcherMock, loggerMock)
+	stats, err := uc.Statistics(context.Background(), "usd", from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", stats.Moeda)
+	assert.Equal(t, 3, stats.Amostras)
+	assert.Equal(t, 4.0, stats.Minima)
+	assert.Equal(t, 6.0, stats.Maxima)
+	assert.Equal(t, 5.0, stats.Media)
+	assert.InDelta(t, 0.8165, stats.DesvioPadrao, 0.0001)
+	assert.Equal(t, 4.0, stats.Primeira)
+	assert.Equal(t, 5.0, stats.Ultima)
+	assert.Equal(t, 25.0, stats.VariacaoPercentual)
+	searcherMock.AssertExpectations(t)
+}
+
+func shouldDefaultPeriodToWindowEndingNow(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+
+	searcherMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return([]RateSnapshot{{Moeda: "USD", Cotacao: 5.0}}, nil)
+
+	uc := NewVariationUseCase(searcherMock, loggerMock)
+	stats, err := uc.Statistics(context.Background(), "USD", time.Time{}, time.Time{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultSeriesWindow, stats.Ate.Sub(stats.De))
+	assert.WithinDuration(t, time.Now(), stats.Ate, time.Second)
+	assert.Equal(t, 0.0, stats.DesvioPadrao)
+}
+
+func shouldReturnNoRatesErrorForEmptyPeriod(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	searcherMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return([]RateSnapshot{}, nil)
+
+	uc := NewVariationUseCase(searcherMock, loggerMock)
+	_, err := uc.Statistics(context.Background(), "USD", time.Time{}, time.Time{})
+
+	assert.ErrorIs(t, err, ErrNoRates)
+}
+
+func shouldRejectStatisticsPeriodWithFromAfterTo(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	uc := NewVariationUseCase(searcherMock, loggerMock)
+	_, err := uc.Statistics(context.Background(), "USD", time.Now(), time.Now().Add(-time.Hour))
+
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+	searcherMock.AssertNotCalled(t, "GetRateSeries", mock.Anything, mock.Anything, mock.Anything)
+}
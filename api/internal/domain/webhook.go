@@ -0,0 +1,535 @@
+package domain
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-frete/api/pkg/logger"
+)
+
+// Eventos que podem ser assinados por webhook, além de EventConversionCreated
+const (
+	EventRateAlertTriggered = "rate_alert.triggered"
+	EventProviderDown       = "provider.down"
+)
+
+var webhookEvents = []string{EventConversionCreated, EventRateAlertTriggered, EventProviderDown}
+
+// Situação de uma entrega de webhook
+const (
+	DeliveryPending   = "pendente"
+	DeliveryDelivered = "entregue"
+	// Esgotou as tentativas: fica na fila de mortas até alguém reenviá-la
+	DeliveryDead = "morta"
+)
+
+// Cabeçalhos de cada entrega. O id é o mesmo em todas as tentativas e
+// reenvios, para o receptor descartar repetições.
+const (
+	WebhookIDHeader        = "X-Webhook-Id"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// Prefixo dos segredos gerados, como o das chaves de API
+const webhookSecretPrefix = "whsec_"
+
+var (
+	ErrWebhookURL       = errors.New("URL do webhook deve ser absoluta, com http ou https")
+	ErrWebhookEvent     = errors.New("tipo de evento inválido para webhook")
+	ErrWebhookNotFound  = errors.New("webhook não encontrado")
+	ErrDeliveryNotFound = errors.New("entrega de webhook não encontrada")
+	ErrDeliveryPending  = errors.New("entrega ainda está na fila de envio")
+	ErrWebhookSignature = errors.New("assinatura do webhook inválida")
+	ErrWebhookTimestamp = errors.New("horário do webhook fora da tolerância")
+)
+
+// Webhook é a assinatura de um cliente: a URL que recebe os eventos dos tipos
+// escolhidos. O segredo assina as entregas e só é mostrado na criação.
+type Webhook struct {
+	ID       string    `bson:"_id" json:"id"`
+	URL      string    `bson:"url" json:"url"`
+	Eventos  []string  `bson:"eventos" json:"eventos"`
+	Segredo  string    `bson:"segredo" json:"-"`
+	APIKeyID string    `bson:"api_key_id,omitempty" json:"api_key_id,omitempty"`
+	CriadoEm time.Time `bson:"criado_em" json:"criado_em"`
+}
+
+// WebhookDelivery é um evento a caminho de um webhook
+type WebhookDelivery struct {
+	ID               string      `json:"id"`
+	WebhookID        string      `json:"webhook_id"`
+	Evento           DomainEvent `json:"evento"`
+	Status           string      `json:"status"`
+	Tentativas       int         `json:"tentativas"`
+	ProximaTentativa time.Time   `json:"proxima_tentativa,omitzero"`
+	UltimoErro       string      `json:"ultimo_erro,omitempty"`
+	UltimoStatusHTTP int         `json:"ultimo_status_http,omitempty"`
+	CriadaEm         time.Time   `json:"criada_em"`
+	EntregueEm       time.Time   `json:"entregue_em,omitzero"`
+}
+
+// DeliveryFilter restringe a listagem de entregas; campos vazios não filtram
+type DeliveryFilter struct {
+	Status    string
+	WebhookID string
+	Limit     int
+}
+
+type WebhookRepository interface {
+	SaveWebhook(w Webhook) error
+	GetWebhook(id string) (*Webhook, error)
+	// ListWebhooks devolve os webhooks da chave; apiKeyID vazio devolve todos
+	ListWebhooks(apiKeyID string) ([]Webhook, error)
+	DeleteWebhook(id string) error
+	WebhooksFor(tipo string) ([]Webhook, error)
+}
+
+// WebhookDeliveryStore é a fila de entregas, com as mortas no mesmo lugar
+type WebhookDeliveryStore interface {
+	// SaveDeliveries ignora as entregas com id já gravado: o mesmo evento
+	// publicado de novo pelo outbox não gera uma segunda entrega
+	SaveDeliveries(deliveries []WebhookDelivery) error
+	// ClaimDeliveries reserva por lease as entregas pendentes vencidas, para
+	// que outra instância não as envie ao mesmo tempo
+	ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, error)
+	UpdateDelivery(d WebhookDelivery) error
+	GetDelivery(id string) (*WebhookDelivery, error)
+	ListDeliveries(filter DeliveryFilter) ([]WebhookDelivery, error)
+}
+
+// WebhookRequest é o POST assinado de uma entrega
+type WebhookRequest struct {
+	URL     string
+	Headers map[string]string
+	Body    []byte
+}
+
+// WebhookSender faz o POST; err só para falhas de rede, o status decide o resto
+type WebhookSender interface {
+	Send(req WebhookRequest) (status int, err error)
+}
+
+// WebhookConfig ajusta as entregas; campos zerados usam os padrões
+type WebhookConfig struct {
+	// Tentativas antes de a entrega ir para a fila de mortas (padrão 8)
+	MaxAttempts int
+	// Espera depois da primeira falha; dobra a cada nova falha até MaxBackoff
+	// (padrões 10s e 1h)
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Entregas reservadas por rodada e envios simultâneos (padrões 50 e 4)
+	BatchSize int
+	Workers   int
+	// Reserva de uma entrega em envio; passado o prazo sem resposta (queda da
+	// instância), ela volta para a fila (padrão 1m)
+	Lease time.Duration
+}
+
+// WebhookUseCase cadastra os webhooks, transforma cada evento numa entrega por
+// assinante e envia as entregas assinadas, com novas tentativas. Implementa
+// EventSink: como sink do outbox recebe as conversões; os demais eventos
+// chegam direto por Publish.
+type WebhookUseCase struct {
+	hooks      WebhookRepository
+	deliveries WebhookDeliveryStore
+	sender     WebhookSender
+	cfg        WebhookConfig
+	log        logger.Logger
+	now        func() time.Time
+}
+
+func NewWebhookUseCase(hooks WebhookRepository, deliveries WebhookDeliveryStore, sender WebhookSender, cfg WebhookConfig, l logger.Logger) *WebhookUseCase {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 10 * time.Second
+	}
+	if cfg.MaxBackoff < cfg.Backoff {
+		cfg.MaxBackoff = max(time.Hour, cfg.Backoff)
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = time.Minute
+	}
+	return &WebhookUseCase{hooks: hooks, deliveries: deliveries, sender: sender, cfg: cfg, log: l, now: time.Now}
+}
+
+// Create cadastra o webhook para a chave da requisição, com um segredo novo
+func (uc *WebhookUseCase) Create(ctx context.Context, rawURL string, eventos []string) (Webhook, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Webhook{}, ErrWebhookURL
+	}
+	if len(eventos) == 0 {
+		return Webhook{}, ErrWebhookEvent
+	}
+	for _, e := range eventos {
+		if !slices.Contains(webhookEvents, e) {
+			log.Warn("Tipo de evento de webhook inválido", "evento", e)
+			return Webhook{}, ErrWebhookEvent
+		}
+	}
+
+	id, err := randomHex(12)
+	if err != nil {
+		return Webhook{}, err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return Webhook{}, err
+	}
+	hook := Webhook{
+		ID:       id,
+		URL:      u.String(),
+		Eventos:  slices.Compact(slices.Sorted(slices.Values(eventos))),
+		Segredo:  webhookSecretPrefix + secret,
+		CriadoEm: uc.now().UTC(),
+	}
+	if key, ok := APIKeyFromContext(ctx); ok {
+		hook.APIKeyID = key.ID
+	}
+
+	if err := uc.hooks.SaveWebhook(hook); err != nil {
+		log.Error("Falha ao salvar webhook", "erro", err.Error())
+		return Webhook{}, err
+	}
+	log.Info("Webhook cadastrado", "webhook_id", hook.ID, "eventos", hook.Eventos)
+	return hook, nil
+}
+
+// List devolve os webhooks da chave da requisição; a chave admin vê todos
+func (uc *WebhookUseCase) List(ctx context.Context) ([]Webhook, error) {
+	hooks, err := uc.hooks.ListWebhooks(ownerOf(ctx))
+	if err != nil {
+		logger.FromContext(ctx, uc.log).Error("Falha ao listar webhooks", "erro", err.Error())
+		return nil, err
+	}
+	if hooks == nil {
+		hooks = []Webhook{}
+	}
+	return hooks, nil
+}
+
+// Delete remove o webhook. O de outra chave responde como inexistente. As
+// entregas pendentes dele vão para a fila de mortas quando chegar a vez delas.
+func (uc *WebhookUseCase) Delete(ctx context.Context, id string) error {
+	log := logger.FromContext(ctx, uc.log)
+
+	hook, err := uc.hooks.GetWebhook(id)
+	if err != nil {
+		return err
+	}
+	if owner := ownerOf(ctx); owner != "" && hook.APIKeyID != owner {
+		return ErrWebhookNotFound
+	}
+	if err := uc.hooks.DeleteWebhook(id); err != nil {
+		log.Error("Falha ao remover webhook", "erro", err.Error(), "webhook_id", id)
+		return err
+	}
+	log.Info("Webhook removido", "webhook_id", id)
+	return nil
+}
+
+// ownerOf é a chave cujos webhooks a requisição enxerga; vazio para admin
+func ownerOf(ctx context.Context) string {
+	key, ok := APIKeyFromContext(ctx)
+	if !ok || key.HasScope(ScopeAdmin) {
+		return ""
+	}
+	return key.ID
+}
+
+func (uc *WebhookUseCase) Name() string {
+	return "webhooks"
+}
+
+// Publish implementa EventSink: grava uma entrega pendente por webhook que
+// assina o tipo do evento; com APIKeyID, só pelos webhooks dessa chave. O envio fica com Dispatch, e um webhook fora do ar
+// não segura os demais nem o outbox.
+func (uc *WebhookUseCase) Publish(event DomainEvent) error {
+	hooks, err := uc.hooks.WebhooksFor(event.Tipo)
+	if err != nil {
+		return err
+	}
+	if event.APIKeyID != "" {
+		hooks = slices.DeleteFunc(hooks, func(w Webhook) bool { return w.APIKeyID != event.APIKeyID })
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	now := uc.now().UTC()
+	deliveries := make([]WebhookDelivery, len(hooks))
+	for i, hook := range hooks {
+		deliveries[i] = WebhookDelivery{
+			ID:               deliveryID(event.ID, hook.ID),
+			WebhookID:        hook.ID,
+			Evento:           event,
+			Status:           DeliveryPending,
+			ProximaTentativa: now,
+			CriadaEm:         now,
+		}
+	}
+	return uc.deliveries.SaveDeliveries(deliveries)
+}
+
+// deliveryID deriva o id do par evento e webhook, o que torna Publish idempotente
+func deliveryID(eventID, webhookID string) string {
+	sum := sha256.Sum256([]byte(eventID + ":" + webhookID))
+	return hex.EncodeToString(sum[:16])
+}
+
+// DispatchOnce envia uma rodada de entregas vencidas e devolve quantas foram aceitas
+func (uc *WebhookUseCase) DispatchOnce(ctx context.Context) (int, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	due, err := uc.deliveries.ClaimDeliveries(uc.now().UTC(), uc.cfg.Lease, uc.cfg.BatchSize)
+	if err != nil {
+		log.Error("Falha ao reservar entregas de webhook", "erro", err.Error())
+		return 0, err
+	}
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	var (
+		mu        sync.Mutex
+		delivered int
+		wg        sync.WaitGroup
+	)
+	queue := make(chan WebhookDelivery)
+	for range min(uc.cfg.Workers, len(due)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range queue {
+				if uc.deliver(ctx, d) {
+					mu.Lock()
+					delivered++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, d := range due {
+		// As não enviadas voltam para a fila quando o lease vencer
+		if ctx.Err() != nil {
+			break
+		}
+		queue <- d
+	}
+	close(queue)
+	wg.Wait()
+
+	return delivered, nil
+}
+
+// deliver envia uma entrega e registra o resultado; true se o receptor aceitou
+func (uc *WebhookUseCase) deliver(ctx context.Context, d WebhookDelivery) bool {
+	log := logger.FromContext(ctx, uc.log)
+
+	hook, err := uc.hooks.GetWebhook(d.WebhookID)
+	if errors.Is(err, ErrWebhookNotFound) {
+		d.Status = DeliveryDead
+		d.UltimoErro = ErrWebhookNotFound.Error()
+		uc.update(ctx, d)
+		return false
+	}
+	if err != nil {
+		// Problema nosso, não do receptor: tenta de novo quando o lease vencer
+		log.Error("Falha ao ler webhook da entrega", "erro", err.Error(), "entrega", d.ID)
+		return false
+	}
+
+	status := 0
+	req, err := signedRequest(*hook, d, uc.now())
+	if err == nil {
+		status, err = uc.sender.Send(req)
+	}
+	d.Tentativas++
+	d.UltimoStatusHTTP = status
+	if err == nil && (status < 200 || status > 299) {
+		err = fmt.Errorf("resposta %d", status)
+	}
+	if err == nil {
+		d.Status = DeliveryDelivered
+		d.EntregueEm = uc.now().UTC()
+		d.UltimoErro = ""
+		uc.update(ctx, d)
+		log.Debug("Webhook entregue", "entrega", d.ID, "webhook_id", hook.ID, "evento", d.Evento.Tipo)
+		return true
+	}
+
+	d.UltimoErro = err.Error()
+	if d.Tentativas >= uc.cfg.MaxAttempts {
+		d.Status = DeliveryDead
+		log.Warn("Entrega de webhook esgotou as tentativas e foi para a fila de mortas",
+			"entrega", d.ID,
+			"webhook_id", hook.ID,
+			"tentativas", d.Tentativas,
+			"erro", d.UltimoErro,
+		)
+	} else {
+		d.ProximaTentativa = uc.now().UTC().Add(uc.backoff(d.Tentativas))
+		log.Warn("Falha ao entregar webhook",
+			"entrega", d.ID,
+			"webhook_id", hook.ID,
+			"tentativas", d.Tentativas,
+			"proxima_tentativa", d.ProximaTentativa,
+			"erro", d.UltimoErro,
+		)
+	}
+	uc.update(ctx, d)
+	return false
+}
+
+func (uc *WebhookUseCase) update(ctx context.Context, d WebhookDelivery) {
+	if err := uc.deliveries.UpdateDelivery(d); err != nil {
+		// O lease vence e a entrega é enviada de novo: pelo menos uma vez
+		logger.FromContext(ctx, uc.log).Error("Falha ao registrar entrega de webhook", "erro", err.Error(), "entrega", d.ID)
+	}
+}
+
+func (uc *WebhookUseCase) backoff(tentativas int) time.Duration {
+	d := uc.cfg.Backoff
+	for i := 1; i < tentativas && d < uc.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, uc.cfg.MaxBackoff)
+}
+
+// Run envia as entregas vencidas a cada intervalo até o contexto ser
+// cancelado; uma rodada cheia emenda na seguinte, como no relay do outbox
+func (uc *WebhookUseCase) Run(ctx context.Context, every time.Duration) {
+	uc.log.Info("Envio de webhooks iniciado", "intervalo", every.String())
+
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		n, err := uc.DispatchOnce(ctx)
+		if err == nil && n == uc.cfg.BatchSize && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			uc.log.Info("Envio de webhooks encerrado")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Deliveries lista as entregas, as mais recentes primeiro (padrão 100, até 1000)
+func (uc *WebhookUseCase) Deliveries(ctx context.Context, filter DeliveryFilter) ([]WebhookDelivery, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 100
+	}
+	filter.Limit = min(filter.Limit, 1000)
+
+	deliveries, err := uc.deliveries.ListDeliveries(filter)
+	if err != nil {
+		logger.FromContext(ctx, uc.log).Error("Falha ao listar entregas de webhook", "erro", err.Error())
+		return nil, err
+	}
+	if deliveries == nil {
+		deliveries = []WebhookDelivery{}
+	}
+	return deliveries, nil
+}
+
+// Replay devolve à fila uma entrega morta ou já entregue, com as tentativas
+// zeradas e o mesmo id
+func (uc *WebhookUseCase) Replay(ctx context.Context, id string) (WebhookDelivery, error) {
+	log := logger.FromContext(ctx, uc.log)
+
+	d, err := uc.deliveries.GetDelivery(id)
+	if err != nil {
+		return WebhookDelivery{}, err
+	}
+	if d.Status == DeliveryPending {
+		return WebhookDelivery{}, ErrDeliveryPending
+	}
+	if _, err := uc.hooks.GetWebhook(d.WebhookID); err != nil {
+		return WebhookDelivery{}, err
+	}
+
+	d.Status = DeliveryPending
+	d.Tentativas = 0
+	d.ProximaTentativa = uc.now().UTC()
+	d.EntregueEm = time.Time{}
+	if err := uc.deliveries.UpdateDelivery(*d); err != nil {
+		log.Error("Falha ao reenfileirar entrega de webhook", "erro", err.Error(), "entrega", id)
+		return WebhookDelivery{}, err
+	}
+	log.Info("Entrega de webhook reenfileirada", "entrega", id, "webhook_id", d.WebhookID)
+	return *d, nil
+}
+
+// signedRequest monta o POST da entrega: o corpo é o evento e a assinatura
+// cobre o horário e o corpo
+func signedRequest(hook Webhook, d WebhookDelivery, now time.Time) (WebhookRequest, error) {
+	body, err := json.Marshal(d.Evento)
+	if err != nil {
+		return WebhookRequest{}, err
+	}
+	timestamp := now.Unix()
+	return WebhookRequest{
+		URL: hook.URL,
+		Headers: map[string]string{
+			"Content-Type":         "application/json",
+			WebhookIDHeader:        d.ID,
+			WebhookEventHeader:     d.Evento.Tipo,
+			WebhookTimestampHeader: strconv.FormatInt(timestamp, 10),
+			WebhookSignatureHeader: SignWebhook(hook.Segredo, timestamp, body),
+		},
+		Body: body,
+	}, nil
+}
+
+// SignWebhook é o valor de X-Webhook-Signature: HMAC-SHA256 de
+// "<timestamp>.<corpo>" com o segredo do webhook, em hexadecimal
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature é o que o receptor faz com uma entrega: confere a
+// assinatura e recusa horários fora da tolerância, o que barra reenvios de
+// uma entrega capturada
+func VerifyWebhookSignature(secret, timestamp, signature string, body []byte, tolerance time.Duration, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrWebhookTimestamp
+	}
+	if d := now.Sub(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+		return ErrWebhookTimestamp
+	}
+	if !hmac.Equal([]byte(signature), []byte(SignWebhook(secret, ts, body))) {
+		return ErrWebhookSignature
+	}
+	return nil
+}
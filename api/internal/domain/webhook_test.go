@@ -0,0 +1,443 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryWebhookStoreFake guarda webhooks e entregas como as duas coleções do Mongo
+type memoryWebhookStoreFake struct {
+	mu         sync.Mutex
+	hooks      map[string]Webhook
+	deliveries map[string]WebhookDelivery
+}
+
+func newMemoryWebhookStoreFake() *memoryWebhookStoreFake {
+	return &memoryWebhookStoreFake{hooks: map[string]Webhook{}, deliveries: map[string]WebhookDelivery{}}
+}
+
+func (f *memoryWebhookStoreFake) SaveWebhook(w Webhook) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hooks[w.ID] = w
+	return nil
+}
+
+func (f *memoryWebhookStoreFake) GetWebhook(id string) (*Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w, ok := f.hooks[id]
+	if !ok {
+		return nil, ErrWebhookNotFound
+	}
+	return &w, nil
+}
+
+func (f *memoryWebhookStoreFake) ListWebhooks(apiKeyID string) ([]Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []Webhook
+	for _, w := range f.hooks {
+		if apiKeyID == "" || w.APIKeyID == apiKeyID {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+func (f *memoryWebhookStoreFake) DeleteWebhook(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.hooks[id]; !ok {
+		return ErrWebhookNotFound
+	}
+	delete(f.hooks, id)
+	return nil
+}
+
+func (f *memoryWebhookStoreFake) WebhooksFor(tipo string) ([]Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []Webhook
+	for _, w := range f.hooks {
+		if slices.Contains(w.Eventos, tipo) {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+func (f *memoryWebhookStoreFake) SaveDeliveries(deliveries []WebhookDelivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, d := range deliveries {
+		if _, ok := f.deliveries[d.ID]; !ok {
+			f.deliveries[d.ID] = d
+		}
+	}
+	return nil
+}
+
+func (f *memoryWebhookStoreFake) ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []WebhookDelivery
+	for id, d := range f.deliveries {
+		if len(out) == limit {
+			break
+		}
+		if d.Status == DeliveryPending && !d.ProximaTentativa.After(now) {
+			out = append(out, d)
+			d.ProximaTentativa = now.Add(lease)
+			f.deliveries[id] = d
+		}
+	}
+	return out, nil
+}
+
+func (f *memoryWebhookStoreFake) UpdateDelivery(d WebhookDelivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deliveries[d.ID] = d
+	return nil
+}
+
+func (f *memoryWebhookStoreFake) GetDelivery(id string) (*WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d, ok := f.deliveries[id]
+	if !ok {
+		return nil, ErrDeliveryNotFound
+	}
+	return &d, nil
+}
+
+func (f *memoryWebhookStoreFake) ListDeliveries(filter DeliveryFilter) ([]WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []WebhookDelivery
+	for _, d := range f.deliveries {
+		if (filter.Status == "" || d.Status == filter.Status) && (filter.WebhookID == "" || d.WebhookID == filter.WebhookID) {
+			out = append(out, d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// only devolve a única entrega gravada
+func (f *memoryWebhookStoreFake) only(t *testing.T) WebhookDelivery {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	require.Len(t, f.deliveries, 1)
+	for _, d := range f.deliveries {
+		return d
+	}
+	return WebhookDelivery{}
+}
+
+// webhookSenderFake responde com os status da fila, um por envio (o último se repete)
+type webhookSenderFake struct {
+	mu       sync.Mutex
+	statuses []int
+	sent     []WebhookRequest
+}
+
+func (s *webhookSenderFake) Send(req WebhookRequest) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, req)
+	status := s.statuses[0]
+	if len(s.statuses) > 1 {
+		s.statuses = s.statuses[1:]
+	}
+	if status == 0 {
+		return 0, errors.New("conexão recusada")
+	}
+	return status, nil
+}
+
+func newWebhookFixture(cfg WebhookConfig, statuses ...int) (*WebhookUseCase, *memoryWebhookStoreFake, *webhookSenderFake, *time.Time) {
+	store := newMemoryWebhookStoreFake()
+	sender := &webhookSenderFake{statuses: statuses}
+	uc := NewWebhookUseCase(store, store, sender, cfg, newOutboxLogger())
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+	return uc, store, sender, &now
+}
+
+func keyContext(id string, scopes ...string) context.Context {
+	return ContextWithAPIKey(context.Background(), &APIKey{ID: id, Scopes: scopes})
+}
+
+func TestWebhooks(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should reject invalid url or events",
+			run:  shouldRejectInvalidURLOrEvents,
+		},
+		{
+			name: "should show webhooks only to their key",
+			run:  shouldShowWebhooksOnlyToTheirKey,
+		},
+		{
+			name: "should fan out one delivery per subscriber once",
+			run:  shouldFanOutOneDeliveryPerSubscriberOnce,
+		},
+		{
+			name: "should deliver key scoped event only to that key",
+			run:  shouldDeliverKeyScopedEventOnlyToThatKey,
+		},
+		{
+			name: "should sign delivery with timestamp",
+			run:  shouldSignDeliveryWithTimestamp,
+		},
+		{
+			name: "should retry with backoff and dead letter after max attempts",
+			run:  shouldRetryWithBackoffAndDeadLetterAfterMaxAttempts,
+		},
+		{
+			name: "should dead letter deliveries of removed webhook",
+			run:  shouldDeadLetterDeliveriesOfRemovedWebhook,
+		},
+		{
+			name: "should replay dead delivery with the same id",
+			run:  shouldReplayDeadDeliveryWithTheSameID,
+		},
+		{
+			name: "should reject tampered or stale signature",
+			run:  shouldRejectTamperedOrStaleSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldRejectInvalidURLOrEvents(t *testing.T) {
+	uc, store, _, _ := newWebhookFixture(WebhookConfig{}, 200)
+	ctx := keyContext("k1", ScopeHistoryRead)
+
+	for _, url := range []string{"", "ftp://exemplo.com/hook", "/hook", "https://"} {
+		_, err := uc.Create(ctx, url, []string{EventConversionCreated})
+		assert.ErrorIs(t, err, ErrWebhookURL, url)
+	}
+	_, err := uc.Create(ctx, "https://exemplo.com/hook", nil)
+	assert.ErrorIs(t, err, ErrWebhookEvent)
+	_, err = uc.Create(ctx, "https://exemplo.com/hook", []string{"conversion.deleted"})
+	assert.ErrorIs(t, err, ErrWebhookEvent)
+	assert.Empty(t, store.hooks)
+}
+
+func shouldShowWebhooksOnlyToTheirKey(t *testing.T) {
+	uc, _, _, _ := newWebhookFixture(WebhookConfig{}, 200)
+
+	hook, err := uc.Create(keyContext("k1", ScopeHistoryRead), "https://a.exemplo.com/hook", []string{EventProviderDown, EventConversionCreated, EventProviderDown})
+	require.NoError(t, err)
+	assert.Equal(t, "k1", hook.APIKeyID)
+	assert.Equal(t, []string{EventConversionCreated, EventProviderDown}, hook.Eventos)
+	assert.Regexp(t, `^whsec_[0-9a-f]{48}$`, hook.Segredo)
+	_, err = uc.Create(keyContext("k2", ScopeHistoryRead), "https://b.exemplo.com/hook", []string{EventConversionCreated})
+	require.NoError(t, err)
+
+	mine, err := uc.List(keyContext("k1", ScopeHistoryRead))
+	require.NoError(t, err)
+	assert.Len(t, mine, 1)
+	all, err := uc.List(keyContext("adm", ScopeAdmin))
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	// Outra chave não enxerga nem remove
+	assert.ErrorIs(t, uc.Delete(keyContext("k2", ScopeHistoryRead), hook.ID), ErrWebhookNotFound)
+	require.NoError(t, uc.Delete(keyContext("k1", ScopeHistoryRead), hook.ID))
+}
+
+func shouldFanOutOneDeliveryPerSubscriberOnce(t *testing.T) {
+	uc, store, _, _ := newWebhookFixture(WebhookConfig{}, 200)
+	ctx := keyContext("k1", ScopeHistoryRead)
+	_, err := uc.Create(ctx, "https://a.exemplo.com/hook", []string{EventConversionCreated})
+	require.NoError(t, err)
+	_, err = uc.Create(ctx, "https://b.exemplo.com/hook", []string{EventConversionCreated, EventProviderDown})
+	require.NoError(t, err)
+	_, err = uc.Create(ctx, "https://c.exemplo.com/hook", []string{EventProviderDown})
+	require.NoError(t, err)
+
+	event, err := NewConversionCreated(conversion("USD"))
+	require.NoError(t, err)
+	require.NoError(t, uc.Publish(event))
+	// O relay do outbox repete o evento quando falha ao marcá-lo como publicado
+	require.NoError(t, uc.Publish(event))
+
+	assert.Len(t, store.deliveries, 2)
+	for _, d := range store.deliveries {
+		assert.Equal(t, DeliveryPending, d.Status)
+		assert.Equal(t, event.ID, d.Evento.ID)
+	}
+}
+
+func shouldDeliverKeyScopedEventOnlyToThatKey(t *testing.T) {
+	uc, store, _, _ := newWebhookFixture(WebhookConfig{}, 200)
+	mine, err := uc.Create(keyContext("k1", ScopeHistoryRead), "https://a.exemplo.com/hook", []string{EventRateAlertTriggered})
+	require.NoError(t, err)
+	_, err = uc.Create(keyContext("k2", ScopeHistoryRead), "https://b.exemplo.com/hook", []string{EventRateAlertTriggered})
+	require.NoError(t, err)
+
+	require.NoError(t, uc.Publish(DomainEvent{ID: "e1", Tipo: EventRateAlertTriggered, Chave: "USD", APIKeyID: "k1", Dados: []byte(`{}`)}))
+
+	require.Len(t, store.deliveries, 1)
+	assert.Equal(t, mine.ID, store.deliveries[deliveryID("e1", mine.ID)].WebhookID)
+}
+
+func shouldSignDeliveryWithTimestamp(t *testing.T) {
+	uc, store, sender, now := newWebhookFixture(WebhookConfig{}, 204)
+	hook, err := uc.Create(keyContext("k1", ScopeHistoryRead), "https://a.exemplo.com/hook", []string{EventConversionCreated})
+	require.NoError(t, err)
+	event, err := NewConversionCreated(conversion("EUR"))
+	require.NoError(t, err)
+	require.NoError(t, uc.Publish(event))
+
+	n, err := uc.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	require.Len(t, sender.sent, 1)
+	req := sender.sent[0]
+	delivery := store.only(t)
+	assert.Equal(t, "https://a.exemplo.com/hook", req.URL)
+	assert.Equal(t, delivery.ID, req.Headers[WebhookIDHeader])
+	assert.Equal(t, EventConversionCreated, req.Headers[WebhookEventHeader])
+	assert.Equal(t, strconv.FormatInt(now.Unix(), 10), req.Headers[WebhookTimestampHeader])
+	assert.NoError(t, VerifyWebhookSignature(hook.Segredo, req.Headers[WebhookTimestampHeader], req.Headers[WebhookSignatureHeader], req.Body, 5*time.Minute, *now))
+
+	var body DomainEvent
+	require.NoError(t, json.Unmarshal(req.Body, &body))
+	assert.Equal(t, event.ID, body.ID)
+
+	assert.Equal(t, DeliveryDelivered, delivery.Status)
+	assert.Equal(t, 1, delivery.Tentativas)
+	assert.Equal(t, 204, delivery.UltimoStatusHTTP)
+	assert.Equal(t, *now, delivery.EntregueEm)
+}
+
+func shouldRetryWithBackoffAndDeadLetterAfterMaxAttempts(t *testing.T) {
+	uc, store, sender, now := newWebhookFixture(WebhookConfig{MaxAttempts: 3, Backoff: time.Second}, 500, 0, 503)
+	_, err := uc.Create(keyContext("k1", ScopeHistoryRead), "https://a.exemplo.com/hook", []string{EventProviderDown})
+	require.NoError(t, err)
+	require.NoError(t, uc.Publish(DomainEvent{ID: "e1", Tipo: EventProviderDown, Chave: "awesomeapi", Dados: json.RawMessage(`{}`)}))
+
+	_, err = uc.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	d := store.only(t)
+	assert.Equal(t, DeliveryPending, d.Status)
+	assert.Equal(t, 500, d.UltimoStatusHTTP)
+	assert.Equal(t, "resposta 500", d.UltimoErro)
+	assert.Equal(t, now.Add(time.Second), d.ProximaTentativa)
+
+	// Antes do prazo nada sai
+	_, err = uc.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, sender.sent, 1)
+
+	*now = now.Add(time.Second)
+	_, err = uc.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	d = store.only(t)
+	assert.Equal(t, "conexão recusada", d.UltimoErro)
+	assert.Equal(t, now.Add(2*time.Second), d.ProximaTentativa)
+
+	*now = now.Add(2 * time.Second)
+	_, err = uc.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	d = store.only(t)
+	assert.Equal(t, DeliveryDead, d.Status)
+	assert.Equal(t, 3, d.Tentativas)
+
+	// Na fila de mortas não há nova tentativa
+	*now = now.Add(time.Hour)
+	_, err = uc.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, sender.sent, 3)
+
+	dead, err := uc.Deliveries(context.Background(), DeliveryFilter{Status: DeliveryDead})
+	require.NoError(t, err)
+	assert.Len(t, dead, 1)
+}
+
+func shouldDeadLetterDeliveriesOfRemovedWebhook(t *testing.T) {
+	uc, store, sender, _ := newWebhookFixture(WebhookConfig{}, 200)
+	ctx := keyContext("k1", ScopeHistoryRead)
+	hook, err := uc.Create(ctx, "https://a.exemplo.com/hook", []string{EventProviderDown})
+	require.NoError(t, err)
+	require.NoError(t, uc.Publish(DomainEvent{ID: "e1", Tipo: EventProviderDown, Dados: json.RawMessage(`{}`)}))
+	require.NoError(t, uc.Delete(ctx, hook.ID))
+
+	_, err = uc.DispatchOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Empty(t, sender.sent)
+	d := store.only(t)
+	assert.Equal(t, DeliveryDead, d.Status)
+	assert.Equal(t, ErrWebhookNotFound.Error(), d.UltimoErro)
+
+	_, err = uc.Replay(context.Background(), d.ID)
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+}
+
+func shouldReplayDeadDeliveryWithTheSameID(t *testing.T) {
+	uc, store, sender, now := newWebhookFixture(WebhookConfig{MaxAttempts: 1}, 410, 200)
+	_, err := uc.Create(keyContext("k1", ScopeHistoryRead), "https://a.exemplo.com/hook", []string{EventProviderDown})
+	require.NoError(t, err)
+	require.NoError(t, uc.Publish(DomainEvent{ID: "e1", Tipo: EventProviderDown, Dados: json.RawMessage(`{}`)}))
+
+	_, err = uc.Replay(context.Background(), store.only(t).ID)
+	assert.ErrorIs(t, err, ErrDeliveryPending)
+	_, err = uc.Replay(context.Background(), "nao-existe")
+	assert.ErrorIs(t, err, ErrDeliveryNotFound)
+
+	_, err = uc.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	dead := store.only(t)
+	require.Equal(t, DeliveryDead, dead.Status)
+
+	*now = now.Add(time.Hour)
+	replayed, err := uc.Replay(context.Background(), dead.ID)
+	require.NoError(t, err)
+	assert.Equal(t, DeliveryPending, replayed.Status)
+	assert.Zero(t, replayed.Tentativas)
+	assert.Equal(t, *now, replayed.ProximaTentativa)
+
+	n, err := uc.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Len(t, sender.sent, 2)
+	assert.Equal(t, sender.sent[0].Headers[WebhookIDHeader], sender.sent[1].Headers[WebhookIDHeader])
+	assert.Equal(t, DeliveryDelivered, store.only(t).Status)
+}
+
+func shouldRejectTamperedOrStaleSignature(t *testing.T) {
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"id":"e1"}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	signature := SignWebhook("whsec_x", now.Unix(), body)
+
+	assert.NoError(t, VerifyWebhookSignature("whsec_x", ts, signature, body, time.Minute, now))
+	assert.ErrorIs(t, VerifyWebhookSignature("whsec_y", ts, signature, body, time.Minute, now), ErrWebhookSignature)
+	assert.ErrorIs(t, VerifyWebhookSignature("whsec_x", ts, signature, []byte(`{"id":"e2"}`), time.Minute, now), ErrWebhookSignature)
+	assert.ErrorIs(t, VerifyWebhookSignature("whsec_x", ts, signature, body, time.Minute, now.Add(2*time.Minute)), ErrWebhookTimestamp)
+	assert.ErrorIs(t, VerifyWebhookSignature("whsec_x", "ontem", signature, body, time.Minute, now), ErrWebhookTimestamp)
+}
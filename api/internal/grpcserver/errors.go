@@ -0,0 +1,81 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/internal/transport"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RetryAfterKey é o trailer com os segundos a esperar, equivalente ao Retry-After do REST
+const RetryAfterKey = "retry-after"
+
+// Tradução dos erros do domínio para códigos gRPC
+var errorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{domain.ErrCurrencyNotFound, codes.NotFound},
+	{domain.ErrAPIKeyNotFound, codes.NotFound},
+	{domain.ErrInvalidPeriod, codes.InvalidArgument},
+	{domain.ErrAsOfInFuture, codes.InvalidArgument},
+	{domain.ErrRateNotAvailable, codes.NotFound},
+	{domain.ErrInvalidScope, codes.InvalidArgument},
+	{domain.ErrAPIKeyName, codes.InvalidArgument},
+	{domain.ErrInvalidAPIKey, codes.Unauthenticated},
+	{domain.ErrQuotaExceeded, codes.ResourceExhausted},
+	{domain.ErrUpstreamBudgetExhausted, codes.Unavailable},
+	{domain.ErrZeroRate, codes.Unavailable},
+	{domain.ErrRateOutlier, codes.Unavailable},
+	{domain.ErrStaleRate, codes.Unavailable},
+	{domain.ErrRateMismatch, codes.Unavailable},
+	{domain.ErrCurrencyNotWatched, codes.InvalidArgument},
+	{domain.ErrSlowConsumer, codes.Unavailable},
+	{domain.ErrBroadcasterStopped, codes.Unavailable},
+	{domain.ErrSaveConversion, codes.Internal},
+	{context.Canceled, codes.Canceled},
+	{context.DeadlineExceeded, codes.DeadlineExceeded},
+}
+
+func knownError(err error) bool {
+	if _, ok := status.FromError(err); ok {
+		return true
+	}
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			return true
+		}
+	}
+	return false
+}
+
+// toStatus converte um erro qualquer em erro de status gRPC. Erros que não
+// vêm do domínio viram Internal sem expor detalhes ao cliente.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			return status.Error(e.code, err.Error())
+		}
+	}
+	return status.Error(codes.Internal, "erro interno no servidor")
+}
+
+// retryAfterTrailer devolve o trailer retry-after quando o erro indica quanto esperar
+func retryAfterTrailer(err error) (metadata.MD, bool) {
+	var ra *domain.RetryAfterError
+	if !errors.As(err, &ra) {
+		return nil, false
+	}
+	return metadata.Pairs(RetryAfterKey, transport.CeilSeconds(ra.After)), true
+}
@@ -0,0 +1,51 @@
+package grpcserver
+
+import (
+	fretev1 "go-frete/api/gen/frete/v1"
+	"go-frete/api/internal/domain"
+	"go-frete/api/pkg/logger"
+	"go-frete/api/pkg/ratelimit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// MethodScopes define o escopo exigido por método, igual às rotas REST equivalentes
+var MethodScopes = map[string]string{
+	fretev1.ConverterService_Convert_FullMethodName:         domain.ScopeConvertWrite,
+	fretev1.ConverterService_ListConversions_FullMethodName: domain.ScopeHistoryRead,
+	fretev1.ConverterService_GetVariation_FullMethodName:    domain.ScopeHistoryRead,
+	fretev1.ConverterService_WatchRates_FullMethodName:      domain.ScopeHistoryRead,
+}
+
+// New monta o servidor gRPC com os interceptors (o primeiro é o mais externo),
+// o serviço de conversão, o health check padrão e a reflection para o grpcurl
+func New(svc *Server, auth *domain.APIKeyUseCase, quotas *domain.QuotaUseCase, limiter *ratelimit.Limiter, l logger.Logger) *grpc.Server {
+	hooks := []Hook{
+		Authenticate(auth, MethodScopes, l),
+		RateLimit(limiter, quotas, l),
+	}
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			UnaryLogging(l),
+			UnaryRecoverer(l),
+			UnaryErrors(),
+			UnaryHooks(hooks...),
+			UnaryEnforceQuota(quotas, fretev1.ConverterService_Convert_FullMethodName),
+		),
+		grpc.ChainStreamInterceptor(
+			StreamLogging(l),
+			StreamRecoverer(l),
+			StreamErrors(),
+			StreamHooks(hooks...),
+		),
+	)
+
+	fretev1.RegisterConverterServiceServer(srv, svc)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	reflection.Register(srv)
+	return srv
+}
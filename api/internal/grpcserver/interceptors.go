@@ -0,0 +1,300 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/internal/transport"
+	"go-frete/api/pkg/logger"
+	"go-frete/api/pkg/ratelimit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Chaves de metadata aceitas/devolvidas, equivalentes aos cabeçalhos do REST
+const (
+	RequestIDKey = "x-request-id"
+	APIKeyKey    = "x-api-key"
+)
+
+// Hook roda antes do método e pode enriquecer o contexto ou barrar a chamada
+type Hook func(ctx context.Context, fullMethod string) (context.Context, error)
+
+// UnaryHooks aplica os hooks em ordem antes de cada chamada unária
+func UnaryHooks(hooks ...Hook) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := runHooks(ctx, info.FullMethod, hooks)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamHooks aplica os hooks em ordem antes de abrir cada stream
+func StreamHooks(hooks ...Hook) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := runHooks(ss.Context(), info.FullMethod, hooks)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func runHooks(ctx context.Context, method string, hooks []Hook) (context.Context, error) {
+	for _, h := range hooks {
+		var err error
+		if ctx, err = h(ctx, method); err != nil {
+			return ctx, err
+		}
+	}
+	return ctx, nil
+}
+
+// serverStream troca o contexto de um stream já aberto
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryLogging reaproveita (ou gera) o x-request-id, devolve-o no header e
+// registra uma linha por chamada com método, código e duração
+func UnaryLogging(l logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx = withRequestID(ctx)
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		logCall(ctx, l, info.FullMethod, err, start)
+		return resp, err
+	}
+}
+
+func StreamLogging(l logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := withRequestID(ss.Context())
+		start := time.Now()
+
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+
+		logCall(ctx, l, info.FullMethod, err, start)
+		return err
+	}
+}
+
+func withRequestID(ctx context.Context) context.Context {
+	id := firstMetadata(ctx, RequestIDKey)
+	if !transport.ValidRequestID(id) {
+		id = transport.NewRequestID()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, id))
+	return logger.WithContext(ctx, "request_id", id)
+}
+
+func logCall(ctx context.Context, l logger.Logger, method string, err error, start time.Time) {
+	remote := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		remote = p.Addr.String()
+	}
+	logger.FromContext(ctx, l).Info("Chamada gRPC atendida",
+		"metodo", method,
+		"codigo", status.Code(err).String(),
+		"duracao_ms", float64(time.Since(start).Microseconds())/1000,
+		"remote_addr", remote,
+	)
+}
+
+// UnaryRecoverer captura pânicos do método, registra a stack e devolve Internal
+func UnaryRecoverer(l logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer recoverPanic(ctx, l, info.FullMethod, &err)
+		return handler(ctx, req)
+	}
+}
+
+func StreamRecoverer(l logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer recoverPanic(ss.Context(), l, info.FullMethod, &err)
+		return handler(srv, ss)
+	}
+}
+
+func recoverPanic(ctx context.Context, l logger.Logger, method string, err *error) {
+	rec := recover()
+	if rec == nil {
+		return
+	}
+	logger.FromContext(ctx, l).Error("Recuperado de pânico",
+		"detalhe", rec,
+		"metodo", method,
+		"stack", string(debug.Stack()),
+	)
+	*err = status.Error(codes.Internal, "erro interno no servidor")
+}
+
+// UnaryErrors traduz os erros do domínio para códigos gRPC
+func UnaryErrors() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		return resp, mapError(ctx, err)
+	}
+}
+
+func StreamErrors() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return mapError(ss.Context(), handler(srv, ss))
+	}
+}
+
+func mapError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if md, ok := retryAfterTrailer(err); ok {
+		grpc.SetTrailer(ctx, md)
+	}
+	return toStatus(err)
+}
+
+// Authenticate resolve a chave enviada no metadata e exige o escopo do método.
+// Métodos fora de scopes (health, reflection) aceitam chamadas anônimas.
+func Authenticate(auth *domain.APIKeyUseCase, scopes map[string]string, l logger.Logger) Hook {
+	return func(ctx context.Context, method string) (context.Context, error) {
+		if plaintext := apiKeyFromMetadata(ctx); plaintext != "" {
+			key, err := auth.Authenticate(ctx, plaintext)
+			if err != nil {
+				if errors.Is(err, domain.ErrInvalidAPIKey) {
+					logger.FromContext(ctx, l).Warn("Chave de API inválida")
+					return ctx, status.Error(codes.Unauthenticated, "Chave de API ausente ou inválida")
+				}
+				return ctx, status.Error(codes.Internal, "Erro ao validar credenciais")
+			}
+			ctx = domain.ContextWithAPIKey(ctx, key)
+			ctx = logger.WithContext(ctx, "api_key_id", key.ID)
+		}
+
+		scope, protected := scopes[method]
+		if !protected {
+			return ctx, nil
+		}
+		key, ok := domain.APIKeyFromContext(ctx)
+		if !ok {
+			return ctx, status.Error(codes.Unauthenticated, "Chave de API ausente ou inválida")
+		}
+		if !key.HasScope(scope) {
+			return ctx, status.Error(codes.PermissionDenied, "Chave de API sem o escopo "+scope)
+		}
+		return ctx, nil
+	}
+}
+
+// RateLimit aplica o token bucket do plano do cliente. Recebe o mesmo Limiter
+// do REST, então os dois protocolos dividem o mesmo limite por chave.
+func RateLimit(limiter *ratelimit.Limiter, quotas *domain.QuotaUseCase, l logger.Logger) Hook {
+	return func(ctx context.Context, method string) (context.Context, error) {
+		key, _ := domain.APIKeyFromContext(ctx)
+		plan := quotas.PlanFor(key)
+		if plan.RequestsPerMinute <= 0 {
+			return ctx, nil
+		}
+
+		res := limiter.Take(clientID(ctx, key), float64(plan.RequestsPerMinute)/60, plan.Burst, time.Now())
+		grpc.SetHeader(ctx, metadata.Pairs(
+			"ratelimit-limit", strconv.Itoa(res.Limit),
+			"ratelimit-remaining", strconv.Itoa(res.Remaining),
+		))
+		if !res.Allowed {
+			logger.FromContext(ctx, l).Warn("Limite de requisições excedido", "plano", plan.Name)
+			grpc.SetTrailer(ctx, metadata.Pairs(RetryAfterKey, transport.CeilSeconds(res.RetryAfter)))
+			return ctx, status.Error(codes.ResourceExhausted, "Limite de requisições excedido")
+		}
+		return ctx, nil
+	}
+}
+
+// UnaryEnforceQuota consome a cota diária de conversões nos métodos informados
+// e a devolve quando o método falha. Precisa ficar depois dos hooks, que
+// colocam a chave no contexto
+func UnaryEnforceQuota(quotas *domain.QuotaUseCase, methods ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !slices.Contains(methods, info.FullMethod) {
+			return handler(ctx, req)
+		}
+		key, _ := domain.APIKeyFromContext(ctx)
+
+		quota, err := quotas.Consume(ctx, key)
+		if quota.Limit > 0 {
+			grpc.SetHeader(ctx, metadata.Pairs(
+				"x-quota-limit", strconv.Itoa(quota.Limit),
+				"x-quota-remaining", strconv.Itoa(quota.Remaining()),
+			))
+		}
+		if err != nil {
+			if errors.Is(err, domain.ErrQuotaExceeded) {
+				grpc.SetTrailer(ctx, metadata.Pairs(RetryAfterKey, transport.CeilSeconds(time.Until(quota.Reset))))
+				return nil, status.Error(codes.ResourceExhausted, err.Error())
+			}
+			return nil, status.Error(codes.Internal, "Erro ao verificar cota")
+		}
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			quotas.Refund(ctx, key, quota)
+		}
+		return resp, err
+	}
+}
+
+func apiKeyFromMetadata(ctx context.Context) string {
+	if key := firstMetadata(ctx, APIKeyKey); key != "" {
+		return key
+	}
+	auth := firstMetadata(ctx, "authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func clientID(ctx context.Context, key *domain.APIKey) string {
+	if key != nil {
+		return "key:" + key.ID
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "ip:"
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	return "ip:" + host
+}
@@ -0,0 +1,205 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	fretev1 "go-frete/api/gen/frete/v1"
+	"go-frete/api/internal/domain"
+	"go-frete/api/pkg/logger"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Limites do WatchRates: moedas por stream e eventos pendentes antes de o
+// cliente ser considerado lento
+const (
+	MaxWatchedCurrencies = 20
+	watchBuffer          = 64
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Za-z]{3}$`)
+
+// Server implementa o ConverterService com os mesmos casos de uso do REST
+type Server struct {
+	fretev1.UnimplementedConverterServiceServer
+
+	converterUseCase *domain.ConverterUseCase
+	listUseCase      *domain.ListConversionsUseCase
+	variationUseCase *domain.VariationUseCase
+	rates            *domain.RateBroadcaster
+	log              logger.Logger
+}
+
+func NewServer(uc *domain.ConverterUseCase, luc *domain.ListConversionsUseCase, vuc *domain.VariationUseCase, rates *domain.RateBroadcaster, l logger.Logger) *Server {
+	return &Server{
+		converterUseCase: uc,
+		listUseCase:      luc,
+		variationUseCase: vuc,
+		rates:            rates,
+		log:              l,
+	}
+}
+
+func (s *Server) Convert(ctx context.Context, req *fretev1.ConvertRequest) (*fretev1.ConvertResponse, error) {
+	if !currencyPattern.MatchString(req.GetMoeda()) {
+		return nil, status.Error(codes.InvalidArgument, "moeda deve ter 3 letras (ex: USD)")
+	}
+	if req.GetValorBrl() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "valor_brl deve ser maior que zero")
+	}
+
+	var (
+		record domain.ConversionRecord
+		err    error
+	)
+	if req.GetAsOf() != nil {
+		record, err = s.converterUseCase.ConvertAsOf(ctx, req.GetMoeda(), req.GetValorBrl(), req.GetAsOf().AsTime())
+	} else {
+		record, err = s.converterUseCase.Convert(ctx, req.GetMoeda(), req.GetValorBrl())
+	}
+	if err != nil {
+		return nil, upstreamError(err)
+	}
+
+	return &fretev1.ConvertResponse{Conversion: toConversion(record)}, nil
+}
+
+func (s *Server) ListConversions(ctx context.Context, req *fretev1.ListConversionsRequest) (*fretev1.ListConversionsResponse, error) {
+	if req.GetLimit() < 0 || req.GetOffset() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit e offset não podem ser negativos")
+	}
+
+	filter := domain.ConversionFilter{
+		Currency: strings.ToUpper(req.GetCurrency()),
+		Limit:    int(req.GetLimit()),
+		Offset:   int(req.GetOffset()),
+	}
+	if req.GetFrom() != nil {
+		filter.From = req.GetFrom().AsTime()
+	}
+	if req.GetTo() != nil {
+		filter.To = req.GetTo().AsTime()
+	}
+	filter.Retroativa = req.AsOf
+
+	page, err := s.listUseCase.Search(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &fretev1.ListConversionsResponse{
+		Conversions: make([]*fretev1.Conversion, 0, len(page.Records)),
+		Limit:       int32(page.Limit),
+		Offset:      int32(page.Offset),
+		HasMore:     page.HasMore,
+	}
+	for _, record := range page.Records {
+		resp.Conversions = append(resp.Conversions, toConversion(record))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetVariation(ctx context.Context, req *fretev1.GetVariationRequest) (*fretev1.GetVariationResponse, error) {
+	if !currencyPattern.MatchString(req.GetMoeda()) {
+		return nil, status.Error(codes.InvalidArgument, "moeda deve ter 3 letras (ex: USD)")
+	}
+
+	variations, err := s.variationUseCase.Execute(ctx, req.GetMoeda())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &fretev1.GetVariationResponse{Variations: make([]*fretev1.Variation, 0, len(variations))}
+	for _, v := range variations {
+		resp.Variations = append(resp.Variations, &fretev1.Variation{
+			Data:               timestamppb.New(v.Data),
+			Cotacao:            v.Cotacao,
+			VariacaoValor:      v.VariacaoValor,
+			VariacaoPercentual: v.VariacaoPercentual,
+		})
+	}
+	return resp, nil
+}
+
+// WatchRates envia a cotação atual de cada moeda e depois apenas as mudanças.
+// Os eventos vêm da mesma transmissão do SSE e do WebSocket, então um stream a
+// mais não gera nenhuma consulta ao provedor.
+func (s *Server) WatchRates(req *fretev1.WatchRatesRequest, stream fretev1.ConverterService_WatchRatesServer) error {
+	ctx := stream.Context()
+	log := logger.FromContext(ctx, s.log)
+
+	moedas := req.GetMoedas()
+	if len(moedas) == 0 || len(moedas) > MaxWatchedCurrencies {
+		return status.Errorf(codes.InvalidArgument, "informe de 1 a %d moedas", MaxWatchedCurrencies)
+	}
+	for _, m := range moedas {
+		if !currencyPattern.MatchString(m) {
+			return status.Errorf(codes.InvalidArgument, "moeda inválida: %q", m)
+		}
+	}
+
+	sub, err := s.rates.Subscribe(moedas, 0, watchBuffer)
+	if err != nil {
+		log.Warn("Assinatura de cotações recusada", "erro", err.Error())
+		return err
+	}
+	defer sub.Close()
+
+	log.Info("Iniciando acompanhamento de cotações", "moedas", moedas)
+
+	for {
+		select {
+		case ev := <-sub.Events():
+			if err := stream.Send(&fretev1.RateUpdate{
+				Moeda:   ev.Moeda,
+				Cotacao: ev.Cotacao,
+				Fonte:   ev.Fonte,
+				Data:    timestamppb.New(ev.Data),
+			}); err != nil {
+				return err
+			}
+		case <-sub.Done():
+			if errors.Is(sub.Err(), domain.ErrSlowConsumer) {
+				log.Warn("Cliente lento desconectado do acompanhamento de cotações")
+			}
+			return sub.Err()
+		case <-ctx.Done():
+			log.Info("Acompanhamento de cotações encerrado pelo cliente")
+			return ctx.Err()
+		}
+	}
+}
+
+// upstreamError trata como indisponibilidade do provedor os erros que o domínio
+// não conhece, como o REST faz com o 502
+func upstreamError(err error) error {
+	if knownError(err) {
+		return err
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
+
+func toConversion(record domain.ConversionRecord) *fretev1.Conversion {
+	c := &fretev1.Conversion{
+		Currency:        record.MoedaDestino,
+		Cotacao:         record.Cotacao,
+		ValorEntrada:    record.ValorEntrada,
+		ValorConvertido: record.ValorConvertido,
+		Data:            timestamppb.New(record.Data),
+		ApiKeyId:        record.APIKeyID,
+		Fonte:           record.Fonte,
+		Retroativa:      record.Retroativa,
+	}
+	if record.DataReferencia != nil {
+		c.DataReferencia = timestamppb.New(*record.DataReferencia)
+	}
+	if record.DataCotacao != nil {
+		c.DataCotacao = timestamppb.New(*record.DataCotacao)
+	}
+	return c
+}
@@ -0,0 +1,471 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	fretev1 "go-frete/api/gen/frete/v1"
+	"go-frete/api/internal/domain"
+	"go-frete/api/pkg/ratelimit"
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type rateProviderMock struct {
+	mock.Mock
+}
+
+func (m *rateProviderMock) GetRate(moeda string) (float64, error) {
+	args := m.Called(moeda)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+type repositoryMock struct {
+	mock.Mock
+}
+
+func (m *repositoryMock) SaveHistory(record domain.ConversionRecord) error {
+	return m.Called(record).Error(0)
+}
+
+type conversionReaderMock struct {
+	mock.Mock
+}
+
+func (m *conversionReaderMock) GetLastConversions(limit int) ([]domain.ConversionRecord, error) {
+	args := m.Called(limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ConversionRecord), args.Error(1)
+}
+
+func (m *conversionReaderMock) FindConversions(filter domain.ConversionFilter) ([]domain.ConversionRecord, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ConversionRecord), args.Error(1)
+}
+
+type rateSeriesReaderMock struct {
+	mock.Mock
+}
+
+func (m *rateSeriesReaderMock) GetRateSeries(moeda string, from, to time.Time) ([]domain.RateSnapshot, error) {
+	args := m.Called(moeda, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.RateSnapshot), args.Error(1)
+}
+
+type apiKeyRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *apiKeyRepositoryMock) SaveAPIKey(key domain.APIKey) error {
+	return m.Called(key).Error(0)
+}
+
+func (m *apiKeyRepositoryMock) GetAPIKeyByHash(hash string) (*domain.APIKey, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *apiKeyRepositoryMock) GetAPIKeyByID(id string) (*domain.APIKey, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *apiKeyRepositoryMock) ListAPIKeys() ([]domain.APIKey, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.APIKey), args.Error(1)
+}
+
+func (m *apiKeyRepositoryMock) RevokeAPIKey(id string, at time.Time) error {
+	return m.Called(id, at).Error(0)
+}
+
+type usageCounterMock struct {
+	mock.Mock
+}
+
+func (m *usageCounterMock) IncrementDailyUsage(clientID string, day time.Time) (int, error) {
+	args := m.Called(clientID, day)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *usageCounterMock) DecrementDailyUsage(clientID string, day time.Time) error {
+	return m.Called(clientID, day).Error(0)
+}
+
+var testPlans = []domain.Plan{
+	{Name: domain.AnonymousPlan, RequestsPerMinute: 600, Burst: 100},
+	{Name: domain.DefaultPlan, RequestsPerMinute: 600, Burst: 100, DailyConversions: 2},
+}
+
+// Chaves aceitas pelo apiKeyRepositoryMock montado no testServer
+const (
+	writerKey = "gf_writer"
+	readerKey = "gf_reader"
+)
+
+type testServer struct {
+	provider *rateProviderMock
+	repo     *repositoryMock
+	reader   *conversionReaderMock
+	searcher *rateSeriesReaderMock
+	counter  *usageCounterMock
+	// Transmissão de cotações do WatchRates, preenchida por start
+	rates *domain.RateBroadcaster
+}
+
+func newTestServer() *testServer {
+	ts := &testServer{
+		provider: new(rateProviderMock),
+		repo:     new(repositoryMock),
+		reader:   new(conversionReaderMock),
+		searcher: new(rateSeriesReaderMock),
+		counter:  new(usageCounterMock),
+	}
+	ts.repo.On("SaveHistory", mock.Anything).Return(nil)
+	return ts
+}
+
+// start sobe o servidor em memória com os interceptors reais e devolve o cliente
+func (ts *testServer) start(t *testing.T) fretev1.ConverterServiceClient {
+	t.Helper()
+
+	loggerMock := new(loggermock.LoggerMock)
+	for _, level := range []string{"Info", "Warn", "Error"} {
+		loggerMock.On(level, mock.Anything, mock.Anything).Return()
+	}
+
+	keysMock := new(apiKeyRepositoryMock)
+	keysMock.On("GetAPIKeyByHash", domain.HashAPIKey(writerKey)).Return(&domain.APIKey{ID: "writer", Scopes: []string{domain.ScopeConvertWrite}}, nil)
+	keysMock.On("GetAPIKeyByHash", domain.HashAPIKey(readerKey)).Return(&domain.APIKey{ID: "reader", Scopes: []string{domain.ScopeHistoryRead}}, nil)
+	keysMock.On("GetAPIKeyByHash", mock.Anything).Return(nil, domain.ErrAPIKeyNotFound)
+
+	ts.rates = domain.NewRateBroadcaster(ts.provider, []string{"USD", "EUR"}, time.Minute, 10, loggerMock)
+
+	svc := NewServer(
+		domain.NewConverterUseCase(ts.provider, ts.repo, loggerMock).
+			WithRateHistory(domain.NewHistoricalRateResolver(ts.searcher, nil)),
+		domain.NewListConversionsUseCase(ts.reader, loggerMock),
+		domain.NewVariationUseCase(ts.searcher, loggerMock),
+		ts.rates,
+		loggerMock,
+	)
+
+	srv := New(svc,
+		domain.NewAPIKeyUseCase(keysMock, loggerMock),
+		domain.NewQuotaUseCase(ts.counter, testPlans, loggerMock),
+		ratelimit.NewLimiter(time.Minute),
+		loggerMock,
+	)
+
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return fretev1.NewConverterServiceClient(conn)
+}
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), APIKeyKey, key)
+}
+
+func TestConverterService(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should convert and return request id header",
+			run:  shouldConvertAndReturnRequestIDHeader,
+		},
+		{
+			name: "should convert as of date with rate in effect",
+			run:  shouldConvertAsOfDateWithRateInEffect,
+		},
+		{
+			name: "should require api key and scope",
+			run:  shouldRequireAPIKeyAndScope,
+		},
+		{
+			name: "should map domain errors to grpc codes",
+			run:  shouldMapDomainErrorsToGRPCCodes,
+		},
+		{
+			name: "should send retry-after trailer when upstream budget is exhausted",
+			run:  shouldSendRetryAfterTrailerWhenUpstreamBudgetIsExhausted,
+		},
+		{
+			name: "should enforce daily quota on convert",
+			run:  shouldEnforceDailyQuotaOnConvert,
+		},
+		{
+			name: "should recover from panic with internal code",
+			run:  shouldRecoverFromPanicWithInternalCode,
+		},
+		{
+			name: "should list conversions with pagination",
+			run:  shouldListConversionsWithPagination,
+		},
+		{
+			name: "should return variations",
+			run:  shouldReturnVariations,
+		},
+		{
+			name: "should stream current rate and only changes",
+			run:  shouldStreamCurrentRateAndOnlyChanges,
+		},
+		{
+			name: "should reject watch with invalid currency",
+			run:  shouldRejectWatchWithInvalidCurrency,
+		},
+		{
+			name: "should reject watch of currency outside broadcast list",
+			run:  shouldRejectWatchOfCurrencyOutsideBroadcastList,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldConvertAndReturnRequestIDHeader(t *testing.T) {
+	ts := newTestServer()
+	ts.provider.On("GetRate", "USD").Return(5.0, nil)
+	ts.counter.On("IncrementDailyUsage", "writer", mock.Anything).Return(1, nil)
+	client := ts.start(t)
+
+	ctx := metadata.AppendToOutgoingContext(withKey(writerKey), RequestIDKey, "req-42")
+	var header metadata.MD
+	resp, err := client.Convert(ctx, &fretev1.ConvertRequest{Moeda: "USD", ValorBrl: 100}, grpc.Header(&header))
+
+	require.NoError(t, err)
+	assert.Equal(t, 20.0, resp.GetConversion().GetValorConvertido())
+	assert.Equal(t, "writer", resp.GetConversion().GetApiKeyId())
+	assert.Equal(t, []string{"req-42"}, header.Get(RequestIDKey))
+	assert.Equal(t, []string{"1"}, header.Get("x-quota-remaining"))
+	ts.counter.AssertNotCalled(t, "DecrementDailyUsage", mock.Anything, mock.Anything)
+}
+
+func shouldConvertAsOfDateWithRateInEffect(t *testing.T) {
+	at := time.Date(2026, 1, 5, 15, 0, 0, 0, time.UTC)
+	rateAt := time.Date(2026, 1, 5, 14, 45, 0, 0, time.UTC)
+	ts := newTestServer()
+	ts.searcher.On("GetRateSeries", "USD", at.Add(-domain.AsOfLookback), at).
+		Return([]domain.RateSnapshot{{Moeda: "USD", Cotacao: 4.0, Fonte: "awesomeapi", Data: rateAt}}, nil)
+	ts.searcher.On("GetRateSeries", "EUR", mock.Anything, mock.Anything).Return([]domain.RateSnapshot{}, nil)
+	ts.counter.On("IncrementDailyUsage", "writer", mock.Anything).Return(1, nil)
+	ts.counter.On("DecrementDailyUsage", "writer", mock.Anything).Return(nil)
+	client := ts.start(t)
+
+	resp, err := client.Convert(withKey(writerKey), &fretev1.ConvertRequest{Moeda: "USD", ValorBrl: 100, AsOf: timestamppb.New(at)})
+
+	require.NoError(t, err)
+	assert.Equal(t, 25.0, resp.GetConversion().GetValorConvertido())
+	assert.True(t, resp.GetConversion().GetRetroativa())
+	assert.Equal(t, rateAt, resp.GetConversion().GetDataCotacao().AsTime())
+	ts.provider.AssertNotCalled(t, "GetRate", mock.Anything)
+
+	_, err = client.Convert(withKey(writerKey), &fretev1.ConvertRequest{Moeda: "EUR", ValorBrl: 100, AsOf: timestamppb.New(at)})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func shouldRequireAPIKeyAndScope(t *testing.T) {
+	client := newTestServer().start(t)
+
+	_, err := client.Convert(context.Background(), &fretev1.ConvertRequest{Moeda: "USD", ValorBrl: 100})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.Convert(withKey("gf_desconhecida"), &fretev1.ConvertRequest{Moeda: "USD", ValorBrl: 100})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.Convert(withKey(readerKey), &fretev1.ConvertRequest{Moeda: "USD", ValorBrl: 100})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func shouldMapDomainErrorsToGRPCCodes(t *testing.T) {
+	ts := newTestServer()
+	ts.provider.On("GetRate", "XYZ").Return(0.0, domain.ErrCurrencyNotFound)
+	ts.provider.On("GetRate", "EUR").Return(0.0, errors.New("erro ao consultar cotação externa"))
+	ts.counter.On("IncrementDailyUsage", "writer", mock.Anything).Return(1, nil)
+	ts.counter.On("DecrementDailyUsage", "writer", mock.Anything).Return(nil)
+	client := ts.start(t)
+
+	_, err := client.Convert(withKey(writerKey), &fretev1.ConvertRequest{Moeda: "XYZ", ValorBrl: 100})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.Convert(withKey(writerKey), &fretev1.ConvertRequest{Moeda: "EUR", ValorBrl: 100})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	_, err = client.Convert(withKey(writerKey), &fretev1.ConvertRequest{Moeda: "US", ValorBrl: 100})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.ListConversions(withKey(readerKey), &fretev1.ListConversionsRequest{
+		From: timestampAt(time.Now()),
+		To:   timestampAt(time.Now().Add(-time.Hour)),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func shouldSendRetryAfterTrailerWhenUpstreamBudgetIsExhausted(t *testing.T) {
+	ts := newTestServer()
+	ts.provider.On("GetRate", "USD").Return(0.0, &domain.RetryAfterError{Err: domain.ErrUpstreamBudgetExhausted, After: 1500 * time.Millisecond})
+	ts.counter.On("IncrementDailyUsage", "writer", mock.Anything).Return(1, nil)
+	ts.counter.On("DecrementDailyUsage", "writer", mock.Anything).Return(nil)
+	client := ts.start(t)
+
+	var trailer metadata.MD
+	_, err := client.Convert(withKey(writerKey), &fretev1.ConvertRequest{Moeda: "USD", ValorBrl: 100}, grpc.Trailer(&trailer))
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, []string{"2"}, trailer.Get(RetryAfterKey))
+	// A conversão que falhou não conta na cota do dia
+	ts.counter.AssertNumberOfCalls(t, "DecrementDailyUsage", 1)
+}
+
+func shouldEnforceDailyQuotaOnConvert(t *testing.T) {
+	ts := newTestServer()
+	ts.counter.On("IncrementDailyUsage", "writer", mock.Anything).Return(3, nil)
+	ts.counter.On("DecrementDailyUsage", "writer", mock.Anything).Return(nil)
+	client := ts.start(t)
+
+	var trailer metadata.MD
+	_, err := client.Convert(withKey(writerKey), &fretev1.ConvertRequest{Moeda: "USD", ValorBrl: 100}, grpc.Trailer(&trailer))
+
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.NotEmpty(t, trailer.Get(RetryAfterKey))
+	ts.provider.AssertNotCalled(t, "GetRate", mock.Anything)
+}
+
+func shouldRecoverFromPanicWithInternalCode(t *testing.T) {
+	ts := newTestServer()
+	ts.reader.On("FindConversions", mock.Anything).Run(func(mock.Arguments) { panic("boom") })
+	client := ts.start(t)
+
+	_, err := client.ListConversions(withKey(readerKey), &fretev1.ListConversionsRequest{})
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func shouldListConversionsWithPagination(t *testing.T) {
+	ts := newTestServer()
+	ts.reader.On("FindConversions", domain.ConversionFilter{Currency: "USD", Limit: 2, Offset: 1}).Return([]domain.ConversionRecord{
+		{MoedaDestino: "USD", Cotacao: 5}, {MoedaDestino: "USD", Cotacao: 5.1},
+	}, nil)
+	client := ts.start(t)
+
+	resp, err := client.ListConversions(withKey(readerKey), &fretev1.ListConversionsRequest{Currency: "usd", Limit: 1, Offset: 1})
+
+	require.NoError(t, err)
+	assert.Len(t, resp.GetConversions(), 1)
+	assert.True(t, resp.GetHasMore())
+	assert.Equal(t, int32(1), resp.GetLimit())
+}
+
+func shouldReturnVariations(t *testing.T) {
+	ts := newTestServer()
+	now := time.Now()
+	ts.searcher.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return([]domain.RateSnapshot{
+		{Moeda: "USD", Cotacao: 5, Data: now.Add(-time.Hour)},
+		{Moeda: "USD", Cotacao: 5.5, Data: now},
+	}, nil)
+	client := ts.start(t)
+
+	resp, err := client.GetVariation(withKey(readerKey), &fretev1.GetVariationRequest{Moeda: "USD"})
+
+	require.NoError(t, err)
+	require.Len(t, resp.GetVariations(), 2)
+	assert.InDelta(t, 10.0, resp.GetVariations()[1].GetVariacaoPercentual(), 0.0001)
+}
+
+func shouldStreamCurrentRateAndOnlyChanges(t *testing.T) {
+	ts := newTestServer()
+	client := ts.start(t)
+	ts.rates.Publish(domain.RateQuote{Moeda: "USD", Cotacao: 5.0, Fonte: "awesomeapi", Data: time.Now()})
+
+	ctx, cancel := context.WithCancel(withKey(readerKey))
+	defer cancel()
+	stream, err := client.WatchRates(ctx, &fretev1.WatchRatesRequest{Moedas: []string{"usd"}})
+	require.NoError(t, err)
+
+	first, err := stream.Recv()
+	require.NoError(t, err)
+
+	// Cotação repetida e moeda fora do pedido não geram mensagens
+	ts.rates.Publish(domain.RateQuote{Moeda: "USD", Cotacao: 5.0, Fonte: "awesomeapi", Data: time.Now()})
+	ts.rates.Publish(domain.RateQuote{Moeda: "EUR", Cotacao: 6.0, Fonte: "awesomeapi", Data: time.Now()})
+	ts.rates.Publish(domain.RateQuote{Moeda: "USD", Cotacao: 5.2, Fonte: "awesomeapi", Data: time.Now()})
+
+	second, err := stream.Recv()
+	require.NoError(t, err)
+
+	assert.Equal(t, 5.0, first.GetCotacao())
+	assert.Equal(t, 5.2, second.GetCotacao())
+	assert.Equal(t, "USD", second.GetMoeda())
+	// Os streams não consultam o provedor: quem consulta é a transmissão
+	ts.provider.AssertNotCalled(t, "GetRate", mock.Anything)
+
+	cancel()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
+
+func shouldRejectWatchWithInvalidCurrency(t *testing.T) {
+	client := newTestServer().start(t)
+
+	stream, err := client.WatchRates(withKey(readerKey), &fretev1.WatchRatesRequest{Moedas: []string{"DOLAR"}})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func shouldRejectWatchOfCurrencyOutsideBroadcastList(t *testing.T) {
+	client := newTestServer().start(t)
+
+	stream, err := client.WatchRates(withKey(readerKey), &fretev1.WatchRatesRequest{Moedas: []string{"USD", "JPY"}})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func timestampAt(t time.Time) *timestamppb.Timestamp {
+	return timestamppb.New(t)
+}
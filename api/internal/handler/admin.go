@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go-frete/api/pkg/logger"
+)
+
+type LogLevelRequest struct {
+	Level string `json:"level"`
+}
+
+type LogLevelResponse struct {
+	Level string `json:"level"`
+}
+
+// LogLevelHandler expõe o nível de log atual e permite trocá-lo sem reiniciar a API
+type LogLevelHandler struct {
+	levels logger.LevelController
+	log    logger.Logger
+}
+
+func NewLogLevelHandler(lc logger.LevelController, l logger.Logger) *LogLevelHandler {
+	return &LogLevelHandler{levels: lc, log: l}
+}
+
+func (h *LogLevelHandler) GetHandle(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, http.StatusOK, LogLevelResponse{Level: h.levels.Level()})
+}
+
+func (h *LogLevelHandler) PutHandle(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	var req LogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn("Falha ao fazer parse do JSON", "erro", err.Error())
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "JSON inválido")
+		return
+	}
+
+	previous := h.levels.Level()
+	if err := h.levels.SetLevel(req.Level); err != nil {
+		log.Warn("Nível de log inválido", "nivel_recebido", req.Level)
+		writeAPIError(w, r, http.StatusBadRequest, APIError{
+			Code: CodeInvalidRequest, Message: "Nível de log inválido (use debug, info, warn ou error)", Field: "level",
+		})
+		return
+	}
+
+	log.Info("Nível de log alterado", "anterior", previous, "atual", h.levels.Level())
+
+	writeJSON(w, r, http.StatusOK, LogLevelResponse{Level: h.levels.Level()})
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type levelControllerMock struct {
+	mock.Mock
+}
+
+func (m *levelControllerMock) Level() string {
+	return m.Called().String(0)
+}
+
+func (m *levelControllerMock) SetLevel(level string) error {
+	return m.Called(level).Error(0)
+}
+
+func TestLogLevelHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should return current log level",
+			run:  shouldReturnCurrentLogLevel,
+		},
+		{
+			name: "should change log level at runtime",
+			run:  shouldChangeLogLevelAtRuntime,
+		},
+		{
+			name: "should return 400 Bad Request for unknown level",
+			run:  shouldReturn400ForUnknownLogLevel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldReturnCurrentLogLevel(t *testing.T) {
+	levelsMock := new(levelControllerMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	levelsMock.On("Level").Return("info")
+
+	handler := NewLogLevelHandler(levelsMock, loggerMock)
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/log-level", nil)
+	recorder := httptest.NewRecorder()
+	handler.GetHandle(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"level":"info"}`, recorder.Body.String())
+}
+
+func shouldChangeLogLevelAtRuntime(t *testing.T) {
+	levelsMock := new(levelControllerMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	levelsMock.On("Level").Return("info").Once()
+	levelsMock.On("SetLevel", "debug").Return(nil)
+	levelsMock.On("Level").Return("debug")
+
+	handler := NewLogLevelHandler(levelsMock, loggerMock)
+
+	req, _ := http.NewRequest(http.MethodPut, "/admin/log-level", bytes.NewBufferString(`{"level":"debug"}`))
+	recorder := httptest.NewRecorder()
+	handler.PutHandle(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"level":"debug"}`, recorder.Body.String())
+	levelsMock.AssertExpectations(t)
+}
+
+func shouldReturn400ForUnknownLogLevel(t *testing.T) {
+	levelsMock := new(levelControllerMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+	levelsMock.On("Level").Return("info")
+	levelsMock.On("SetLevel", "verbose").Return(errors.New("unrecognized level"))
+
+	handler := NewLogLevelHandler(levelsMock, loggerMock)
+
+	req, _ := http.NewRequest(http.MethodPut, "/admin/log-level", bytes.NewBufferString(`{"level":"verbose"}`))
+	recorder := httptest.NewRecorder()
+	handler.PutHandle(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
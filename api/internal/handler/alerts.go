@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/pkg/logger"
+)
+
+// AlertRequest define uma regra de alerta, na criação e na alteração
+type AlertRequest struct {
+	Moeda           string   `json:"moeda"`
+	Tipo            string   `json:"tipo"`
+	Valor           float64  `json:"valor"`
+	JanelaMinutos   int      `json:"janela_minutos"`
+	Dias            int      `json:"dias"`
+	CooldownMinutos *int     `json:"cooldown_minutos"`
+	Canais          []string `json:"canais"`
+	Email           string   `json:"email"`
+	Ativa           *bool    `json:"ativa"`
+}
+
+func (req AlertRequest) input() domain.AlertRuleInput {
+	return domain.AlertRuleInput{
+		Moeda:           req.Moeda,
+		Tipo:            req.Tipo,
+		Valor:           req.Valor,
+		JanelaMinutos:   req.JanelaMinutos,
+		Dias:            req.Dias,
+		CooldownMinutos: req.CooldownMinutos,
+		Canais:          req.Canais,
+		Email:           req.Email,
+		Ativa:           req.Ativa,
+	}
+}
+
+// alertFields liga cada erro de validação ao campo da requisição
+var alertFields = []struct {
+	err   error
+	field string
+}{
+	{domain.ErrAlertCurrency, "moeda"},
+	{domain.ErrAlertType, "tipo"},
+	{domain.ErrAlertValue, "valor"},
+	{domain.ErrAlertWindow, "janela_minutos"},
+	{domain.ErrAlertDays, "dias"},
+	{domain.ErrAlertCooldown, "cooldown_minutos"},
+	{domain.ErrAlertChannel, "canais"},
+	{domain.ErrAlertEmail, "email"},
+}
+
+// AlertHandler atende o cadastro de alertas de cotação e o histórico de disparos
+type AlertHandler struct {
+	useCase *domain.AlertUseCase
+	log     logger.Logger
+}
+
+func NewAlertHandler(uc *domain.AlertUseCase, l logger.Logger) *AlertHandler {
+	return &AlertHandler{useCase: uc, log: l}
+}
+
+func (h *AlertHandler) CreateHandle(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+
+	rule, err := h.useCase.Create(r.Context(), req.input())
+	if err != nil {
+		h.writeRuleError(w, r, err, "Erro ao cadastrar alerta")
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, rule)
+}
+
+func (h *AlertHandler) UpdateHandle(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+
+	rule, err := h.useCase.Update(r.Context(), r.PathValue("id"), req.input())
+	if err != nil {
+		h.writeRuleError(w, r, err, "Erro ao alterar alerta")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, rule)
+}
+
+func (h *AlertHandler) ListHandle(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.useCase.List(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao listar alertas")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, rules)
+}
+
+func (h *AlertHandler) GetHandle(w http.ResponseWriter, r *http.Request) {
+	rule, err := h.useCase.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeRuleError(w, r, err, "Erro ao buscar alerta")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, rule)
+}
+
+func (h *AlertHandler) DeleteHandle(w http.ResponseWriter, r *http.Request) {
+	if err := h.useCase.Delete(r.Context(), r.PathValue("id")); err != nil {
+		h.writeRuleError(w, r, err, "Erro ao remover alerta")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HistoryHandle lista os disparos, filtrados por alerta_id e moeda
+func (h *AlertHandler) HistoryHandle(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := domain.AlertTriggerFilter{AlertaID: q.Get("alerta_id"), Moeda: q.Get("moeda")}
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: "Deve ser um inteiro não negativo", Field: "limit"})
+			return
+		}
+		filter.Limit = n
+	}
+
+	triggers, err := h.useCase.History(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao listar disparos de alerta")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, triggers)
+}
+
+func (h *AlertHandler) decode(w http.ResponseWriter, r *http.Request) (AlertRequest, bool) {
+	var req AlertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromContext(r.Context(), h.log).Warn("Falha ao fazer parse do JSON", "erro", err.Error())
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "JSON inválido")
+		return req, false
+	}
+	return req, true
+}
+
+func (h *AlertHandler) writeRuleError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	if errors.Is(err, domain.ErrAlertNotFound) {
+		writeError(w, r, http.StatusNotFound, CodeNotFound, err.Error())
+		return
+	}
+	for _, f := range alertFields {
+		if errors.Is(err, f.err) {
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: f.field})
+			return
+		}
+	}
+	writeError(w, r, http.StatusInternalServerError, CodeInternal, message)
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-frete/api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// alertStoreMock faz o papel dos repositórios de regras e de disparos
+type alertStoreMock struct {
+	mock.Mock
+}
+
+func (m *alertStoreMock) SaveAlertRule(rule domain.AlertRule) error {
+	return m.Called(rule).Error(0)
+}
+
+func (m *alertStoreMock) GetAlertRule(id string) (*domain.AlertRule, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AlertRule), args.Error(1)
+}
+
+func (m *alertStoreMock) ListAlertRules(apiKeyID string) ([]domain.AlertRule, error) {
+	args := m.Called(apiKeyID)
+	return args.Get(0).([]domain.AlertRule), args.Error(1)
+}
+
+func (m *alertStoreMock) DeleteAlertRule(id string) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *alertStoreMock) ActiveAlertRules(moeda string) ([]domain.AlertRule, error) {
+	args := m.Called(moeda)
+	return args.Get(0).([]domain.AlertRule), args.Error(1)
+}
+
+func (m *alertStoreMock) TryTriggerAlert(id string, at time.Time, cooldown time.Duration) (bool, error) {
+	args := m.Called(id, at, cooldown)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *alertStoreMock) RearmAlert(id string) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *alertStoreMock) SaveAlertTrigger(t domain.AlertTrigger) error {
+	return m.Called(t).Error(0)
+}
+
+func (m *alertStoreMock) ListAlertTriggers(filter domain.AlertTriggerFilter) ([]domain.AlertTrigger, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]domain.AlertTrigger), args.Error(1)
+}
+
+func newAlertHandlerFake(store *alertStoreMock) *AlertHandler {
+	uc := domain.NewAlertUseCase(store, store, nil, domain.NewCurrencyRegistry(nil), nil, newExportLogger())
+	return NewAlertHandler(uc, newExportLogger())
+}
+
+func TestAlertHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should create alert for the key",
+			run:  shouldCreateAlertForTheKey,
+		},
+		{
+			name: "should return 400 with field for invalid alert",
+			run:  shouldReturn400WithFieldForInvalidAlert,
+		},
+		{
+			name: "should return 404 for alert of another key",
+			run:  shouldReturn404ForAlertOfAnotherKey,
+		},
+		{
+			name: "should list alert history of the key",
+			run:  shouldListAlertHistoryOfTheKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldCreateAlertForTheKey(t *testing.T) {
+	store := new(alertStoreMock)
+	store.On("SaveAlertRule", mock.MatchedBy(func(r domain.AlertRule) bool {
+		return r.APIKeyID == "k1" && r.Moeda == "USD" && r.Tipo == domain.AlertBelow && r.Valor == 5 && r.CooldownMinutos == 30 && r.Ativa
+	})).Return(nil).Once()
+	handler := newAlertHandlerFake(store)
+
+	body := `{"moeda":"usd","tipo":"abaixo","valor":5,"cooldown_minutos":30}`
+	recorder := httptest.NewRecorder()
+	handler.CreateHandle(recorder, withKey(httptest.NewRequest(http.MethodPost, "/v1/alerts", strings.NewReader(body)), "k1", domain.ScopeHistoryRead))
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"canais":["webhook"]`)
+	assert.Contains(t, recorder.Body.String(), `"disparada":false`)
+	assert.NotContains(t, recorder.Body.String(), "ultimo_disparo")
+	store.AssertExpectations(t)
+}
+
+func shouldReturn400WithFieldForInvalidAlert(t *testing.T) {
+	handler := newAlertHandlerFake(new(alertStoreMock))
+
+	for body, field := range map[string]string{
+		`{"moeda":"XYZ","tipo":"abaixo","valor":5}`:                                     "moeda",
+		`{"moeda":"USD","tipo":"igual","valor":5}`:                                      "tipo",
+		`{"moeda":"USD","tipo":"acima","valor":-1}`:                                     "valor",
+		`{"moeda":"USD","tipo":"variacao","valor":2}`:                                   "janela_minutos",
+		`{"moeda":"USD","tipo":"maxima"}`:                                               "dias",
+		`{"moeda":"USD","tipo":"acima","valor":6,"canais":["email"],"email":"a@b.com"}`: "email",
+	} {
+		recorder := httptest.NewRecorder()
+		handler.CreateHandle(recorder, httptest.NewRequest(http.MethodPost, "/v1/alerts", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, body)
+		assert.Contains(t, recorder.Body.String(), `"field":"`+field+`"`, body)
+	}
+}
+
+func shouldReturn404ForAlertOfAnotherKey(t *testing.T) {
+	store := new(alertStoreMock)
+	store.On("GetAlertRule", "a1").Return(&domain.AlertRule{ID: "a1", APIKeyID: "k1", Moeda: "USD"}, nil)
+	handler := newAlertHandlerFake(store)
+
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		req := httptest.NewRequest(method, "/v1/alerts/a1", strings.NewReader(`{"moeda":"USD","tipo":"abaixo","valor":4}`))
+		req.SetPathValue("id", "a1")
+		recorder := httptest.NewRecorder()
+		switch method {
+		case http.MethodGet:
+			handler.GetHandle(recorder, withKey(req, "k2", domain.ScopeHistoryRead))
+		case http.MethodPut:
+			handler.UpdateHandle(recorder, withKey(req, "k2", domain.ScopeHistoryRead))
+		case http.MethodDelete:
+			handler.DeleteHandle(recorder, withKey(req, "k2", domain.ScopeHistoryRead))
+		}
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code, method)
+	}
+	store.AssertNotCalled(t, "SaveAlertRule", mock.Anything)
+	store.AssertNotCalled(t, "DeleteAlertRule", mock.Anything)
+}
+
+func shouldListAlertHistoryOfTheKey(t *testing.T) {
+	store := new(alertStoreMock)
+	store.On("ListAlertTriggers", domain.AlertTriggerFilter{AlertaID: "a1", APIKeyID: "k1", Moeda: "USD", Limit: 10}).
+		Return([]domain.AlertTrigger{{ID: "t1", AlertaID: "a1", Moeda: "USD", Tipo: domain.AlertBelow, Cotacao: 4.9, Descricao: "USD abaixo de 5.0000 (cotação 4.9000)",
+			Notificacoes: []domain.AlertNotification{{Canal: domain.AlertChannelWebhook, Status: domain.NotificationSent}}}}, nil)
+	handler := newAlertHandlerFake(store)
+
+	recorder := httptest.NewRecorder()
+	handler.HistoryHandle(recorder, withKey(httptest.NewRequest(http.MethodGet, "/v1/alerts/history?alerta_id=a1&moeda=usd&limit=10", nil), "k1", domain.ScopeHistoryRead))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"status":"enviada"`)
+	store.AssertExpectations(t)
+
+	recorder = httptest.NewRecorder()
+	handler.HistoryHandle(recorder, httptest.NewRequest(http.MethodGet, "/v1/alerts/history?limit=x", nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"field":"limit"`)
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/pkg/logger"
+)
+
+// AnalyticsHandler calcula indicadores técnicos sobre as cotações coletadas
+type AnalyticsHandler struct {
+	analytics *domain.AnalyticsUseCase
+	log       logger.Logger
+}
+
+func NewAnalyticsHandler(uc *domain.AnalyticsUseCase, l logger.Logger) *AnalyticsHandler {
+	return &AnalyticsHandler{analytics: uc, log: l}
+}
+
+// Handle atende GET /v1/analytics/USD?indicators=sma,rsi&window=14&from=2026-01-01
+func (h *AnalyticsHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
+	q := r.URL.Query()
+
+	req := domain.AnalyticsRequest{Moeda: r.PathValue("moeda")}
+	var apiErr *APIError
+	req.From, req.To, apiErr = parsePeriod(r)
+	if apiErr != nil {
+		writeAPIError(w, r, http.StatusBadRequest, *apiErr)
+		return
+	}
+	// Aceita tanto indicators=sma,ema quanto indicators=sma&indicators=ema
+	for _, raw := range q["indicators"] {
+		for ind := range strings.SplitSeq(raw, ",") {
+			if ind = strings.ToLower(strings.TrimSpace(ind)); ind != "" {
+				req.Indicadores = append(req.Indicadores, ind)
+			}
+		}
+	}
+	if raw := q.Get("window"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: "Deve ser um inteiro", Field: "window"})
+			return
+		}
+		req.Janela = n
+	}
+	if raw := q.Get("k"); raw != "" {
+		k, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: "Deve ser um número", Field: "k"})
+			return
+		}
+		req.K = k
+	}
+
+	analytics, err := h.analytics.Execute(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidPeriod):
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "from"})
+		case errors.Is(err, domain.ErrInvalidIndicator):
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "indicators"})
+		case errors.Is(err, domain.ErrInvalidWindow):
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "window"})
+		case errors.Is(err, domain.ErrInvalidBollinger):
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "k"})
+		case errors.Is(err, domain.ErrNoRates):
+			writeError(w, r, http.StatusNotFound, CodeNotFound, err.Error())
+		default:
+			log.Error("Falha ao calcular indicadores", "erro", err.Error())
+			writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao calcular indicadores")
+		}
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, analytics)
+}
+
+// CompareHandle atende GET /v1/analytics/compare?currencies=USD,EUR,CNY&interval=1d
+func (h *AnalyticsHandler) CompareHandle(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	req := domain.CompareRequest{Moedas: parseCurrencies(r.URL.Query().Get("currencies"))}
+	var apiErr *APIError
+	req.From, req.To, apiErr = parsePeriod(r)
+	if apiErr != nil {
+		writeAPIError(w, r, http.StatusBadRequest, *apiErr)
+		return
+	}
+	if raw := r.URL.Query().Get("interval"); raw != "" {
+		d, err := parseSpan(raw)
+		if err != nil {
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: "Use uma duração como 15m, 1h ou 1d", Field: "interval"})
+			return
+		}
+		req.Intervalo = d
+	}
+
+	comparison, err := h.analytics.Compare(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidPeriod):
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "from"})
+		case errors.Is(err, domain.ErrCompareCurrencies):
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "currencies"})
+		case errors.Is(err, domain.ErrInvalidInterval):
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "interval"})
+		case errors.Is(err, domain.ErrNoRates), errors.Is(err, domain.ErrNoCommonRates):
+			writeError(w, r, http.StatusNotFound, CodeNotFound, err.Error())
+		default:
+			log.Error("Falha ao comparar moedas", "erro", err.Error())
+			writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao comparar moedas")
+		}
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, comparison)
+}
+
+// parseSpan aceita as durações de time.ParseDuration e também dias (30d)
+func parseSpan(raw string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(raw)
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-frete/api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAnalyticsHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should return requested indicators",
+			run:  shouldReturnRequestedIndicators,
+		},
+		{
+			name: "should return 400 with field for invalid analytics query",
+			run:  shouldReturn400WithFieldForInvalidAnalyticsQuery,
+		},
+		{
+			name: "should compare currencies with daily interval",
+			run:  shouldCompareCurrenciesWithDailyInterval,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func analyticsRequest(query string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/v1/analytics/USD"+query, nil)
+	req.SetPathValue("moeda", "USD")
+	return req
+}
+
+func shouldReturnRequestedIndicators(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	from := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	searcherMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return([]domain.RateSnapshot{
+		{Moeda: "USD", Cotacao: 5, Data: from},
+		{Moeda: "USD", Cotacao: 6, Data: from.Add(time.Hour)},
+		{Moeda: "USD", Cotacao: 4, Data: from.Add(2 * time.Hour)},
+	}, nil)
+	handler := NewAnalyticsHandler(domain.NewAnalyticsUseCase(searcherMock, newExportLogger()), newExportLogger())
+
+	recorder := httptest.NewRecorder()
+	handler.Handle(recorder, analyticsRequest("?indicators=SMA&indicators=drawdown&window=2"))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"indicadores":["sma","drawdown"]`)
+	assert.Contains(t, recorder.Body.String(), `"sma":5.5`)
+	assert.Contains(t, recorder.Body.String(), `"drawdown_maximo":-33.33`)
+	assert.NotContains(t, recorder.Body.String(), `"rsi"`)
+}
+
+func shouldReturn400WithFieldForInvalidAnalyticsQuery(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	handler := NewAnalyticsHandler(domain.NewAnalyticsUseCase(searcherMock, newExportLogger()), newExportLogger())
+
+	for query, field := range map[string]string{
+		"?indicators=macd":               "indicators",
+		"?window=x":                      "window",
+		"?window=1":                      "window",
+		"?k=-1":                          "k",
+		"?from=ontem":                    "from",
+		"?from=2026-02-01&to=2026-01-01": "from",
+	} {
+		recorder := httptest.NewRecorder()
+		handler.Handle(recorder, analyticsRequest(query))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+		assert.Contains(t, recorder.Body.String(), `"field":"`+field+`"`, query)
+	}
+	searcherMock.AssertNotCalled(t, "GetRateSeries", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func shouldCompareCurrenciesWithDailyInterval(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	day := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	searcherMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return([]domain.RateSnapshot{
+		{Moeda: "USD", Cotacao: 5, Data: day.Add(18 * time.Hour)},
+		{Moeda: "USD", Cotacao: 5.5, Data: day.Add(42 * time.Hour)},
+	}, nil)
+	searcherMock.On("GetRateSeries", "CNY", mock.Anything, mock.Anything).Return([]domain.RateSnapshot{
+		{Moeda: "CNY", Cotacao: 0.7, Data: day.Add(17 * time.Hour)},
+		{Moeda: "CNY", Cotacao: 0.77, Data: day.Add(41 * time.Hour)},
+	}, nil)
+	handler := NewAnalyticsHandler(domain.NewAnalyticsUseCase(searcherMock, newExportLogger()), newExportLogger())
+
+	recorder := httptest.NewRecorder()
+	handler.CompareHandle(recorder, httptest.NewRequest(http.MethodGet, "/v1/analytics/compare?currencies=usd,cny&interval=1d", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"intervalo_minutos":1440`)
+	assert.Contains(t, recorder.Body.String(), `"amostras":2`)
+	assert.Contains(t, recorder.Body.String(), `"desempenho":{"CNY":100,"USD":100}`)
+
+	recorder = httptest.NewRecorder()
+	handler.CompareHandle(recorder, httptest.NewRequest(http.MethodGet, "/v1/analytics/compare?currencies=USD,CNY&interval=semana", nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"field":"interval"`)
+}
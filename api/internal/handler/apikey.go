@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/pkg/logger"
+)
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	Plan   string   `json:"plan,omitempty"`
+}
+
+// CreateAPIKeyResponse é a única vez em que a chave em texto puro é devolvida
+type CreateAPIKeyResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	Plan      string    `json:"plan,omitempty"`
+	Key       string    `json:"key"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// APIKeyHandler atende as rotas administrativas de chaves de API
+type APIKeyHandler struct {
+	useCase *domain.APIKeyUseCase
+	log     logger.Logger
+}
+
+func NewAPIKeyHandler(uc *domain.APIKeyUseCase, l logger.Logger) *APIKeyHandler {
+	return &APIKeyHandler{useCase: uc, log: l}
+}
+
+func (h *APIKeyHandler) CreateHandle(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn("Falha ao fazer parse do JSON", "erro", err.Error())
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "JSON inválido")
+		return
+	}
+
+	key, plaintext, err := h.useCase.Create(r.Context(), req.Name, req.Scopes, req.Plan)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidScope) || errors.Is(err, domain.ErrAPIKeyName) {
+			writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+		writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao criar chave de API")
+		return
+	}
+
+	writeCreatedKey(w, r, key, plaintext)
+}
+
+func (h *APIKeyHandler) ListHandle(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.useCase.List(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao listar chaves de API")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, keys)
+}
+
+func (h *APIKeyHandler) RevokeHandle(w http.ResponseWriter, r *http.Request) {
+	err := h.useCase.Revoke(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			writeError(w, r, http.StatusNotFound, CodeNotFound, err.Error())
+			return
+		}
+		writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao revogar chave de API")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *APIKeyHandler) RotateHandle(w http.ResponseWriter, r *http.Request) {
+	key, plaintext, err := h.useCase.Rotate(r.Context(), r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrAPIKeyNotFound):
+			writeError(w, r, http.StatusNotFound, CodeNotFound, err.Error())
+		case errors.Is(err, domain.ErrInvalidAPIKey):
+			writeError(w, r, http.StatusConflict, CodeConflict, err.Error())
+		default:
+			writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao rotacionar chave de API")
+		}
+		return
+	}
+
+	writeCreatedKey(w, r, key, plaintext)
+}
+
+func writeCreatedKey(w http.ResponseWriter, r *http.Request, key domain.APIKey, plaintext string) {
+	writeJSON(w, r, http.StatusCreated, CreateAPIKeyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		Scopes:    key.Scopes,
+		Plan:      key.Plan,
+		Key:       plaintext,
+		CreatedAt: key.CreatedAt,
+	})
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/pkg/logger"
+)
+
+// APIKeyHeader é o cabeçalho alternativo ao Authorization: Bearer
+const APIKeyHeader = "X-API-Key"
+
+// Authenticate resolve a chave de API enviada e a coloca no contexto.
+// Requisições sem chave seguem anônimas; quem exige chave é o RequireScope.
+func Authenticate(auth *domain.APIKeyUseCase, l logger.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			plaintext := apiKeyFromRequest(r)
+			if plaintext == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, err := auth.Authenticate(r.Context(), plaintext)
+			if err != nil {
+				if errors.Is(err, domain.ErrInvalidAPIKey) {
+					logger.FromContext(r.Context(), l).Warn("Chave de API inválida")
+					unauthorized(w, r)
+					return
+				}
+				writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao validar credenciais")
+				return
+			}
+
+			ctx := domain.ContextWithAPIKey(r.Context(), key)
+			ctx = logger.WithContext(ctx, "api_key_id", key.ID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireScope bloqueia a rota para quem não tem chave (401) ou não tem o escopo (403)
+func RequireScope(scope string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := domain.APIKeyFromContext(r.Context())
+			if !ok {
+				unauthorized(w, r)
+				return
+			}
+			if !key.HasScope(scope) {
+				writeError(w, r, http.StatusForbidden, CodeForbidden, "Chave de API sem o escopo "+scope)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Protect é um atalho para registrar uma rota exigindo o escopo informado
+func Protect(scope string, h http.HandlerFunc) http.Handler {
+	return RequireScope(scope)(h)
+}
+
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	auth := r.Header.Get("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="go-frete"`)
+	writeError(w, r, http.StatusUnauthorized, CodeUnauthorized, "Chave de API ausente ou inválida")
+}
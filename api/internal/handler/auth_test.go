@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type apiKeyRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *apiKeyRepositoryMock) SaveAPIKey(key domain.APIKey) error {
+	return m.Called(key).Error(0)
+}
+
+func (m *apiKeyRepositoryMock) GetAPIKeyByHash(hash string) (*domain.APIKey, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *apiKeyRepositoryMock) GetAPIKeyByID(id string) (*domain.APIKey, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *apiKeyRepositoryMock) ListAPIKeys() ([]domain.APIKey, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.APIKey), args.Error(1)
+}
+
+func (m *apiKeyRepositoryMock) RevokeAPIKey(id string, at time.Time) error {
+	return m.Called(id, at).Error(0)
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should return 401 when key is missing",
+			run:  shouldReturn401WhenKeyIsMissing,
+		},
+		{
+			name: "should return 401 when key is invalid",
+			run:  shouldReturn401WhenKeyIsInvalid,
+		},
+		{
+			name: "should return 403 when key lacks scope",
+			run:  shouldReturn403WhenKeyLacksScope,
+		},
+		{
+			name: "should accept bearer token with required scope",
+			run:  shouldAcceptBearerTokenWithRequiredScope,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func authenticatedRoute(repoMock *apiKeyRepositoryMock, loggerMock *loggermock.LoggerMock, scope string, next http.HandlerFunc) http.Handler {
+	auth := domain.NewAPIKeyUseCase(repoMock, loggerMock)
+	return Chain(Protect(scope, next), Authenticate(auth, loggerMock))
+}
+
+func shouldReturn401WhenKeyIsMissing(t *testing.T) {
+	h := authenticatedRoute(new(apiKeyRepositoryMock), new(loggermock.LoggerMock), domain.ScopeHistoryRead, func(w http.ResponseWriter, r *http.Request) {})
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/convert/list", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.NotEmpty(t, recorder.Header().Get("WWW-Authenticate"))
+}
+
+func shouldReturn401WhenKeyIsInvalid(t *testing.T) {
+	repoMock := new(apiKeyRepositoryMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+	repoMock.On("GetAPIKeyByHash", domain.HashAPIKey("gf_wrong")).Return(nil, domain.ErrAPIKeyNotFound)
+
+	h := authenticatedRoute(repoMock, loggerMock, domain.ScopeHistoryRead, func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodGet, "/convert/list", nil)
+	req.Header.Set(APIKeyHeader, "gf_wrong")
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func shouldReturn403WhenKeyLacksScope(t *testing.T) {
+	repoMock := new(apiKeyRepositoryMock)
+
+	stored := &domain.APIKey{ID: "k1", Scopes: []string{domain.ScopeHistoryRead}}
+	repoMock.On("GetAPIKeyByHash", domain.HashAPIKey("gf_reader")).Return(stored, nil)
+
+	h := authenticatedRoute(repoMock, new(loggermock.LoggerMock), domain.ScopeConvertWrite, func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodPost, "/converter", nil)
+	req.Header.Set(APIKeyHeader, "gf_reader")
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func shouldAcceptBearerTokenWithRequiredScope(t *testing.T) {
+	repoMock := new(apiKeyRepositoryMock)
+
+	stored := &domain.APIKey{ID: "k1", Scopes: []string{domain.ScopeConvertWrite}}
+	repoMock.On("GetAPIKeyByHash", domain.HashAPIKey("gf_writer")).Return(stored, nil)
+
+	var seen string
+	h := authenticatedRoute(repoMock, new(loggermock.LoggerMock), domain.ScopeConvertWrite, func(w http.ResponseWriter, r *http.Request) {
+		key, _ := domain.APIKeyFromContext(r.Context())
+		seen = key.ID
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/converter", nil)
+	req.Header.Set("Authorization", "Bearer gf_writer")
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "k1", seen)
+}
+
+func TestAPIKeyHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should create key and return plaintext once",
+			run:  shouldCreateKeyAndReturnPlaintextOnce,
+		},
+		{
+			name: "should return 400 Bad Request for invalid scope",
+			run:  shouldReturn400ForInvalidScope,
+		},
+		{
+			name: "should return 404 when revoking unknown key",
+			run:  shouldReturn404WhenRevokingUnknownKey,
+		},
+		{
+			name: "should return 204 when key is revoked",
+			run:  shouldReturn204WhenKeyIsRevoked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldCreateKeyAndReturnPlaintextOnce(t *testing.T) {
+	repoMock := new(apiKeyRepositoryMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	repoMock.On("SaveAPIKey", mock.Anything).Return(nil)
+
+	handler := NewAPIKeyHandler(domain.NewAPIKeyUseCase(repoMock, loggerMock), loggerMock)
+
+	body := []byte(`{"name": "frete-batch", "scopes": ["convert:write"]}`)
+	req, _ := http.NewRequest(http.MethodPost, "/admin/api-keys", bytes.NewBuffer(body))
+	recorder := httptest.NewRecorder()
+	handler.CreateHandle(recorder, req)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"key":"gf_`)
+	assert.NotContains(t, recorder.Body.String(), "key_hash")
+}
+
+func shouldReturn400ForInvalidScope(t *testing.T) {
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+
+	handler := NewAPIKeyHandler(domain.NewAPIKeyUseCase(new(apiKeyRepositoryMock), loggerMock), loggerMock)
+
+	body := []byte(`{"name": "frete-batch", "scopes": ["root"]}`)
+	req, _ := http.NewRequest(http.MethodPost, "/admin/api-keys", bytes.NewBuffer(body))
+	recorder := httptest.NewRecorder()
+	handler.CreateHandle(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func shouldReturn404WhenRevokingUnknownKey(t *testing.T) {
+	repoMock := new(apiKeyRepositoryMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	repoMock.On("GetAPIKeyByID", "nope").Return(nil, domain.ErrAPIKeyNotFound)
+
+	handler := NewAPIKeyHandler(domain.NewAPIKeyUseCase(repoMock, loggerMock), loggerMock)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/admin/api-keys/nope", nil)
+	req.SetPathValue("id", "nope")
+	recorder := httptest.NewRecorder()
+	handler.RevokeHandle(recorder, req)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func shouldReturn204WhenKeyIsRevoked(t *testing.T) {
+	repoMock := new(apiKeyRepositoryMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	repoMock.On("GetAPIKeyByID", "k1").Return(&domain.APIKey{ID: "k1"}, nil)
+	repoMock.On("RevokeAPIKey", "k1", mock.Anything).Return(nil)
+
+	handler := NewAPIKeyHandler(domain.NewAPIKeyUseCase(repoMock, loggerMock), loggerMock)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/admin/api-keys/k1", nil)
+	req.SetPathValue("id", "k1")
+	recorder := httptest.NewRecorder()
+	handler.RevokeHandle(recorder, req)
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	repoMock.AssertExpectations(t)
+}
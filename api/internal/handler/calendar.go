@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/pkg/logger"
+)
+
+type CalendarResponse struct {
+	Codigo    string            `json:"codigo"`
+	Nome      string            `json:"nome"`
+	Fuso      string            `json:"fuso"`
+	Ano       int               `json:"ano"`
+	DiasUteis int               `json:"dias_uteis"`
+	Feriados  []CalendarHoliday `json:"feriados"`
+}
+
+type CalendarHoliday struct {
+	Data string `json:"data"`
+	Nome string `json:"nome"`
+}
+
+// CalendarHandler expõe os feriados bancários usados para pular dias sem cotação
+type CalendarHandler struct {
+	calendars *domain.Calendars
+	log       logger.Logger
+}
+
+func NewCalendarHandler(c *domain.Calendars, l logger.Logger) *CalendarHandler {
+	return &CalendarHandler{calendars: c, log: l}
+}
+
+// Handle atende GET /v1/calendar/{country}?year=2026 (padrão: ano atual)
+func (h *CalendarHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	cal, err := h.calendars.Get(r.PathValue("country"))
+	if errors.Is(err, domain.ErrUnknownCalendar) {
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "Calendário não encontrado (use BR, US, EU ou TARGET2)")
+		return
+	}
+
+	year := time.Now().In(cal.Location).Year()
+	if raw := r.URL.Query().Get("year"); raw != "" {
+		year, err = strconv.Atoi(raw)
+		if err != nil || year < 1900 || year > 2200 {
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: "Ano deve estar entre 1900 e 2200", Field: "year"})
+			return
+		}
+	}
+
+	resp := CalendarResponse{
+		Codigo:   cal.Code,
+		Nome:     cal.Name,
+		Fuso:     cal.Location.String(),
+		Ano:      year,
+		Feriados: []CalendarHoliday{},
+	}
+	for _, holiday := range cal.Holidays(year) {
+		resp.Feriados = append(resp.Feriados, CalendarHoliday{Data: holiday.Data.Format(time.DateOnly), Nome: holiday.Nome})
+	}
+	for d := time.Date(year, time.January, 1, 12, 0, 0, 0, cal.Location); d.Year() == year; d = d.AddDate(0, 0, 1) {
+		if cal.IsBusinessDay(d) {
+			resp.DiasUteis++
+		}
+	}
+
+	writeJSON(w, r, http.StatusOK, resp)
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should list holidays and business days of year",
+			run:  shouldListHolidaysAndBusinessDaysOfYear,
+		},
+		{
+			name: "should return 404 for unknown calendar",
+			run:  shouldReturn404ForUnknownCalendar,
+		},
+		{
+			name: "should return 400 for invalid year",
+			run:  shouldReturn400ForInvalidYear,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func calendarRequest(country, query string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/v1/calendar/"+country+query, nil)
+	req.SetPathValue("country", country)
+	return req
+}
+
+func shouldListHolidaysAndBusinessDaysOfYear(t *testing.T) {
+	handler := NewCalendarHandler(domain.NewCalendars(map[string][]domain.Holiday{}), new(loggermock.LoggerMock))
+
+	recorder := httptest.NewRecorder()
+	handler.Handle(recorder, calendarRequest("target2", "?year=2026"))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"codigo":"EU"`)
+	assert.Contains(t, recorder.Body.String(), `{"data":"2026-04-06","nome":"Easter Monday"}`)
+	// 261 dias de semana em 2026, menos 5 feriados que caem em dia de semana (26/12 é sábado)
+	assert.Contains(t, recorder.Body.String(), `"dias_uteis":256`)
+}
+
+func shouldReturn404ForUnknownCalendar(t *testing.T) {
+	handler := NewCalendarHandler(domain.NewCalendars(nil), new(loggermock.LoggerMock))
+
+	recorder := httptest.NewRecorder()
+	handler.Handle(recorder, calendarRequest("JP", ""))
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), CodeNotFound)
+}
+
+func shouldReturn400ForInvalidYear(t *testing.T) {
+	handler := NewCalendarHandler(domain.NewCalendars(nil), new(loggermock.LoggerMock))
+
+	recorder := httptest.NewRecorder()
+	handler.Handle(recorder, calendarRequest("BR", "?year=dois-mil"))
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"field":"year"`)
+}
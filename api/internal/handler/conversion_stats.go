@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/pkg/logger"
+)
+
+// ConversionStatsHandler resume o histórico de conversões, incluindo os dias já arquivados
+type ConversionStatsHandler struct {
+	stats *domain.ConversionStatsUseCase
+	log   logger.Logger
+}
+
+func NewConversionStatsHandler(uc *domain.ConversionStatsUseCase, l logger.Logger) *ConversionStatsHandler {
+	return &ConversionStatsHandler{stats: uc, log: l}
+}
+
+// Handle atende GET /v1/conversions/statistics?currency=USD&from=2026-01-01&to=2026-01-31
+func (h *ConversionStatsHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	from, to, apiErr := parsePeriod(r)
+	if apiErr != nil {
+		writeAPIError(w, r, http.StatusBadRequest, *apiErr)
+		return
+	}
+
+	stats, err := h.stats.Execute(r.Context(), r.URL.Query().Get("currency"), from, to)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidPeriod) {
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "from"})
+			return
+		}
+		log.Error("Falha ao resumir histórico de conversões", "erro", err.Error())
+		writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao resumir histórico")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, stats)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type conversionAggregateReaderMock struct {
+	mock.Mock
+}
+
+func (m *conversionAggregateReaderMock) DailyAggregates(moeda string, from, to time.Time) ([]domain.ConversionDailyAggregate, error) {
+	args := m.Called(moeda, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ConversionDailyAggregate), args.Error(1)
+}
+
+func (m *conversionAggregateReaderMock) AggregateConversions(moeda string, from, to time.Time) ([]domain.ConversionDailyAggregate, error) {
+	args := m.Called(moeda, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ConversionDailyAggregate), args.Error(1)
+}
+
+func (m *conversionAggregateReaderMock) RetentionWatermark() (time.Time, error) {
+	args := m.Called()
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func TestConversionStatsHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should return archived and live days",
+			run:  shouldReturnArchivedAndLiveDays,
+		},
+		{
+			name: "should return 400 for inverted period",
+			run:  shouldReturn400ForInvertedPeriod,
+		},
+		{
+			name: "should return 500 when aggregation fails",
+			run:  shouldReturn500WhenAggregationFails,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldReturnArchivedAndLiveDays(t *testing.T) {
+	watermark := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	repo := new(conversionAggregateReaderMock)
+	repo.On("RetentionWatermark").Return(watermark, nil)
+	repo.On("DailyAggregates", "USD", mock.Anything, mock.Anything).Return([]domain.ConversionDailyAggregate{
+		{Dia: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), Moeda: "USD", Conversoes: 2, ValorEntrada: 200, ValorConvertido: 40, CotacaoMedia: 5},
+	}, nil)
+	repo.On("AggregateConversions", "USD", watermark, mock.Anything).Return([]domain.ConversionDailyAggregate{
+		{Dia: time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC), Moeda: "USD", Conversoes: 1, ValorEntrada: 100, ValorConvertido: 20, CotacaoMedia: 5},
+	}, nil)
+	handler := NewConversionStatsHandler(domain.NewConversionStatsUseCase(repo, new(loggermock.LoggerMock)), new(loggermock.LoggerMock))
+
+	recorder := httptest.NewRecorder()
+	handler.Handle(recorder, httptest.NewRequest(http.MethodGet, "/v1/conversions/statistics?currency=usd&from=2026-01-01&to=2026-01-31", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"conversoes":3`)
+	assert.Contains(t, recorder.Body.String(), `"arquivado":true`)
+	assert.Contains(t, recorder.Body.String(), `"arquivado":false`)
+}
+
+func shouldReturn400ForInvertedPeriod(t *testing.T) {
+	handler := NewConversionStatsHandler(domain.NewConversionStatsUseCase(new(conversionAggregateReaderMock), new(loggermock.LoggerMock)), new(loggermock.LoggerMock))
+
+	recorder := httptest.NewRecorder()
+	handler.Handle(recorder, httptest.NewRequest(http.MethodGet, "/v1/conversions/statistics?from=2026-02-01&to=2026-01-01", nil))
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"field":"from"`)
+}
+
+func shouldReturn500WhenAggregationFails(t *testing.T) {
+	repo := new(conversionAggregateReaderMock)
+	repo.On("RetentionWatermark").Return(time.Time{}, nil)
+	repo.On("AggregateConversions", "", mock.Anything, mock.Anything).Return(nil, errors.New("mongo fora"))
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Error", mock.Anything, mock.Anything).Return()
+	handler := NewConversionStatsHandler(domain.NewConversionStatsUseCase(repo, loggerMock), loggerMock)
+
+	recorder := httptest.NewRecorder()
+	handler.Handle(recorder, httptest.NewRequest(http.MethodGet, "/v1/conversions/statistics", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), CodeInternal)
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// V1Prefix é o prefixo das rotas versionadas, que respondem sempre com Envelope
+const V1Prefix = "/v1"
+
+// Códigos estáveis devolvidos em APIError.Code
+const (
+	CodeInvalidRequest   = "invalid_request"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeConflict         = "conflict"
+	CodeCurrencyNotFound = "currency_not_found"
+	CodeRateNotAvailable = "rate_not_available"
+	CodeRateLimited      = "rate_limited"
+	CodeQuotaExceeded    = "quota_exceeded"
+	CodeUpstreamBusy     = "upstream_unavailable"
+	CodeUpstreamError    = "upstream_error"
+	CodeSlowConsumer     = "slow_consumer"
+	CodeStreamClosed     = "stream_closed"
+	CodeInternal         = "internal_error"
+)
+
+// Envelope é o formato de toda resposta das rotas /v1
+type Envelope struct {
+	Data   any        `json:"data,omitempty"`
+	Meta   Meta       `json:"meta"`
+	Errors []APIError `json:"errors,omitempty"`
+}
+
+// Meta carrega informações sobre a resposta, não sobre o recurso
+type Meta struct {
+	RequestID     string      `json:"request_id,omitempty"`
+	GeneratedAt   time.Time   `json:"generated_at"`
+	RateSource    string      `json:"rate_source,omitempty"`
+	RateTimestamp *time.Time  `json:"rate_timestamp,omitempty"`
+	Pagination    *Pagination `json:"pagination,omitempty"`
+}
+
+type Pagination struct {
+	Limit   int    `json:"limit"`
+	Offset  int    `json:"offset"`
+	Count   int    `json:"count"`
+	HasMore bool   `json:"has_more"`
+	Next    string `json:"next,omitempty"`
+}
+
+// APIError descreve um erro; Field aponta o parâmetro culpado quando houver
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+// isV1 decide o formato da resposta. As rotas legadas continuam devolvendo o
+// JSON cru e erros em texto puro para não quebrar os clientes antigos.
+func isV1(r *http.Request) bool {
+	return r.URL.Path == V1Prefix || strings.HasPrefix(r.URL.Path, V1Prefix+"/")
+}
+
+// writeJSON responde com data envelopado nas rotas /v1 ou cru nas legadas
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, data any) {
+	writeJSONWithMeta(w, r, status, data, Meta{})
+}
+
+func writeJSONWithMeta(w http.ResponseWriter, r *http.Request, status int, data any, meta Meta) {
+	var body any = data
+	if isV1(r) {
+		body = Envelope{Data: data, Meta: fillMeta(r, meta)}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError responde com o erro envelopado nas rotas /v1 ou com http.Error nas legadas
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	writeAPIError(w, r, status, APIError{Code: code, Message: message})
+}
+
+func writeAPIError(w http.ResponseWriter, r *http.Request, status int, apiErr APIError) {
+	if !isV1(r) {
+		http.Error(w, apiErr.Message, status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Envelope{Meta: fillMeta(r, Meta{}), Errors: []APIError{apiErr}})
+}
+
+func fillMeta(r *http.Request, meta Meta) Meta {
+	meta.RequestID = RequestIDFromContext(r.Context())
+	meta.GeneratedAt = time.Now().UTC()
+	return meta
+}
+
+// pagination monta o bloco de paginação com o link da próxima página
+func pagination(r *http.Request, limit, offset, count int, hasMore bool) *Pagination {
+	p := &Pagination{Limit: limit, Offset: offset, Count: count, HasMore: hasMore}
+	if hasMore {
+		q := r.URL.Query()
+		q.Set("limit", strconv.Itoa(limit))
+		q.Set("offset", strconv.Itoa(offset+count))
+		p.Next = (&url.URL{Path: r.URL.Path, RawQuery: q.Encode()}).String()
+	}
+	return p
+}
+
+// NotFoundV1 responde 404 envelopado para caminhos /v1 sem rota
+func NotFoundV1(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusNotFound, CodeNotFound, "Recurso não encontrado")
+}
+
+// Deprecated marca uma rota legada com os cabeçalhos Deprecation, Sunset e
+// Link apontando a rota /v1 equivalente. Os {parametros} do successor são
+// preenchidos com os valores do caminho da requisição.
+func Deprecated(successor string, sunset time.Time) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			if !sunset.IsZero() {
+				w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+			w.Header().Add("Link", "<"+expandPath(successor, r)+`>; rel="successor-version"`)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func expandPath(pattern string, r *http.Request) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(pattern, '{')
+		end := strings.IndexByte(pattern, '}')
+		if start < 0 || end < start {
+			b.WriteString(pattern)
+			return b.String()
+		}
+		b.WriteString(pattern[:start])
+		b.WriteString(url.PathEscape(r.PathValue(pattern[start+1 : end])))
+		pattern = pattern[end+1:]
+	}
+}
@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type envelopeBody struct {
+	Data   json.RawMessage `json:"data"`
+	Meta   Meta            `json:"meta"`
+	Errors []APIError      `json:"errors"`
+}
+
+// newTestRouter monta as rotas reais com mocks; key é a chave colocada no contexto
+func newTestRouter(readerMock *conversionReaderMock, providerMock *rateProviderMock, key *domain.APIKey) http.Handler {
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Error", mock.Anything, mock.Anything).Return()
+
+	repoMock := new(repositoryMock)
+	repoMock.On("SaveHistory", mock.Anything).Return(nil)
+
+	counterMock := new(usageCounterMock)
+	counterMock.On("IncrementDailyUsage", mock.Anything, mock.Anything).Return(1, nil)
+	counterMock.On("DecrementDailyUsage", mock.Anything, mock.Anything).Return(nil)
+
+	mux := http.NewServeMux()
+	Routes{
+		Converter: NewConverterHandler(
+			domain.NewConverterUseCase(providerMock, repoMock, loggerMock),
+			domain.NewListConversionsUseCase(readerMock, loggerMock),
+			domain.NewVariationUseCase(new(rateSeriesReaderMock), loggerMock),
+			loggerMock,
+		),
+		LogLevels:    NewLogLevelHandler(new(levelControllerMock), loggerMock),
+		APIKeys:      NewAPIKeyHandler(domain.NewAPIKeyUseCase(new(apiKeyRepositoryMock), loggerMock), loggerMock),
+		Quotas:       domain.NewQuotaUseCase(counterMock, testPlans, loggerMock),
+		LegacySunset: time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC),
+	}.Register(mux)
+
+	return Chain(mux, RequestID(), func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if key != nil {
+				r = r.WithContext(domain.ContextWithAPIKey(r.Context(), key))
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+}
+
+func decodeEnvelope(t *testing.T, recorder *httptest.ResponseRecorder) envelopeBody {
+	t.Helper()
+	var body envelopeBody
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body), recorder.Body.String())
+	return body
+}
+
+func TestV1Envelope(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should wrap created conversion with rate meta",
+			run:  shouldWrapCreatedConversionWithRateMeta,
+		},
+		{
+			name: "should return envelope error with code and field",
+			run:  shouldReturnEnvelopeErrorWithCodeAndField,
+		},
+		{
+			name: "should paginate conversions with next link",
+			run:  shouldPaginateConversionsWithNextLink,
+		},
+		{
+			name: "should reject invalid pagination parameter",
+			run:  shouldRejectInvalidPaginationParameter,
+		},
+		{
+			name: "should envelope auth errors only on v1 routes",
+			run:  shouldEnvelopeAuthErrorsOnlyOnV1Routes,
+		},
+		{
+			name: "should return envelope 404 for unknown v1 path",
+			run:  shouldReturnEnvelope404ForUnknownV1Path,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldWrapCreatedConversionWithRateMeta(t *testing.T) {
+	providerMock := new(rateProviderMock)
+	providerMock.On("GetRate", "USD").Return(5.0, nil)
+	router := newTestRouter(new(conversionReaderMock), providerMock, &domain.APIKey{ID: "k1", Scopes: []string{domain.ScopeConvertWrite}})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/conversions", bytes.NewBufferString(`{"moeda": "USD", "valor_brl": 100}`))
+	req.Header.Set(RequestIDHeader, "req-123")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	body := decodeEnvelope(t, recorder)
+	assert.Equal(t, "req-123", body.Meta.RequestID)
+	assert.False(t, body.Meta.GeneratedAt.IsZero())
+	assert.NotNil(t, body.Meta.RateTimestamp)
+	assert.Empty(t, body.Errors)
+
+	var record domain.ConversionRecord
+	require.NoError(t, json.Unmarshal(body.Data, &record))
+	assert.Equal(t, 20.0, record.ValorConvertido)
+	assert.Equal(t, "k1", record.APIKeyID)
+	assert.Empty(t, recorder.Header().Get("Deprecation"))
+}
+
+func shouldReturnEnvelopeErrorWithCodeAndField(t *testing.T) {
+	providerMock := new(rateProviderMock)
+	providerMock.On("GetRate", "XYZ").Return(0.0, domain.ErrCurrencyNotFound)
+	router := newTestRouter(new(conversionReaderMock), providerMock, &domain.APIKey{ID: "k1", Scopes: []string{domain.ScopeConvertWrite}})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/conversions", bytes.NewBufferString(`{"moeda": "XYZ", "valor_brl": 100}`)))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	body := decodeEnvelope(t, recorder)
+	require.Len(t, body.Errors, 1)
+	assert.Equal(t, CodeCurrencyNotFound, body.Errors[0].Code)
+	assert.Equal(t, "moeda", body.Errors[0].Field)
+	assert.Empty(t, body.Data)
+}
+
+func shouldPaginateConversionsWithNextLink(t *testing.T) {
+	readerMock := new(conversionReaderMock)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	readerMock.On("FindConversions", domain.ConversionFilter{
+		Currency: "USD",
+		From:     from,
+		To:       from.Add(24*time.Hour - time.Nanosecond),
+		Limit:    3,
+		Offset:   4,
+	}).Return([]domain.ConversionRecord{{MoedaDestino: "USD"}, {MoedaDestino: "USD"}, {MoedaDestino: "USD"}}, nil)
+	router := newTestRouter(readerMock, new(rateProviderMock), &domain.APIKey{ID: "k1", Scopes: []string{domain.ScopeHistoryRead}})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/conversions?currency=usd&from=2026-01-01&to=2026-01-01&limit=2&offset=4", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	body := decodeEnvelope(t, recorder)
+	require.NotNil(t, body.Meta.Pagination)
+	assert.Equal(t, 2, body.Meta.Pagination.Count)
+	assert.True(t, body.Meta.Pagination.HasMore)
+	assert.Contains(t, body.Meta.Pagination.Next, "offset=6")
+	assert.Contains(t, body.Meta.Pagination.Next, "currency=usd")
+	readerMock.AssertExpectations(t)
+}
+
+func shouldRejectInvalidPaginationParameter(t *testing.T) {
+	readerMock := new(conversionReaderMock)
+	router := newTestRouter(readerMock, new(rateProviderMock), &domain.APIKey{ID: "k1", Scopes: []string{domain.ScopeHistoryRead}})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/conversions?offset=-1", nil))
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	body := decodeEnvelope(t, recorder)
+	require.Len(t, body.Errors, 1)
+	assert.Equal(t, "offset", body.Errors[0].Field)
+	readerMock.AssertNotCalled(t, "FindConversions", mock.Anything)
+}
+
+func shouldEnvelopeAuthErrorsOnlyOnV1Routes(t *testing.T) {
+	router := newTestRouter(new(conversionReaderMock), new(rateProviderMock), nil)
+
+	v1 := httptest.NewRecorder()
+	router.ServeHTTP(v1, httptest.NewRequest(http.MethodGet, "/v1/conversions", nil))
+	legacy := httptest.NewRecorder()
+	router.ServeHTTP(legacy, httptest.NewRequest(http.MethodGet, "/convert/list", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, v1.Code)
+	assert.Equal(t, CodeUnauthorized, decodeEnvelope(t, v1).Errors[0].Code)
+
+	// Clientes antigos continuam recebendo texto puro
+	assert.Equal(t, http.StatusUnauthorized, legacy.Code)
+	assert.Contains(t, legacy.Header().Get("Content-Type"), "text/plain")
+	assert.Equal(t, "Chave de API ausente ou inválida\n", legacy.Body.String())
+}
+
+func shouldReturnEnvelope404ForUnknownV1Path(t *testing.T) {
+	router := newTestRouter(new(conversionReaderMock), new(rateProviderMock), nil)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/nao-existe", nil))
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, CodeNotFound, decodeEnvelope(t, recorder).Errors[0].Code)
+}
+
+func TestLegacyRoutes(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should keep legacy body and announce successor",
+			run:  shouldKeepLegacyBodyAndAnnounceSuccessor,
+		},
+		{
+			name: "should expand path parameters in successor link",
+			run:  shouldExpandPathParametersInSuccessorLink,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldKeepLegacyBodyAndAnnounceSuccessor(t *testing.T) {
+	providerMock := new(rateProviderMock)
+	providerMock.On("GetRate", "USD").Return(5.0, nil)
+	router := newTestRouter(new(conversionReaderMock), providerMock, &domain.APIKey{ID: "k1", Scopes: []string{domain.ScopeConvertWrite}})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/converter", bytes.NewBufferString(`{"moeda": "USD", "valor_brl": 100}`)))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"valor_convertido": 20}`, recorder.Body.String())
+	assert.Equal(t, "true", recorder.Header().Get("Deprecation"))
+	assert.Equal(t, "Wed, 30 Jun 2027 00:00:00 GMT", recorder.Header().Get("Sunset"))
+	assert.Equal(t, `</v1/conversions>; rel="successor-version"`, recorder.Header().Get("Link"))
+}
+
+func shouldExpandPathParametersInSuccessorLink(t *testing.T) {
+	h := Deprecated("/v1/currencies/{moeda}/variations", time.Time{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /variation/{moeda}", h)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/variation/JPY", nil))
+
+	assert.Equal(t, `</v1/currencies/JPY/variations>; rel="successor-version"`, recorder.Header().Get("Link"))
+	assert.Empty(t, recorder.Header().Get("Sunset"))
+}
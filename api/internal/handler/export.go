@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/pkg/logger"
+)
+
+// ExportHandler entrega o histórico filtrado como arquivo, lido do banco aos poucos
+type ExportHandler struct {
+	export *domain.ExportUseCase
+	log    logger.Logger
+}
+
+func NewExportHandler(uc *domain.ExportUseCase, l logger.Logger) *ExportHandler {
+	return &ExportHandler{export: uc, log: l}
+}
+
+// Handle atende GET /v1/conversions/export?format=csv&delimiter=tab&decimal=comma com
+// os filtros da busca do histórico (currency, from, to, as_of e limit)
+func (h *ExportHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	filter, apiErr := parseConversionFilter(r)
+	if apiErr != nil {
+		writeAPIError(w, r, http.StatusBadRequest, *apiErr)
+		return
+	}
+	opts, apiErr := parseExportOptions(r)
+	if apiErr != nil {
+		writeAPIError(w, r, http.StatusBadRequest, *apiErr)
+		return
+	}
+	if err := h.export.Validate(filter, opts); err != nil {
+		writeAPIError(w, r, http.StatusBadRequest, exportError(err))
+		return
+	}
+
+	out := &exportResponse{ResponseWriter: w, format: opts.Format, now: time.Now()}
+	if _, err := h.export.Execute(r.Context(), filter, opts, out); err != nil {
+		if out.started {
+			// O status 200 já foi enviado: só resta cortar o arquivo, que chega incompleto
+			log.Warn("Exportação interrompida depois de iniciada", "erro", err.Error())
+			return
+		}
+		writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao exportar histórico")
+		return
+	}
+	// Sem nenhuma linha escrita (nem o cabeçalho) ainda é preciso responder o arquivo
+	out.start()
+}
+
+// parseExportOptions lê format, delimiter e decimal
+func parseExportOptions(r *http.Request) (domain.ExportOptions, *APIError) {
+	format, err := domain.ParseExportFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		return domain.ExportOptions{}, &APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "format"}
+	}
+	delimiter, decimalComma, apiErr := parseCSVOptions(r)
+	return domain.ExportOptions{Format: format, Delimiter: delimiter, DecimalComma: decimalComma}, apiErr
+}
+
+// parseCSVOptions lê delimiter (um caractere ou "tab") e decimal (point ou comma)
+func parseCSVOptions(r *http.Request) (delimiter rune, decimalComma bool, apiErr *APIError) {
+	q := r.URL.Query()
+
+	switch raw := q.Get("delimiter"); {
+	case raw == "":
+	case raw == "tab":
+		delimiter = '\t'
+	case utf8.RuneCountInString(raw) == 1:
+		delimiter, _ = utf8.DecodeRuneInString(raw)
+	default:
+		return 0, false, &APIError{Code: CodeInvalidRequest, Message: domain.ErrInvalidDelimiter.Error(), Field: "delimiter"}
+	}
+
+	switch q.Get("decimal") {
+	case "", "point":
+	case "comma":
+		decimalComma = true
+	default:
+		return 0, false, &APIError{Code: CodeInvalidRequest, Message: "Use point ou comma", Field: "decimal"}
+	}
+	return delimiter, decimalComma, nil
+}
+
+func exportError(err error) APIError {
+	field := "from"
+	switch {
+	case errors.Is(err, domain.ErrInvalidExportFormat):
+		field = "format"
+	case errors.Is(err, domain.ErrInvalidDelimiter):
+		field = "delimiter"
+	}
+	return APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: field}
+}
+
+// exportResponse só envia os cabeçalhos do arquivo no primeiro Write, para que
+// uma falha antes disso ainda possa virar uma resposta de erro
+type exportResponse struct {
+	http.ResponseWriter
+	format  domain.ExportFormat
+	now     time.Time
+	started bool
+}
+
+func (e *exportResponse) start() {
+	if e.started {
+		return
+	}
+	e.started = true
+	e.Header().Set("Content-Type", e.format.ContentType())
+	e.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="conversions-%s.%s"`, e.now.UTC().Format("20060102-150405"), e.format))
+	e.WriteHeader(http.StatusOK)
+}
+
+func (e *exportResponse) Write(p []byte) (int, error) {
+	e.start()
+	return e.ResponseWriter.Write(p)
+}
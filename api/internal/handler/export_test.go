@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type conversionStreamerMock struct {
+	mock.Mock
+}
+
+func (m *conversionStreamerMock) StreamConversions(ctx context.Context, filter domain.ConversionFilter, fn func(domain.ConversionRecord) error) error {
+	args := m.Called(filter)
+	if records, ok := args.Get(0).([]domain.ConversionRecord); ok {
+		for _, r := range records {
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func newExportLogger() *loggermock.LoggerMock {
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Error", mock.Anything, mock.Anything).Return()
+	return loggerMock
+}
+
+func TestExportHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should stream csv attachment with filters",
+			run:  shouldStreamCSVAttachmentWithFilters,
+		},
+		{
+			name: "should answer empty export with header only",
+			run:  shouldAnswerEmptyExportWithHeaderOnly,
+		},
+		{
+			name: "should return 400 for invalid export options",
+			run:  shouldReturn400ForInvalidExportOptions,
+		},
+		{
+			name: "should return 500 when export fails before first row",
+			run:  shouldReturn500WhenExportFailsBeforeFirstRow,
+		},
+		{
+			name: "should cut file when export fails after first row",
+			run:  shouldCutFileWhenExportFailsAfterFirstRow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldStreamCSVAttachmentWithFilters(t *testing.T) {
+	streamer := new(conversionStreamerMock)
+	streamer.On("StreamConversions", domain.ConversionFilter{
+		Currency: "USD",
+		From:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}).Return([]domain.ConversionRecord{
+		{MoedaDestino: "USD", Cotacao: 5.25, ValorEntrada: 100, ValorConvertido: 19.05, Data: time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)},
+	}, nil)
+	handler := NewExportHandler(domain.NewExportUseCase(streamer, newExportLogger()), newExportLogger())
+
+	recorder := httptest.NewRecorder()
+	handler.Handle(recorder, httptest.NewRequest(http.MethodGet, "/v1/conversions/export?currency=usd&from=2026-01-01&decimal=comma", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Regexp(t, `^attachment; filename="conversions-\d{8}-\d{6}\.csv"$`, recorder.Header().Get("Content-Disposition"))
+	assert.Contains(t, recorder.Body.String(), "2026-01-02T10:00:00Z;USD;100;5,25;19,05;")
+	streamer.AssertExpectations(t)
+}
+
+func shouldAnswerEmptyExportWithHeaderOnly(t *testing.T) {
+	streamer := new(conversionStreamerMock)
+	streamer.On("StreamConversions", mock.Anything).Return(nil, nil)
+	handler := NewExportHandler(domain.NewExportUseCase(streamer, newExportLogger()), newExportLogger())
+
+	recorder := httptest.NewRecorder()
+	handler.Handle(recorder, httptest.NewRequest(http.MethodGet, "/v1/conversions/export?format=ndjson", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/x-ndjson", recorder.Header().Get("Content-Type"))
+	assert.Empty(t, recorder.Body.String())
+}
+
+func shouldReturn400ForInvalidExportOptions(t *testing.T) {
+	handler := NewExportHandler(domain.NewExportUseCase(new(conversionStreamerMock), newExportLogger()), newExportLogger())
+
+	for query, field := range map[string]string{
+		"format=pdf":                    "format",
+		"delimiter=%3B%3B":              "delimiter",
+		"delimiter=,&decimal=comma":     "delimiter",
+		"decimal=virgula":               "decimal",
+		"from=2026-02-01&to=2026-01-01": "from",
+		"as_of=talvez":                  "as_of",
+	} {
+		recorder := httptest.NewRecorder()
+		handler.Handle(recorder, httptest.NewRequest(http.MethodGet, "/v1/conversions/export?"+query, nil))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+		assert.Contains(t, recorder.Body.String(), `"field":"`+field+`"`, query)
+	}
+}
+
+func shouldReturn500WhenExportFailsBeforeFirstRow(t *testing.T) {
+	streamer := new(conversionStreamerMock)
+	streamer.On("StreamConversions", mock.Anything).Return(nil, errors.New("mongo fora"))
+	handler := NewExportHandler(domain.NewExportUseCase(streamer, newExportLogger()), newExportLogger())
+
+	recorder := httptest.NewRecorder()
+	handler.Handle(recorder, httptest.NewRequest(http.MethodGet, "/v1/conversions/export?format=ndjson", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Content-Disposition"))
+	assert.Contains(t, recorder.Body.String(), CodeInternal)
+}
+
+func shouldCutFileWhenExportFailsAfterFirstRow(t *testing.T) {
+	// Registros suficientes para o CSV esvaziar o buffer antes da falha
+	records := make([]domain.ConversionRecord, 200)
+	for i := range records {
+		records[i] = domain.ConversionRecord{MoedaDestino: "USD", Cotacao: 5, ValorEntrada: 100, ValorConvertido: 20, Fonte: strings.Repeat("x", 50)}
+	}
+	streamer := new(conversionStreamerMock)
+	streamer.On("StreamConversions", mock.Anything).Return(records, errors.New("cursor perdido"))
+	handler := NewExportHandler(domain.NewExportUseCase(streamer, newExportLogger()), newExportLogger())
+
+	recorder := httptest.NewRecorder()
+	handler.Handle(recorder, httptest.NewRequest(http.MethodGet, "/v1/conversions/export", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), CodeInternal)
+}
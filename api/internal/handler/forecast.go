@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/pkg/logger"
+)
+
+// ForecastHandler prevê as cotações dos próximos dias úteis e mostra o erro de
+// cada modelo no histórico
+type ForecastHandler struct {
+	forecast *domain.ForecastUseCase
+	log      logger.Logger
+}
+
+func NewForecastHandler(uc *domain.ForecastUseCase, l logger.Logger) *ForecastHandler {
+	return &ForecastHandler{forecast: uc, log: l}
+}
+
+// Handle atende GET /v1/forecast/USD?horizon=30d&models=drift&level=95
+func (h *ForecastHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
+	q := r.URL.Query()
+
+	req := domain.ForecastRequest{Moeda: r.PathValue("moeda")}
+	var apiErr *APIError
+	req.From, req.To, apiErr = parsePeriod(r)
+	if apiErr != nil {
+		writeAPIError(w, r, http.StatusBadRequest, *apiErr)
+		return
+	}
+	if raw := q.Get("horizon"); raw != "" {
+		d, err := parseSpan(raw)
+		if err != nil || d <= 0 || d%(24*time.Hour) != 0 {
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: "Use um número de dias, como 30d", Field: "horizon"})
+			return
+		}
+		req.HorizonteDias = int(d / (24 * time.Hour))
+	}
+	for _, raw := range q["models"] {
+		for m := range strings.SplitSeq(raw, ",") {
+			if m = strings.ToLower(strings.TrimSpace(m)); m != "" {
+				req.Modelos = append(req.Modelos, m)
+			}
+		}
+	}
+	if raw := q.Get("level"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: "Deve ser um inteiro", Field: "level"})
+			return
+		}
+		req.Nivel = n
+	}
+
+	forecast, err := h.forecast.Execute(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidPeriod):
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "from"})
+		case errors.Is(err, domain.ErrInvalidHorizon):
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "horizon"})
+		case errors.Is(err, domain.ErrInvalidModel):
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "models"})
+		case errors.Is(err, domain.ErrInvalidConfidence):
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "level"})
+		case errors.Is(err, domain.ErrNoRates):
+			writeError(w, r, http.StatusNotFound, CodeNotFound, err.Error())
+		case errors.Is(err, domain.ErrNotEnoughRates):
+			writeAPIError(w, r, http.StatusUnprocessableEntity, APIError{Code: CodeRateNotAvailable, Message: err.Error(), Field: "from"})
+		default:
+			log.Error("Falha ao calcular previsão", "erro", err.Error())
+			writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao calcular previsão")
+		}
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, forecast)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-frete/api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestForecastHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should return forecast for horizon in days",
+			run:  shouldReturnForecastForHorizonInDays,
+		},
+		{
+			name: "should return 400 with field for invalid forecast query",
+			run:  shouldReturn400WithFieldForInvalidForecastQuery,
+		},
+		{
+			name: "should return 422 when history is too short",
+			run:  shouldReturn422WhenHistoryIsTooShort,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func forecastRequest(query string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/v1/forecast/USD"+query, nil)
+	req.SetPathValue("moeda", "USD")
+	return req
+}
+
+func newForecastHandlerFake(days int) (*ForecastHandler, *rateSeriesReaderMock) {
+	searcherMock := new(rateSeriesReaderMock)
+	start := time.Date(2026, 1, 1, 15, 0, 0, 0, time.UTC)
+	series := make([]domain.RateSnapshot, days)
+	for i := range series {
+		series[i] = domain.RateSnapshot{Moeda: "USD", Cotacao: 5 + float64(i%3)/10, Data: start.AddDate(0, 0, i)}
+	}
+	searcherMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return(series, nil)
+	return NewForecastHandler(domain.NewForecastUseCase(searcherMock, newExportLogger()), newExportLogger()), searcherMock
+}
+
+func shouldReturnForecastForHorizonInDays(t *testing.T) {
+	handler, _ := newForecastHandlerFake(20)
+
+	recorder := httptest.NewRecorder()
+	handler.Handle(recorder, forecastRequest("?horizon=5d&models=DRIFT&level=80"))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"horizonte_dias":5`)
+	assert.Contains(t, recorder.Body.String(), `"nivel":80`)
+	assert.Contains(t, recorder.Body.String(), `"recomendado":"drift"`)
+	assert.NotContains(t, recorder.Body.String(), domain.ModelRandomWalk)
+}
+
+func shouldReturn400WithFieldForInvalidForecastQuery(t *testing.T) {
+	handler, searcherMock := newForecastHandlerFake(20)
+
+	for query, field := range map[string]string{
+		"?horizon=36h":                   "horizon",
+		"?horizon=0d":                    "horizon",
+		"?horizon=365d":                  "horizon",
+		"?models=arima":                  "models",
+		"?level=alto":                    "level",
+		"?level=50":                      "level",
+		"?from=2026-02-01&to=2026-01-01": "from",
+	} {
+		recorder := httptest.NewRecorder()
+		handler.Handle(recorder, forecastRequest(query))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+		assert.Contains(t, recorder.Body.String(), `"field":"`+field+`"`, query)
+	}
+	searcherMock.AssertNotCalled(t, "GetRateSeries", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func shouldReturn422WhenHistoryIsTooShort(t *testing.T) {
+	handler, _ := newForecastHandlerFake(5)
+
+	recorder := httptest.NewRecorder()
+	handler.Handle(recorder, forecastRequest(""))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), CodeRateNotAvailable)
+}
@@ -2,19 +2,30 @@ package handler
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	"go-frete/api/internal/domain"
 	"go-frete/api/pkg/logger"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Request struct {
 	Moeda    string  `json:"moeda"`
 	ValorBRL float64 `json:"valor_brl"`
+	// Data (2006-01-02) ou data e hora RFC 3339 da conversão retroativa; vazio usa a cotação atual
+	Date string `json:"date,omitempty"`
+
+	asOf time.Time
 }
 
 type Response struct {
 	ValorConvertido float64 `json:"valor_convertido"`
+	// Preenchidos só na conversão retroativa
+	Cotacao     float64    `json:"cotacao,omitempty"`
+	Fonte       string     `json:"fonte,omitempty"`
+	DataCotacao *time.Time `json:"data_cotacao,omitempty"`
 }
 
 // O "Garçom" que atende o cliente
@@ -30,110 +41,294 @@ func NewConverterHandler(uc *domain.ConverterUseCase, luc *domain.ListConversion
 }
 
 func (h *ConverterHandler) Handle(w http.ResponseWriter, r *http.Request) {
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Println("Recuperado de pânico:", r)
-			http.Error(w, "Erro interno no servidor", http.StatusInternalServerError)
-		}
-	}()
+	log := logger.FromContext(r.Context(), h.log)
 
-	h.log.Info("Recebendo requisição", "endpoint", r.URL.Path, "metodo", r.Method)
 	if r.Method != http.MethodPost {
-		h.log.Warn("Método HTTP não permitido", "metodo_recebido", r.Method)
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		log.Warn("Método HTTP não permitido", "metodo_recebido", r.Method)
+		writeError(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Método não permitido")
 		return
 	}
 
-	var req Request
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.log.Warn("Falha ao fazer parse do JSON", "erro", err.Error())
-		http.Error(w, "JSON inválido", http.StatusBadRequest)
+	req, ok := h.decodeRequest(w, r)
+	if !ok {
 		return
 	}
 
-	h.log.Info("Dados validados com sucesso", "moeda", req.Moeda, "valor_brl", req.ValorBRL)
-
 	// CHAMA A REGRA DE NEGÓCIO
-	valorConvertido, err := h.converterUseCase.Execute(req.Moeda, req.ValorBRL)
-
+	record, err := h.convert(r, req)
 	if err != nil {
-		// Tratamento de erros customizados
-		if err.Error() == "moeda_nao_encontrada" {
-			h.log.Warn("Moeda solicitada não é suportada", "moeda_solicitada", req.Moeda)
-			http.Error(w, "Moeda não encontrada ou inválida", http.StatusUnprocessableEntity)
-			return
-		}
-		h.log.Error("Falha ao processar conversão na regra de negócio", "erro", err.Error(), "moeda", req.Moeda)
-		http.Error(w, err.Error(), http.StatusBadGateway)
+		h.writeConversionError(w, r, err, req.Moeda)
 		return
 	}
-	h.log.Info("Requisição finalizada com sucesso", "valor_convertido", valorConvertido)
+	log.Info("Requisição finalizada com sucesso", "valor_convertido", record.ValorConvertido)
 
 	// DEVOLVE A RESPOSTA
-	respo := Response{ValorConvertido: valorConvertido}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(respo)
+	resp := Response{ValorConvertido: record.ValorConvertido}
+	if record.Retroativa {
+		resp.Cotacao, resp.Fonte, resp.DataCotacao = record.Cotacao, record.Fonte, record.DataCotacao
+	}
+	writeJSON(w, r, http.StatusOK, resp)
 }
 
-func (h *ConverterHandler) ListHandle(w http.ResponseWriter, r *http.Request) {
-	defer func() {
-		if rec := recover(); rec != nil {
-			h.log.Error("Recuperado de pânico no list handler", "detalhe", rec)
-			http.Error(w, "Erro interno no servidor", http.StatusInternalServerError)
+// CreateHandle atende POST /v1/conversions devolvendo o registro completo criado
+func (h *ConverterHandler) CreateHandle(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	req, ok := h.decodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := h.convert(r, req)
+	if err != nil {
+		h.writeConversionError(w, r, err, req.Moeda)
+		return
+	}
+	log.Info("Requisição finalizada com sucesso", "valor_convertido", record.ValorConvertido)
+
+	rateTimestamp := record.Data
+	if record.DataCotacao != nil {
+		rateTimestamp = *record.DataCotacao
+	}
+	writeJSONWithMeta(w, r, http.StatusCreated, record, Meta{
+		RateSource:    record.Fonte,
+		RateTimestamp: &rateTimestamp,
+	})
+}
+
+func (h *ConverterHandler) decodeRequest(w http.ResponseWriter, r *http.Request) (Request, bool) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn("Falha ao fazer parse do JSON", "erro", err.Error())
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "JSON inválido")
+		return req, false
+	}
+	if req.Date != "" {
+		asOf, err := parseAsOf(req.Date, time.Now())
+		if err != nil {
+			writeAPIError(w, r, http.StatusBadRequest, APIError{
+				Code: CodeInvalidRequest, Message: "Use uma data (2006-01-02) ou data e hora RFC 3339", Field: "date",
+			})
+			return req, false
 		}
-	}()
+		req.asOf = asOf
+	}
 
-	h.log.Info("Recebendo requisição de listagem", "endpoint", r.URL.Path, "metodo", r.Method)
+	log.Info("Dados validados com sucesso", "moeda", req.Moeda, "valor_brl", req.ValorBRL)
+	return req, true
+}
+
+// convert usa a cotação atual ou, com date informado, a vigente naquele instante
+func (h *ConverterHandler) convert(r *http.Request, req Request) (domain.ConversionRecord, error) {
+	if req.asOf.IsZero() {
+		return h.converterUseCase.Convert(r.Context(), req.Moeda, req.ValorBRL)
+	}
+	return h.converterUseCase.ConvertAsOf(r.Context(), req.Moeda, req.ValorBRL, req.asOf)
+}
+
+// parseAsOf lê o date da conversão retroativa. Uma data sem hora vale pelo
+// fechamento do dia, ou pelo instante atual se o dia ainda não terminou.
+func parseAsOf(raw string, now time.Time) (time.Time, error) {
+	t, err := parseTimeParam(raw)
+	if err != nil || len(raw) != len(time.DateOnly) {
+		return t, err
+	}
+	if end := t.Add(24*time.Hour - time.Nanosecond); end.Before(now) {
+		return end, nil
+	}
+	if t.After(now) {
+		// Dia futuro: o caso de uso recusa
+		return t, nil
+	}
+	return now, nil
+}
+
+// Tratamento de erros customizados da conversão
+func (h *ConverterHandler) writeConversionError(w http.ResponseWriter, r *http.Request, err error, moeda string) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	switch {
+	case errors.Is(err, domain.ErrCurrencyNotFound):
+		log.Warn("Moeda solicitada não é suportada", "moeda_solicitada", moeda)
+		writeAPIError(w, r, http.StatusUnprocessableEntity, APIError{
+			Code: CodeCurrencyNotFound, Message: "Moeda não encontrada ou inválida", Field: "moeda",
+		})
+	case errors.Is(err, domain.ErrAsOfInFuture):
+		writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "date"})
+	case errors.Is(err, domain.ErrRateNotAvailable):
+		log.Warn("Sem cotação para a data da conversão retroativa", "moeda", moeda)
+		writeAPIError(w, r, http.StatusUnprocessableEntity, APIError{Code: CodeRateNotAvailable, Message: err.Error(), Field: "date"})
+	case errors.Is(err, domain.ErrUpstreamBudgetExhausted):
+		log.Warn("Orçamento de chamadas ao provedor esgotado", "moeda", moeda)
+		setRetryAfter(w, err)
+		writeError(w, r, http.StatusServiceUnavailable, CodeUpstreamBusy, err.Error())
+	default:
+		log.Error("Falha ao processar conversão na regra de negócio", "erro", err.Error(), "moeda", moeda)
+		writeError(w, r, http.StatusBadGateway, CodeUpstreamError, err.Error())
+	}
+}
+
+func (h *ConverterHandler) ListHandle(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
 
 	if r.Method != http.MethodGet {
-		h.log.Warn("Método HTTP não permitido para listagem", "metodo_recebido", r.Method)
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		log.Warn("Método HTTP não permitido para listagem", "metodo_recebido", r.Method)
+		writeError(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Método não permitido")
 		return
 	}
 
 	// Chama a regra de negócio
-	records, err := h.listUseCase.Execute()
+	records, err := h.listUseCase.Execute(r.Context())
 	if err != nil {
-		h.log.Error("Falha ao processar listagem na regra de negócio", "erro", err.Error())
-		http.Error(w, "Erro ao buscar histórico", http.StatusInternalServerError)
+		log.Error("Falha ao processar listagem na regra de negócio", "erro", err.Error())
+		writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao buscar histórico")
 		return
 	}
 
-	h.log.Info("Listagem finalizada com sucesso")
+	log.Info("Listagem finalizada com sucesso")
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(records)
+	writeJSON(w, r, http.StatusOK, records)
 }
 
-func (h *ConverterHandler) VariationHandle(w http.ResponseWriter, r *http.Request) {
-	defer func() {
-		if rec := recover(); rec != nil {
-			h.log.Error("Recuperado de pânico no variation handler", "detalhe", rec)
-			http.Error(w, "Erro interno no servidor", http.StatusInternalServerError)
+// SearchHandle atende GET /v1/conversions com filtros e paginação por limit/offset
+func (h *ConverterHandler) SearchHandle(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	filter, apiErr := parseConversionFilter(r)
+	if apiErr != nil {
+		writeAPIError(w, r, http.StatusBadRequest, *apiErr)
+		return
+	}
+
+	page, err := h.listUseCase.Search(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidPeriod) {
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "from"})
+			return
+		}
+		log.Error("Falha ao processar busca na regra de negócio", "erro", err.Error())
+		writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao buscar histórico")
+		return
+	}
+
+	writeJSONWithMeta(w, r, http.StatusOK, page.Records, Meta{
+		Pagination: pagination(r, page.Limit, page.Offset, len(page.Records), page.HasMore),
+	})
+}
+
+// parseConversionFilter lê currency, from, to, as_of, limit e offset da query string
+func parseConversionFilter(r *http.Request) (domain.ConversionFilter, *APIError) {
+	q := r.URL.Query()
+	filter := domain.ConversionFilter{Currency: strings.ToUpper(q.Get("currency"))}
+
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"limit", &filter.Limit}, {"offset", &filter.Offset}} {
+		raw := q.Get(p.name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return filter, &APIError{Code: CodeInvalidRequest, Message: "Deve ser um inteiro não negativo", Field: p.name}
+		}
+		*p.dst = n
+	}
+
+	if raw := q.Get("as_of"); raw != "" {
+		asOf, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, &APIError{Code: CodeInvalidRequest, Message: "Use true ou false", Field: "as_of"}
+		}
+		filter.Retroativa = &asOf
+	}
+
+	var apiErr *APIError
+	filter.From, filter.To, apiErr = parsePeriod(r)
+	return filter, apiErr
+}
+
+// parsePeriod lê from e to da query string; ausentes ficam com o valor zero
+func parsePeriod(r *http.Request) (from, to time.Time, apiErr *APIError) {
+	q := r.URL.Query()
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		raw := q.Get(p.name)
+		if raw == "" {
+			continue
 		}
-	}()
+		t, err := parseTimeParam(raw)
+		if err != nil {
+			return from, to, &APIError{Code: CodeInvalidRequest, Message: "Use uma data (2006-01-02) ou data e hora RFC 3339", Field: p.name}
+		}
+		*p.dst = t
+	}
+	// Uma data sem hora no "to" inclui o dia inteiro
+	if raw := q.Get("to"); len(raw) == len(time.DateOnly) {
+		to = to.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	return from, to, nil
+}
+
+func parseTimeParam(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, raw)
+}
+
+func (h *ConverterHandler) VariationHandle(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
 
 	// Captura a variável {moeda} da URL
 	moeda := r.PathValue("moeda")
 
 	if moeda == "" {
-		h.log.Warn("Moeda não informada na rota")
-		http.Error(w, "Moeda deve ser informada na rota (ex: /variation/JPY)", http.StatusBadRequest)
+		log.Warn("Moeda não informada na rota")
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "Moeda deve ser informada na rota (ex: /variation/JPY)")
 		return
 	}
 
-	h.log.Info("Recebendo requisição de variação", "moeda", moeda)
+	variations, err := h.variationUseCase.Execute(r.Context(), moeda)
+	if err != nil {
+		log.Error("Falha ao calcular variação", "erro", err.Error())
+		writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao buscar variações")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, variations)
+}
+
+// StatisticsHandle resume as cotações coletadas da moeda no período (padrão: últimos 30 dias)
+func (h *ConverterHandler) StatisticsHandle(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
+	moeda := r.PathValue("moeda")
 
-	variations, err := h.variationUseCase.Execute(moeda)
+	from, to, apiErr := parsePeriod(r)
+	if apiErr != nil {
+		writeAPIError(w, r, http.StatusBadRequest, *apiErr)
+		return
+	}
+
+	stats, err := h.variationUseCase.Statistics(r.Context(), moeda, from, to)
 	if err != nil {
-		h.log.Error("Falha ao calcular variação", "erro", err.Error())
-		http.Error(w, "Erro ao buscar variações", http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, domain.ErrInvalidPeriod):
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "from"})
+		case errors.Is(err, domain.ErrNoRates):
+			writeError(w, r, http.StatusNotFound, CodeNotFound, err.Error())
+		default:
+			log.Error("Falha ao calcular estatísticas", "erro", err.Error())
+			writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao calcular estatísticas")
+		}
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(variations)
+	writeJSON(w, r, http.StatusOK, stats)
 }
@@ -6,12 +6,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"go-frete/api/internal/domain"
 	"go-frete/api/tests/mocks/loggermock"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type rateProviderMock struct {
@@ -44,16 +46,24 @@ func (m *conversionReaderMock) GetLastConversions(limit int) ([]domain.Conversio
 	return args.Get(0).([]domain.ConversionRecord), args.Error(1)
 }
 
-type conversionSearcherMock struct {
+func (m *conversionReaderMock) FindConversions(filter domain.ConversionFilter) ([]domain.ConversionRecord, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ConversionRecord), args.Error(1)
+}
+
+type rateSeriesReaderMock struct {
 	mock.Mock
 }
 
-func (m *conversionSearcherMock) GetConversionsByCurrency(currency string) ([]domain.ConversionRecord, error) {
-	args := m.Called(currency)
+func (m *rateSeriesReaderMock) GetRateSeries(moeda string, from, to time.Time) ([]domain.RateSnapshot, error) {
+	args := m.Called(moeda, from, to)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]domain.ConversionRecord), args.Error(1)
+	return args.Get(0).([]domain.RateSnapshot), args.Error(1)
 }
 
 func TestConverterHandler_Handle(t *testing.T) {
@@ -158,7 +168,7 @@ func shouldReturn422UnprocessableEntity(t *testing.T) {
 	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
 	loggerMock.On("Error", mock.Anything, mock.Anything).Return()
 
-	providerMock.On("GetRate", "XYZ").Return(0.0, errors.New("moeda_nao_encontrada"))
+	providerMock.On("GetRate", "XYZ").Return(0.0, domain.ErrCurrencyNotFound)
 
 	usecase := domain.NewConverterUseCase(providerMock, repoMock, loggerMock)
 	listUseCase := domain.NewListConversionsUseCase(new(conversionReaderMock), loggerMock)
@@ -198,6 +208,7 @@ func shouldReturn502BadGatewayForGenericError(t *testing.T) {
 func shouldRecoverFromPanicInHandle(t *testing.T) {
 	loggerMock := new(loggermock.LoggerMock)
 	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Error", mock.Anything, mock.Anything).Return()
 
 	handler := NewConverterHandler(nil, nil, nil, loggerMock)
 
@@ -205,7 +216,8 @@ func shouldRecoverFromPanicInHandle(t *testing.T) {
 	req, _ := http.NewRequest(http.MethodPost, "/converter", bytes.NewBuffer(body))
 	recorder := httptest.NewRecorder()
 
-	handler.Handle(recorder, req)
+	// O pânico agora é tratado pelo middleware Recoverer
+	Recoverer(loggerMock)(http.HandlerFunc(handler.Handle)).ServeHTTP(recorder, req)
 
 	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
 }
@@ -305,7 +317,8 @@ func shouldRecoverFromPanicInListHandle(t *testing.T) {
 	req, _ := http.NewRequest(http.MethodGet, "/convert/list", nil)
 	recorder := httptest.NewRecorder()
 
-	handler.ListHandle(recorder, req)
+	// O pânico agora é tratado pelo middleware Recoverer
+	Recoverer(loggerMock)(http.HandlerFunc(handler.ListHandle)).ServeHTTP(recorder, req)
 
 	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
 }
@@ -339,12 +352,12 @@ func TestConverterHandler_VariationHandle(t *testing.T) {
 }
 
 func shouldReturn200OkForVariation(t *testing.T) {
-	searcherMock := new(conversionSearcherMock)
+	searcherMock := new(rateSeriesReaderMock)
 	loggerMock := new(loggermock.LoggerMock)
 
 	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
 
-	searcherMock.On("GetConversionsByCurrency", "USD").Return(nil, nil)
+	searcherMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return(nil, nil)
 
 	variationUseCase := domain.NewVariationUseCase(searcherMock, loggerMock)
 	handler := NewConverterHandler(nil, nil, variationUseCase, loggerMock)
@@ -375,13 +388,13 @@ func shouldReturn400BadRequestForMissingCurrency(t *testing.T) {
 }
 
 func shouldReturn500ForVariationError(t *testing.T) {
-	searcherMock := new(conversionSearcherMock)
+	searcherMock := new(rateSeriesReaderMock)
 	loggerMock := new(loggermock.LoggerMock)
 
 	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
 	loggerMock.On("Error", mock.Anything, mock.Anything).Return()
 
-	searcherMock.On("GetConversionsByCurrency", "EUR").Return(nil, errors.New("db error"))
+	searcherMock.On("GetRateSeries", "EUR", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
 
 	variationUseCase := domain.NewVariationUseCase(searcherMock, loggerMock)
 	handler := NewConverterHandler(nil, nil, variationUseCase, loggerMock)
@@ -407,7 +420,237 @@ func shouldRecoverFromPanicInVariationHandle(t *testing.T) {
 
 	recorder := httptest.NewRecorder()
 
-	handler.VariationHandle(recorder, req)
+	// O pânico agora é tratado pelo middleware Recoverer
+	Recoverer(loggerMock)(http.HandlerFunc(handler.VariationHandle)).ServeHTTP(recorder, req)
 
 	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
 }
+
+func TestConverterHandler_StatisticsHandle(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should return 200 OK with statistics for period",
+			run:  shouldReturn200OkWithStatisticsForPeriod,
+		},
+		{
+			name: "should return 404 when no rates were collected",
+			run:  shouldReturn404WhenNoRatesWereCollected,
+		},
+		{
+			name: "should return 400 for invalid period",
+			run:  shouldReturn400ForInvalidStatisticsPeriod,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func statisticsRequest(query string) *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, "/v1/currencies/USD/statistics"+query, nil)
+	req.SetPathValue("moeda", "USD")
+	return req
+}
+
+func shouldReturn200OkWithStatisticsForPeriod(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	// "to" só com data inclui o dia inteiro
+	to := time.Date(2026, 1, 31, 23, 59, 59, int(time.Second-time.Nanosecond), time.UTC)
+	searcherMock.On("GetRateSeries", "USD", from, to).Return([]domain.RateSnapshot{
+		{Moeda: "USD", Cotacao: 5.0, Data: from},
+		{Moeda: "USD", Cotacao: 5.5, Data: to},
+	}, nil)
+
+	handler := NewConverterHandler(nil, nil, domain.NewVariationUseCase(searcherMock, loggerMock), loggerMock)
+	recorder := httptest.NewRecorder()
+	handler.StatisticsHandle(recorder, statisticsRequest("?from=2026-01-01&to=2026-01-31"))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"amostras":2`)
+	assert.Contains(t, recorder.Body.String(), `"variacao_percentual":10`)
+	searcherMock.AssertExpectations(t)
+}
+
+func shouldReturn404WhenNoRatesWereCollected(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	searcherMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return([]domain.RateSnapshot{}, nil)
+
+	handler := NewConverterHandler(nil, nil, domain.NewVariationUseCase(searcherMock, loggerMock), loggerMock)
+	recorder := httptest.NewRecorder()
+	handler.StatisticsHandle(recorder, statisticsRequest(""))
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), CodeNotFound)
+}
+
+func shouldReturn400ForInvalidStatisticsPeriod(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	handler := NewConverterHandler(nil, nil, domain.NewVariationUseCase(searcherMock, loggerMock), loggerMock)
+
+	recorder := httptest.NewRecorder()
+	handler.StatisticsHandle(recorder, statisticsRequest("?from=ontem"))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	handler.StatisticsHandle(recorder, statisticsRequest("?from=2026-02-01&to=2026-01-01"))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	searcherMock.AssertNotCalled(t, "GetRateSeries", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestConverterHandler_AsOf(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should convert with rate in effect at date",
+			run:  shouldConvertWithRateInEffectAtDate,
+		},
+		{
+			name: "should return rate details on legacy route",
+			run:  shouldReturnRateDetailsOnLegacyRoute,
+		},
+		{
+			name: "should return 400 for invalid date",
+			run:  shouldReturn400ForInvalidDate,
+		},
+		{
+			name: "should return 422 when no rate is available at date",
+			run:  shouldReturn422WhenNoRateIsAvailableAtDate,
+		},
+		{
+			name: "should filter history by as of flag",
+			run:  shouldFilterHistoryByAsOfFlag,
+		},
+		{
+			name: "should treat date only as end of day",
+			run:  shouldTreatDateOnlyAsEndOfDay,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+// newAsOfHandler monta o handler com conversões retroativas lendo a série do mock
+func newAsOfHandler(seriesMock *rateSeriesReaderMock, repoMock *repositoryMock) *ConverterHandler {
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+
+	usecase := domain.NewConverterUseCase(new(rateProviderMock), repoMock, loggerMock).
+		WithRateHistory(domain.NewHistoricalRateResolver(seriesMock, nil))
+	return NewConverterHandler(usecase, nil, nil, loggerMock)
+}
+
+func shouldConvertWithRateInEffectAtDate(t *testing.T) {
+	at := time.Date(2026, 1, 5, 15, 0, 0, 0, time.UTC)
+	rateAt := time.Date(2026, 1, 5, 14, 45, 0, 0, time.UTC)
+	seriesMock := new(rateSeriesReaderMock)
+	seriesMock.On("GetRateSeries", "USD", at.Add(-domain.AsOfLookback), at).
+		Return([]domain.RateSnapshot{{Moeda: "USD", Cotacao: 4.0, Fonte: "awesomeapi", Data: rateAt}}, nil)
+	repoMock := new(repositoryMock)
+	repoMock.On("SaveHistory", mock.Anything).Return(nil)
+
+	body := bytes.NewBufferString(`{"moeda": "USD", "valor_brl": 100, "date": "2026-01-05T15:00:00Z"}`)
+	recorder := httptest.NewRecorder()
+	newAsOfHandler(seriesMock, repoMock).CreateHandle(recorder, httptest.NewRequest(http.MethodPost, "/v1/conversions", body))
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"valor_convertido":25`)
+	assert.Contains(t, recorder.Body.String(), `"retroativa":true`)
+	assert.Contains(t, recorder.Body.String(), `"data_cotacao":"2026-01-05T14:45:00Z"`)
+	// O meta aponta o horário da cotação usada, não o da conversão
+	assert.Contains(t, recorder.Body.String(), `"rate_timestamp":"2026-01-05T14:45:00Z"`)
+	repoMock.AssertCalled(t, "SaveHistory", mock.MatchedBy(func(r domain.ConversionRecord) bool { return r.Retroativa }))
+}
+
+func shouldReturnRateDetailsOnLegacyRoute(t *testing.T) {
+	seriesMock := new(rateSeriesReaderMock)
+	seriesMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).
+		Return([]domain.RateSnapshot{{Moeda: "USD", Cotacao: 4.0, Fonte: "awesomeapi", Data: time.Date(2026, 1, 5, 18, 0, 0, 0, time.UTC)}}, nil)
+	repoMock := new(repositoryMock)
+	repoMock.On("SaveHistory", mock.Anything).Return(nil)
+
+	body := bytes.NewBufferString(`{"moeda": "USD", "valor_brl": 100, "date": "2026-01-05"}`)
+	recorder := httptest.NewRecorder()
+	newAsOfHandler(seriesMock, repoMock).Handle(recorder, httptest.NewRequest(http.MethodPost, "/converter", body))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"valor_convertido":25,"cotacao":4,"fonte":"awesomeapi","data_cotacao":"2026-01-05T18:00:00Z"}`, recorder.Body.String())
+}
+
+func shouldReturn400ForInvalidDate(t *testing.T) {
+	seriesMock := new(rateSeriesReaderMock)
+
+	body := bytes.NewBufferString(`{"moeda": "USD", "valor_brl": 100, "date": "05/01/2026"}`)
+	recorder := httptest.NewRecorder()
+	newAsOfHandler(seriesMock, new(repositoryMock)).CreateHandle(recorder, httptest.NewRequest(http.MethodPost, "/v1/conversions", body))
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"field":"date"`)
+	seriesMock.AssertNotCalled(t, "GetRateSeries", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func shouldReturn422WhenNoRateIsAvailableAtDate(t *testing.T) {
+	seriesMock := new(rateSeriesReaderMock)
+	seriesMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return([]domain.RateSnapshot{}, nil)
+	repoMock := new(repositoryMock)
+
+	body := bytes.NewBufferString(`{"moeda": "USD", "valor_brl": 100, "date": "2020-01-01"}`)
+	recorder := httptest.NewRecorder()
+	newAsOfHandler(seriesMock, repoMock).CreateHandle(recorder, httptest.NewRequest(http.MethodPost, "/v1/conversions", body))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), CodeRateNotAvailable)
+	repoMock.AssertNotCalled(t, "SaveHistory", mock.Anything)
+}
+
+func shouldFilterHistoryByAsOfFlag(t *testing.T) {
+	live := false
+	readerMock := new(conversionReaderMock)
+	readerMock.On("FindConversions", domain.ConversionFilter{Limit: domain.SearchLimit + 1, Retroativa: &live}).
+		Return([]domain.ConversionRecord{}, nil)
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	handler := NewConverterHandler(nil, domain.NewListConversionsUseCase(readerMock, loggerMock), nil, loggerMock)
+
+	recorder := httptest.NewRecorder()
+	handler.SearchHandle(recorder, httptest.NewRequest(http.MethodGet, "/v1/conversions?as_of=false", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	readerMock.AssertExpectations(t)
+
+	recorder = httptest.NewRecorder()
+	handler.SearchHandle(recorder, httptest.NewRequest(http.MethodGet, "/v1/conversions?as_of=talvez", nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func shouldTreatDateOnlyAsEndOfDay(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	past, err := parseAsOf("2026-01-05", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 5, 23, 59, 59, int(time.Second-time.Nanosecond), time.UTC), past)
+
+	// O dia de hoje ainda não fechou: vale a cotação atual
+	today, err := parseAsOf("2026-01-10", now)
+	require.NoError(t, err)
+	assert.Equal(t, now, today)
+
+	exact, err := parseAsOf("2026-01-05T09:30:00-03:00", now)
+	require.NoError(t, err)
+	assert.True(t, exact.Equal(time.Date(2026, 1, 5, 12, 30, 0, 0, time.UTC)))
+}
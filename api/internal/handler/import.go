@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/pkg/logger"
+)
+
+// ImportHandler recebe arquivos CSV ou NDJSON com conversões para o histórico
+type ImportHandler struct {
+	imports  *domain.ImportUseCase
+	maxBytes int64
+	log      logger.Logger
+}
+
+func NewImportHandler(uc *domain.ImportUseCase, maxBytes int64, l logger.Logger) *ImportHandler {
+	return &ImportHandler{imports: uc, maxBytes: maxBytes, log: l}
+}
+
+// Handle atende POST /v1/conversions/import?format=csv&columns=data=Data,currency=Moeda&tz=America/Sao_Paulo&dry_run=true.
+// O arquivo vem no corpo, lido aos poucos; a resposta é o relatório da importação.
+func (h *ImportHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	opts, apiErr := parseImportOptions(r)
+	if apiErr != nil {
+		writeAPIError(w, r, http.StatusBadRequest, *apiErr)
+		return
+	}
+
+	body := r.Body
+	if h.maxBytes > 0 {
+		body = http.MaxBytesReader(w, r.Body, h.maxBytes)
+	}
+	report, err := h.imports.Execute(r.Context(), body, opts)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			writeError(w, r, http.StatusRequestEntityTooLarge, CodeInvalidRequest,
+				fmt.Sprintf("Arquivo maior que %d bytes: divida em partes; %d conversões já foram importadas e reenviar não as duplica", tooLarge.Limit, report.Importadas))
+		case errors.Is(err, domain.ErrInvalidImportFormat),
+			errors.Is(err, domain.ErrInvalidColumnMapping),
+			errors.Is(err, domain.ErrMissingColumns),
+			errors.Is(err, domain.ErrInvalidDelimiter):
+			writeAPIError(w, r, http.StatusBadRequest, importError(err))
+		default:
+			log.Error("Falha na importação do histórico", "importadas", report.Importadas, "erro", err.Error())
+			writeError(w, r, http.StatusInternalServerError, CodeInternal,
+				fmt.Sprintf("Erro ao importar histórico: %d conversões já foram importadas e reenviar o arquivo não as duplica", report.Importadas))
+		}
+		return
+	}
+	writeJSON(w, r, http.StatusOK, report)
+}
+
+// parseImportOptions lê format (ou o Content-Type), delimiter, decimal, columns, tz e dry_run
+func parseImportOptions(r *http.Request) (domain.ImportOptions, *APIError) {
+	q := r.URL.Query()
+
+	rawFormat := q.Get("format")
+	if rawFormat == "" {
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == domain.ExportNDJSON.ContentType() {
+			rawFormat = string(domain.ExportNDJSON)
+		}
+	}
+	format, err := domain.ParseImportFormat(rawFormat)
+	if err != nil {
+		return domain.ImportOptions{}, &APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "format"}
+	}
+	delimiter, decimalComma, apiErr := parseCSVOptions(r)
+	if apiErr != nil {
+		return domain.ImportOptions{}, apiErr
+	}
+	opts := domain.ImportOptions{Format: format, Delimiter: delimiter, DecimalComma: decimalComma}
+
+	if opts.Columns, err = domain.ParseColumnMapping(q.Get("columns")); err != nil {
+		return opts, &APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "columns"}
+	}
+	if raw := q.Get("tz"); raw != "" {
+		if opts.Location, err = time.LoadLocation(raw); err != nil {
+			return opts, &APIError{Code: CodeInvalidRequest, Message: "Fuso horário desconhecido: " + raw, Field: "tz"}
+		}
+	}
+	if raw := q.Get("dry_run"); raw != "" {
+		if opts.DryRun, err = strconv.ParseBool(raw); err != nil {
+			return opts, &APIError{Code: CodeInvalidRequest, Message: "Use true ou false", Field: "dry_run"}
+		}
+	}
+	return opts, nil
+}
+
+func importError(err error) APIError {
+	field := ""
+	switch {
+	case errors.Is(err, domain.ErrInvalidImportFormat):
+		field = "format"
+	case errors.Is(err, domain.ErrInvalidColumnMapping), errors.Is(err, domain.ErrMissingColumns):
+		field = "columns"
+	case errors.Is(err, domain.ErrInvalidDelimiter):
+		field = "delimiter"
+	}
+	return APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: field}
+}
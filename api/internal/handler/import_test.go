@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-frete/api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type importRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *importRepositoryMock) ExistingConversionKeys(keys []domain.ConversionKey) ([]domain.ConversionKey, error) {
+	args := m.Called(keys)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ConversionKey), args.Error(1)
+}
+
+func (m *importRepositoryMock) SaveSnapshots(snapshots []domain.RateSnapshot) error {
+	return m.Called(snapshots).Error(0)
+}
+
+func (m *importRepositoryMock) RetentionWatermark() (time.Time, error) {
+	args := m.Called()
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+// newImportRepositoryMock não acha duplicatas, aceita as cotações e nunca arquivou nada
+func newImportRepositoryMock() *importRepositoryMock {
+	m := new(importRepositoryMock)
+	m.On("ExistingConversionKeys", mock.Anything).Return([]domain.ConversionKey{}, nil)
+	m.On("SaveSnapshots", mock.Anything).Return(nil)
+	m.On("RetentionWatermark").Return(time.Time{}, nil)
+	return m
+}
+
+func newImportHandlerFake(repo *repositoryMock, maxBytes int64) *ImportHandler {
+	return NewImportHandler(domain.NewImportUseCase(repo, newImportRepositoryMock(), domain.NewCurrencyRegistry(nil), newExportLogger()), maxBytes, newExportLogger())
+}
+
+func TestImportHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should import csv and return report",
+			run:  shouldImportCSVAndReturnReport,
+		},
+		{
+			name: "should read ndjson from content type",
+			run:  shouldReadNDJSONFromContentType,
+		},
+		{
+			name: "should return 400 for invalid import options",
+			run:  shouldReturn400ForInvalidImportOptions,
+		},
+		{
+			name: "should return 413 when file exceeds the limit",
+			run:  shouldReturn413WhenFileExceedsTheLimit,
+		},
+		{
+			name: "should return 500 when saving fails",
+			run:  shouldReturn500WhenSavingFails,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldImportCSVAndReturnReport(t *testing.T) {
+	repo := new(repositoryMock)
+	repo.On("SaveHistory", mock.MatchedBy(func(r domain.ConversionRecord) bool {
+		return r.MoedaDestino == "USD" && r.ValorEntrada == 1234.5 && r.Cotacao == 5.25
+	})).Return(nil).Once()
+	handler := newImportHandlerFake(repo, 0)
+
+	file := "Quando;Moeda;valor_entrada;cotacao\n05/01/2026;USD;1.234,50;5,25\n06/01/2026;XYZ;100;5\n"
+	recorder := httptest.NewRecorder()
+	handler.Handle(recorder, httptest.NewRequest(http.MethodPost, "/v1/conversions/import?decimal=comma&columns=data=Quando,currency=Moeda&tz=America/Sao_Paulo", strings.NewReader(file)))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"importadas":1`)
+	assert.Contains(t, recorder.Body.String(), `{"linha":3,"campo":"currency","mensagem":"moeda desconhecida: XYZ"}`)
+	repo.AssertExpectations(t)
+}
+
+func shouldReadNDJSONFromContentType(t *testing.T) {
+	handler := newImportHandlerFake(new(repositoryMock), 0)
+
+	req := httptest.NewRequest(http.MethodPost, "/convert/import?dry_run=true",
+		strings.NewReader(`{"data":"2026-01-05","currency":"EUR","valor_entrada":100,"cotacao":6}`+"\n"))
+	req.Header.Set("Content-Type", "application/x-ndjson; charset=utf-8")
+	recorder := httptest.NewRecorder()
+	handler.Handle(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"importadas":1`)
+	assert.Contains(t, recorder.Body.String(), `"simulacao":true`)
+}
+
+func shouldReturn400ForInvalidImportOptions(t *testing.T) {
+	handler := newImportHandlerFake(new(repositoryMock), 0)
+
+	for query, field := range map[string]string{
+		"format=xlsx":            "format",
+		"delimiter=%3B%3B":       "delimiter",
+		"decimal=virgula":        "decimal",
+		"columns=moeda=Moeda":    "columns",
+		"tz=America/Atlantida":   "tz",
+		"dry_run=talvez":         "dry_run",
+		"columns=currency=Moeda": "columns",
+	} {
+		recorder := httptest.NewRecorder()
+		handler.Handle(recorder, httptest.NewRequest(http.MethodPost, "/v1/conversions/import?"+query, strings.NewReader("data,currency,valor_entrada,cotacao\n")))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+		assert.Contains(t, recorder.Body.String(), `"field":"`+field+`"`, query)
+	}
+}
+
+func shouldReturn413WhenFileExceedsTheLimit(t *testing.T) {
+	handler := newImportHandlerFake(new(repositoryMock), 64)
+
+	file := "data,currency,valor_entrada,cotacao\n" + strings.Repeat("2026-01-05,XYZ,100,5\n", 10)
+	recorder := httptest.NewRecorder()
+	handler.Handle(recorder, httptest.NewRequest(http.MethodPost, "/v1/conversions/import", strings.NewReader(file)))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "64 bytes")
+}
+
+func shouldReturn500WhenSavingFails(t *testing.T) {
+	repo := new(repositoryMock)
+	repo.On("SaveHistory", mock.Anything).Return(errors.New("mongo fora"))
+	handler := newImportHandlerFake(repo, 0)
+
+	recorder := httptest.NewRecorder()
+	handler.Handle(recorder, httptest.NewRequest(http.MethodPost, "/v1/conversions/import", strings.NewReader("data,currency,valor_entrada,cotacao\n2026-01-05,USD,100,5\n")))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), CodeInternal)
+	assert.Contains(t, recorder.Body.String(), "0 conversões já foram importadas")
+}
@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMiddlewares(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should apply middlewares in declared order",
+			run:  shouldApplyMiddlewaresInDeclaredOrder,
+		},
+		{
+			name: "should generate request id when header is missing",
+			run:  shouldGenerateRequestIDWhenHeaderIsMissing,
+		},
+		{
+			name: "should propagate incoming request id",
+			run:  shouldPropagateIncomingRequestID,
+		},
+		{
+			name: "should attach request id to logs down the chain",
+			run:  shouldAttachRequestIDToLogsDownTheChain,
+		},
+		{
+			name: "should recover from panic and return 500",
+			run:  shouldRecoverFromPanicInMiddleware,
+		},
+		{
+			name: "should write access log with status and bytes",
+			run:  shouldWriteAccessLogWithStatusAndBytes,
+		},
+		{
+			name: "should answer CORS preflight",
+			run:  shouldAnswerCORSPreflight,
+		},
+		{
+			name: "should gzip response when client accepts it",
+			run:  shouldGzipResponseWhenClientAcceptsIt,
+		},
+		{
+			name: "should not gzip response when client does not accept it",
+			run:  shouldNotGzipResponseWhenClientDoesNotAcceptIt,
+		},
+		{
+			name: "should honor quality values in Accept-Encoding",
+			run:  shouldHonorQualityValuesInAcceptEncoding,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldApplyMiddlewaresInDeclaredOrder(t *testing.T) {
+	var order []string
+	mark := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), mark("a"), mark("b"), mark("c"))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, []string{"a", "b", "c"}, order)
+}
+
+func shouldGenerateRequestIDWhenHeaderIsMissing(t *testing.T) {
+	var seen string
+	h := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.NotEmpty(t, seen)
+	assert.Equal(t, seen, recorder.Header().Get(RequestIDHeader))
+}
+
+func shouldPropagateIncomingRequestID(t *testing.T) {
+	var seen string
+	h := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+
+	assert.Equal(t, "abc-123", seen)
+	assert.Equal(t, "abc-123", recorder.Header().Get(RequestIDHeader))
+}
+
+func shouldAttachRequestIDToLogsDownTheChain(t *testing.T) {
+	searcherMock := new(rateSeriesReaderMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	// Toda linha de log emitida durante a requisição deve começar com o request_id
+	loggerMock.On("Info", mock.Anything, mock.MatchedBy(func(kv []any) bool {
+		return len(kv) >= 2 && kv[0] == "request_id" && kv[1] == "req-42"
+	})).Return()
+
+	searcherMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return(nil, nil)
+
+	variationUseCase := domain.NewVariationUseCase(searcherMock, loggerMock)
+	handler := NewConverterHandler(nil, nil, variationUseCase, loggerMock)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /variation/{moeda}", handler.VariationHandle)
+	h := Chain(mux, RequestID(), AccessLog(loggerMock))
+
+	req := httptest.NewRequest(http.MethodGet, "/variation/USD", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	loggerMock.AssertExpectations(t)
+}
+
+func shouldRecoverFromPanicInMiddleware(t *testing.T) {
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Error", "Recuperado de pânico", mock.Anything).Return()
+
+	h := Recoverer(loggerMock)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	loggerMock.AssertExpectations(t)
+}
+
+func shouldWriteAccessLogWithStatusAndBytes(t *testing.T) {
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", "Requisição atendida", mock.MatchedBy(func(kv []any) bool {
+		fields := map[any]any{}
+		for i := 0; i+1 < len(kv); i += 2 {
+			fields[kv[i]] = kv[i+1]
+		}
+		return fields["status"] == http.StatusTeapot && fields["bytes"] == 5
+	})).Return()
+
+	h := AccessLog(loggerMock)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("hello"))
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	loggerMock.AssertExpectations(t)
+}
+
+func shouldAnswerCORSPreflight(t *testing.T) {
+	called := false
+	h := CORS(CORSOptions{
+		AllowedOrigins: []string{"https://painel.frete.com"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders: []string{"Content-Type"},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+
+	req := httptest.NewRequest(http.MethodOptions, "/converter", nil)
+	req.Header.Set("Origin", "https://painel.frete.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "https://painel.frete.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", recorder.Header().Get("Access-Control-Allow-Methods"))
+}
+
+func shouldGzipResponseWhenClientAcceptsIt(t *testing.T) {
+	h := Gzip()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"valor_convertido":20}`))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+
+	assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+
+	gr, err := gzip.NewReader(recorder.Body)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(gr)
+	assert.Equal(t, `{"valor_convertido":20}`, string(body))
+}
+
+func shouldNotGzipResponseWhenClientDoesNotAcceptIt(t *testing.T) {
+	h := Gzip()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("plain"))
+	}))
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+	assert.Equal(t, "plain", recorder.Body.String())
+}
+
+func shouldHonorQualityValuesInAcceptEncoding(t *testing.T) {
+	h := Gzip()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("plain"))
+	}))
+
+	tests := []struct {
+		header string
+		gzip   bool
+	}{
+		{header: "gzip;q=0", gzip: false},
+		{header: "deflate, gzip; q=0.000", gzip: false},
+		{header: "GZIP;q=0.5", gzip: true},
+		{header: "gzipx, br", gzip: false},
+		{header: "*", gzip: true},
+		{header: "*;q=1, gzip;q=0", gzip: false},
+		{header: "br, *;q=0", gzip: false},
+		{header: "gzip;q=abc", gzip: false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", tt.header)
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, req)
+
+		if tt.gzip {
+			assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"), tt.header)
+		} else {
+			assert.Empty(t, recorder.Header().Get("Content-Encoding"), tt.header)
+			assert.Equal(t, "plain", recorder.Body.String(), tt.header)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"go-frete/api/pkg/logger"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+//go:embed spec/openapi.json
+var openAPISpec []byte
+
+// A página de documentação e tudo o que ela carrega vão no binário: nada vem
+// de CDN, então /docs funciona também em rede fechada
+//
+//go:embed spec/docs
+var docsAssets embed.FS
+
+// LoadOpenAPISpec carrega e valida o documento OpenAPI embutido no binário
+func LoadOpenAPISpec() (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(openAPISpec)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// OpenAPIHandle serve o documento OpenAPI como está no repositório
+func OpenAPIHandle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(openAPISpec)
+}
+
+// DocsHandler serve em /docs a página que renderiza o /openapi.json e, em
+// /docs/, os arquivos que ela usa
+func DocsHandler() http.Handler {
+	assets, err := fs.Sub(docsAssets, "spec/docs")
+	if err != nil {
+		panic(err)
+	}
+	files := http.StripPrefix("/docs/", http.FileServerFS(assets))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/docs" {
+			http.ServeFileFS(w, r, assets, "index.html")
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
+
+// ValidateRequests rejeita com 400 requisições que não respeitam a especificação.
+// Rotas fora do documento seguem adiante sem validação.
+func ValidateRequests(doc *openapi3.T, l logger.Logger) (Middleware, error) {
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &openapi3filter.Options{
+		// A autenticação é responsabilidade do middleware Authenticate
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		MultiError:         true,
+	}
+
+	// Corpos de arquivo (importação) não são lidos aqui: validá-los exigiria
+	// carregar o arquivo inteiro em memória antes do handler
+	streamedOpts := *opts
+	streamedOpts.ExcludeRequestBody = true
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				// Rota ou método fora do documento: o mux decide (404/405)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    opts,
+			}
+			if streamedBody(route.Operation) {
+				input.Options = &streamedOpts
+			}
+			if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+				logger.FromContext(r.Context(), l).Warn("Requisição fora da especificação", "erro", err.Error())
+				writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "Requisição inválida: "+err.Error())
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// streamedBody diz se a operação tem x-streamed-body: o corpo é lido aos
+// poucos pelo handler, que faz a própria validação
+func streamedBody(op *openapi3.Operation) bool {
+	streamed, _ := op.Extensions["x-streamed-body"].(bool)
+	return streamed
+}
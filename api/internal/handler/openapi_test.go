@@ -0,0 +1,403 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPISpec(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should load and validate embedded spec",
+			run:  shouldLoadAndValidateEmbeddedSpec,
+		},
+		{
+			name: "should serve spec",
+			run:  shouldServeSpec,
+		},
+		{
+			name: "should serve docs page with bundled assets",
+			run:  shouldServeDocsPageWithBundledAssets,
+		},
+		{
+			name: "should reject request body outside the spec",
+			run:  shouldRejectRequestBodyOutsideTheSpec,
+		},
+		{
+			name: "should let valid and unknown requests through",
+			run:  shouldLetValidAndUnknownRequestsThrough,
+		},
+		{
+			name: "should leave streamed body to the handler",
+			run:  shouldLeaveStreamedBodyToTheHandler,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldLoadAndValidateEmbeddedSpec(t *testing.T) {
+	doc, err := LoadOpenAPISpec()
+
+	assert.NoError(t, err)
+	assert.NotNil(t, doc.Paths.Find("/converter"))
+	assert.NotNil(t, doc.Paths.Find("/variation/{moeda}"))
+	assert.NotNil(t, doc.Paths.Find("/v1/conversions"))
+	assert.True(t, doc.Paths.Find("/converter").Post.Deprecated)
+}
+
+func shouldServeSpec(t *testing.T) {
+	spec := httptest.NewRecorder()
+	OpenAPIHandle(spec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, spec.Code)
+	assert.Equal(t, "application/json", spec.Header().Get("Content-Type"))
+	assert.Contains(t, spec.Body.String(), `"openapi": "3.0.3"`)
+}
+
+func shouldServeDocsPageWithBundledAssets(t *testing.T) {
+	router := newTestRouter(new(conversionReaderMock), new(rateProviderMock), nil)
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	page := get("/docs")
+	require.Equal(t, http.StatusOK, page.Code)
+	assert.Contains(t, page.Header().Get("Content-Type"), "text/html")
+	// Tudo o que a página carrega sai do próprio binário
+	assert.NotContains(t, page.Body.String(), "https://")
+	assert.Contains(t, page.Body.String(), `src="/docs/docs.js"`)
+
+	script := get("/docs/docs.js")
+	assert.Equal(t, http.StatusOK, script.Code)
+	assert.Contains(t, script.Header().Get("Content-Type"), "javascript")
+	assert.Contains(t, script.Body.String(), `fetch("/openapi.json")`)
+
+	assert.Equal(t, http.StatusOK, get("/docs/docs.css").Code)
+	assert.Equal(t, http.StatusNotFound, get("/docs/redoc.js").Code)
+}
+
+func shouldRejectRequestBodyOutsideTheSpec(t *testing.T) {
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+
+	doc, _ := LoadOpenAPISpec()
+	validate, err := ValidateRequests(doc, loggerMock)
+	require.NoError(t, err)
+
+	called := false
+	h := validate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+
+	req := httptest.NewRequest(http.MethodPost, "/converter", bytes.NewBufferString(`{"moeda": "USD"}`))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "valor_brl")
+}
+
+func shouldLetValidAndUnknownRequestsThrough(t *testing.T) {
+	doc, _ := LoadOpenAPISpec()
+	validate, err := ValidateRequests(doc, new(loggermock.LoggerMock))
+	require.NoError(t, err)
+
+	var body string
+	h := validate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/converter", bytes.NewBufferString(`{"moeda": "USD", "valor_brl": 100}`))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+
+	// O corpo continua disponível para o handler depois da validação
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"moeda": "USD", "valor_brl": 100}`, body)
+
+	unknown := httptest.NewRecorder()
+	h.ServeHTTP(unknown, httptest.NewRequest(http.MethodGet, "/nao-documentada", nil))
+	assert.Equal(t, http.StatusOK, unknown.Code)
+}
+
+func shouldLeaveStreamedBodyToTheHandler(t *testing.T) {
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+
+	doc, _ := LoadOpenAPISpec()
+	validate, err := ValidateRequests(doc, loggerMock)
+	require.NoError(t, err)
+
+	var body string
+	h := validate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+
+	// application/x-ndjson não tem decodificador no validador: sem x-streamed-body seria 400
+	file := `{"data":"2026-01-05","currency":"USD","valor_entrada":100,"cotacao":5}` + "\n"
+	req := httptest.NewRequest(http.MethodPost, "/v1/conversions/import?dry_run=true", bytes.NewBufferString(file))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, file, body)
+
+	// Os parâmetros continuam validados
+	invalid := httptest.NewRecorder()
+	h.ServeHTTP(invalid, httptest.NewRequest(http.MethodPost, "/v1/conversions/import?dry_run=talvez", bytes.NewBufferString(file)))
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+}
+
+// assertConformsToSpec valida a resposta real do handler contra a especificação
+func assertConformsToSpec(t *testing.T, doc *openapi3.T, req *http.Request, recorder *httptest.ResponseRecorder) {
+	t.Helper()
+
+	router, err := legacy.NewRouter(doc)
+	require.NoError(t, err)
+
+	route, pathParams, err := router.FindRoute(req)
+	require.NoError(t, err, "rota %s %s não documentada", req.Method, req.URL.Path)
+
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+		},
+		Status: recorder.Code,
+		Header: recorder.Header(),
+		Body:   io.NopCloser(bytes.NewReader(recorder.Body.Bytes())),
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+		},
+	}
+
+	assert.NoError(t, openapi3filter.ValidateResponse(context.Background(), input),
+		"resposta %d de %s %s fora da especificação", recorder.Code, req.Method, req.URL.Path)
+}
+
+func TestHandlersConformToOpenAPISpec(t *testing.T) {
+	doc, err := LoadOpenAPISpec()
+	require.NoError(t, err)
+
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Error", mock.Anything, mock.Anything).Return()
+
+	providerMock := new(rateProviderMock)
+	providerMock.On("GetRate", "USD").Return(5.0, nil)
+	providerMock.On("GetRate", "XYZ").Return(0.0, domain.ErrCurrencyNotFound)
+
+	repoMock := new(repositoryMock)
+	repoMock.On("SaveHistory", mock.Anything).Return(nil)
+
+	now := time.Now()
+	readerMock := new(conversionReaderMock)
+	readerMock.On("GetLastConversions", 10).Return([]domain.ConversionRecord{
+		{MoedaDestino: "USD", Cotacao: 5, ValorEntrada: 100, ValorConvertido: 20, Data: now, APIKeyID: "k1"},
+	}, nil)
+	readerMock.On("FindConversions", mock.Anything).Return([]domain.ConversionRecord{
+		{MoedaDestino: "USD", Cotacao: 5, ValorEntrada: 100, ValorConvertido: 20, Data: now, Fonte: "awesomeapi"},
+	}, nil)
+
+	searcherMock := new(rateSeriesReaderMock)
+	searcherMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return([]domain.RateSnapshot{
+		{Moeda: "USD", Cotacao: 5, Data: now.Add(-time.Hour)},
+		{Moeda: "USD", Cotacao: 5.5, Data: now},
+	}, nil)
+	searcherMock.On("GetRateSeries", "EUR", mock.Anything, mock.Anything).Return([]domain.RateSnapshot{
+		{Moeda: "EUR", Cotacao: 6, Data: now.Add(-time.Hour)},
+		{Moeda: "EUR", Cotacao: 6.3, Data: now},
+	}, nil)
+	searcherMock.On("GetRateSeries", "JPY", mock.Anything, mock.Anything).Return(nil, nil)
+
+	keysMock := new(apiKeyRepositoryMock)
+	keysMock.On("SaveAPIKey", mock.Anything).Return(nil)
+	keysMock.On("ListAPIKeys").Return([]domain.APIKey{{ID: "k1", Name: "batch", Scopes: []string{domain.ScopeConvertWrite}, CreatedAt: now}}, nil)
+
+	levelsMock := new(levelControllerMock)
+	levelsMock.On("Level").Return("info")
+
+	converter := NewConverterHandler(
+		domain.NewConverterUseCase(providerMock, repoMock, loggerMock),
+		domain.NewListConversionsUseCase(readerMock, loggerMock),
+		domain.NewVariationUseCase(searcherMock, loggerMock),
+		loggerMock,
+	)
+	keys := NewAPIKeyHandler(domain.NewAPIKeyUseCase(keysMock, loggerMock), loggerMock)
+	levels := NewLogLevelHandler(levelsMock, loggerMock)
+	calendars := NewCalendarHandler(domain.NewCalendars(nil), loggerMock)
+	persistence := NewPersistenceHandler(domain.NewResilientSaver(repoMock, domain.PersistJournaled, loggerMock))
+
+	aggregatesMock := new(conversionAggregateReaderMock)
+	aggregatesMock.On("RetentionWatermark").Return(now.AddDate(0, 0, -7), nil)
+	aggregatesMock.On("DailyAggregates", "USD", mock.Anything, mock.Anything).Return([]domain.ConversionDailyAggregate{
+		{Dia: now.AddDate(0, 0, -10), Moeda: "USD", Conversoes: 2, ValorEntrada: 200, ValorConvertido: 40, CotacaoMinima: 5, CotacaoMaxima: 5, CotacaoMedia: 5},
+	}, nil)
+	aggregatesMock.On("AggregateConversions", "USD", mock.Anything, mock.Anything).Return([]domain.ConversionDailyAggregate{}, nil)
+	conversionStats := NewConversionStatsHandler(domain.NewConversionStatsUseCase(aggregatesMock, loggerMock), loggerMock)
+
+	streamerMock := new(conversionStreamerMock)
+	streamerMock.On("StreamConversions", mock.Anything).Return([]domain.ConversionRecord{
+		{MoedaDestino: "USD", Cotacao: 5, ValorEntrada: 100, ValorConvertido: 20, Data: now},
+	}, nil)
+	export := NewExportHandler(domain.NewExportUseCase(streamerMock, loggerMock), loggerMock)
+
+	importStore := newImportRepositoryMock()
+	importRepo := new(repositoryMock)
+	importRepo.On("SaveHistory", mock.Anything).Return(nil)
+	imports := NewImportHandler(domain.NewImportUseCase(importRepo, importStore, domain.NewCurrencyRegistry(nil), loggerMock), 1<<20, loggerMock)
+	importFile := "data,currency,valor_entrada,cotacao\n2026-01-05,USD,100,5\n2026-01-05,XYZ,100,5\n"
+
+	webhookStore := new(webhookStoreMock)
+	webhookStore.On("SaveWebhook", mock.Anything).Return(nil)
+	webhookStore.On("ListWebhooks", "").Return([]domain.Webhook{{ID: "w1", URL: "https://exemplo.com/hook", Eventos: []string{domain.EventConversionCreated}, CriadoEm: now}}, nil)
+	webhookStore.On("ListDeliveries", mock.Anything).Return([]domain.WebhookDelivery{{
+		ID: "d1", WebhookID: "w1", Status: domain.DeliveryDead, Tentativas: 8, UltimoErro: "resposta 500", UltimoStatusHTTP: 500, CriadaEm: now,
+		Evento: domain.DomainEvent{ID: "e1", Tipo: domain.EventConversionCreated, Chave: "USD", Sequencia: 1, CriadoEm: now, Dados: []byte(`{"currency":"USD"}`)},
+	}}, nil)
+	webhookStore.On("GetDelivery", "d1").Return(&domain.WebhookDelivery{ID: "d1", WebhookID: "w1", Status: domain.DeliveryDead, CriadaEm: now,
+		Evento: domain.DomainEvent{ID: "e1", Tipo: domain.EventProviderDown, Chave: "awesomeapi", CriadoEm: now, Dados: []byte(`{}`)},
+	}, nil)
+	webhookStore.On("GetDelivery", "d2").Return(&domain.WebhookDelivery{ID: "d2", Status: domain.DeliveryPending}, nil)
+	webhookStore.On("GetWebhook", "w1").Return(&domain.Webhook{ID: "w1"}, nil)
+	webhookStore.On("UpdateDelivery", mock.Anything).Return(nil)
+	webhooks := NewWebhookHandler(domain.NewWebhookUseCase(webhookStore, webhookStore, nil, domain.WebhookConfig{}, loggerMock), loggerMock)
+
+	alertStore := new(alertStoreMock)
+	alertRule := domain.AlertRule{ID: "a1", Moeda: "USD", Tipo: domain.AlertBelow, Valor: 5, CooldownMinutos: 60,
+		Canais: []string{domain.AlertChannelWebhook}, Ativa: true, CriadaEm: now, AtualizadaEm: now}
+	alertStore.On("SaveAlertRule", mock.Anything).Return(nil)
+	alertStore.On("GetAlertRule", "a1").Return(&alertRule, nil)
+	alertStore.On("GetAlertRule", "a2").Return(nil, domain.ErrAlertNotFound)
+	alertStore.On("ListAlertRules", "").Return([]domain.AlertRule{alertRule}, nil)
+	alertStore.On("DeleteAlertRule", "a1").Return(nil)
+	alertStore.On("ListAlertTriggers", mock.Anything).Return([]domain.AlertTrigger{{
+		ID: "t1", AlertaID: "a1", Moeda: "USD", Tipo: domain.AlertBelow, Valor: 5, Cotacao: 4.9, Descricao: "USD abaixo de 5.0000 (cotação 4.9000)", DisparadoEm: now,
+		Notificacoes: []domain.AlertNotification{{Canal: domain.AlertChannelWebhook, Status: domain.NotificationSent}},
+	}}, nil)
+	alerts := NewAlertHandler(domain.NewAlertUseCase(alertStore, alertStore, nil, domain.NewCurrencyRegistry(nil), nil, loggerMock), loggerMock)
+	analytics := NewAnalyticsHandler(domain.NewAnalyticsUseCase(searcherMock, loggerMock), loggerMock)
+
+	closesMock := new(rateSeriesReaderMock)
+	var closes []domain.RateSnapshot
+	for d := 20; d > 0; d-- {
+		closes = append(closes, domain.RateSnapshot{Moeda: "USD", Cotacao: 5 + float64(d%3)/10, Data: now.AddDate(0, 0, -d)})
+	}
+	closesMock.On("GetRateSeries", "USD", mock.Anything, mock.Anything).Return(closes, nil)
+	forecast := NewForecastHandler(domain.NewForecastUseCase(closesMock, loggerMock), loggerMock)
+	shortForecast := NewForecastHandler(domain.NewForecastUseCase(searcherMock, loggerMock), loggerMock)
+
+	scenarios := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		params  map[string]string
+		handler http.HandlerFunc
+	}{
+		{"convert 200", http.MethodPost, "/converter", `{"moeda": "USD", "valor_brl": 100}`, nil, converter.Handle},
+		{"convert 422", http.MethodPost, "/converter", `{"moeda": "XYZ", "valor_brl": 100}`, nil, converter.Handle},
+		{"list 200", http.MethodGet, "/convert/list", "", nil, converter.ListHandle},
+		{"variation 200", http.MethodGet, "/variation/USD", "", map[string]string{"moeda": "USD"}, converter.VariationHandle},
+		{"variation empty 200", http.MethodGet, "/variation/JPY", "", map[string]string{"moeda": "JPY"}, converter.VariationHandle},
+		{"log level 200", http.MethodGet, "/admin/log-level", "", nil, levels.GetHandle},
+		{"create key 201", http.MethodPost, "/admin/api-keys", `{"name": "batch", "scopes": ["convert:write"]}`, nil, keys.CreateHandle},
+		{"create key 400", http.MethodPost, "/admin/api-keys", `{"name": "batch", "scopes": ["root"]}`, nil, keys.CreateHandle},
+		{"list keys 200", http.MethodGet, "/admin/api-keys", "", nil, keys.ListHandle},
+		{"openapi 200", http.MethodGet, "/openapi.json", "", nil, OpenAPIHandle},
+		{"v1 convert 201", http.MethodPost, "/v1/conversions", `{"moeda": "USD", "valor_brl": 100}`, nil, converter.CreateHandle},
+		{"v1 convert 422", http.MethodPost, "/v1/conversions", `{"moeda": "XYZ", "valor_brl": 100}`, nil, converter.CreateHandle},
+		{"v1 search 200", http.MethodGet, "/v1/conversions?currency=USD&limit=1", "", nil, converter.SearchHandle},
+		{"v1 search 400", http.MethodGet, "/v1/conversions?from=ontem", "", nil, converter.SearchHandle},
+		{"v1 variations 200", http.MethodGet, "/v1/currencies/USD/variations", "", map[string]string{"moeda": "USD"}, converter.VariationHandle},
+		{"v1 log level 200", http.MethodGet, "/v1/admin/log-level", "", nil, levels.GetHandle},
+		{"v1 create key 201", http.MethodPost, "/v1/admin/api-keys", `{"name": "batch", "scopes": ["convert:write"]}`, nil, keys.CreateHandle},
+		{"v1 create key 400", http.MethodPost, "/v1/admin/api-keys", `{"name": "batch", "scopes": ["root"]}`, nil, keys.CreateHandle},
+		{"v1 list keys 200", http.MethodGet, "/v1/admin/api-keys", "", nil, keys.ListHandle},
+		{"v1 calendar 200", http.MethodGet, "/v1/calendar/BR?year=2026", "", map[string]string{"country": "BR"}, calendars.Handle},
+		{"v1 calendar 404", http.MethodGet, "/v1/calendar/JP", "", map[string]string{"country": "JP"}, calendars.Handle},
+		{"v1 persistence 200", http.MethodGet, "/v1/admin/persistence", "", nil, persistence.Handle},
+		{"v1 conversion statistics 200", http.MethodGet, "/v1/conversions/statistics?currency=USD", "", nil, conversionStats.Handle},
+		{"v1 conversion statistics 400", http.MethodGet, "/v1/conversions/statistics?from=ontem", "", nil, conversionStats.Handle},
+		{"v1 export 200", http.MethodGet, "/v1/conversions/export?currency=USD&decimal=comma", "", nil, export.Handle},
+		{"v1 export 400", http.MethodGet, "/v1/conversions/export?delimiter=%3B%3B", "", nil, export.Handle},
+		{"export 200", http.MethodGet, "/convert/export", "", nil, export.Handle},
+		{"export 400", http.MethodGet, "/convert/export?decimal=comma&delimiter=,", "", nil, export.Handle},
+		{"v1 import 200", http.MethodPost, "/v1/conversions/import", importFile, nil, imports.Handle},
+		{"v1 import 400", http.MethodPost, "/v1/conversions/import?columns=moeda=Moeda", importFile, nil, imports.Handle},
+		{"import 200", http.MethodPost, "/convert/import?dry_run=true", importFile, nil, imports.Handle},
+		{"import 400", http.MethodPost, "/convert/import", "data,moeda\n", nil, imports.Handle},
+		{"v1 create webhook 201", http.MethodPost, "/v1/webhooks", `{"url": "https://exemplo.com/hook", "eventos": ["provider.down"]}`, nil, webhooks.CreateHandle},
+		{"v1 create webhook 400", http.MethodPost, "/v1/webhooks", `{"url": "ftp://exemplo.com", "eventos": ["provider.down"]}`, nil, webhooks.CreateHandle},
+		{"v1 list webhooks 200", http.MethodGet, "/v1/webhooks", "", nil, webhooks.ListHandle},
+		{"v1 webhook deliveries 200", http.MethodGet, "/v1/admin/webhooks/deliveries?status=morta", "", nil, webhooks.DeliveriesHandle},
+		{"v1 webhook deliveries 400", http.MethodGet, "/v1/admin/webhooks/deliveries?limit=muitas", "", nil, webhooks.DeliveriesHandle},
+		{"v1 replay delivery 202", http.MethodPost, "/v1/admin/webhooks/deliveries/d1/replay", "", map[string]string{"id": "d1"}, webhooks.ReplayHandle},
+		{"v1 replay delivery 409", http.MethodPost, "/v1/admin/webhooks/deliveries/d2/replay", "", map[string]string{"id": "d2"}, webhooks.ReplayHandle},
+		{"v1 create alert 201", http.MethodPost, "/v1/alerts", `{"moeda": "USD", "tipo": "abaixo", "valor": 5}`, nil, alerts.CreateHandle},
+		{"v1 create alert 400", http.MethodPost, "/v1/alerts", `{"moeda": "USD", "tipo": "variacao", "valor": -2}`, nil, alerts.CreateHandle},
+		{"v1 list alerts 200", http.MethodGet, "/v1/alerts", "", nil, alerts.ListHandle},
+		{"v1 get alert 200", http.MethodGet, "/v1/alerts/a1", "", map[string]string{"id": "a1"}, alerts.GetHandle},
+		{"v1 get alert 404", http.MethodGet, "/v1/alerts/a2", "", map[string]string{"id": "a2"}, alerts.GetHandle},
+		{"v1 update alert 200", http.MethodPut, "/v1/alerts/a1", `{"moeda": "USD", "tipo": "maxima", "dias": 30}`, map[string]string{"id": "a1"}, alerts.UpdateHandle},
+		{"v1 delete alert 204", http.MethodDelete, "/v1/alerts/a1", "", map[string]string{"id": "a1"}, alerts.DeleteHandle},
+		{"v1 alert history 200", http.MethodGet, "/v1/alerts/history?moeda=USD", "", nil, alerts.HistoryHandle},
+		{"v1 analytics 200", http.MethodGet, "/v1/analytics/USD?window=2&indicators=sma,bollinger,drawdown", "", map[string]string{"moeda": "USD"}, analytics.Handle},
+		{"v1 analytics all 200", http.MethodGet, "/v1/analytics/USD", "", map[string]string{"moeda": "USD"}, analytics.Handle},
+		{"v1 analytics 400", http.MethodGet, "/v1/analytics/USD?indicators=macd", "", map[string]string{"moeda": "USD"}, analytics.Handle},
+		{"v1 analytics 404", http.MethodGet, "/v1/analytics/JPY", "", map[string]string{"moeda": "JPY"}, analytics.Handle},
+		{"v1 compare 200", http.MethodGet, "/v1/analytics/compare?currencies=USD,EUR&interval=1h", "", nil, analytics.CompareHandle},
+		{"v1 compare 400", http.MethodGet, "/v1/analytics/compare?currencies=USD", "", nil, analytics.CompareHandle},
+		{"v1 compare 404", http.MethodGet, "/v1/analytics/compare?currencies=USD,JPY", "", nil, analytics.CompareHandle},
+		{"v1 forecast 200", http.MethodGet, "/v1/forecast/USD?horizon=10d&level=80", "", map[string]string{"moeda": "USD"}, forecast.Handle},
+		{"v1 forecast 400", http.MethodGet, "/v1/forecast/USD?horizon=12h", "", map[string]string{"moeda": "USD"}, forecast.Handle},
+		{"v1 forecast 404", http.MethodGet, "/v1/forecast/JPY", "", map[string]string{"moeda": "JPY"}, shortForecast.Handle},
+		{"v1 forecast 422", http.MethodGet, "/v1/forecast/USD", "", map[string]string{"moeda": "USD"}, shortForecast.Handle},
+	}
+
+	for _, sc := range scenarios {
+		t.Run(sc.name, func(t *testing.T) {
+			req := httptest.NewRequest(sc.method, sc.path, bytes.NewBufferString(sc.body))
+			if sc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			for k, v := range sc.params {
+				req.SetPathValue(k, v)
+			}
+
+			recorder := httptest.NewRecorder()
+			sc.handler(recorder, req)
+
+			assertConformsToSpec(t, doc, req, recorder)
+		})
+	}
+}
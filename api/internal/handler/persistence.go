@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-frete/api/internal/domain"
+)
+
+// PersistenceHandler expõe a política de gravação do histórico e a fila do diário local
+type PersistenceHandler struct {
+	saver *domain.ResilientSaver
+}
+
+func NewPersistenceHandler(s *domain.ResilientSaver) *PersistenceHandler {
+	return &PersistenceHandler{saver: s}
+}
+
+// Handle atende GET /v1/admin/persistence
+func (h *PersistenceHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, http.StatusOK, h.saver.Stats())
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/internal/transport"
+	"go-frete/api/pkg/logger"
+	"go-frete/api/pkg/ratelimit"
+)
+
+// RateLimit aplica um token bucket por chave de API (ou por IP, sem chave)
+// com os limites do plano do cliente. Deve vir depois do Authenticate.
+func RateLimit(limiter *ratelimit.Limiter, quotas *domain.QuotaUseCase, l logger.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, _ := domain.APIKeyFromContext(r.Context())
+			plan := quotas.PlanFor(key)
+			if plan.RequestsPerMinute <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res := limiter.Take(clientID(r, key), float64(plan.RequestsPerMinute)/60, plan.Burst, time.Now())
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			w.Header().Set("RateLimit-Reset", transport.CeilSeconds(res.Reset))
+
+			if !res.Allowed {
+				logger.FromContext(r.Context(), l).Warn("Limite de requisições excedido", "plano", plan.Name)
+				w.Header().Set("Retry-After", transport.CeilSeconds(res.RetryAfter))
+				writeError(w, r, http.StatusTooManyRequests, CodeRateLimited, "Limite de requisições excedido")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// EnforceQuota consome a cota diária de conversões da chave antes da rota e a
+// devolve quando a rota não responde 2xx, para que só conversões feitas contem
+func EnforceQuota(quotas *domain.QuotaUseCase) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, _ := domain.APIKeyFromContext(r.Context())
+
+			status, err := quotas.Consume(r.Context(), key)
+			if status.Limit > 0 {
+				w.Header().Set("X-Quota-Limit", strconv.Itoa(status.Limit))
+				w.Header().Set("X-Quota-Remaining", strconv.Itoa(status.Remaining()))
+			}
+			if err != nil {
+				if errors.Is(err, domain.ErrQuotaExceeded) {
+					w.Header().Set("Retry-After", transport.CeilSeconds(time.Until(status.Reset)))
+					writeError(w, r, http.StatusTooManyRequests, CodeQuotaExceeded, err.Error())
+					return
+				}
+				writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao verificar cota")
+				return
+			}
+
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			if s := rec.Status(); s < 200 || s >= 300 {
+				quotas.Refund(r.Context(), key, status)
+			}
+		})
+	}
+}
+
+func clientID(r *http.Request, key *domain.APIKey) string {
+	if key != nil {
+		return "key:" + key.ID
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// setRetryAfter copia o intervalo de um domain.RetryAfterError para o cabeçalho
+func setRetryAfter(w http.ResponseWriter, err error) {
+	var ra *domain.RetryAfterError
+	if errors.As(err, &ra) {
+		w.Header().Set("Retry-After", transport.CeilSeconds(ra.After))
+	}
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/pkg/ratelimit"
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type usageCounterMock struct {
+	mock.Mock
+}
+
+func (m *usageCounterMock) IncrementDailyUsage(clientID string, day time.Time) (int, error) {
+	args := m.Called(clientID, day)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *usageCounterMock) DecrementDailyUsage(clientID string, day time.Time) error {
+	return m.Called(clientID, day).Error(0)
+}
+
+var testPlans = []domain.Plan{
+	{Name: domain.AnonymousPlan, RequestsPerMinute: 60, Burst: 1},
+	{Name: domain.DefaultPlan, RequestsPerMinute: 60, Burst: 2, DailyConversions: 1},
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should return 429 with Retry-After when bucket is empty",
+			run:  shouldReturn429WithRetryAfterWhenBucketIsEmpty,
+		},
+		{
+			name: "should keep separate buckets per api key",
+			run:  shouldKeepSeparateBucketsPerAPIKey,
+		},
+		{
+			name: "should return 429 when daily quota is exceeded",
+			run:  shouldReturn429WhenDailyQuotaIsExceeded,
+		},
+		{
+			name: "should refund daily quota when route does not succeed",
+			run:  shouldRefundDailyQuotaWhenRouteDoesNotSucceed,
+		},
+		{
+			name: "should return 503 when upstream budget is exhausted",
+			run:  shouldReturn503WhenUpstreamBudgetIsExhausted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldReturn429WithRetryAfterWhenBucketIsEmpty(t *testing.T) {
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+
+	quotas := domain.NewQuotaUseCase(new(usageCounterMock), testPlans, loggerMock)
+	h := RateLimit(ratelimit.NewLimiter(time.Minute), quotas, loggerMock)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	first := httptest.NewRecorder()
+	h.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/convert/list", nil))
+	second := httptest.NewRecorder()
+	h.ServeHTTP(second, httptest.NewRequest(http.MethodGet, "/convert/list", nil))
+
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "1", first.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", first.Header().Get("RateLimit-Remaining"))
+
+	assert.Equal(t, http.StatusTooManyRequests, second.Code)
+	assert.Equal(t, "1", second.Header().Get("Retry-After"))
+}
+
+func shouldKeepSeparateBucketsPerAPIKey(t *testing.T) {
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+
+	quotas := domain.NewQuotaUseCase(new(usageCounterMock), testPlans, loggerMock)
+	h := RateLimit(ratelimit.NewLimiter(time.Minute), quotas, loggerMock)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	send := func(id string) int {
+		req := httptest.NewRequest(http.MethodGet, "/convert/list", nil)
+		req = req.WithContext(domain.ContextWithAPIKey(req.Context(), &domain.APIKey{ID: id}))
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send("a"))
+	assert.Equal(t, http.StatusOK, send("a"))
+	assert.Equal(t, http.StatusTooManyRequests, send("a"))
+	assert.Equal(t, http.StatusOK, send("b"))
+}
+
+func shouldReturn429WhenDailyQuotaIsExceeded(t *testing.T) {
+	counterMock := new(usageCounterMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+	counterMock.On("IncrementDailyUsage", "k1", mock.Anything).Return(2, nil)
+	counterMock.On("DecrementDailyUsage", "k1", mock.Anything).Return(nil)
+
+	quotas := domain.NewQuotaUseCase(counterMock, testPlans, loggerMock)
+	called := false
+	h := EnforceQuota(quotas)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+
+	req := httptest.NewRequest(http.MethodPost, "/converter", nil)
+	req = req.WithContext(domain.ContextWithAPIKey(req.Context(), &domain.APIKey{ID: "k1"}))
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "0", recorder.Header().Get("X-Quota-Remaining"))
+	assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+}
+
+func shouldRefundDailyQuotaWhenRouteDoesNotSucceed(t *testing.T) {
+	tests := []struct {
+		status int
+		refund bool
+	}{
+		{status: http.StatusCreated, refund: false},
+		{status: http.StatusUnprocessableEntity, refund: true},
+		{status: http.StatusBadGateway, refund: true},
+	}
+
+	for _, tt := range tests {
+		counterMock := new(usageCounterMock)
+		counterMock.On("IncrementDailyUsage", "k1", mock.Anything).Return(1, nil)
+		counterMock.On("DecrementDailyUsage", "k1", mock.Anything).Return(nil)
+
+		quotas := domain.NewQuotaUseCase(counterMock, testPlans, new(loggermock.LoggerMock))
+		h := EnforceQuota(quotas)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/conversions", nil)
+		req = req.WithContext(domain.ContextWithAPIKey(req.Context(), &domain.APIKey{ID: "k1"}))
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, req)
+
+		assert.Equal(t, tt.status, recorder.Code)
+		if tt.refund {
+			day := counterMock.Calls[0].Arguments.Get(1)
+			counterMock.AssertCalled(t, "DecrementDailyUsage", "k1", day)
+		} else {
+			counterMock.AssertNotCalled(t, "DecrementDailyUsage", mock.Anything, mock.Anything)
+		}
+	}
+}
+
+func shouldReturn503WhenUpstreamBudgetIsExhausted(t *testing.T) {
+	providerMock := new(rateProviderMock)
+	repoMock := new(repositoryMock)
+	loggerMock := new(loggermock.LoggerMock)
+
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Error", mock.Anything, mock.Anything).Return()
+	providerMock.On("GetRate", "USD").Return(5.0, nil).Once()
+
+	// Orçamento de uma única chamada: a segunda conversão deve ser recusada
+	budgeted := domain.NewBudgetedRateProvider(providerMock, 1, 1)
+	repoMock.On("SaveHistory", mock.Anything).Return(nil)
+
+	usecase := domain.NewConverterUseCase(budgeted, repoMock, loggerMock)
+	handler := NewConverterHandler(usecase, nil, nil, loggerMock)
+
+	for i, expected := range []int{http.StatusOK, http.StatusServiceUnavailable} {
+		body := []byte(`{"moeda": "USD", "valor_brl": 100.0}`)
+		req, _ := http.NewRequest(http.MethodPost, "/converter", bytes.NewBuffer(body))
+		recorder := httptest.NewRecorder()
+		handler.Handle(recorder, req)
+
+		assert.Equal(t, expected, recorder.Code, "chamada %d", i)
+		if expected == http.StatusServiceUnavailable {
+			assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+		}
+	}
+}
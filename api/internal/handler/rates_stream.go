@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/pkg/logger"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+// Eventos pendentes por cliente antes de ele ser desconectado por lentidão
+const streamBuffer = 64
+
+// Tempo que o cliente deve esperar antes de reconectar ao stream SSE
+const sseRetry = 3 * time.Second
+
+// Tempo máximo para escrever uma mensagem no WebSocket
+const wsWriteTimeout = 10 * time.Second
+
+// Mensagens trocadas no WebSocket. O cliente envia {"type":"subscribe","currencies":[...]}
+// para trocar as moedas; o servidor envia "subscribed", "rate" e "error".
+type StreamMessage struct {
+	Type       string            `json:"type"`
+	Currencies []string          `json:"currencies,omitempty"`
+	Event      *domain.RateEvent `json:"event,omitempty"`
+	Error      *APIError         `json:"error,omitempty"`
+}
+
+// RateStreamHandler transmite as cotações do RateBroadcaster via SSE e WebSocket
+type RateStreamHandler struct {
+	broadcaster *domain.RateBroadcaster
+	heartbeat   time.Duration
+	origins     []string
+	log         logger.Logger
+}
+
+// NewRateStreamHandler recebe as origens aceitas no handshake do WebSocket ("*" libera todas)
+func NewRateStreamHandler(b *domain.RateBroadcaster, heartbeat time.Duration, origins []string, l logger.Logger) *RateStreamHandler {
+	return &RateStreamHandler{broadcaster: b, heartbeat: heartbeat, origins: origins, log: l}
+}
+
+// SSEHandle transmite eventos "rate" em text/event-stream. O filtro vem de
+// ?currencies=USD,EUR e a retomada do cabeçalho Last-Event-ID (ou ?last_event_id).
+func (h *RateStreamHandler) SSEHandle(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	sub, ok := h.subscribe(w, r)
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Impede o nginx de segurar os eventos no buffer
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds()); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		log.Error("Resposta não suporta streaming", "erro", err.Error())
+		return
+	}
+	log.Info("Cliente conectado ao stream de cotações", "protocolo", "sse")
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			log.Info("Cliente desconectou do stream de cotações", "protocolo", "sse")
+			return
+		case <-sub.Done():
+			h.logEnd(log, sub.Err(), "sse")
+			if sub.Err() != nil {
+				writeSSEError(w, sub.Err())
+				rc.Flush()
+			}
+			return
+		case ev := <-sub.Events():
+			err = writeSSEEvent(w, ev)
+		case <-heartbeat.C:
+			// Comentário SSE: mantém proxies e o cliente cientes de que a conexão vive
+			_, err = fmt.Fprint(w, ": ping\n\n")
+		}
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			log.Info("Falha ao escrever no stream de cotações", "protocolo", "sse", "erro", err.Error())
+			return
+		}
+	}
+}
+
+func writeSSEEvent(w http.ResponseWriter, ev domain.RateEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: rate\ndata: %s\n\n", ev.ID, data)
+	return err
+}
+
+func writeSSEError(w http.ResponseWriter, err error) {
+	data, _ := json.Marshal(streamError(err))
+	fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+}
+
+// WebSocketHandle transmite mensagens "rate" pelo WebSocket e aceita trocas de
+// assinatura do cliente sem reconectar
+func (h *RateStreamHandler) WebSocketHandle(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	// Validações antes do handshake para o cliente receber o erro como HTTP
+	sub, ok := h.subscribe(w, r)
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+		OriginPatterns:     h.origins,
+		InsecureSkipVerify: slices.Contains(h.origins, "*"),
+	})
+	if err != nil {
+		// Accept já respondeu ao cliente
+		log.Warn("Falha no handshake do WebSocket", "erro", err.Error())
+		return
+	}
+	defer conn.CloseNow()
+	log.Info("Cliente conectado ao stream de cotações", "protocolo", "websocket")
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go h.readSubscriptions(ctx, cancel, conn, sub, log)
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Cliente desconectou do stream de cotações", "protocolo", "websocket")
+			return
+		case <-sub.Done():
+			h.logEnd(log, sub.Err(), "websocket")
+			if sub.Err() != nil {
+				apiErr := streamError(sub.Err())
+				writeWS(ctx, conn, StreamMessage{Type: "error", Error: &apiErr})
+				conn.Close(websocket.StatusTryAgainLater, apiErr.Message)
+				return
+			}
+			conn.Close(websocket.StatusNormalClosure, "")
+			return
+		case ev := <-sub.Events():
+			if err := writeWS(ctx, conn, StreamMessage{Type: "rate", Event: &ev}); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			pingCtx, cancelPing := context.WithTimeout(ctx, h.heartbeat)
+			err := conn.Ping(pingCtx)
+			cancelPing()
+			if err != nil {
+				log.Info("Cliente não respondeu ao ping", "protocolo", "websocket", "erro", err.Error())
+				return
+			}
+		}
+	}
+}
+
+// readSubscriptions lê as mensagens do cliente; a leitura também é necessária
+// para o Ping receber o pong
+func (h *RateStreamHandler) readSubscriptions(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn, sub *domain.RateSubscription, log logger.Logger) {
+	defer cancel()
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+
+		// JSON inválido não derruba a conexão: o cliente recebe o erro e segue assinado
+		var msg StreamMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			writeWS(ctx, conn, StreamMessage{Type: "error", Error: &APIError{Code: CodeInvalidRequest, Message: "JSON inválido"}})
+			continue
+		}
+
+		if msg.Type != "subscribe" {
+			writeWS(ctx, conn, StreamMessage{Type: "error", Error: &APIError{Code: CodeInvalidRequest, Message: "tipo de mensagem desconhecido", Field: "type"}})
+			continue
+		}
+		if err := sub.SetCurrencies(msg.Currencies); err != nil {
+			apiErr := streamError(err)
+			writeWS(ctx, conn, StreamMessage{Type: "error", Error: &apiErr})
+			continue
+		}
+		log.Info("Assinatura de cotações alterada", "moedas", msg.Currencies)
+		writeWS(ctx, conn, StreamMessage{Type: "subscribed", Currencies: h.subscribed(msg.Currencies)})
+	}
+}
+
+func writeWS(ctx context.Context, conn *websocket.Conn, msg StreamMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, wsWriteTimeout)
+	defer cancel()
+	return wsjson.Write(ctx, conn, msg)
+}
+
+// subscribe lê filtro e ponto de retomada e registra o assinante, respondendo
+// o erro ao cliente quando não for possível
+func (h *RateStreamHandler) subscribe(w http.ResponseWriter, r *http.Request) (*domain.RateSubscription, bool) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	lastEventID, err := parseLastEventID(r)
+	if err != nil {
+		writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: "Last-Event-ID deve ser um número inteiro não negativo", Field: "last_event_id"})
+		return nil, false
+	}
+
+	sub, err := h.broadcaster.Subscribe(parseCurrencies(r.URL.Query().Get("currencies")), lastEventID, streamBuffer)
+	if err != nil {
+		log.Warn("Assinatura de cotações recusada", "erro", err.Error())
+		status := http.StatusBadRequest
+		if errors.Is(err, domain.ErrBroadcasterStopped) {
+			status = http.StatusServiceUnavailable
+		}
+		writeAPIError(w, r, status, streamError(err))
+		return nil, false
+	}
+	return sub, true
+}
+
+func (h *RateStreamHandler) subscribed(currencies []string) []string {
+	if len(currencies) == 0 {
+		return h.broadcaster.Currencies()
+	}
+	out := make([]string, 0, len(currencies))
+	for _, c := range currencies {
+		out = append(out, strings.ToUpper(strings.TrimSpace(c)))
+	}
+	return out
+}
+
+func (h *RateStreamHandler) logEnd(log logger.Logger, err error, protocolo string) {
+	switch {
+	case errors.Is(err, domain.ErrSlowConsumer):
+		log.Warn("Cliente lento desconectado do stream de cotações", "protocolo", protocolo)
+	default:
+		log.Info("Stream de cotações encerrado", "protocolo", protocolo)
+	}
+}
+
+// streamError traduz os erros da assinatura para o formato de erro da API
+func streamError(err error) APIError {
+	switch {
+	case errors.Is(err, domain.ErrCurrencyNotWatched):
+		return APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "currencies"}
+	case errors.Is(err, domain.ErrSlowConsumer):
+		return APIError{Code: CodeSlowConsumer, Message: err.Error() + "; reconecte informando o último id recebido"}
+	case errors.Is(err, domain.ErrBroadcasterStopped):
+		return APIError{Code: CodeStreamClosed, Message: err.Error()}
+	default:
+		return APIError{Code: CodeInternal, Message: "erro interno no servidor"}
+	}
+}
+
+func parseCurrencies(raw string) []string {
+	var out []string
+	for _, c := range strings.Split(raw, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// parseLastEventID aceita o cabeçalho enviado pelo EventSource ao reconectar ou,
+// para clientes que não controlam cabeçalhos, o parâmetro last_event_id
+func parseLastEventID(r *http.Request) (uint64, error) {
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = r.URL.Query().Get("last_event_id")
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(strings.TrimSpace(raw), 10, 64)
+}
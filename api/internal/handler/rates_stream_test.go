@@ -0,0 +1,328 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var streamReaderKey = &domain.APIKey{ID: "k1", Scopes: []string{domain.ScopeHistoryRead}}
+
+// newStreamServer sobe as rotas de streaming com um broadcaster alimentado pelo teste
+func newStreamServer(t *testing.T, heartbeat time.Duration, key *domain.APIKey) (*httptest.Server, *domain.RateBroadcaster) {
+	t.Helper()
+	loggerMock := new(loggermock.LoggerMock)
+	loggerMock.On("Info", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Warn", mock.Anything, mock.Anything).Return()
+	loggerMock.On("Error", mock.Anything, mock.Anything).Return()
+
+	broadcaster := domain.NewRateBroadcaster(new(rateProviderMock), []string{"USD", "EUR"}, time.Minute, 100, loggerMock)
+
+	mux := http.NewServeMux()
+	Routes{Rates: NewRateStreamHandler(broadcaster, heartbeat, []string{"*"}, loggerMock)}.Register(mux)
+
+	server := httptest.NewServer(Chain(mux, RequestID(), Gzip(), func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if key != nil {
+				r = r.WithContext(domain.ContextWithAPIKey(r.Context(), key))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, broadcaster
+}
+
+func publish(b *domain.RateBroadcaster, moeda string, cotacao float64) {
+	b.Publish(domain.RateQuote{Moeda: moeda, Cotacao: cotacao, Fonte: "awesomeapi", Data: time.Now()})
+}
+
+// latestEventID devolve o ID da última cotação publicada; a contagem parte do
+// instante da subida, então os testes não assumem valores absolutos
+func latestEventID(t *testing.T, b *domain.RateBroadcaster) uint64 {
+	sub, err := b.Subscribe(nil, 0, 10)
+	require.NoError(t, err)
+	defer sub.Close()
+
+	var last uint64
+	for {
+		select {
+		case ev := <-sub.Events():
+			last = ev.ID
+		default:
+			return last
+		}
+	}
+}
+
+type sseFrame struct {
+	id, event, data, comment string
+}
+
+// readSSEFrame lê linhas até a linha em branco que encerra o frame
+func readSSEFrame(t *testing.T, reader *bufio.Reader) sseFrame {
+	t.Helper()
+	var f sseFrame
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return f
+		case strings.HasPrefix(line, ":"):
+			f.comment = strings.TrimSpace(line[1:])
+		case strings.HasPrefix(line, "id: "):
+			f.id = line[4:]
+		case strings.HasPrefix(line, "event: "):
+			f.event = line[7:]
+		case strings.HasPrefix(line, "data: "):
+			f.data = line[6:]
+		}
+	}
+}
+
+func openSSE(t *testing.T, server *httptest.Server, query string, header http.Header) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/v1/rates/stream"+query, nil)
+	require.NoError(t, err)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp, bufio.NewReader(resp.Body)
+}
+
+func TestRateStreamSSE(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should stream snapshot and updates as server-sent events",
+			run:  shouldStreamSnapshotAndUpdatesAsServerSentEvents,
+		},
+		{
+			name: "should resume from last event id header",
+			run:  shouldResumeFromLastEventIDHeader,
+		},
+		{
+			name: "should send heartbeat comments",
+			run:  shouldSendHeartbeatComments,
+		},
+		{
+			name: "should reject currency outside broadcast list",
+			run:  shouldRejectCurrencyOutsideBroadcastList,
+		},
+		{
+			name: "should reject invalid last event id",
+			run:  shouldRejectInvalidLastEventID,
+		},
+		{
+			name: "should require api key for stream",
+			run:  shouldRequireAPIKeyForStream,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldStreamSnapshotAndUpdatesAsServerSentEvents(t *testing.T) {
+	server, broadcaster := newStreamServer(t, time.Minute, streamReaderKey)
+	publish(broadcaster, "USD", 5.0)
+	first := latestEventID(t, broadcaster)
+	publish(broadcaster, "EUR", 6.0)
+
+	resp, reader := openSSE(t, server, "?currencies=usd", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no", resp.Header.Get("X-Accel-Buffering"))
+
+	readSSEFrame(t, reader) // retry
+	snapshot := readSSEFrame(t, reader)
+	assert.Equal(t, strconv.FormatUint(first, 10), snapshot.id)
+	assert.Equal(t, "rate", snapshot.event)
+
+	publish(broadcaster, "EUR", 6.1)
+	publish(broadcaster, "USD", 5.1)
+
+	update := readSSEFrame(t, reader)
+	assert.Equal(t, strconv.FormatUint(first+3, 10), update.id)
+	var ev domain.RateEvent
+	require.NoError(t, json.Unmarshal([]byte(update.data), &ev))
+	assert.Equal(t, "USD", ev.Moeda)
+	assert.Equal(t, 5.1, ev.Cotacao)
+	assert.Equal(t, "awesomeapi", ev.Fonte)
+}
+
+func shouldResumeFromLastEventIDHeader(t *testing.T) {
+	server, broadcaster := newStreamServer(t, time.Minute, streamReaderKey)
+	publish(broadcaster, "USD", 5.0)
+	first := latestEventID(t, broadcaster)
+	publish(broadcaster, "USD", 5.1)
+	publish(broadcaster, "USD", 5.2)
+
+	_, reader := openSSE(t, server, "", http.Header{"Last-Event-Id": {strconv.FormatUint(first, 10)}})
+
+	readSSEFrame(t, reader) // retry
+	assert.Equal(t, strconv.FormatUint(first+1, 10), readSSEFrame(t, reader).id)
+	assert.Equal(t, strconv.FormatUint(first+2, 10), readSSEFrame(t, reader).id)
+}
+
+func shouldSendHeartbeatComments(t *testing.T) {
+	server, _ := newStreamServer(t, 10*time.Millisecond, streamReaderKey)
+
+	_, reader := openSSE(t, server, "", nil)
+
+	readSSEFrame(t, reader) // retry
+	assert.Equal(t, "ping", readSSEFrame(t, reader).comment)
+}
+
+func shouldRejectCurrencyOutsideBroadcastList(t *testing.T) {
+	server, _ := newStreamServer(t, time.Minute, streamReaderKey)
+
+	resp, _ := openSSE(t, server, "?currencies=USD,JPY", nil)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	var body envelopeBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Errors, 1)
+	assert.Equal(t, CodeInvalidRequest, body.Errors[0].Code)
+	assert.Equal(t, "currencies", body.Errors[0].Field)
+}
+
+func shouldRejectInvalidLastEventID(t *testing.T) {
+	server, _ := newStreamServer(t, time.Minute, streamReaderKey)
+
+	resp, _ := openSSE(t, server, "?last_event_id=abc", nil)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func shouldRequireAPIKeyForStream(t *testing.T) {
+	server, _ := newStreamServer(t, time.Minute, nil)
+
+	resp, _ := openSSE(t, server, "", nil)
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestRateStreamWebSocket(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should stream rates and switch subscription over websocket",
+			run:  shouldStreamRatesAndSwitchSubscriptionOverWebSocket,
+		},
+		{
+			name: "should keep connection after invalid client message",
+			run:  shouldKeepConnectionAfterInvalidClientMessage,
+		},
+		{
+			name: "should reject websocket handshake for unknown currency",
+			run:  shouldRejectWebSocketHandshakeForUnknownCurrency,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func dialWS(t *testing.T, server *httptest.Server, query string) *websocket.Conn {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http")+"/v1/rates/ws"+query, &websocket.DialOptions{
+		HTTPHeader: http.Header{"Accept-Encoding": {"gzip"}},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.CloseNow() })
+	return conn
+}
+
+func readWS(t *testing.T, conn *websocket.Conn) StreamMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var msg StreamMessage
+	require.NoError(t, wsjson.Read(ctx, conn, &msg))
+	return msg
+}
+
+func shouldStreamRatesAndSwitchSubscriptionOverWebSocket(t *testing.T) {
+	server, broadcaster := newStreamServer(t, time.Minute, streamReaderKey)
+	publish(broadcaster, "USD", 5.0)
+
+	conn := dialWS(t, server, "?currencies=USD")
+
+	snapshot := readWS(t, conn)
+	assert.Equal(t, "rate", snapshot.Type)
+
+	require.NoError(t, wsjson.Write(context.Background(), conn, StreamMessage{Type: "subscribe", Currencies: []string{"eur"}}))
+	ack := readWS(t, conn)
+	assert.Equal(t, "subscribed", ack.Type)
+	assert.Equal(t, []string{"EUR"}, ack.Currencies)
+
+	publish(broadcaster, "USD", 5.1)
+	publish(broadcaster, "EUR", 6.0)
+
+	update := readWS(t, conn)
+	assert.Equal(t, "rate", update.Type)
+	assert.Equal(t, "EUR", update.Event.Moeda)
+	assert.Equal(t, snapshot.Event.ID+2, update.Event.ID)
+}
+
+func shouldKeepConnectionAfterInvalidClientMessage(t *testing.T) {
+	server, broadcaster := newStreamServer(t, time.Minute, streamReaderKey)
+	conn := dialWS(t, server, "")
+
+	require.NoError(t, conn.Write(context.Background(), websocket.MessageText, []byte("{")))
+	invalid := readWS(t, conn)
+	assert.Equal(t, "error", invalid.Type)
+	assert.Equal(t, CodeInvalidRequest, invalid.Error.Code)
+
+	require.NoError(t, wsjson.Write(context.Background(), conn, StreamMessage{Type: "subscribe", Currencies: []string{"JPY"}}))
+	unknown := readWS(t, conn)
+	assert.Equal(t, "error", unknown.Type)
+	assert.Equal(t, "currencies", unknown.Error.Field)
+
+	publish(broadcaster, "USD", 5.0)
+	assert.Equal(t, "rate", readWS(t, conn).Type)
+}
+
+func shouldRejectWebSocketHandshakeForUnknownCurrency(t *testing.T) {
+	server, _ := newStreamServer(t, time.Minute, streamReaderKey)
+
+	_, resp, err := websocket.Dial(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http")+"/v1/rates/ws?currencies=JPY", nil)
+
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
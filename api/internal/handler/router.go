@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"go-frete/api/internal/domain"
+)
+
+// Routes reúne os handlers da API para registrá-los no mux
+type Routes struct {
+	Converter *ConverterHandler
+	LogLevels *LogLevelHandler
+	APIKeys   *APIKeyHandler
+	Quotas    *domain.QuotaUseCase
+	// Opcional: sem ele as rotas de streaming de cotações não são registradas
+	Rates *RateStreamHandler
+	// Opcional: sem ele a rota de calendário não é registrada
+	Calendars *CalendarHandler
+	// Opcional: sem ele a rota de estado da persistência não é registrada
+	Persistence *PersistenceHandler
+	// Opcional: sem ele a rota de estatísticas do histórico não é registrada
+	ConversionStats *ConversionStatsHandler
+	// Opcional: sem ele as rotas de exportação do histórico não são registradas
+	Export *ExportHandler
+	// Opcional: sem ele as rotas de importação do histórico não são registradas
+	Import *ImportHandler
+	// Opcional: sem ele as rotas de webhooks não são registradas
+	Webhooks *WebhookHandler
+	// Opcional: sem ele as rotas de alertas de cotação não são registradas
+	Alerts *AlertHandler
+	// Opcional: sem ele as rotas de indicadores técnicos e comparação de moedas não são registradas
+	Analytics *AnalyticsHandler
+	// Opcional: sem ele a rota de previsão de cotações não é registrada
+	Forecast *ForecastHandler
+
+	// Data anunciada no cabeçalho Sunset das rotas legadas (zero para omitir)
+	LegacySunset time.Time
+}
+
+// Register monta as rotas /v1 e as rotas legadas, que respondem como antes
+// mas anunciam a rota /v1 que as substitui
+func (rt Routes) Register(mux *http.ServeMux) {
+	convert := func(h http.HandlerFunc) http.Handler {
+		return Chain(h, RequireScope(domain.ScopeConvertWrite), EnforceQuota(rt.Quotas))
+	}
+	legacy := func(successor string, h http.Handler) http.Handler {
+		return Deprecated(successor, rt.LegacySunset)(h)
+	}
+
+	mux.HandleFunc("GET /openapi.json", OpenAPIHandle)
+	docs := DocsHandler()
+	mux.Handle("GET /docs", docs)
+	mux.Handle("GET /docs/", docs)
+
+	// API versionada
+	mux.Handle("POST /v1/conversions", convert(rt.Converter.CreateHandle))
+	mux.Handle("GET /v1/conversions", Protect(domain.ScopeHistoryRead, rt.Converter.SearchHandle))
+	if rt.ConversionStats != nil {
+		mux.Handle("GET /v1/conversions/statistics", Protect(domain.ScopeHistoryRead, rt.ConversionStats.Handle))
+	}
+	if rt.Export != nil {
+		mux.Handle("GET /v1/conversions/export", Protect(domain.ScopeHistoryRead, rt.Export.Handle))
+	}
+	if rt.Import != nil {
+		mux.Handle("POST /v1/conversions/import", Protect(domain.ScopeAdmin, rt.Import.Handle))
+	}
+	mux.Handle("GET /v1/currencies/{moeda}/variations", Protect(domain.ScopeHistoryRead, rt.Converter.VariationHandle))
+	mux.Handle("GET /v1/currencies/{moeda}/statistics", Protect(domain.ScopeHistoryRead, rt.Converter.StatisticsHandle))
+	mux.Handle("GET /v1/admin/log-level", Protect(domain.ScopeAdmin, rt.LogLevels.GetHandle))
+	mux.Handle("PUT /v1/admin/log-level", Protect(domain.ScopeAdmin, rt.LogLevels.PutHandle))
+	mux.Handle("GET /v1/admin/api-keys", Protect(domain.ScopeAdmin, rt.APIKeys.ListHandle))
+	mux.Handle("POST /v1/admin/api-keys", Protect(domain.ScopeAdmin, rt.APIKeys.CreateHandle))
+	mux.Handle("DELETE /v1/admin/api-keys/{id}", Protect(domain.ScopeAdmin, rt.APIKeys.RevokeHandle))
+	mux.Handle("POST /v1/admin/api-keys/{id}/rotate", Protect(domain.ScopeAdmin, rt.APIKeys.RotateHandle))
+	if rt.Rates != nil {
+		mux.Handle("GET /v1/rates/stream", Protect(domain.ScopeHistoryRead, rt.Rates.SSEHandle))
+		mux.Handle("GET /v1/rates/ws", Protect(domain.ScopeHistoryRead, rt.Rates.WebSocketHandle))
+	}
+	if rt.Calendars != nil {
+		mux.Handle("GET /v1/calendar/{country}", Protect(domain.ScopeHistoryRead, rt.Calendars.Handle))
+	}
+	if rt.Persistence != nil {
+		mux.Handle("GET /v1/admin/persistence", Protect(domain.ScopeAdmin, rt.Persistence.Handle))
+	}
+	if rt.Webhooks != nil {
+		// Quem lê o histórico pode recebê-lo por webhook
+		mux.Handle("POST /v1/webhooks", Protect(domain.ScopeHistoryRead, rt.Webhooks.CreateHandle))
+		mux.Handle("GET /v1/webhooks", Protect(domain.ScopeHistoryRead, rt.Webhooks.ListHandle))
+		mux.Handle("DELETE /v1/webhooks/{id}", Protect(domain.ScopeHistoryRead, rt.Webhooks.DeleteHandle))
+		mux.Handle("GET /v1/admin/webhooks/deliveries", Protect(domain.ScopeAdmin, rt.Webhooks.DeliveriesHandle))
+		mux.Handle("POST /v1/admin/webhooks/deliveries/{id}/replay", Protect(domain.ScopeAdmin, rt.Webhooks.ReplayHandle))
+	}
+	if rt.Alerts != nil {
+		// Como o streaming, os alertas acompanham as cotações
+		mux.Handle("POST /v1/alerts", Protect(domain.ScopeHistoryRead, rt.Alerts.CreateHandle))
+		mux.Handle("GET /v1/alerts", Protect(domain.ScopeHistoryRead, rt.Alerts.ListHandle))
+		mux.Handle("GET /v1/alerts/history", Protect(domain.ScopeHistoryRead, rt.Alerts.HistoryHandle))
+		mux.Handle("GET /v1/alerts/{id}", Protect(domain.ScopeHistoryRead, rt.Alerts.GetHandle))
+		mux.Handle("PUT /v1/alerts/{id}", Protect(domain.ScopeHistoryRead, rt.Alerts.UpdateHandle))
+		mux.Handle("DELETE /v1/alerts/{id}", Protect(domain.ScopeHistoryRead, rt.Alerts.DeleteHandle))
+	}
+	if rt.Analytics != nil {
+		mux.Handle("GET /v1/analytics/compare", Protect(domain.ScopeHistoryRead, rt.Analytics.CompareHandle))
+		mux.Handle("GET /v1/analytics/{moeda}", Protect(domain.ScopeHistoryRead, rt.Analytics.Handle))
+	}
+	if rt.Forecast != nil {
+		mux.Handle("GET /v1/forecast/{moeda}", Protect(domain.ScopeHistoryRead, rt.Forecast.Handle))
+	}
+	mux.HandleFunc(V1Prefix+"/", NotFoundV1)
+
+	// Rotas legadas, mantidas durante a migração dos clientes
+	mux.Handle("POST /converter", legacy("/v1/conversions", convert(rt.Converter.Handle)))
+	mux.Handle("GET /convert/list", legacy("/v1/conversions", Protect(domain.ScopeHistoryRead, rt.Converter.ListHandle)))
+	if rt.Export != nil {
+		mux.Handle("GET /convert/export", legacy("/v1/conversions/export", Protect(domain.ScopeHistoryRead, rt.Export.Handle)))
+	}
+	if rt.Import != nil {
+		mux.Handle("POST /convert/import", legacy("/v1/conversions/import", Protect(domain.ScopeAdmin, rt.Import.Handle)))
+	}
+	mux.Handle("GET /variation/{moeda}", legacy("/v1/currencies/{moeda}/variations", Protect(domain.ScopeHistoryRead, rt.Converter.VariationHandle)))
+	mux.Handle("GET /admin/log-level", legacy("/v1/admin/log-level", Protect(domain.ScopeAdmin, rt.LogLevels.GetHandle)))
+	mux.Handle("PUT /admin/log-level", legacy("/v1/admin/log-level", Protect(domain.ScopeAdmin, rt.LogLevels.PutHandle)))
+	mux.Handle("GET /admin/api-keys", legacy("/v1/admin/api-keys", Protect(domain.ScopeAdmin, rt.APIKeys.ListHandle)))
+	mux.Handle("POST /admin/api-keys", legacy("/v1/admin/api-keys", Protect(domain.ScopeAdmin, rt.APIKeys.CreateHandle)))
+	mux.Handle("DELETE /admin/api-keys/{id}", legacy("/v1/admin/api-keys/{id}", Protect(domain.ScopeAdmin, rt.APIKeys.RevokeHandle)))
+	mux.Handle("POST /admin/api-keys/{id}/rotate", legacy("/v1/admin/api-keys/{id}/rotate", Protect(domain.ScopeAdmin, rt.APIKeys.RotateHandle)))
+}
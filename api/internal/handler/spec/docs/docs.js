@@ -0,0 +1,283 @@
+// Página de documentação da API: lê o /openapi.json e monta a referência das
+// rotas sem depender de nada de fora do binário
+(function () {
+  "use strict";
+
+  var METODOS = ["get", "post", "put", "patch", "delete"];
+  // Profundidade máxima ao abrir schemas aninhados (e ciclos de $ref)
+  var PROFUNDIDADE = 6;
+
+  var menu = document.getElementById("menu");
+  var conteudo = document.getElementById("conteudo");
+  var spec;
+
+  function el(tag, attrs, filhos) {
+    var node = document.createElement(tag);
+    Object.keys(attrs || {}).forEach(function (k) {
+      if (k === "texto") {
+        node.textContent = attrs[k];
+      } else {
+        node.setAttribute(k, attrs[k]);
+      }
+    });
+    (filhos || []).forEach(function (f) {
+      if (f) {
+        node.appendChild(typeof f === "string" ? document.createTextNode(f) : f);
+      }
+    });
+    return node;
+  }
+
+  // resolve segue um $ref local (#/components/...) até o objeto
+  function resolve(obj) {
+    var vistos = 0;
+    while (obj && obj.$ref && vistos < PROFUNDIDADE) {
+      obj = obj.$ref.replace(/^#\//, "").split("/").reduce(function (atual, parte) {
+        return atual && atual[parte.replace(/~1/g, "/").replace(/~0/g, "~")];
+      }, spec);
+      vistos++;
+    }
+    return obj || {};
+  }
+
+  function nomeDoRef(obj) {
+    return obj && obj.$ref ? obj.$ref.split("/").pop() : "";
+  }
+
+  function tipoDe(schema) {
+    var nome = nomeDoRef(schema);
+    schema = resolve(schema);
+    var tipo = schema.type || (schema.properties ? "object" : "");
+    if (tipo === "array") {
+      tipo = "array de " + tipoDe(schema.items || {});
+    } else if (schema.oneOf || schema.anyOf) {
+      tipo = (schema.oneOf || schema.anyOf).map(tipoDe).join(" | ");
+    } else if (schema.format) {
+      tipo += " (" + schema.format + ")";
+    }
+    if (nome) {
+      tipo = nome + (tipo && tipo !== "object" ? ": " + tipo : "");
+    }
+    if (schema.nullable) {
+      tipo += " | null";
+    }
+    return tipo || "qualquer";
+  }
+
+  // detalhes lista enum, limites e padrão do schema
+  function detalhes(schema) {
+    var partes = [];
+    if (schema.enum) {
+      partes.push("valores: " + schema.enum.map(JSON.stringify).join(", "));
+    }
+    ["minimum", "maximum", "minLength", "maxLength", "maxItems", "pattern"].forEach(function (k) {
+      if (schema[k] !== undefined) {
+        partes.push(k + ": " + schema[k]);
+      }
+    });
+    if (schema["default"] !== undefined) {
+      partes.push("padrão: " + JSON.stringify(schema["default"]));
+    }
+    return partes.join("; ");
+  }
+
+  function renderSchema(schema, nivel) {
+    schema = resolve(schema);
+    if (schema.type === "array" && schema.items) {
+      return renderSchema(schema.items, nivel);
+    }
+    var props = schema.properties || {};
+    var nomes = Object.keys(props);
+    if (nomes.length === 0 || nivel >= PROFUNDIDADE) {
+      return null;
+    }
+
+    var obrigatorios = schema.required || [];
+    var linhas = nomes.map(function (nome) {
+      var prop = props[nome];
+      var resolvido = resolve(prop);
+      var descricao = [resolvido.description, detalhes(resolvido)].filter(Boolean).join(" — ");
+      var filho = renderSchema(prop, nivel + 1);
+      return el("tr", {}, [
+        el("td", {}, [
+          el("code", { texto: nome }),
+          obrigatorios.indexOf(nome) >= 0 ? el("span", { "class": "obrigatorio", texto: " *" }) : null,
+        ]),
+        el("td", {}, [
+          el("span", { "class": "tipo", texto: tipoDe(prop) }),
+          descricao ? el("div", { texto: descricao }) : null,
+          filho ? el("details", {}, [el("summary", { texto: "campos" }), filho]) : null,
+        ]),
+      ]);
+    });
+    return el("div", { "class": "schema" }, [el("table", {}, [el("tbody", {}, linhas)])]);
+  }
+
+  function renderConteudo(content) {
+    var tipos = Object.keys(content || {});
+    return tipos.map(function (tipo) {
+      var schema = content[tipo].schema || {};
+      return el("div", {}, [
+        el("code", { texto: tipo }),
+        " ",
+        el("span", { "class": "tipo", texto: tipoDe(schema) }),
+        renderSchema(schema, 0),
+      ]);
+    });
+  }
+
+  function renderParametros(params) {
+    if (params.length === 0) {
+      return null;
+    }
+    var linhas = params.map(function (p) {
+      p = resolve(p);
+      var schema = resolve(p.schema || {});
+      var descricao = [p.description, detalhes(schema)].filter(Boolean).join(" — ");
+      return el("tr", {}, [
+        el("td", {}, [
+          el("code", { texto: p.name }),
+          p.required ? el("span", { "class": "obrigatorio", texto: " *" }) : null,
+        ]),
+        el("td", { texto: p["in"] }),
+        el("td", { "class": "tipo", texto: tipoDe(p.schema || {}) }),
+        el("td", { texto: descricao }),
+      ]);
+    });
+    return el("div", {}, [
+      el("h4", { texto: "Parâmetros" }),
+      el("table", {}, [
+        el("thead", {}, [el("tr", {}, ["Nome", "Onde", "Tipo", "Descrição"].map(function (t) {
+          return el("th", { texto: t });
+        }))]),
+        el("tbody", {}, linhas),
+      ]),
+    ]);
+  }
+
+  function renderRespostas(respostas) {
+    var itens = Object.keys(respostas || {}).map(function (codigo) {
+      var r = resolve(respostas[codigo]);
+      return el("details", {}, [
+        el("summary", {}, [el("code", { texto: codigo }), " " + (r.description || "")]),
+      ].concat(renderConteudo(r.content)));
+    });
+    return el("div", {}, [el("h4", { texto: "Respostas" })].concat(itens));
+  }
+
+  function seguranca(op) {
+    var regras = op.security || spec.security || [];
+    return regras.length === 0 ? "pública" : "chave de API";
+  }
+
+  function ancora(metodo, caminho) {
+    return (metodo + caminho).replace(/[^a-zA-Z0-9]+/g, "-");
+  }
+
+  // grupo agrupa as rotas pelo recurso: /v1/conversions/export fica em
+  // "conversions"; as rotas sem /v1 ficam em "legado"
+  function grupo(caminho) {
+    var partes = caminho.split("/").filter(Boolean);
+    if (partes[0] !== "v1") {
+      return caminho === "/openapi.json" || caminho === "/docs" ? "documentação" : "legado";
+    }
+    return partes[1] === "admin" ? "admin" : partes[1];
+  }
+
+  function renderOperacao(caminho, metodo, op, comuns) {
+    var params = (comuns || []).concat(op.parameters || []);
+    var corpo = op.requestBody ? resolve(op.requestBody) : null;
+    return el("section", { id: ancora(metodo, caminho), "class": "operacao" + (op.deprecated ? " obsoleta" : "") }, [
+      el("h3", {}, [
+        el("span", { "class": "metodo " + metodo, texto: metodo.toUpperCase() }),
+        el("span", { "class": "caminho", texto: caminho }),
+        op.deprecated ? el("span", { "class": "selo", texto: "obsoleta" + (op["x-successor"] ? ": use " + op["x-successor"] : "") }) : null,
+        el("span", { "class": "selo", texto: seguranca(op) }),
+      ]),
+      op.summary ? el("p", {}, [el("strong", { texto: op.summary })]) : null,
+      op.description ? el("p", { "class": "descricao", texto: op.description }) : null,
+      renderParametros(params),
+      corpo ? el("div", {}, [
+        el("h4", { texto: "Corpo" + (corpo.required ? " (obrigatório)" : "") }),
+        corpo.description ? el("p", { texto: corpo.description }) : null,
+      ].concat(renderConteudo(corpo.content))) : null,
+      renderRespostas(op.responses),
+    ]);
+  }
+
+  function render() {
+    document.title = spec.info.title + " " + spec.info.version + " - Documentação";
+    conteudo.textContent = "";
+    conteudo.appendChild(el("h1", {}, [spec.info.title + " ", el("span", { "class": "selo", texto: spec.info.version })]));
+    if (spec.info.description) {
+      conteudo.appendChild(el("p", { "class": "descricao", texto: spec.info.description }));
+    }
+    conteudo.appendChild(el("p", {}, ["Documento completo: ", el("a", { href: "/openapi.json", texto: "/openapi.json" })]));
+
+    var grupos = {};
+    var ordem = [];
+    Object.keys(spec.paths).forEach(function (caminho) {
+      var item = spec.paths[caminho];
+      METODOS.forEach(function (metodo) {
+        if (!item[metodo]) {
+          return;
+        }
+        var g = grupo(caminho);
+        if (!grupos[g]) {
+          grupos[g] = [];
+          ordem.push(g);
+        }
+        grupos[g].push(renderOperacao(caminho, metodo, item[metodo], item.parameters));
+      });
+    });
+
+    menu.textContent = "";
+    ordem.forEach(function (g) {
+      conteudo.appendChild(el("h2", { id: "grupo-" + g, texto: g }));
+      menu.appendChild(el("h2", {}, [el("a", { href: "#grupo-" + g, texto: g })]));
+      grupos[g].forEach(function (secao) {
+        conteudo.appendChild(secao);
+        var titulo = secao.querySelector("h3");
+        menu.appendChild(el("a", { href: "#" + secao.id }, [
+          titulo.querySelector(".metodo").textContent + " " + titulo.querySelector(".caminho").textContent,
+        ]));
+      });
+    });
+
+    var schemas = (spec.components && spec.components.schemas) || {};
+    if (Object.keys(schemas).length > 0) {
+      conteudo.appendChild(el("h2", { id: "schemas", texto: "schemas" }));
+      menu.appendChild(el("h2", {}, [el("a", { href: "#schemas", texto: "schemas" })]));
+      Object.keys(schemas).forEach(function (nome) {
+        conteudo.appendChild(el("section", { id: "schema-" + nome, "class": "operacao" }, [
+          el("h3", { texto: nome }),
+          schemas[nome].description ? el("p", { texto: schemas[nome].description }) : null,
+          renderSchema(schemas[nome], 0),
+        ]));
+      });
+    }
+
+    if (location.hash) {
+      var alvo = document.getElementById(decodeURIComponent(location.hash.slice(1)));
+      if (alvo) {
+        alvo.scrollIntoView();
+      }
+    }
+  }
+
+  fetch("/openapi.json")
+    .then(function (resp) {
+      if (!resp.ok) {
+        throw new Error("status " + resp.status);
+      }
+      return resp.json();
+    })
+    .then(function (doc) {
+      spec = doc;
+      render();
+    })
+    .catch(function (err) {
+      conteudo.textContent = "";
+      conteudo.appendChild(el("p", { "class": "erro", texto: "Falha ao carregar /openapi.json: " + err.message }));
+    });
+})();
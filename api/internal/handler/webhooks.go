@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/pkg/logger"
+)
+
+type CreateWebhookRequest struct {
+	URL     string   `json:"url"`
+	Eventos []string `json:"eventos"`
+}
+
+// CreateWebhookResponse é a única vez em que o segredo do webhook é devolvido
+type CreateWebhookResponse struct {
+	ID       string    `json:"id"`
+	URL      string    `json:"url"`
+	Eventos  []string  `json:"eventos"`
+	Segredo  string    `json:"segredo"`
+	CriadoEm time.Time `json:"criado_em"`
+}
+
+// WebhookHandler atende o cadastro de webhooks pelos clientes e as rotas
+// administrativas da fila de entregas
+type WebhookHandler struct {
+	useCase *domain.WebhookUseCase
+	log     logger.Logger
+}
+
+func NewWebhookHandler(uc *domain.WebhookUseCase, l logger.Logger) *WebhookHandler {
+	return &WebhookHandler{useCase: uc, log: l}
+}
+
+func (h *WebhookHandler) CreateHandle(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context(), h.log)
+
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn("Falha ao fazer parse do JSON", "erro", err.Error())
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "JSON inválido")
+		return
+	}
+
+	hook, err := h.useCase.Create(r.Context(), req.URL, req.Eventos)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrWebhookURL):
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error(), Field: "url"})
+		case errors.Is(err, domain.ErrWebhookEvent):
+			writeAPIError(w, r, http.StatusBadRequest, APIError{
+				Code:    CodeInvalidRequest,
+				Message: "Informe ao menos um evento: conversion.created, rate_alert.triggered ou provider.down",
+				Field:   "eventos",
+			})
+		default:
+			writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao cadastrar webhook")
+		}
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, CreateWebhookResponse{
+		ID:       hook.ID,
+		URL:      hook.URL,
+		Eventos:  hook.Eventos,
+		Segredo:  hook.Segredo,
+		CriadoEm: hook.CriadoEm,
+	})
+}
+
+func (h *WebhookHandler) ListHandle(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.useCase.List(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao listar webhooks")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, hooks)
+}
+
+func (h *WebhookHandler) DeleteHandle(w http.ResponseWriter, r *http.Request) {
+	err := h.useCase.Delete(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrWebhookNotFound) {
+			writeError(w, r, http.StatusNotFound, CodeNotFound, err.Error())
+			return
+		}
+		writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao remover webhook")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeliveriesHandle lista a fila de entregas; status=morta é a fila de mortas
+func (h *WebhookHandler) DeliveriesHandle(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := domain.DeliveryFilter{Status: q.Get("status"), WebhookID: q.Get("webhook_id")}
+
+	switch filter.Status {
+	case "", domain.DeliveryPending, domain.DeliveryDelivered, domain.DeliveryDead:
+	default:
+		writeAPIError(w, r, http.StatusBadRequest, APIError{
+			Code: CodeInvalidRequest, Message: "Use pendente, entregue ou morta", Field: "status",
+		})
+		return
+	}
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			writeAPIError(w, r, http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: "Deve ser um inteiro não negativo", Field: "limit"})
+			return
+		}
+		filter.Limit = n
+	}
+
+	deliveries, err := h.useCase.Deliveries(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao listar entregas de webhook")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, deliveries)
+}
+
+func (h *WebhookHandler) ReplayHandle(w http.ResponseWriter, r *http.Request) {
+	delivery, err := h.useCase.Replay(r.Context(), r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrDeliveryNotFound), errors.Is(err, domain.ErrWebhookNotFound):
+			writeError(w, r, http.StatusNotFound, CodeNotFound, err.Error())
+		case errors.Is(err, domain.ErrDeliveryPending):
+			writeError(w, r, http.StatusConflict, CodeConflict, err.Error())
+		default:
+			writeError(w, r, http.StatusInternalServerError, CodeInternal, "Erro ao reenviar entrega de webhook")
+		}
+		return
+	}
+
+	writeJSON(w, r, http.StatusAccepted, delivery)
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-frete/api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// webhookStoreMock faz o papel dos dois repositórios de webhooks
+type webhookStoreMock struct {
+	mock.Mock
+}
+
+func (m *webhookStoreMock) SaveWebhook(w domain.Webhook) error {
+	return m.Called(w).Error(0)
+}
+
+func (m *webhookStoreMock) GetWebhook(id string) (*domain.Webhook, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Webhook), args.Error(1)
+}
+
+func (m *webhookStoreMock) ListWebhooks(apiKeyID string) ([]domain.Webhook, error) {
+	args := m.Called(apiKeyID)
+	return args.Get(0).([]domain.Webhook), args.Error(1)
+}
+
+func (m *webhookStoreMock) DeleteWebhook(id string) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *webhookStoreMock) WebhooksFor(tipo string) ([]domain.Webhook, error) {
+	args := m.Called(tipo)
+	return args.Get(0).([]domain.Webhook), args.Error(1)
+}
+
+func (m *webhookStoreMock) SaveDeliveries(deliveries []domain.WebhookDelivery) error {
+	return m.Called(deliveries).Error(0)
+}
+
+func (m *webhookStoreMock) ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	args := m.Called(now, lease, limit)
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *webhookStoreMock) UpdateDelivery(d domain.WebhookDelivery) error {
+	return m.Called(d).Error(0)
+}
+
+func (m *webhookStoreMock) GetDelivery(id string) (*domain.WebhookDelivery, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *webhookStoreMock) ListDeliveries(filter domain.DeliveryFilter) ([]domain.WebhookDelivery, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func newWebhookHandlerFake(store *webhookStoreMock) *WebhookHandler {
+	uc := domain.NewWebhookUseCase(store, store, nil, domain.WebhookConfig{}, newExportLogger())
+	return NewWebhookHandler(uc, newExportLogger())
+}
+
+func withKey(r *http.Request, id string, scopes ...string) *http.Request {
+	return r.WithContext(domain.ContextWithAPIKey(r.Context(), &domain.APIKey{ID: id, Scopes: scopes}))
+}
+
+func TestWebhookHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should create webhook and return secret once",
+			run:  shouldCreateWebhookAndReturnSecretOnce,
+		},
+		{
+			name: "should return 400 with field for invalid webhook",
+			run:  shouldReturn400WithFieldForInvalidWebhook,
+		},
+		{
+			name: "should return 404 when deleting webhook of another key",
+			run:  shouldReturn404WhenDeletingWebhookOfAnotherKey,
+		},
+		{
+			name: "should list dead letter deliveries",
+			run:  shouldListDeadLetterDeliveries,
+		},
+		{
+			name: "should return 400 for invalid delivery filter",
+			run:  shouldReturn400ForInvalidDeliveryFilter,
+		},
+		{
+			name: "should map replay errors",
+			run:  shouldMapReplayErrors,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldCreateWebhookAndReturnSecretOnce(t *testing.T) {
+	store := new(webhookStoreMock)
+	store.On("SaveWebhook", mock.MatchedBy(func(w domain.Webhook) bool {
+		return w.APIKeyID == "k1" && w.URL == "https://exemplo.com/hook" && strings.HasPrefix(w.Segredo, "whsec_")
+	})).Return(nil).Once()
+	handler := newWebhookHandlerFake(store)
+
+	body := `{"url":"https://exemplo.com/hook","eventos":["conversion.created","provider.down"]}`
+	recorder := httptest.NewRecorder()
+	handler.CreateHandle(recorder, withKey(httptest.NewRequest(http.MethodPost, "/v1/webhooks", strings.NewReader(body)), "k1", domain.ScopeHistoryRead))
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"segredo":"whsec_`)
+	assert.Contains(t, recorder.Body.String(), `"eventos":["conversion.created","provider.down"]`)
+	store.AssertExpectations(t)
+
+	// Na listagem o segredo não aparece
+	store.On("ListWebhooks", "k1").Return([]domain.Webhook{{ID: "w1", URL: "https://exemplo.com/hook", Segredo: "whsec_x", APIKeyID: "k1"}}, nil)
+	recorder = httptest.NewRecorder()
+	handler.ListHandle(recorder, withKey(httptest.NewRequest(http.MethodGet, "/v1/webhooks", nil), "k1", domain.ScopeHistoryRead))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"id":"w1"`)
+	assert.NotContains(t, recorder.Body.String(), "whsec_")
+}
+
+func shouldReturn400WithFieldForInvalidWebhook(t *testing.T) {
+	handler := newWebhookHandlerFake(new(webhookStoreMock))
+
+	for body, field := range map[string]string{
+		`{"url":"ftp://exemplo.com","eventos":["provider.down"]}`:     "url",
+		`{"url":"https://exemplo.com","eventos":[]}`:                  "eventos",
+		`{"url":"https://exemplo.com","eventos":["rate.teleported"]}`: "eventos",
+	} {
+		recorder := httptest.NewRecorder()
+		handler.CreateHandle(recorder, httptest.NewRequest(http.MethodPost, "/v1/webhooks", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, body)
+		assert.Contains(t, recorder.Body.String(), `"field":"`+field+`"`, body)
+	}
+}
+
+func shouldReturn404WhenDeletingWebhookOfAnotherKey(t *testing.T) {
+	store := new(webhookStoreMock)
+	store.On("GetWebhook", "w1").Return(&domain.Webhook{ID: "w1", APIKeyID: "k1"}, nil)
+	handler := newWebhookHandlerFake(store)
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/webhooks/w1", nil)
+	req.SetPathValue("id", "w1")
+	recorder := httptest.NewRecorder()
+	handler.DeleteHandle(recorder, withKey(req, "k2", domain.ScopeHistoryRead))
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	store.AssertNotCalled(t, "DeleteWebhook", mock.Anything)
+}
+
+func shouldListDeadLetterDeliveries(t *testing.T) {
+	store := new(webhookStoreMock)
+	store.On("ListDeliveries", domain.DeliveryFilter{Status: domain.DeliveryDead, WebhookID: "w1", Limit: 10}).
+		Return([]domain.WebhookDelivery{{ID: "d1", WebhookID: "w1", Status: domain.DeliveryDead, Tentativas: 8, UltimoErro: "resposta 500"}}, nil)
+	handler := newWebhookHandlerFake(store)
+
+	recorder := httptest.NewRecorder()
+	handler.DeliveriesHandle(recorder, httptest.NewRequest(http.MethodGet, "/v1/admin/webhooks/deliveries?status=morta&webhook_id=w1&limit=10", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"status":"morta"`)
+	assert.Contains(t, recorder.Body.String(), `"ultimo_erro":"resposta 500"`)
+	assert.NotContains(t, recorder.Body.String(), "entregue_em")
+	store.AssertExpectations(t)
+}
+
+func shouldReturn400ForInvalidDeliveryFilter(t *testing.T) {
+	handler := newWebhookHandlerFake(new(webhookStoreMock))
+
+	for query, field := range map[string]string{"status=dead": "status", "limit=-1": "limit"} {
+		recorder := httptest.NewRecorder()
+		handler.DeliveriesHandle(recorder, httptest.NewRequest(http.MethodGet, "/v1/admin/webhooks/deliveries?"+query, nil))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+		assert.Contains(t, recorder.Body.String(), `"field":"`+field+`"`, query)
+	}
+}
+
+func shouldMapReplayErrors(t *testing.T) {
+	store := new(webhookStoreMock)
+	store.On("GetDelivery", "d1").Return(&domain.WebhookDelivery{ID: "d1", Status: domain.DeliveryPending}, nil)
+	store.On("GetDelivery", "d2").Return(nil, domain.ErrDeliveryNotFound)
+	store.On("GetDelivery", "d3").Return(&domain.WebhookDelivery{ID: "d3", WebhookID: "w1", Status: domain.DeliveryDead, Tentativas: 8}, nil)
+	store.On("GetWebhook", "w1").Return(&domain.Webhook{ID: "w1"}, nil)
+	store.On("UpdateDelivery", mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+		return d.ID == "d3" && d.Status == domain.DeliveryPending && d.Tentativas == 0
+	})).Return(nil).Once()
+	handler := newWebhookHandlerFake(store)
+
+	for id, status := range map[string]int{"d1": http.StatusConflict, "d2": http.StatusNotFound, "d3": http.StatusAccepted} {
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/webhooks/deliveries/"+id+"/replay", nil)
+		req.SetPathValue("id", id)
+		recorder := httptest.NewRecorder()
+		handler.ReplayHandle(recorder, req)
+
+		assert.Equal(t, status, recorder.Code, id)
+	}
+	store.AssertExpectations(t)
+}
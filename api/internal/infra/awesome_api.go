@@ -3,52 +3,148 @@ package infra
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strconv"
+	"time"
+
+	"go-frete/api/internal/domain"
 )
 
+const awesomeAPIURL = "https://economia.awesomeapi.com.br"
+
 type AwesomeAPIData struct {
-	Bid string `json:"bid"`
+	Bid       string `json:"bid"`
+	Timestamp string `json:"timestamp"`
+}
+
+// AwesomeAPIDaily é um item da série diária; só o primeiro traz code/name,
+// os demais trazem apenas os valores do dia
+type AwesomeAPIDaily struct {
+	Bid       string `json:"bid"`
+	Timestamp string `json:"timestamp"`
 }
 
 // O Adapter que implementa a Interface do Domain
-type AwesomeAPIAdapter struct{}
+type AwesomeAPIAdapter struct {
+	baseURL string
+	client  *http.Client
+}
 
 func NewAwesomeAPIAdapter() *AwesomeAPIAdapter {
-	return &AwesomeAPIAdapter{}
+	return &AwesomeAPIAdapter{baseURL: awesomeAPIURL, client: http.DefaultClient}
+}
+
+// Source identifica o provedor nos registros de conversão
+func (a *AwesomeAPIAdapter) Source() string {
+	return "awesomeapi"
 }
 
 // GetRate cumpre o contrato exigido pelo domain.RateProvider
 func (a *AwesomeAPIAdapter) GetRate(moeda string) (float64, error) {
-	url := "https://economia.awesomeapi.com.br/json/last/" + moeda + "-BRL"
+	quote, err := a.GetQuote(moeda)
+	return quote.Cotacao, err
+}
 
-	resp, err := http.Get(url)
+// GetQuote cumpre o contrato domain.QuoteProvider: a cotação com o horário em que foi publicada
+func (a *AwesomeAPIAdapter) GetQuote(moeda string) (domain.RateQuote, error) {
+	url := a.baseURL + "/json/last/" + moeda + "-BRL"
+
+	resp, err := a.client.Get(url)
 	if err != nil {
-		return 0, errors.New("erro ao consultar cotação externa")
+		return domain.RateQuote{}, errors.New("erro ao consultar cotação externa")
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return 0, errors.New("erro ao ler resposta da API")
+		return domain.RateQuote{}, errors.New("erro ao ler resposta da API")
 	}
 
 	var apiResponse map[string]AwesomeAPIData
 	if err = json.Unmarshal(body, &apiResponse); err != nil {
-		return 0, errors.New("erro ao processar cotação")
+		return domain.RateQuote{}, errors.New("erro ao processar cotação")
 	}
 
 	mapKey := moeda + "BRL"
 	data, ok := apiResponse[mapKey]
 	if !ok {
-		return 0, errors.New("moeda_nao_encontrada")
+		return domain.RateQuote{}, domain.ErrCurrencyNotFound
 	}
 
 	cotacao, err := strconv.ParseFloat(data.Bid, 64)
 	if err != nil {
-		return 0, errors.New("erro no valor da cotação")
+		return domain.RateQuote{}, errors.New("erro no valor da cotação")
+	}
+
+	quote := domain.RateQuote{Moeda: moeda, Cotacao: cotacao, Fonte: a.Source()}
+	// Sem horário válido a cotação segue sem data: a validação de idade é pulada
+	if seconds, err := strconv.ParseInt(data.Timestamp, 10, 64); err == nil {
+		quote.Data = time.Unix(seconds, 0).UTC()
+	}
+	return quote, nil
+}
+
+// GetLastDays busca o fechamento dos últimos days dias (/json/daily/{par}/{dias})
+func (a *AwesomeAPIAdapter) GetLastDays(moeda string, days int) ([]domain.RateSnapshot, error) {
+	return a.getDaily(moeda, fmt.Sprintf("%s/json/daily/%s-BRL/%d", a.baseURL, moeda, days))
+}
+
+// GetDailyRates cumpre o contrato domain.HistoricalRateProvider: fechamento de
+// cada dia entre from e to, inclusive
+func (a *AwesomeAPIAdapter) GetDailyRates(moeda string, from, to time.Time) ([]domain.RateSnapshot, error) {
+	// O número de dias no caminho é o limite de itens; a janela vem da query
+	days := int(to.Sub(from).Hours()/24) + 1
+	url := fmt.Sprintf("%s/json/daily/%s-BRL/%d?start_date=%s&end_date=%s",
+		a.baseURL, moeda, days, from.Format("20060102"), to.Format("20060102"))
+	return a.getDaily(moeda, url)
+}
+
+func (a *AwesomeAPIAdapter) getDaily(moeda, url string) ([]domain.RateSnapshot, error) {
+	resp, err := a.client.Get(url)
+	if err != nil {
+		return nil, errors.New("erro ao consultar cotação externa")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, domain.ErrCurrencyNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("erro ao consultar cotação externa: status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.New("erro ao ler resposta da API")
+	}
+
+	var items []AwesomeAPIDaily
+	if err = json.Unmarshal(body, &items); err != nil {
+		return nil, errors.New("erro ao processar cotação")
+	}
+
+	snapshots := make([]domain.RateSnapshot, 0, len(items))
+	for _, item := range items {
+		cotacao, err := strconv.ParseFloat(item.Bid, 64)
+		if err != nil {
+			return nil, errors.New("erro no valor da cotação")
+		}
+		seconds, err := strconv.ParseInt(item.Timestamp, 10, 64)
+		if err != nil {
+			return nil, errors.New("erro na data da cotação")
+		}
+		snapshots = append(snapshots, domain.RateSnapshot{
+			Moeda:   moeda,
+			Cotacao: cotacao,
+			Fonte:   a.Source(),
+			Data:    time.Unix(seconds, 0).UTC(),
+		})
 	}
 
-	return cotacao, nil
+	// A API devolve do mais novo para o mais antigo
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Data.Before(snapshots[j].Data) })
+	return snapshots, nil
 }
@@ -0,0 +1,120 @@
+package infra
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-frete/api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFixtureServer responde cada caminho (com query) com o arquivo de testdata/{dir}
+// correspondente, no formato devolvido pelo provedor. Arquivos *not_found.json respondem 404.
+func newFixtureServer(t *testing.T, dir string, routes map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fixture, ok := routes[r.URL.RequestURI()]
+		if !ok {
+			t.Errorf("requisição inesperada: %s", r.URL.RequestURI())
+			http.NotFound(w, r)
+			return
+		}
+		body, err := os.ReadFile(filepath.Join("testdata", dir, fixture))
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(fixture, "not_found.json") {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newAwesomeAPIFixture(t *testing.T, routes map[string]string) *AwesomeAPIAdapter {
+	server := newFixtureServer(t, "awesomeapi", routes)
+	return &AwesomeAPIAdapter{baseURL: server.URL, client: server.Client()}
+}
+
+func TestAwesomeAPIAdapter(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should read last rate",
+			run:  shouldReadLastRate,
+		},
+		{
+			name: "should read last days oldest first",
+			run:  shouldReadLastDaysOldestFirst,
+		},
+		{
+			name: "should request daily rates for date range",
+			run:  shouldRequestDailyRatesForDateRange,
+		},
+		{
+			name: "should map unknown pair to currency not found",
+			run:  shouldMapUnknownPairToCurrencyNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldReadLastRate(t *testing.T) {
+	adapter := newAwesomeAPIFixture(t, map[string]string{"/json/last/USD-BRL": "last_usd.json"})
+
+	cotacao, err := adapter.GetRate("USD")
+	require.NoError(t, err)
+	assert.Equal(t, 5.4102, cotacao)
+
+	quote, err := adapter.GetQuote("USD")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 9, 21, 59, 59, 0, time.UTC), quote.Data)
+	assert.Equal(t, "awesomeapi", quote.Fonte)
+}
+
+func shouldReadLastDaysOldestFirst(t *testing.T) {
+	adapter := newAwesomeAPIFixture(t, map[string]string{"/json/daily/USD-BRL/3": "daily_usd_3.json"})
+
+	snapshots, err := adapter.GetLastDays("USD", 3)
+
+	require.NoError(t, err)
+	require.Len(t, snapshots, 3)
+	assert.Equal(t, domain.RateSnapshot{Moeda: "USD", Cotacao: 5.3974, Fonte: "awesomeapi", Data: time.Date(2026, 1, 7, 21, 59, 59, 0, time.UTC)}, snapshots[0])
+	assert.Equal(t, 5.4102, snapshots[2].Cotacao)
+}
+
+func shouldRequestDailyRatesForDateRange(t *testing.T) {
+	adapter := newAwesomeAPIFixture(t, map[string]string{
+		"/json/daily/USD-BRL/5?start_date=20260105&end_date=20260109": "daily_usd_20260105_20260109.json",
+	})
+
+	snapshots, err := adapter.GetDailyRates("USD", time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, err)
+	require.Len(t, snapshots, 5)
+	for i, s := range snapshots {
+		assert.Equal(t, 5+i, s.Data.Day())
+	}
+	assert.Equal(t, 5.4039, snapshots[0].Cotacao)
+}
+
+func shouldMapUnknownPairToCurrencyNotFound(t *testing.T) {
+	adapter := newAwesomeAPIFixture(t, map[string]string{"/json/daily/XYZ-BRL/3": "daily_not_found.json"})
+
+	_, err := adapter.GetLastDays("XYZ", 3)
+
+	assert.ErrorIs(t, err, domain.ErrCurrencyNotFound)
+}
@@ -0,0 +1,84 @@
+package infra
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go-frete/api/internal/domain"
+)
+
+// WriterSink escreve cada evento como uma linha JSON (NDJSON), por padrão na
+// saída padrão: útil em desenvolvimento e para coletores de log
+type WriterSink struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{out: w}
+}
+
+func (s *WriterSink) Name() string {
+	return "stdout"
+}
+
+// Publish implementa domain.EventSink
+func (s *WriterSink) Publish(event domain.DomainEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.out.Write(append(line, '\n'))
+	return err
+}
+
+// HTTPSink envia cada evento num POST JSON para a URL configurada. Qualquer
+// resposta fora de 2xx é falha, e o evento é reenviado pelo relay.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPSink(url string, timeout time.Duration) *HTTPSink {
+	return &HTTPSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (s *HTTPSink) Name() string {
+	return "http"
+}
+
+// Publish implementa domain.EventSink. X-Event-Id repete o id do corpo para
+// quem descarta repetições antes de ler o JSON.
+func (s *HTTPSink) Publish(event domain.DomainEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Id", event.ID)
+	req.Header.Set("X-Event-Type", event.Tipo)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Esvazia o corpo para reaproveitar a conexão
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("resposta %d de %s", resp.StatusCode, s.url)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-frete/api/internal/domain"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleEvent(id, chave string, seq int64) domain.DomainEvent {
+	return domain.DomainEvent{
+		ID:        id,
+		Tipo:      domain.EventConversionCreated,
+		Chave:     chave,
+		Sequencia: seq,
+		CriadoEm:  time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC),
+		Dados:     json.RawMessage(`{"currency":"` + chave + `","cotacao":5}`),
+	}
+}
+
+func TestEventSinks(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should write one json line per event",
+			run:  shouldWriteOneJSONLinePerEvent,
+		},
+		{
+			name: "should post event with id headers",
+			run:  shouldPostEventWithIDHeaders,
+		},
+		{
+			name: "should fail http publish outside 2xx",
+			run:  shouldFailHTTPPublishOutside2xx,
+		},
+		{
+			name: "should publish to embedded jetstream without duplicates",
+			run:  shouldPublishToEmbeddedJetStreamWithoutDuplicates,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldWriteOneJSONLinePerEvent(t *testing.T) {
+	var out strings.Builder
+	sink := NewWriterSink(&out)
+
+	require.NoError(t, sink.Publish(sampleEvent("a1", "USD", 1)))
+	require.NoError(t, sink.Publish(sampleEvent("a2", "EUR", 2)))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"id":"a1","tipo":"conversion.created","chave":"USD","sequencia":1,"criado_em":"2026-01-05T12:00:00Z","dados":{"currency":"USD","cotacao":5}}`, lines[0])
+}
+
+func shouldPostEventWithIDHeaders(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer receiver.Close()
+
+	err := NewHTTPSink(receiver.URL+"/events", time.Second).Publish(sampleEvent("a1", "USD", 1))
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPost, got.Method)
+	assert.Equal(t, "/events", got.URL.Path)
+	assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+	assert.Equal(t, "a1", got.Header.Get("X-Event-Id"))
+	assert.Equal(t, "conversion.created", got.Header.Get("X-Event-Type"))
+	assert.Contains(t, string(body), `"dados":{"currency":"USD","cotacao":5}`)
+}
+
+func shouldFailHTTPPublishOutside2xx(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	err := NewHTTPSink(receiver.URL, time.Second).Publish(sampleEvent("a1", "USD", 1))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "503")
+}
+
+func shouldPublishToEmbeddedJetStreamWithoutDuplicates(t *testing.T) {
+	sink, err := NewNATSSink(NATSConfig{
+		URL:           NATSEmbedded,
+		Stream:        "GOFRETE",
+		SubjectPrefix: "gofrete",
+		Dir:           t.TempDir(),
+		Port:          server.RANDOM_PORT,
+	})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	require.NoError(t, sink.Publish(sampleEvent("a1", "USD", 1)))
+	require.NoError(t, sink.Publish(sampleEvent("a2", "EUR", 2)))
+	// Repetição do relay depois de uma falha ao marcar o evento como publicado
+	require.NoError(t, sink.Publish(sampleEvent("a1", "USD", 1)))
+
+	// Outro serviço, conectado pela porta do servidor embutido
+	nc, err := nats.Connect(sink.URL())
+	require.NoError(t, err)
+	defer nc.Close()
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := js.Stream(ctx, "GOFRETE")
+	require.NoError(t, err)
+	info, err := stream.Info(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), info.State.Msgs)
+
+	msg, err := stream.GetMsg(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "gofrete.conversion.created.USD", msg.Subject)
+	var event domain.DomainEvent
+	require.NoError(t, json.Unmarshal(msg.Data, &event))
+	assert.Equal(t, "a1", event.ID)
+}
@@ -0,0 +1,92 @@
+package infra
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go-frete/api/internal/domain"
+)
+
+// FileArchive grava as conversões de cada dia num NDJSON compactado com gzip
+// (conversions-2025-01-31.ndjson.gz), uma conversão por linha
+type FileArchive struct {
+	dir string
+}
+
+func NewFileArchive(dir string) (*FileArchive, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileArchive{dir: dir}, nil
+}
+
+// WriteDay implementa domain.ConversionArchive. O arquivo só aparece com o
+// nome final depois de completo; repetir o dia o substitui.
+func (a *FileArchive) WriteDay(day time.Time, records []domain.ConversionRecord) (string, error) {
+	path := filepath.Join(a.dir, fmt.Sprintf("conversions-%s.ndjson.gz", day.Format(time.DateOnly)))
+	tmp := path + ".tmp"
+
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return "", err
+	}
+	if err := writeNDJSONGzip(f, records); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return path, os.Rename(tmp, path)
+}
+
+func writeNDJSONGzip(f *os.File, records []domain.ConversionRecord) error {
+	gz := gzip.NewWriter(f)
+	enc := json.NewEncoder(gz)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return gz.Close()
+}
+
+// ReadArchive implementa domain.ConversionArchive
+func (a *FileArchive) ReadArchive(path string) ([]domain.ConversionRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var records []domain.ConversionRecord
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var r domain.ConversionRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("%s, linha %d: %w", path, line, err)
+		}
+		records = append(records, r)
+	}
+	return records, scanner.Err()
+}
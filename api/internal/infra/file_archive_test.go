@@ -0,0 +1,86 @@
+package infra
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-frete/api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileArchive(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should write and read back day archive",
+			run:  shouldWriteAndReadBackDayArchive,
+		},
+		{
+			name: "should replace archive when day is written again",
+			run:  shouldReplaceArchiveWhenDayIsWrittenAgain,
+		},
+		{
+			name: "should fail on corrupted archive",
+			run:  shouldFailOnCorruptedArchive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldWriteAndReadBackDayArchive(t *testing.T) {
+	archive, err := NewFileArchive(filepath.Join(t.TempDir(), "archive"))
+	require.NoError(t, err)
+	day := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	records := []domain.ConversionRecord{
+		{MoedaDestino: "USD", Cotacao: 5, ValorEntrada: 100, ValorConvertido: 20, Data: day.Add(10 * time.Hour), Fonte: "awesomeapi"},
+		{MoedaDestino: "EUR", Cotacao: 6, ValorEntrada: 60, ValorConvertido: 10, Data: day.Add(11 * time.Hour), APIKeyID: "k1"},
+	}
+
+	path, err := archive.WriteDay(day, records)
+	require.NoError(t, err)
+	assert.Equal(t, "conversions-2026-01-02.ndjson.gz", filepath.Base(path))
+	assert.NoFileExists(t, path+".tmp")
+
+	read, err := archive.ReadArchive(path)
+	require.NoError(t, err)
+	require.Len(t, read, 2)
+	assert.Equal(t, records[0].Fonte, read[0].Fonte)
+	assert.Equal(t, records[1].APIKeyID, read[1].APIKeyID)
+	assert.True(t, records[1].Data.Equal(read[1].Data))
+}
+
+func shouldReplaceArchiveWhenDayIsWrittenAgain(t *testing.T) {
+	archive, err := NewFileArchive(t.TempDir())
+	require.NoError(t, err)
+	day := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	_, err = archive.WriteDay(day, []domain.ConversionRecord{{MoedaDestino: "USD"}, {MoedaDestino: "EUR"}})
+	require.NoError(t, err)
+	path, err := archive.WriteDay(day, []domain.ConversionRecord{{MoedaDestino: "USD"}})
+	require.NoError(t, err)
+
+	read, err := archive.ReadArchive(path)
+	require.NoError(t, err)
+	assert.Len(t, read, 1)
+}
+
+func shouldFailOnCorruptedArchive(t *testing.T) {
+	dir := t.TempDir()
+	archive, err := NewFileArchive(dir)
+	require.NoError(t, err)
+	path := filepath.Join(dir, "conversions-2026-01-02.ndjson.gz")
+	require.NoError(t, os.WriteFile(path, []byte("não é gzip"), 0o644))
+
+	_, err = archive.ReadArchive(path)
+
+	assert.Error(t, err)
+}
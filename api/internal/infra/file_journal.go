@@ -0,0 +1,191 @@
+package infra
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go-frete/api/internal/domain"
+)
+
+// FileJournal guarda as conversões pendentes num arquivo local, uma por linha
+// em JSON. Cada Append só retorna depois do fsync.
+type FileJournal struct {
+	path string
+
+	mu    sync.Mutex
+	file  *os.File
+	count int
+}
+
+func NewFileJournal(path string) (*FileJournal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	j := &FileJournal{path: path}
+	if err := j.dropTornTail(); err != nil {
+		return nil, err
+	}
+	lines, err := j.readLines()
+	if err != nil {
+		return nil, err
+	}
+	j.count = len(lines)
+	if err := j.open(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (j *FileJournal) open() error {
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	j.file = f
+	return nil
+}
+
+// Append implementa domain.ConversionJournal
+func (j *FileJournal) Append(record domain.ConversionRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := j.file.Sync(); err != nil {
+		return err
+	}
+	j.count++
+	return nil
+}
+
+// Pending implementa domain.ConversionJournal
+func (j *FileJournal) Pending() ([]domain.ConversionRecord, error) {
+	j.mu.Lock()
+	lines, err := j.readLines()
+	j.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]domain.ConversionRecord, 0, len(lines))
+	for i, line := range lines {
+		var record domain.ConversionRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("diário %s, linha %d: %w", j.path, i+1, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Ack implementa domain.ConversionJournal: reescreve o arquivo sem os n
+// primeiros registros e troca o original de uma vez (rename)
+func (j *FileJournal) Ack(n int) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	lines, err := j.readLines()
+	if err != nil {
+		return err
+	}
+	if n > len(lines) {
+		n = len(lines)
+	}
+	rest := lines[n:]
+
+	tmp := j.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, line := range rest {
+		w.Write(line)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	j.file.Close()
+	if err := os.Rename(tmp, j.path); err != nil {
+		j.open()
+		return err
+	}
+	j.count = len(rest)
+	return j.open()
+}
+
+// Len implementa domain.ConversionJournal
+func (j *FileJournal) Len() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.count
+}
+
+// Close fecha o arquivo do diário
+func (j *FileJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// dropTornTail corta a última linha sem quebra, para que o próximo Append
+// não se junte a ela
+func (j *FileJournal) dropTornTail() error {
+	data, err := os.ReadFile(j.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 || data[len(data)-1] == '\n' {
+		return nil
+	}
+	return os.Truncate(j.path, int64(bytes.LastIndexByte(data, '\n')+1))
+}
+
+// readLines lê as linhas completas do arquivo. Uma última linha sem quebra é
+// escrita interrompida por queda do processo e fica de fora.
+func (j *FileJournal) readLines() ([][]byte, error) {
+	data, err := os.ReadFile(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lines [][]byte
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			return lines, nil
+		}
+		if line := bytes.TrimSpace(data[:i]); len(line) > 0 {
+			lines = append(lines, line)
+		}
+		data = data[i+1:]
+	}
+}
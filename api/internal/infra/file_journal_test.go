@@ -0,0 +1,96 @@
+package infra
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-frete/api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newJournalForTest(t *testing.T, path string) *FileJournal {
+	j, err := NewFileJournal(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { j.Close() })
+	return j
+}
+
+func TestFileJournal(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should keep pending records across restarts",
+			run:  shouldKeepPendingRecordsAcrossRestarts,
+		},
+		{
+			name: "should drop acknowledged records and keep appending",
+			run:  shouldDropAcknowledgedRecordsAndKeepAppending,
+		},
+		{
+			name: "should discard torn last line",
+			run:  shouldDiscardTornLastLine,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldKeepPendingRecordsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal", "conversions.ndjson")
+	first, err := NewFileJournal(path)
+	require.NoError(t, err)
+	require.NoError(t, first.Append(domain.ConversionRecord{MoedaDestino: "USD", Cotacao: 5}))
+	require.NoError(t, first.Append(domain.ConversionRecord{MoedaDestino: "EUR", Cotacao: 6}))
+	require.NoError(t, first.Close())
+
+	reopened := newJournalForTest(t, path)
+	pending, err := reopened.Pending()
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, reopened.Len())
+	if assert.Len(t, pending, 2) {
+		assert.Equal(t, "USD", pending[0].MoedaDestino)
+		assert.Equal(t, "EUR", pending[1].MoedaDestino)
+	}
+}
+
+func shouldDropAcknowledgedRecordsAndKeepAppending(t *testing.T) {
+	j := newJournalForTest(t, filepath.Join(t.TempDir(), "conversions.ndjson"))
+	for _, moeda := range []string{"USD", "EUR", "GBP"} {
+		require.NoError(t, j.Append(domain.ConversionRecord{MoedaDestino: moeda}))
+	}
+
+	require.NoError(t, j.Ack(2))
+	require.NoError(t, j.Append(domain.ConversionRecord{MoedaDestino: "JPY"}))
+	pending, err := j.Pending()
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, j.Len())
+	if assert.Len(t, pending, 2) {
+		assert.Equal(t, "GBP", pending[0].MoedaDestino)
+		assert.Equal(t, "JPY", pending[1].MoedaDestino)
+	}
+}
+
+func shouldDiscardTornLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conversions.ndjson")
+	// Queda do processo no meio da escrita da segunda linha
+	require.NoError(t, os.WriteFile(path, []byte(`{"currency":"USD","cotacao":5}`+"\n"+`{"currency":"EU`), 0o600))
+
+	j := newJournalForTest(t, path)
+	require.NoError(t, j.Append(domain.ConversionRecord{MoedaDestino: "GBP"}))
+	pending, err := j.Pending()
+
+	require.NoError(t, err)
+	if assert.Len(t, pending, 2) {
+		assert.Equal(t, "USD", pending[0].MoedaDestino)
+		assert.Equal(t, "GBP", pending[1].MoedaDestino)
+	}
+}
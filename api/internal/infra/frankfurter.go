@@ -0,0 +1,60 @@
+package infra
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go-frete/api/internal/domain"
+)
+
+const frankfurterURL = "https://api.frankfurter.dev/v1"
+
+// FrankfurterResponse é a resposta de /latest: taxas de referência diárias do BCE
+type FrankfurterResponse struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// FrankfurterAdapter consulta a Frankfurter, usada para conferir as cotações da AwesomeAPI
+type FrankfurterAdapter struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewFrankfurterAdapter() *FrankfurterAdapter {
+	return &FrankfurterAdapter{baseURL: frankfurterURL, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (a *FrankfurterAdapter) Source() string {
+	return "frankfurter"
+}
+
+// GetRate cumpre o contrato exigido pelo domain.RateProvider
+func (a *FrankfurterAdapter) GetRate(moeda string) (float64, error) {
+	resp, err := a.client.Get(fmt.Sprintf("%s/latest?base=%s&symbols=BRL", a.baseURL, moeda))
+	if err != nil {
+		return 0, errors.New("erro ao consultar cotação de conferência")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity {
+		return 0, domain.ErrCurrencyNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("erro ao consultar cotação de conferência: status %d", resp.StatusCode)
+	}
+
+	var body FrankfurterResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, errors.New("erro ao processar cotação de conferência")
+	}
+	cotacao, ok := body.Rates["BRL"]
+	if !ok {
+		return 0, domain.ErrCurrencyNotFound
+	}
+	return cotacao, nil
+}
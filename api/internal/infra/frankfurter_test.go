@@ -0,0 +1,52 @@
+package infra
+
+import (
+	"testing"
+
+	"go-frete/api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFrankfurterFixture(t *testing.T, routes map[string]string) *FrankfurterAdapter {
+	server := newFixtureServer(t, "frankfurter", routes)
+	return &FrankfurterAdapter{baseURL: server.URL, client: server.Client()}
+}
+
+func TestFrankfurterAdapter(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should read reference rate in brl",
+			run:  shouldReadReferenceRateInBRL,
+		},
+		{
+			name: "should map unknown currency to currency not found",
+			run:  shouldMapUnknownCurrencyToCurrencyNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldReadReferenceRateInBRL(t *testing.T) {
+	adapter := newFrankfurterFixture(t, map[string]string{"/latest?base=USD&symbols=BRL": "latest_usd_brl.json"})
+
+	cotacao, err := adapter.GetRate("USD")
+
+	require.NoError(t, err)
+	assert.Equal(t, 5.4035, cotacao)
+}
+
+func shouldMapUnknownCurrencyToCurrencyNotFound(t *testing.T) {
+	adapter := newFrankfurterFixture(t, map[string]string{"/latest?base=XYZ&symbols=BRL": "not_found.json"})
+
+	_, err := adapter.GetRate("XYZ")
+
+	assert.ErrorIs(t, err, domain.ErrCurrencyNotFound)
+}
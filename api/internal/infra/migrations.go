@@ -0,0 +1,290 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	schemaMigrations    = "schema_migrations"
+	schemaMigrationLock = "schema_migrations_lock"
+	// Trava esquecida por um processo que morreu no meio é assumida depois disso
+	migrationLockTTL = 10 * time.Minute
+)
+
+var (
+	ErrMigrationLocked  = errors.New("outra execução de migrations está em andamento")
+	ErrUnknownMigration = errors.New("versão de migration desconhecida")
+)
+
+// Migration é um passo versionado do esquema do banco. Down desfaz o Up e
+// pode ser nil quando o passo não tem volta.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+	Down        func(ctx context.Context, db *mongo.Database) error
+}
+
+// MigrationStatus diz se uma migration já foi aplicada e quando
+type MigrationStatus struct {
+	Version     int        `bson:"_id"`
+	Description string     `bson:"description"`
+	AppliedAt   *time.Time `bson:"applied_at,omitempty"`
+}
+
+func (s MigrationStatus) Applied() bool {
+	return s.AppliedAt != nil
+}
+
+// Migrator aplica e desfaz as migrations em ordem, registrando cada versão
+// aplicada na coleção schema_migrations
+type Migrator struct {
+	db         *mongo.Database
+	migrations []Migration
+	now        func() time.Time
+}
+
+// Migrator devolve o executor das migrations do esquema da API
+func (m *MongoDBAdapter) Migrator() *Migrator {
+	return newMigrator(m.database, schemaSteps)
+}
+
+func newMigrator(db *mongo.Database, migrations []Migration) *Migrator {
+	if err := checkMigrations(migrations); err != nil {
+		panic(err)
+	}
+	return &Migrator{db: db, migrations: migrations, now: time.Now}
+}
+
+// Latest é a versão da última migration conhecida por este binário
+func (mg *Migrator) Latest() int {
+	if len(mg.migrations) == 0 {
+		return 0
+	}
+	return mg.migrations[len(mg.migrations)-1].Version
+}
+
+// Status lista todas as migrations conhecidas, aplicadas ou não
+func (mg *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := mg.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]MigrationStatus, 0, len(mg.migrations))
+	for _, m := range mg.migrations {
+		status := MigrationStatus{Version: m.Version, Description: m.Description}
+		if s, ok := applied[m.Version]; ok {
+			status.AppliedAt = s.AppliedAt
+		}
+		out = append(out, status)
+	}
+	return out, nil
+}
+
+// Version é a maior versão aplicada no banco (0 se nenhuma)
+func (mg *Migrator) Version(ctx context.Context) (int, error) {
+	applied, err := mg.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	version := 0
+	for v := range applied {
+		version = max(version, v)
+	}
+	return version, nil
+}
+
+// Up aplica, em ordem, as migrations pendentes até target (0 = a última).
+// onStep é chamado antes de cada passo e pode ser nil.
+func (mg *Migrator) Up(ctx context.Context, target int, onStep func(Migration)) ([]Migration, error) {
+	if target == 0 {
+		target = mg.Latest()
+	}
+	if !mg.known(target) {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownMigration, target)
+	}
+
+	release, err := mg.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, err := mg.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range pendingMigrations(mg.migrations, applied, target) {
+		if onStep != nil {
+			onStep(m)
+		}
+		if err := m.Up(ctx, mg.db); err != nil {
+			return done, fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+		appliedAt := mg.now().UTC()
+		_, err := mg.db.Collection(schemaMigrations).InsertOne(ctx, MigrationStatus{
+			Version: m.Version, Description: m.Description, AppliedAt: &appliedAt,
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d aplicada mas não registrada: %w", m.Version, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// Down desfaz, da mais nova para a mais antiga, as migrations aplicadas
+// acima de target (0 = desfaz todas)
+func (mg *Migrator) Down(ctx context.Context, target int, onStep func(Migration)) ([]Migration, error) {
+	if target != 0 && !mg.known(target) {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownMigration, target)
+	}
+
+	release, err := mg.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, err := mg.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range rollbackMigrations(mg.migrations, applied, target) {
+		if m.Down == nil {
+			return done, fmt.Errorf("migration %d (%s) não pode ser desfeita", m.Version, m.Description)
+		}
+		if onStep != nil {
+			onStep(m)
+		}
+		if err := m.Down(ctx, mg.db); err != nil {
+			return done, fmt.Errorf("rollback da migration %d (%s): %w", m.Version, m.Description, err)
+		}
+		if _, err := mg.db.Collection(schemaMigrations).DeleteOne(ctx, bson.D{{Key: "_id", Value: m.Version}}); err != nil {
+			return done, fmt.Errorf("migration %d desfeita mas ainda registrada: %w", m.Version, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+func (mg *Migrator) applied(ctx context.Context) (map[int]MigrationStatus, error) {
+	cursor, err := mg.db.Collection(schemaMigrations).Find(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	var rows []MigrationStatus
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	out := make(map[int]MigrationStatus, len(rows))
+	for _, r := range rows {
+		out[r.Version] = r
+	}
+	return out, nil
+}
+
+func (mg *Migrator) known(version int) bool {
+	for _, m := range mg.migrations {
+		if m.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// lock impede que duas instâncias subindo juntas apliquem as mesmas migrations
+func (mg *Migrator) lock(ctx context.Context) (func(), error) {
+	locks := mg.db.Collection(schemaMigrationLock)
+	now := mg.now().UTC()
+
+	_, err := locks.InsertOne(ctx, bson.D{{Key: "_id", Value: "migrations"}, {Key: "locked_at", Value: now}})
+	if mongo.IsDuplicateKeyError(err) {
+		res, err := locks.UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: "migrations"}, {Key: "locked_at", Value: bson.D{{Key: "$lt", Value: now.Add(-migrationLockTTL)}}}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "locked_at", Value: now}}}},
+		)
+		if err != nil {
+			return nil, err
+		}
+		if res.ModifiedCount == 0 {
+			return nil, ErrMigrationLocked
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		locks.DeleteOne(ctx, bson.D{{Key: "_id", Value: "migrations"}})
+	}, nil
+}
+
+// checkMigrations exige versões positivas, únicas e em ordem crescente
+func checkMigrations(migrations []Migration) error {
+	for i, m := range migrations {
+		if m.Version <= 0 || m.Up == nil {
+			return fmt.Errorf("migration %d inválida: versão positiva e Up são obrigatórios", m.Version)
+		}
+		if i > 0 && m.Version <= migrations[i-1].Version {
+			return fmt.Errorf("migration %d fora de ordem (depois da %d)", m.Version, migrations[i-1].Version)
+		}
+	}
+	return nil
+}
+
+// pendingMigrations são as ainda não aplicadas até target, em ordem crescente
+func pendingMigrations(all []Migration, applied map[int]MigrationStatus, target int) []Migration {
+	var out []Migration
+	for _, m := range all {
+		if _, ok := applied[m.Version]; !ok && m.Version <= target {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// rollbackMigrations são as aplicadas acima de target, da mais nova para a mais antiga
+func rollbackMigrations(all []Migration, applied map[int]MigrationStatus, target int) []Migration {
+	var out []Migration
+	for _, m := range all {
+		if _, ok := applied[m.Version]; ok && m.Version > target {
+			out = append(out, m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version > out[j].Version })
+	return out
+}
+
+// createIndexes e dropIndexes servem de Up e Down para migrations só de índices
+func createIndexes(collection string, models ...mongo.IndexModel) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		_, err := db.Collection(collection).Indexes().CreateMany(ctx, models)
+		return err
+	}
+}
+
+func dropIndexes(collection string, names ...string) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		for _, name := range names {
+			_, err := db.Collection(collection).Indexes().DropOne(ctx, name)
+			// Índice já removido à mão não impede o rollback
+			if err != nil && commandCode(err) != codeIndexNotFound && commandCode(err) != codeNamespaceNotFound {
+				return err
+			}
+		}
+		return nil
+	}
+}
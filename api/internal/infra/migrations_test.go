@@ -0,0 +1,96 @@
+package infra
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func noop(context.Context, *mongo.Database) error { return nil }
+
+func steps(versions ...int) []Migration {
+	out := make([]Migration, 0, len(versions))
+	for _, v := range versions {
+		out = append(out, Migration{Version: v, Up: noop, Down: noop})
+	}
+	return out
+}
+
+func appliedVersions(versions ...int) map[int]MigrationStatus {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	out := map[int]MigrationStatus{}
+	for _, v := range versions {
+		out[v] = MigrationStatus{Version: v, AppliedAt: &at}
+	}
+	return out
+}
+
+func versionsOf(migrations []Migration) []int {
+	out := []int{}
+	for _, m := range migrations {
+		out = append(out, m.Version)
+	}
+	return out
+}
+
+func TestMigrations(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should plan pending migrations in order up to target",
+			run:  shouldPlanPendingMigrationsInOrderUpToTarget,
+		},
+		{
+			name: "should plan rollback from newest to oldest above target",
+			run:  shouldPlanRollbackFromNewestToOldestAboveTarget,
+		},
+		{
+			name: "should reject migrations out of order",
+			run:  shouldRejectMigrationsOutOfOrder,
+		},
+		{
+			name: "should keep schema steps valid",
+			run:  shouldKeepSchemaStepsValid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldPlanPendingMigrationsInOrderUpToTarget(t *testing.T) {
+	all := steps(1, 2, 3, 4)
+
+	assert.Equal(t, []int{2, 4}, versionsOf(pendingMigrations(all, appliedVersions(1, 3), 4)))
+	assert.Equal(t, []int{2}, versionsOf(pendingMigrations(all, appliedVersions(1, 3), 2)))
+	assert.Equal(t, []int{}, versionsOf(pendingMigrations(all, appliedVersions(1, 2, 3, 4), 4)))
+}
+
+func shouldPlanRollbackFromNewestToOldestAboveTarget(t *testing.T) {
+	all := steps(1, 2, 3, 4)
+
+	assert.Equal(t, []int{4, 3, 2}, versionsOf(rollbackMigrations(all, appliedVersions(1, 2, 3, 4), 1)))
+	assert.Equal(t, []int{3, 1}, versionsOf(rollbackMigrations(all, appliedVersions(1, 3), 0)))
+}
+
+func shouldRejectMigrationsOutOfOrder(t *testing.T) {
+	assert.NoError(t, checkMigrations(steps(1, 2, 5)))
+	assert.Error(t, checkMigrations(steps(1, 3, 2)))
+	assert.Error(t, checkMigrations(steps(1, 1)))
+	assert.Error(t, checkMigrations(steps(0)))
+	assert.Error(t, checkMigrations([]Migration{{Version: 1}}))
+}
+
+func shouldKeepSchemaStepsValid(t *testing.T) {
+	assert.NoError(t, checkMigrations(schemaSteps))
+	for _, m := range schemaSteps {
+		assert.NotEmpty(t, m.Description, m.Version)
+		assert.NotNil(t, m.Down, "migration %d sem rollback", m.Version)
+	}
+}
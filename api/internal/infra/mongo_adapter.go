@@ -2,6 +2,9 @@ package infra
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strings"
 	"time"
 
 	"go-frete/api/internal/domain"
@@ -11,14 +14,36 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-const conversionHistory = "conversion_history"
+const (
+	conversionHistory = "conversion_history"
+	rateSnapshots     = "rate_snapshots"
+)
+
+// Códigos de erro do servidor tratados na criação de coleções, nas migrations
+// e nas gravações idempotentes
+const (
+	codeNamespaceNotFound = 26
+	codeIndexNotFound     = 27
+	codeNamespaceExists   = 48
+	codeInvalidOptions    = 72
+	codeDuplicateKey      = 11000
+	codeUnknownField      = 40415
+)
+
+// Índices únicos do histórico cuja duplicata é a mesma conversão gravada de
+// novo: o _id gerado no domínio e a chave das conversões importadas
+const (
+	idIndex        = "_id_"
+	importKeyIndex = "chave_importacao_unique"
+)
 
 type MongoDBAdapter struct {
 	client   *mongo.Client
 	database *mongo.Database
 }
 
-// NewMongoDBAdapter conecta no banco e retorna o adapter
+// NewMongoDBAdapter conecta no banco e retorna o adapter. Coleções e índices
+// ficam nas migrations (schemaSteps), não na conexão
 func NewMongoDBAdapter(uri, dbName string) (*MongoDBAdapter, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -38,16 +63,115 @@ func NewMongoDBAdapter(uri, dbName string) (*MongoDBAdapter, error) {
 	}, nil
 }
 
+// createRateSnapshots cria a coleção de cotações como time-series (MongoDB 5+).
+// Em servidores sem suporte cai para uma coleção comum indexada por moeda e data.
+func createRateSnapshots(ctx context.Context, db *mongo.Database) error {
+	ts := options.TimeSeries().SetTimeField("data").SetMetaField("moeda").SetGranularity("minutes")
+	err := db.CreateCollection(ctx, rateSnapshots, options.CreateCollection().SetTimeSeriesOptions(ts))
+
+	switch commandCode(err) {
+	case 0:
+		// Criada agora, ou falha que não veio do servidor
+		return err
+	case codeNamespaceExists:
+		return nil
+	case codeInvalidOptions, codeUnknownField:
+		// Servidor sem time-series: segue com uma coleção comum
+	default:
+		return err
+	}
+
+	if err := db.CreateCollection(ctx, rateSnapshots); err != nil && commandCode(err) != codeNamespaceExists {
+		return err
+	}
+	_, err = db.Collection(rateSnapshots).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "moeda", Value: 1}, {Key: "data", Value: 1}},
+	})
+	return err
+}
+
+// commandCode devolve o código do erro de comando do servidor (0 se não houver)
+func commandCode(err error) int32 {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Code
+	}
+	return 0
+}
+
 // SaveHistory implementa a interface domain.ConversionSaver
 func (m *MongoDBAdapter) SaveHistory(record domain.ConversionRecord) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// Insere a struct que será traduzida para BSON (formato do Mongo)
+	// Insere a struct que será traduzida para BSON (formato do Mongo). O _id vem
+	// do domínio: duplicata no _id (ou na chave de importação) é a mesma
+	// conversão gravada de novo.
 	_, err := m.database.Collection(conversionHistory).InsertOne(ctx, record)
+	if onlyDuplicatesOn(err, idIndex, importKeyIndex) {
+		return nil
+	}
 	return err
 }
 
+// SaveHistoryBatch implementa a interface domain.ConversionBatchSaver com um
+// único InsertMany não ordenado: numa regravação, os registros que já estavam
+// no banco falham por chave duplicada sem impedir a gravação dos outros
+func (m *MongoDBAdapter) SaveHistoryBatch(records []domain.ConversionRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	docs := make([]any, len(records))
+	for i, record := range records {
+		docs[i] = record
+	}
+	_, err := m.database.Collection(conversionHistory).InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if onlyDuplicatesOn(err, idIndex, importKeyIndex) {
+		return nil
+	}
+	return err
+}
+
+// onlyDuplicatesOn diz se todas as falhas de err são de chave duplicada num
+// dos índices informados. mongo.IsDuplicateKeyError não serve: basta uma
+// falha para ele devolver true, e ele não diz de qual índice ela veio.
+func onlyDuplicatesOn(err error, indexes ...string) bool {
+	var failures []mongo.WriteError
+	var we mongo.WriteException
+	var bwe mongo.BulkWriteException
+	switch {
+	case errors.As(err, &we) && we.WriteConcernError == nil:
+		failures = we.WriteErrors
+	case errors.As(err, &bwe) && bwe.WriteConcernError == nil:
+		for _, e := range bwe.WriteErrors {
+			failures = append(failures, e.WriteError)
+		}
+	}
+	if len(failures) == 0 {
+		return false
+	}
+	for _, f := range failures {
+		if f.Code != codeDuplicateKey || !slices.Contains(indexes, duplicateIndex(f.Message)) {
+			return false
+		}
+	}
+	return true
+}
+
+// duplicateIndex tira o nome do índice da mensagem do servidor, no formato
+// "E11000 duplicate key error collection: db.c index: nome dup key: {...}"
+func duplicateIndex(message string) string {
+	_, rest, ok := strings.Cut(message, " index: ")
+	if !ok {
+		return ""
+	}
+	name, _, _ := strings.Cut(rest, " ")
+	return name
+}
+
 func (m *MongoDBAdapter) GetLastConversions(limit int) ([]domain.ConversionRecord, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -71,18 +195,55 @@ func (m *MongoDBAdapter) GetLastConversions(limit int) ([]domain.ConversionRecor
 	return results, nil
 }
 
-// GetConversionsByCurrency busca as conversões de uma moeda ordenadas da mais antiga para a mais nova
-func (m *MongoDBAdapter) GetConversionsByCurrency(currency string) ([]domain.ConversionRecord, error) {
+// SaveSnapshots implementa a interface domain.RateSnapshotSaver
+func (m *MongoDBAdapter) SaveSnapshots(snapshots []domain.RateSnapshot) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// Filtro: Onde a chave "currency" seja igual a moeda pedida
-	filter := bson.D{{Key: "currency", Value: currency}}
+	docs := make([]any, 0, len(snapshots))
+	for _, s := range snapshots {
+		docs = append(docs, s)
+	}
+	_, err := m.database.Collection(rateSnapshots).InsertMany(ctx, docs)
+	return err
+}
+
+// GetRateSeries busca as cotações coletadas de uma moeda no período, da mais antiga para a mais nova
+func (m *MongoDBAdapter) GetRateSeries(moeda string, from, to time.Time) ([]domain.RateSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	// Ordenação: data crescente (1)
+	filter := bson.D{
+		{Key: "moeda", Value: moeda},
+		{Key: "data", Value: bson.D{{Key: "$gte", Value: from}, {Key: "$lte", Value: to}}},
+	}
 	opts := options.Find().SetSort(bson.D{{Key: "data", Value: 1}})
 
-	cursor, err := m.database.Collection(conversionHistory).Find(ctx, filter, opts)
+	cursor, err := m.database.Collection(rateSnapshots).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []domain.RateSnapshot{}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// FindConversions busca uma página do histórico, da conversão mais nova para a mais antiga
+func (m *MongoDBAdapter) FindConversions(f domain.ConversionFilter) ([]domain.ConversionRecord, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "data", Value: -1}}).
+		SetSkip(int64(f.Offset)).
+		SetLimit(int64(f.Limit))
+
+	cursor, err := m.database.Collection(conversionHistory).Find(ctx, conversionFilter(f), opts)
 	if err != nil {
 		return nil, err
 	}
@@ -95,3 +256,58 @@ func (m *MongoDBAdapter) GetConversionsByCurrency(currency string) ([]domain.Con
 
 	return results, nil
 }
+
+// StreamConversions implementa a interface domain.ConversionStreamer. Sem o
+// timeout das outras consultas: uma exportação grande leva o tempo que o
+// cliente levar para ler, e termina quando fn devolve erro ou quando ctx é
+// cancelado (o cliente desconectou), fechando o cursor no servidor.
+func (m *MongoDBAdapter) StreamConversions(ctx context.Context, f domain.ConversionFilter, fn func(domain.ConversionRecord) error) error {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "data", Value: 1}}).
+		SetLimit(int64(f.Limit)).
+		SetBatchSize(1000)
+
+	cursor, err := m.database.Collection(conversionHistory).Find(ctx, conversionFilter(f), opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var record domain.ConversionRecord
+		if err := cursor.Decode(&record); err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// conversionFilter traduz o filtro do histórico para a consulta no Mongo
+func conversionFilter(f domain.ConversionFilter) bson.D {
+	filter := bson.D{}
+	if f.Currency != "" {
+		filter = append(filter, bson.E{Key: "currency", Value: f.Currency})
+	}
+	period := bson.D{}
+	if !f.From.IsZero() {
+		period = append(period, bson.E{Key: "$gte", Value: f.From})
+	}
+	if !f.To.IsZero() {
+		period = append(period, bson.E{Key: "$lte", Value: f.To})
+	}
+	if len(period) > 0 {
+		filter = append(filter, bson.E{Key: "data", Value: period})
+	}
+	if f.Retroativa != nil {
+		// Registros antigos não têm o campo: contam como conversões do dia
+		if *f.Retroativa {
+			filter = append(filter, bson.E{Key: "retroativa", Value: true})
+		} else {
+			filter = append(filter, bson.E{Key: "retroativa", Value: bson.D{{Key: "$ne", Value: true}}})
+		}
+	}
+	return filter
+}
@@ -0,0 +1,82 @@
+package infra
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestOnlyDuplicatesOn(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should accept batch failing only on duplicate keys of given indexes",
+			run:  shouldAcceptBatchFailingOnlyOnDuplicateKeysOfGivenIndexes,
+		},
+		{
+			name: "should reject duplicate keys of other indexes",
+			run:  shouldRejectDuplicateKeysOfOtherIndexes,
+		},
+		{
+			name: "should reject batch with any other failure",
+			run:  shouldRejectBatchWithAnyOtherFailure,
+		},
+		{
+			name: "should reject errors without write failures",
+			run:  shouldRejectErrorsWithoutWriteFailures,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+// duplicateOn monta a falha que o servidor devolve para uma chave repetida no índice
+func duplicateOn(index string) mongo.WriteError {
+	return mongo.WriteError{
+		Code:    codeDuplicateKey,
+		Message: "E11000 duplicate key error collection: frete.conversion_history index: " + index + ` dup key: { _id: "abc" }`,
+	}
+}
+
+func bulkWriteErrors(failures ...mongo.WriteError) mongo.BulkWriteException {
+	var bwe mongo.BulkWriteException
+	for i, f := range failures {
+		f.Index = i
+		bwe.WriteErrors = append(bwe.WriteErrors, mongo.BulkWriteError{WriteError: f})
+	}
+	return bwe
+}
+
+func shouldAcceptBatchFailingOnlyOnDuplicateKeysOfGivenIndexes(t *testing.T) {
+	assert.True(t, onlyDuplicatesOn(bulkWriteErrors(duplicateOn(idIndex), duplicateOn(importKeyIndex)), idIndex, importKeyIndex))
+	assert.True(t, onlyDuplicatesOn(fmt.Errorf("lote: %w", bulkWriteErrors(duplicateOn(idIndex))), idIndex))
+	assert.True(t, onlyDuplicatesOn(mongo.WriteException{WriteErrors: mongo.WriteErrors{duplicateOn(idIndex)}}, idIndex))
+}
+
+func shouldRejectDuplicateKeysOfOtherIndexes(t *testing.T) {
+	// Uma conversão da API que repete outra não pode sumir como se já estivesse gravada
+	assert.False(t, onlyDuplicatesOn(bulkWriteErrors(duplicateOn(idIndex), duplicateOn("outro_unique")), idIndex, importKeyIndex))
+	assert.False(t, onlyDuplicatesOn(mongo.WriteException{WriteErrors: mongo.WriteErrors{duplicateOn(importKeyIndex)}}, idIndex))
+	assert.False(t, onlyDuplicatesOn(mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: codeDuplicateKey}}}, idIndex))
+}
+
+func shouldRejectBatchWithAnyOtherFailure(t *testing.T) {
+	assert.False(t, onlyDuplicatesOn(bulkWriteErrors(duplicateOn(idIndex), mongo.WriteError{Code: 121}), idIndex))
+
+	withConcern := bulkWriteErrors(duplicateOn(idIndex))
+	withConcern.WriteConcernError = &mongo.WriteConcernError{Code: 64}
+	assert.False(t, onlyDuplicatesOn(withConcern, idIndex))
+}
+
+func shouldRejectErrorsWithoutWriteFailures(t *testing.T) {
+	assert.False(t, onlyDuplicatesOn(nil, idIndex))
+	assert.False(t, onlyDuplicatesOn(errors.New("server selection timeout"), idIndex))
+	assert.False(t, onlyDuplicatesOn(mongo.BulkWriteException{}, idIndex))
+}
@@ -0,0 +1,160 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-frete/api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	alertRules    = "alert_rules"
+	alertTriggers = "alert_triggers"
+)
+
+// SaveAlertRule implementa a interface domain.AlertRepository
+func (m *MongoDBAdapter) SaveAlertRule(rule domain.AlertRule) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := m.database.Collection(alertRules).ReplaceOne(ctx, bson.D{{Key: "_id", Value: rule.ID}}, rule, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (m *MongoDBAdapter) GetAlertRule(id string) (*domain.AlertRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var rule domain.AlertRule
+	err := m.database.Collection(alertRules).FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&rule)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrAlertNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (m *MongoDBAdapter) ListAlertRules(apiKeyID string) ([]domain.AlertRule, error) {
+	filter := bson.D{}
+	if apiKeyID != "" {
+		filter = bson.D{{Key: "api_key_id", Value: apiKeyID}}
+	}
+	return m.findAlertRules(filter)
+}
+
+// ActiveAlertRules usa o índice de moeda e ativa, consultado a cada cotação nova
+func (m *MongoDBAdapter) ActiveAlertRules(moeda string) ([]domain.AlertRule, error) {
+	return m.findAlertRules(bson.D{{Key: "moeda", Value: moeda}, {Key: "ativa", Value: true}})
+}
+
+func (m *MongoDBAdapter) findAlertRules(filter bson.D) ([]domain.AlertRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := m.database.Collection(alertRules).Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "criada_em", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.AlertRule
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (m *MongoDBAdapter) DeleteAlertRule(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := m.database.Collection(alertRules).DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrAlertNotFound
+	}
+	return nil
+}
+
+// TryTriggerAlert faz a checagem e a marcação numa única atualização: entre
+// instâncias que avaliam a mesma cotação, só a primeira altera o documento
+func (m *MongoDBAdapter) TryTriggerAlert(id string, at time.Time, cooldown time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "_id", Value: id},
+		{Key: "disparada", Value: bson.D{{Key: "$ne", Value: true}}},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "ultimo_disparo", Value: bson.D{{Key: "$exists", Value: false}}}},
+			bson.D{{Key: "ultimo_disparo", Value: bson.D{{Key: "$lte", Value: at.Add(-cooldown)}}}},
+		}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "disparada", Value: true},
+		{Key: "ultimo_disparo", Value: at},
+	}}}
+	res, err := m.database.Collection(alertRules).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (m *MongoDBAdapter) RearmAlert(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := m.database.Collection(alertRules).UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: id}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "disparada", Value: false}}}},
+	)
+	return err
+}
+
+// SaveAlertTrigger implementa a interface domain.AlertTriggerStore
+func (m *MongoDBAdapter) SaveAlertTrigger(t domain.AlertTrigger) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := m.database.Collection(alertTriggers).InsertOne(ctx, t)
+	return err
+}
+
+func (m *MongoDBAdapter) ListAlertTriggers(filter domain.AlertTriggerFilter) ([]domain.AlertTrigger, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.D{}
+	if filter.AlertaID != "" {
+		query = append(query, bson.E{Key: "alerta_id", Value: filter.AlertaID})
+	}
+	if filter.APIKeyID != "" {
+		query = append(query, bson.E{Key: "api_key_id", Value: filter.APIKeyID})
+	}
+	if filter.Moeda != "" {
+		query = append(query, bson.E{Key: "moeda", Value: filter.Moeda})
+	}
+	cursor, err := m.database.Collection(alertTriggers).Find(ctx, query,
+		options.Find().SetSort(bson.D{{Key: "disparado_em", Value: -1}}).SetLimit(int64(filter.Limit)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.AlertTrigger
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
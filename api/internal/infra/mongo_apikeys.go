@@ -0,0 +1,84 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-frete/api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const apiKeys = "api_keys"
+
+// SaveAPIKey implementa a interface domain.APIKeyRepository
+func (m *MongoDBAdapter) SaveAPIKey(key domain.APIKey) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := m.database.Collection(apiKeys).InsertOne(ctx, key)
+	return err
+}
+
+func (m *MongoDBAdapter) GetAPIKeyByHash(hash string) (*domain.APIKey, error) {
+	return m.findAPIKey(bson.D{{Key: "key_hash", Value: hash}})
+}
+
+func (m *MongoDBAdapter) GetAPIKeyByID(id string) (*domain.APIKey, error) {
+	return m.findAPIKey(bson.D{{Key: "_id", Value: id}})
+}
+
+func (m *MongoDBAdapter) findAPIKey(filter bson.D) (*domain.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var key domain.APIKey
+	err := m.database.Collection(apiKeys).FindOne(ctx, filter).Decode(&key)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (m *MongoDBAdapter) ListAPIKeys() ([]domain.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := m.database.Collection(apiKeys).Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.APIKey
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *MongoDBAdapter) RevokeAPIKey(id string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{{Key: "_id", Value: id}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "revoked_at", Value: at}}}}
+
+	res, err := m.database.Collection(apiKeys).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
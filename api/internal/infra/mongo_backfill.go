@@ -0,0 +1,41 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-frete/api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const backfillCoverage = "backfill_coverage"
+
+// GetBackfillCoverage implementa a interface domain.BackfillRepository
+func (m *MongoDBAdapter) GetBackfillCoverage(moeda string) (*domain.BackfillCoverage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var coverage domain.BackfillCoverage
+	err := m.database.Collection(backfillCoverage).FindOne(ctx, bson.D{{Key: "_id", Value: moeda}}).Decode(&coverage)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &coverage, nil
+}
+
+// SaveBackfillCoverage grava (ou substitui) a cobertura da moeda
+func (m *MongoDBAdapter) SaveBackfillCoverage(coverage domain.BackfillCoverage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := m.database.Collection(backfillCoverage).ReplaceOne(ctx,
+		bson.D{{Key: "_id", Value: coverage.Moeda}}, coverage, options.Replace().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,56 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"go-frete/api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ExistingConversionKeys implementa a interface domain.ConversionKeyReader
+func (m *MongoDBAdapter) ExistingConversionKeys(keys []domain.ConversionKey) ([]domain.ConversionKey, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Cada chave vira um ramo do $or; o índice currency_data_desc atende todos.
+	// A busca vai no histórico inteiro: uma conversão feita pela API e
+	// reimportada de uma exportação também é repetida
+	or := make(bson.A, len(keys))
+	for i, k := range keys {
+		or[i] = bson.D{
+			{Key: "currency", Value: k.Moeda},
+			{Key: "data", Value: time.UnixMilli(k.Data).UTC()},
+			{Key: "valor_entrada", Value: k.ValorEntrada},
+			{Key: "cotacao", Value: k.Cotacao},
+		}
+	}
+	cursor, err := m.database.Collection(conversionHistory).Find(ctx,
+		bson.D{{Key: "$or", Value: or}},
+		options.Find().SetProjection(bson.D{
+			{Key: "currency", Value: 1},
+			{Key: "data", Value: 1},
+			{Key: "valor_entrada", Value: 1},
+			{Key: "cotacao", Value: 1},
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []domain.ConversionRecord
+	if err = cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	existing := make([]domain.ConversionKey, len(records))
+	for i, r := range records {
+		existing[i] = domain.KeyOf(r)
+	}
+	return existing, nil
+}
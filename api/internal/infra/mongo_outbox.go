@@ -0,0 +1,228 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"go-frete/api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	outboxEvents = "outbox_events"
+	counters     = "counters"
+)
+
+// outboxDocument é o evento como fica no banco. Os dados vão como texto JSON,
+// do jeito que serão publicados.
+type outboxDocument struct {
+	ID               string     `bson:"_id"`
+	Tipo             string     `bson:"tipo"`
+	Chave            string     `bson:"chave"`
+	Sequencia        int64      `bson:"sequencia"`
+	CriadoEm         time.Time  `bson:"criado_em"`
+	Dados            string     `bson:"dados"`
+	PublicadoEm      *time.Time `bson:"publicado_em,omitempty"`
+	Tentativas       int        `bson:"tentativas,omitempty"`
+	ProximaTentativa *time.Time `bson:"proxima_tentativa,omitempty"`
+	UltimoErro       string     `bson:"ultimo_erro,omitempty"`
+}
+
+// SupportsTransactions diz se o servidor aceita transações (replica set ou
+// cluster shardeado), exigidas pelo outbox
+func (m *MongoDBAdapter) SupportsTransactions() (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := m.database.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false, err
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid", nil
+}
+
+// SaveHistoryWithEvents implementa a interface domain.ConversionOutbox. O
+// contador da sequência é incrementado dentro da transação: duas gravações
+// concorrentes conflitam nele e a segunda é refeita depois do commit da
+// primeira, então a ordem das sequências é a ordem dos commits.
+func (m *MongoDBAdapter) SaveHistoryWithEvents(records []domain.ConversionRecord, events []domain.DomainEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	session, err := m.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (any, error) {
+		// Regravação de um lote já confirmado: os registros que existem ficam
+		// de fora junto com seus eventos, que também já foram gravados
+		records, events, err := m.withoutSavedConversions(sc, records, events)
+		if err != nil || len(records) == 0 {
+			return nil, err
+		}
+
+		var counter struct {
+			Valor int64 `bson:"valor"`
+		}
+		err = m.database.Collection(counters).FindOneAndUpdate(sc,
+			bson.D{{Key: "_id", Value: outboxEvents}},
+			bson.D{{Key: "$inc", Value: bson.D{{Key: "valor", Value: int64(len(events))}}}},
+			options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+		).Decode(&counter)
+		if err != nil {
+			return nil, err
+		}
+
+		history := make([]any, len(records))
+		for i, record := range records {
+			history[i] = record
+		}
+		if _, err := m.database.Collection(conversionHistory).InsertMany(sc, history); err != nil {
+			return nil, err
+		}
+
+		first := counter.Valor - int64(len(events)) + 1
+		docs := make([]any, len(events))
+		for i, e := range events {
+			docs[i] = outboxDocument{
+				ID:        e.ID,
+				Tipo:      e.Tipo,
+				Chave:     e.Chave,
+				Sequencia: first + int64(i),
+				CriadoEm:  e.CriadoEm,
+				Dados:     string(e.Dados),
+			}
+		}
+		_, err = m.database.Collection(outboxEvents).InsertMany(sc, docs)
+		return nil, err
+	})
+	// Uma duplicata pela chave de importação desfaz a transação do lote
+	// inteiro, e o ResilientSaver regrava um registro por vez; sozinho, ele já
+	// está no histórico
+	if len(records) == 1 && onlyDuplicatesOn(err, idIndex, importKeyIndex) {
+		return nil
+	}
+	return err
+}
+
+// withoutSavedConversions tira de records, e de events na mesma posição, as
+// conversões cujo _id já está no histórico
+func (m *MongoDBAdapter) withoutSavedConversions(ctx context.Context, records []domain.ConversionRecord, events []domain.DomainEvent) ([]domain.ConversionRecord, []domain.DomainEvent, error) {
+	ids := make([]string, 0, len(records))
+	for _, record := range records {
+		if record.ID != "" {
+			ids = append(ids, record.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return records, events, nil
+	}
+
+	cursor, err := m.database.Collection(conversionHistory).Find(ctx,
+		bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}},
+		options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	var saved []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &saved); err != nil {
+		return nil, nil, err
+	}
+	if len(saved) == 0 {
+		return records, events, nil
+	}
+
+	skip := make(map[string]bool, len(saved))
+	for _, s := range saved {
+		skip[s.ID] = true
+	}
+	keptRecords := make([]domain.ConversionRecord, 0, len(records))
+	keptEvents := make([]domain.DomainEvent, 0, len(events))
+	for i, record := range records {
+		if record.ID != "" && skip[record.ID] {
+			continue
+		}
+		keptRecords = append(keptRecords, record)
+		keptEvents = append(keptEvents, events[i])
+	}
+	return keptRecords, keptEvents, nil
+}
+
+// PendingEvents implementa a interface domain.OutboxStore
+func (m *MongoDBAdapter) PendingEvents(limit int, skipKeys []string) ([]domain.OutboxEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// publicado_em nulo também casa com o campo ausente e usa o índice pendentes
+	filter := bson.D{{Key: "publicado_em", Value: nil}}
+	if len(skipKeys) > 0 {
+		filter = append(filter, bson.E{Key: "chave", Value: bson.D{{Key: "$nin", Value: skipKeys}}})
+	}
+	cursor, err := m.database.Collection(outboxEvents).Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "sequencia", Value: 1}}).SetLimit(int64(limit)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []outboxDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	entries := make([]domain.OutboxEntry, len(docs))
+	for i, d := range docs {
+		entries[i] = domain.OutboxEntry{
+			Event: domain.DomainEvent{
+				ID:        d.ID,
+				Tipo:      d.Tipo,
+				Chave:     d.Chave,
+				Sequencia: d.Sequencia,
+				CriadoEm:  d.CriadoEm,
+				Dados:     []byte(d.Dados),
+			},
+			Tentativas: d.Tentativas,
+		}
+		if d.ProximaTentativa != nil {
+			entries[i].ProximaTentativa = *d.ProximaTentativa
+		}
+	}
+	return entries, nil
+}
+
+// MarkPublished implementa a interface domain.OutboxStore. O índice TTL apaga
+// os eventos publicados depois de uma semana.
+func (m *MongoDBAdapter) MarkPublished(ids []string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := m.database.Collection(outboxEvents).UpdateMany(ctx,
+		bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "publicado_em", Value: at}}}},
+	)
+	return err
+}
+
+// MarkFailed implementa a interface domain.OutboxStore
+func (m *MongoDBAdapter) MarkFailed(id string, tentativas int, motivo string, retryAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := m.database.Collection(outboxEvents).UpdateByID(ctx, id, bson.D{{Key: "$set", Value: bson.D{
+		{Key: "tentativas", Value: tentativas},
+		{Key: "ultimo_erro", Value: motivo},
+		{Key: "proxima_tentativa", Value: retryAt},
+	}}})
+	return err
+}
@@ -0,0 +1,19 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"go-frete/api/internal/domain"
+)
+
+const rateQuarantine = "rate_quarantine"
+
+// QuarantineRate implementa a interface domain.RateQuarantine
+func (m *MongoDBAdapter) QuarantineRate(rate domain.QuarantinedRate) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := m.database.Collection(rateQuarantine).InsertOne(ctx, rate)
+	return err
+}
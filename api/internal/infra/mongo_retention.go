@@ -0,0 +1,208 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-frete/api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	conversionDaily = "conversion_daily"
+	retentionState  = "retention_state"
+)
+
+type retentionStateDoc struct {
+	ArquivadoAte time.Time `bson:"arquivado_ate"`
+}
+
+// FirstConversionSince implementa a interface domain.ConversionRetentionRepository
+func (m *MongoDBAdapter) FirstConversionSince(t time.Time) (*time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var record domain.ConversionRecord
+	err := m.database.Collection(conversionHistory).FindOne(ctx,
+		bson.D{{Key: "data", Value: bson.D{{Key: "$gte", Value: t}}}},
+		options.FindOne().SetSort(bson.D{{Key: "data", Value: 1}}).SetProjection(bson.D{{Key: "data", Value: 1}}),
+	).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record.Data, nil
+}
+
+func (m *MongoDBAdapter) ConversionsBetween(from, to time.Time) ([]domain.ConversionRecord, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := m.database.Collection(conversionHistory).Find(ctx,
+		periodFilter("data", from, to),
+		options.Find().SetSort(bson.D{{Key: "data", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []domain.ConversionRecord{}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// SaveDailyAggregates grava os resumos com _id moeda:dia, então repetir o dia só os substitui
+func (m *MongoDBAdapter) SaveDailyAggregates(aggregates []domain.ConversionDailyAggregate) error {
+	if len(aggregates) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	models := make([]mongo.WriteModel, 0, len(aggregates))
+	for _, a := range aggregates {
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.D{{Key: "_id", Value: a.Moeda + ":" + a.Dia.Format(time.DateOnly)}}).
+			SetReplacement(a).
+			SetUpsert(true))
+	}
+	_, err := m.database.Collection(conversionDaily).BulkWrite(ctx, models)
+	return err
+}
+
+func (m *MongoDBAdapter) MarkArchived(from, to, archivedAt time.Time, expireAt *time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	set := bson.D{{Key: "arquivada_em", Value: archivedAt}}
+	if expireAt != nil {
+		// Apagado pelo índice TTL de expira_em (migration 5)
+		set = append(set, bson.E{Key: "expira_em", Value: *expireAt})
+	}
+	_, err := m.database.Collection(conversionHistory).UpdateMany(ctx,
+		periodFilter("data", from, to),
+		bson.D{{Key: "$set", Value: set}},
+	)
+	return err
+}
+
+func (m *MongoDBAdapter) ReplaceArchivedConversions(from, to time.Time, records []domain.ConversionRecord) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := append(periodFilter("data", from, to), bson.E{Key: "arquivada_em", Value: bson.D{{Key: "$exists", Value: true}}})
+	if _, err := m.database.Collection(conversionHistory).DeleteMany(ctx, filter); err != nil {
+		return err
+	}
+	docs := make([]any, len(records))
+	for i, record := range records {
+		docs[i] = record
+	}
+	_, err := m.database.Collection(conversionHistory).InsertMany(ctx, docs)
+	return err
+}
+
+// RetentionWatermark devolve o primeiro dia ainda não arquivado (zero se nunca houve arquivamento)
+func (m *MongoDBAdapter) RetentionWatermark() (time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var doc retentionStateDoc
+	err := m.database.Collection(retentionState).FindOne(ctx, bson.D{{Key: "_id", Value: conversionHistory}}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return doc.ArquivadoAte, nil
+}
+
+func (m *MongoDBAdapter) SaveRetentionWatermark(t time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := m.database.Collection(retentionState).ReplaceOne(ctx,
+		bson.D{{Key: "_id", Value: conversionHistory}},
+		retentionStateDoc{ArquivadoAte: t},
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// DailyAggregates implementa a interface domain.ConversionAggregateReader
+func (m *MongoDBAdapter) DailyAggregates(moeda string, from, to time.Time) ([]domain.ConversionDailyAggregate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := periodFilter("dia", from, to.Add(time.Nanosecond))
+	if moeda != "" {
+		filter = append(filter, bson.E{Key: "moeda", Value: moeda})
+	}
+	cursor, err := m.database.Collection(conversionDaily).Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "dia", Value: 1}, {Key: "moeda", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []domain.ConversionDailyAggregate{}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// AggregateConversions resume por dia (UTC) e moeda as conversões que estão no banco
+func (m *MongoDBAdapter) AggregateConversions(moeda string, from, to time.Time) ([]domain.ConversionDailyAggregate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match := periodFilter("data", from, to.Add(time.Nanosecond))
+	if moeda != "" {
+		match = append(match, bson.E{Key: "currency", Value: moeda})
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "dia", Value: bson.D{{Key: "$dateTrunc", Value: bson.D{{Key: "date", Value: "$data"}, {Key: "unit", Value: "day"}}}}},
+				{Key: "moeda", Value: "$currency"},
+			}},
+			{Key: "conversoes", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "valor_entrada", Value: bson.D{{Key: "$sum", Value: "$valor_entrada"}}},
+			{Key: "valor_convertido", Value: bson.D{{Key: "$sum", Value: "$valor_convertido"}}},
+			{Key: "cotacao_minima", Value: bson.D{{Key: "$min", Value: "$cotacao"}}},
+			{Key: "cotacao_maxima", Value: bson.D{{Key: "$max", Value: "$cotacao"}}},
+			{Key: "cotacao_media", Value: bson.D{{Key: "$avg", Value: "$cotacao"}}},
+		}}},
+		{{Key: "$set", Value: bson.D{{Key: "dia", Value: "$_id.dia"}, {Key: "moeda", Value: "$_id.moeda"}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "dia", Value: 1}, {Key: "moeda", Value: 1}}}},
+	}
+
+	cursor, err := m.database.Collection(conversionHistory).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []domain.ConversionDailyAggregate{}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// periodFilter filtra field no intervalo [from, to)
+func periodFilter(field string, from, to time.Time) bson.D {
+	return bson.D{{Key: field, Value: bson.D{{Key: "$gte", Value: from}, {Key: "$lt", Value: to}}}}
+}
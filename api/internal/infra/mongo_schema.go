@@ -0,0 +1,223 @@
+package infra
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// schemaSteps são as migrations do banco da API. Novos passos entram sempre
+// no fim, com versão maior que a última; passos já publicados não mudam.
+var schemaSteps = []Migration{
+	{
+		Version:     1,
+		Description: "índices do histórico de conversões",
+		// Listagem por data (GET /convert/list) e busca por moeda e período (GET /v1/conversions)
+		Up: createIndexes(conversionHistory,
+			mongo.IndexModel{Keys: bson.D{{Key: "data", Value: -1}}, Options: options.Index().SetName("data_desc")},
+			mongo.IndexModel{Keys: bson.D{{Key: "currency", Value: 1}, {Key: "data", Value: -1}}, Options: options.Index().SetName("currency_data_desc")},
+		),
+		Down: dropIndexes(conversionHistory, "data_desc", "currency_data_desc"),
+	},
+	{
+		Version:     2,
+		Description: "índices das chaves de API e da quarentena de cotações",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// A autenticação busca a chave pelo hash a cada requisição
+			if err := createIndexes(apiKeys,
+				mongo.IndexModel{Keys: bson.D{{Key: "key_hash", Value: 1}}, Options: options.Index().SetName("key_hash_unique").SetUnique(true)},
+			)(ctx, db); err != nil {
+				return err
+			}
+			return createIndexes(rateQuarantine,
+				mongo.IndexModel{Keys: bson.D{{Key: "moeda", Value: 1}, {Key: "recebida_em", Value: -1}}, Options: options.Index().SetName("moeda_recebida_em_desc")},
+			)(ctx, db)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndexes(apiKeys, "key_hash_unique")(ctx, db); err != nil {
+				return err
+			}
+			return dropIndexes(rateQuarantine, "moeda_recebida_em_desc")(ctx, db)
+		},
+	},
+	{
+		Version:     3,
+		Description: "validação de esquema do histórico de conversões",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return setValidator(ctx, db, conversionHistory, conversionHistorySchema)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return setValidator(ctx, db, conversionHistory, bson.D{})
+		},
+	},
+	{
+		Version:     4,
+		Description: "fonte awesomeapi nas conversões anteriores ao campo fonte",
+		// Antes do campo fonte só existia a AwesomeAPI; a marca fonte_inferida
+		// permite desfazer sem apagar a fonte das conversões novas
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection(conversionHistory).UpdateMany(ctx,
+				bson.D{{Key: "fonte", Value: bson.D{{Key: "$exists", Value: false}}}},
+				bson.D{{Key: "$set", Value: bson.D{{Key: "fonte", Value: "awesomeapi"}, {Key: "fonte_inferida", Value: true}}}},
+			)
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection(conversionHistory).UpdateMany(ctx,
+				bson.D{{Key: "fonte_inferida", Value: true}},
+				bson.D{{Key: "$unset", Value: bson.D{{Key: "fonte", Value: ""}, {Key: "fonte_inferida", Value: ""}}}},
+			)
+			return err
+		},
+	},
+	{
+		Version:     5,
+		Description: "índice TTL das conversões arquivadas e índice dos resumos diários",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// expireAfterSeconds 0: cada conversão é apagada no instante gravado em expira_em
+			if err := createIndexes(conversionHistory,
+				mongo.IndexModel{Keys: bson.D{{Key: "expira_em", Value: 1}}, Options: options.Index().SetName("expira_em_ttl").SetExpireAfterSeconds(0)},
+			)(ctx, db); err != nil {
+				return err
+			}
+			return createIndexes(conversionDaily,
+				mongo.IndexModel{Keys: bson.D{{Key: "moeda", Value: 1}, {Key: "dia", Value: 1}}, Options: options.Index().SetName("moeda_dia")},
+			)(ctx, db)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndexes(conversionHistory, "expira_em_ttl")(ctx, db); err != nil {
+				return err
+			}
+			return dropIndexes(conversionDaily, "moeda_dia")(ctx, db)
+		},
+	},
+	{
+		Version:     6,
+		Description: "índices do outbox de eventos",
+		// O relay lê os pendentes (publicado_em nulo) em ordem de sequência; os
+		// publicados saem do banco depois de uma semana
+		Up: createIndexes(outboxEvents,
+			mongo.IndexModel{Keys: bson.D{{Key: "publicado_em", Value: 1}, {Key: "sequencia", Value: 1}}, Options: options.Index().SetName("pendentes")},
+			mongo.IndexModel{Keys: bson.D{{Key: "publicado_em", Value: 1}}, Options: options.Index().SetName("publicado_em_ttl").SetExpireAfterSeconds(7 * 24 * 60 * 60)},
+		),
+		Down: dropIndexes(outboxEvents, "pendentes", "publicado_em_ttl"),
+	},
+	{
+		Version:     7,
+		Description: "índices dos webhooks e da fila de entregas",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Cada evento busca os webhooks que assinam o tipo dele
+			if err := createIndexes(webhooks,
+				mongo.IndexModel{Keys: bson.D{{Key: "eventos", Value: 1}}, Options: options.Index().SetName("eventos")},
+				mongo.IndexModel{Keys: bson.D{{Key: "api_key_id", Value: 1}}, Options: options.Index().SetName("api_key_id")},
+			)(ctx, db); err != nil {
+				return err
+			}
+			// O envio reserva as pendentes vencidas; a listagem do admin filtra
+			// por situação ou webhook. As entregues saem do banco em 30 dias; as
+			// mortas ficam até serem reenviadas.
+			return createIndexes(webhookDeliveries,
+				mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "proxima_tentativa", Value: 1}}, Options: options.Index().SetName("fila")},
+				mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "criada_em", Value: -1}}, Options: options.Index().SetName("status_criada_em_desc")},
+				mongo.IndexModel{Keys: bson.D{{Key: "webhook_id", Value: 1}, {Key: "criada_em", Value: -1}}, Options: options.Index().SetName("webhook_criada_em_desc")},
+				mongo.IndexModel{Keys: bson.D{{Key: "entregue_em", Value: 1}}, Options: options.Index().SetName("entregue_em_ttl").SetExpireAfterSeconds(30 * 24 * 60 * 60)},
+			)(ctx, db)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndexes(webhooks, "eventos", "api_key_id")(ctx, db); err != nil {
+				return err
+			}
+			return dropIndexes(webhookDeliveries, "fila", "status_criada_em_desc", "webhook_criada_em_desc", "entregue_em_ttl")(ctx, db)
+		},
+	},
+	{
+		Version:     8,
+		Description: "índices das regras de alerta e do histórico de disparos",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Cada cotação nova busca as regras ativas da moeda
+			if err := createIndexes(alertRules,
+				mongo.IndexModel{Keys: bson.D{{Key: "moeda", Value: 1}, {Key: "ativa", Value: 1}}, Options: options.Index().SetName("moeda_ativa")},
+				mongo.IndexModel{Keys: bson.D{{Key: "api_key_id", Value: 1}}, Options: options.Index().SetName("api_key_id")},
+			)(ctx, db); err != nil {
+				return err
+			}
+			// O histórico é listado por chave ou por regra, do disparo mais novo
+			return createIndexes(alertTriggers,
+				mongo.IndexModel{Keys: bson.D{{Key: "api_key_id", Value: 1}, {Key: "disparado_em", Value: -1}}, Options: options.Index().SetName("api_key_disparado_em_desc")},
+				mongo.IndexModel{Keys: bson.D{{Key: "alerta_id", Value: 1}, {Key: "disparado_em", Value: -1}}, Options: options.Index().SetName("alerta_disparado_em_desc")},
+			)(ctx, db)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndexes(alertRules, "moeda_ativa", "api_key_id")(ctx, db); err != nil {
+				return err
+			}
+			return dropIndexes(alertTriggers, "api_key_disparado_em_desc", "alerta_disparado_em_desc")(ctx, db)
+		},
+	},
+	{
+		Version:     9,
+		Description: "índice único da chave das conversões importadas",
+		// A importação pula as conversões cuja chave natural já está no
+		// histórico; o índice segura as que passam juntas pela checagem. Ele
+		// é parcial: as conversões feitas pela API não têm a chave e podem
+		// repetir moeda, data, valor e cotação
+		Up: createIndexes(conversionHistory,
+			mongo.IndexModel{
+				Keys: bson.D{{Key: "chave_importacao", Value: 1}},
+				Options: options.Index().
+					SetName(importKeyIndex).
+					SetUnique(true).
+					SetPartialFilterExpression(bson.D{{Key: "chave_importacao", Value: bson.D{{Key: "$exists", Value: true}}}}),
+			},
+		),
+		Down: dropIndexes(conversionHistory, importKeyIndex),
+	},
+	{
+		Version:     10,
+		Description: "coleção time-series das cotações",
+		// Antes criada a cada conexão; em bancos onde ela já existe o passo não muda nada
+		Up: createRateSnapshots,
+		// A coleção guarda a série coletada e já existia antes deste passo: o
+		// rollback a mantém
+		Down: func(context.Context, *mongo.Database) error { return nil },
+	},
+}
+
+// Formato mínimo de um registro do histórico. Campos novos e opcionais não
+// precisam entrar aqui; os obrigatórios e seus tipos, sim.
+var conversionHistorySchema = bson.D{{Key: "$jsonSchema", Value: bson.D{
+	{Key: "bsonType", Value: "object"},
+	{Key: "required", Value: bson.A{"currency", "cotacao", "valor_entrada", "valor_convertido", "data"}},
+	{Key: "properties", Value: bson.D{
+		{Key: "currency", Value: bson.D{{Key: "bsonType", Value: "string"}}},
+		{Key: "cotacao", Value: bson.D{{Key: "bsonType", Value: bson.A{"double", "int", "long", "decimal"}}}},
+		{Key: "valor_entrada", Value: bson.D{{Key: "bsonType", Value: bson.A{"double", "int", "long", "decimal"}}}},
+		{Key: "valor_convertido", Value: bson.D{{Key: "bsonType", Value: bson.A{"double", "int", "long", "decimal"}}}},
+		{Key: "data", Value: bson.D{{Key: "bsonType", Value: "date"}}},
+		{Key: "api_key_id", Value: bson.D{{Key: "bsonType", Value: "string"}}},
+		{Key: "fonte", Value: bson.D{{Key: "bsonType", Value: "string"}}},
+		{Key: "retroativa", Value: bson.D{{Key: "bsonType", Value: "bool"}}},
+		{Key: "data_referencia", Value: bson.D{{Key: "bsonType", Value: "date"}}},
+		{Key: "data_cotacao", Value: bson.D{{Key: "bsonType", Value: "date"}}},
+	}},
+}}}
+
+// setValidator aplica o validador na coleção, criando-a se ainda não existir.
+// O nível moderate não barra atualizações de documentos antigos fora do formato.
+func setValidator(ctx context.Context, db *mongo.Database, collection string, validator bson.D) error {
+	err := db.CreateCollection(ctx, collection, options.CreateCollection().
+		SetValidator(validator).
+		SetValidationLevel("moderate").
+		SetValidationAction("error"))
+	if commandCode(err) != codeNamespaceExists {
+		return err
+	}
+	return db.RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: collection},
+		{Key: "validator", Value: validator},
+		{Key: "validationLevel", Value: "moderate"},
+		{Key: "validationAction", Value: "error"},
+	}).Err()
+}
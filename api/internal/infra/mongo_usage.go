@@ -0,0 +1,54 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const dailyUsage = "daily_usage"
+
+type dailyUsageDoc struct {
+	Count int `bson:"count"`
+}
+
+// IncrementDailyUsage implementa a interface domain.UsageCounter com um $inc atômico
+func (m *MongoDBAdapter) IncrementDailyUsage(clientID string, day time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{{Key: "_id", Value: clientID + ":" + day.Format("2006-01-02")}}
+	update := bson.D{
+		{Key: "$inc", Value: bson.D{{Key: "count", Value: 1}}},
+		{Key: "$setOnInsert", Value: bson.D{
+			{Key: "client_id", Value: clientID},
+			{Key: "day", Value: day},
+		}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var doc dailyUsageDoc
+	err := m.database.Collection(dailyUsage).FindOneAndUpdate(ctx, filter, update, opts).Decode(&doc)
+	if err != nil {
+		return 0, err
+	}
+	return doc.Count, nil
+}
+
+// DecrementDailyUsage implementa a interface domain.UsageCounter; o filtro em
+// count evita que um estorno repetido deixe o contador negativo
+func (m *MongoDBAdapter) DecrementDailyUsage(clientID string, day time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "_id", Value: clientID + ":" + day.Format("2006-01-02")},
+		{Key: "count", Value: bson.D{{Key: "$gt", Value: 0}}},
+	}
+	update := bson.D{{Key: "$inc", Value: bson.D{{Key: "count", Value: -1}}}}
+
+	_, err := m.database.Collection(dailyUsage).UpdateOne(ctx, filter, update)
+	return err
+}
@@ -0,0 +1,263 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-frete/api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	webhooks          = "webhooks"
+	webhookDeliveries = "webhook_deliveries"
+)
+
+// deliveryDocument é a entrega como fica no banco; o evento vai inteiro, com
+// os dados em texto JSON como no outbox
+type deliveryDocument struct {
+	ID               string     `bson:"_id"`
+	WebhookID        string     `bson:"webhook_id"`
+	EventoID         string     `bson:"evento_id"`
+	Tipo             string     `bson:"tipo"`
+	Chave            string     `bson:"chave"`
+	Sequencia        int64      `bson:"sequencia,omitempty"`
+	EventoCriadoEm   time.Time  `bson:"evento_criado_em"`
+	Dados            string     `bson:"dados"`
+	APIKeyID         string     `bson:"api_key_id,omitempty"`
+	Status           string     `bson:"status"`
+	Tentativas       int        `bson:"tentativas"`
+	ProximaTentativa time.Time  `bson:"proxima_tentativa"`
+	UltimoErro       string     `bson:"ultimo_erro,omitempty"`
+	UltimoStatusHTTP int        `bson:"ultimo_status_http,omitempty"`
+	CriadaEm         time.Time  `bson:"criada_em"`
+	EntregueEm       *time.Time `bson:"entregue_em,omitempty"`
+}
+
+func toDeliveryDocument(d domain.WebhookDelivery) deliveryDocument {
+	doc := deliveryDocument{
+		ID:               d.ID,
+		WebhookID:        d.WebhookID,
+		EventoID:         d.Evento.ID,
+		Tipo:             d.Evento.Tipo,
+		Chave:            d.Evento.Chave,
+		Sequencia:        d.Evento.Sequencia,
+		EventoCriadoEm:   d.Evento.CriadoEm,
+		Dados:            string(d.Evento.Dados),
+		APIKeyID:         d.Evento.APIKeyID,
+		Status:           d.Status,
+		Tentativas:       d.Tentativas,
+		ProximaTentativa: d.ProximaTentativa,
+		UltimoErro:       d.UltimoErro,
+		UltimoStatusHTTP: d.UltimoStatusHTTP,
+		CriadaEm:         d.CriadaEm,
+	}
+	if !d.EntregueEm.IsZero() {
+		doc.EntregueEm = &d.EntregueEm
+	}
+	return doc
+}
+
+func (d deliveryDocument) delivery() domain.WebhookDelivery {
+	out := domain.WebhookDelivery{
+		ID:        d.ID,
+		WebhookID: d.WebhookID,
+		Evento: domain.DomainEvent{
+			ID:        d.EventoID,
+			Tipo:      d.Tipo,
+			Chave:     d.Chave,
+			Sequencia: d.Sequencia,
+			CriadoEm:  d.EventoCriadoEm,
+			Dados:     []byte(d.Dados),
+			APIKeyID:  d.APIKeyID,
+		},
+		Status:           d.Status,
+		Tentativas:       d.Tentativas,
+		ProximaTentativa: d.ProximaTentativa,
+		UltimoErro:       d.UltimoErro,
+		UltimoStatusHTTP: d.UltimoStatusHTTP,
+		CriadaEm:         d.CriadaEm,
+	}
+	if d.EntregueEm != nil {
+		out.EntregueEm = *d.EntregueEm
+	}
+	return out
+}
+
+// SaveWebhook implementa a interface domain.WebhookRepository
+func (m *MongoDBAdapter) SaveWebhook(w domain.Webhook) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := m.database.Collection(webhooks).InsertOne(ctx, w)
+	return err
+}
+
+func (m *MongoDBAdapter) GetWebhook(id string) (*domain.Webhook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var w domain.Webhook
+	err := m.database.Collection(webhooks).FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&w)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (m *MongoDBAdapter) ListWebhooks(apiKeyID string) ([]domain.Webhook, error) {
+	filter := bson.D{}
+	if apiKeyID != "" {
+		filter = bson.D{{Key: "api_key_id", Value: apiKeyID}}
+	}
+	return m.findWebhooks(filter)
+}
+
+// WebhooksFor usa o índice multikey de eventos
+func (m *MongoDBAdapter) WebhooksFor(tipo string) ([]domain.Webhook, error) {
+	return m.findWebhooks(bson.D{{Key: "eventos", Value: tipo}})
+}
+
+func (m *MongoDBAdapter) findWebhooks(filter bson.D) ([]domain.Webhook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := m.database.Collection(webhooks).Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "criado_em", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.Webhook
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (m *MongoDBAdapter) DeleteWebhook(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := m.database.Collection(webhooks).DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// SaveDeliveries implementa a interface domain.WebhookDeliveryStore. A
+// inserção não ordenada segue depois de um id repetido, e as repetições não
+// contam como erro.
+func (m *MongoDBAdapter) SaveDeliveries(deliveries []domain.WebhookDelivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	docs := make([]any, len(deliveries))
+	for i, d := range deliveries {
+		docs[i] = toDeliveryDocument(d)
+	}
+	_, err := m.database.Collection(webhookDeliveries).InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	var bulk mongo.BulkWriteException
+	if errors.As(err, &bulk) && bulk.WriteConcernError == nil {
+		for _, we := range bulk.WriteErrors {
+			if we.Code != codeDuplicateKey {
+				return err
+			}
+		}
+		return nil
+	}
+	return err
+}
+
+// ClaimDeliveries reserva uma entrega por vez com FindOneAndUpdate: duas
+// instâncias nunca pegam a mesma, e a reserva vence sozinha no lease
+func (m *MongoDBAdapter) ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "status", Value: domain.DeliveryPending},
+		{Key: "proxima_tentativa", Value: bson.D{{Key: "$lte", Value: now}}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "proxima_tentativa", Value: now.Add(lease)}}}}
+	opts := options.FindOneAndUpdate().SetSort(bson.D{{Key: "proxima_tentativa", Value: 1}})
+
+	var claimed []domain.WebhookDelivery
+	for len(claimed) < limit {
+		var doc deliveryDocument
+		err := m.database.Collection(webhookDeliveries).FindOneAndUpdate(ctx, filter, update, opts).Decode(&doc)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+		if err != nil {
+			return claimed, err
+		}
+		claimed = append(claimed, doc.delivery())
+	}
+	return claimed, nil
+}
+
+func (m *MongoDBAdapter) UpdateDelivery(d domain.WebhookDelivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := m.database.Collection(webhookDeliveries).ReplaceOne(ctx, bson.D{{Key: "_id", Value: d.ID}}, toDeliveryDocument(d))
+	return err
+}
+
+func (m *MongoDBAdapter) GetDelivery(id string) (*domain.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var doc deliveryDocument
+	err := m.database.Collection(webhookDeliveries).FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	d := doc.delivery()
+	return &d, nil
+}
+
+func (m *MongoDBAdapter) ListDeliveries(filter domain.DeliveryFilter) ([]domain.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := bson.D{}
+	if filter.Status != "" {
+		query = append(query, bson.E{Key: "status", Value: filter.Status})
+	}
+	if filter.WebhookID != "" {
+		query = append(query, bson.E{Key: "webhook_id", Value: filter.WebhookID})
+	}
+	cursor, err := m.database.Collection(webhookDeliveries).Find(ctx, query,
+		options.Find().SetSort(bson.D{{Key: "criada_em", Value: -1}}).SetLimit(int64(filter.Limit)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []deliveryDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	out := make([]domain.WebhookDelivery, len(docs))
+	for i, d := range docs {
+		out[i] = d.delivery()
+	}
+	return out, nil
+}
@@ -0,0 +1,130 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go-frete/api/internal/domain"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATSEmbedded no lugar da URL sobe um servidor NATS com JetStream no próprio processo
+const NATSEmbedded = "embedded"
+
+// NATSConfig descreve onde publicar os eventos
+type NATSConfig struct {
+	// URL do servidor (nats://host:4222) ou NATSEmbedded
+	URL string
+	// Stream que guarda os eventos e prefixo dos assuntos (<prefixo>.<tipo>.<chave>)
+	Stream        string
+	SubjectPrefix string
+	// Só no servidor embutido: diretório do JetStream e porta para os outros
+	// serviços se conectarem (-1 escolhe uma porta livre)
+	Dir  string
+	Port int
+}
+
+// NATSSink publica os eventos no JetStream, um assunto por tipo e chave
+// (gofrete.conversion.created.USD). O id do evento vai como Nats-Msg-Id: uma
+// repetição do relay dentro da janela de duplicatas do stream é descartada
+// pelo servidor.
+type NATSSink struct {
+	conn     *nats.Conn
+	js       jetstream.JetStream
+	prefix   string
+	embedded *server.Server
+}
+
+func NewNATSSink(cfg NATSConfig) (*NATSSink, error) {
+	s := &NATSSink{prefix: cfg.SubjectPrefix}
+
+	url := cfg.URL
+	if url == NATSEmbedded {
+		ns, err := server.NewServer(&server.Options{
+			ServerName: "go-frete",
+			Port:       cfg.Port,
+			JetStream:  true,
+			StoreDir:   cfg.Dir,
+			NoSigs:     true,
+			NoLog:      true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		go ns.Start()
+		if !ns.ReadyForConnections(10 * time.Second) {
+			ns.Shutdown()
+			return nil, errors.New("servidor NATS embutido não ficou pronto")
+		}
+		s.embedded = ns
+		url = ns.ClientURL()
+	}
+
+	conn, err := nats.Connect(url, nats.Name("go-frete-outbox"), nats.MaxReconnects(-1))
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	s.conn = conn
+	if s.js, err = jetstream.New(conn); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = s.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     cfg.Stream,
+		Subjects: []string{cfg.SubjectPrefix + ".>"},
+		Storage:  jetstream.FileStorage,
+		// O mesmo prazo dos eventos publicados no outbox
+		MaxAge: 7 * 24 * time.Hour,
+	})
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *NATSSink) Name() string {
+	return "nats"
+}
+
+// URL é o endereço do servidor em uso, o embutido inclusive
+func (s *NATSSink) URL() string {
+	return s.conn.ConnectedUrl()
+}
+
+// Publish implementa domain.EventSink e só volta depois da confirmação do stream
+func (s *NATSSink) Publish(event domain.DomainEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	subject := s.prefix + "." + event.Tipo
+	if event.Chave != "" {
+		subject += "." + event.Chave
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = s.js.Publish(ctx, subject, payload, jetstream.WithMsgID(event.ID))
+	return err
+}
+
+// Close encerra a conexão e, se houver, o servidor embutido
+func (s *NATSSink) Close() {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	if s.embedded != nil {
+		s.embedded.Shutdown()
+		s.embedded.WaitForShutdown()
+	}
+}
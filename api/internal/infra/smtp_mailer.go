@@ -0,0 +1,77 @@
+package infra
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// SMTPMailer envia os e-mails de alerta por SMTP simples, sem autenticação,
+// como o do Mailpit no docker-compose. Usa STARTTLS quando o servidor oferece.
+type SMTPMailer struct {
+	addr    string
+	from    string
+	timeout time.Duration
+}
+
+func NewSMTPMailer(addr, from string, timeout time.Duration) *SMTPMailer {
+	return &SMTPMailer{addr: addr, from: from, timeout: timeout}
+}
+
+// SendAlert implementa a interface domain.AlertMailer
+func (m *SMTPMailer) SendAlert(to, subject, body string) error {
+	conn, err := net.DialTimeout("tcp", m.addr, m.timeout)
+	if err != nil {
+		return err
+	}
+	// O prazo vale para a conversa inteira: net/smtp não tem timeout próprio
+	conn.SetDeadline(time.Now().Add(m.timeout))
+
+	host, _, _ := net.SplitHostPort(m.addr)
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(m.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(m.message(to, subject, body)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (m *SMTPMailer) message(to, subject, body string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", m.from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.Write(bytes.ReplaceAll([]byte(body), []byte("\n"), []byte("\r\n")))
+	return b.Bytes()
+}
@@ -0,0 +1,112 @@
+package infra
+
+import (
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpMessage é o que o servidor de teste recebeu numa conversa
+type smtpMessage struct {
+	from, to, data string
+}
+
+// serveSMTP atende uma conversa SMTP mínima, como o Mailpit, e devolve a
+// mensagem recebida pelo canal
+func serveSMTP(t *testing.T, rcptReply string) (string, <-chan smtpMessage) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan smtpMessage, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		var msg smtpMessage
+
+		tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); cmd {
+			case "EHLO", "HELO":
+				tp.PrintfLine("250-localhost")
+				tp.PrintfLine("250 8BITMIME")
+			case "MAIL":
+				msg.from = line
+				tp.PrintfLine("250 OK")
+			case "RCPT":
+				msg.to = line
+				tp.PrintfLine("%s", rcptReply)
+			case "DATA":
+				tp.PrintfLine("354 Envie a mensagem")
+				data, _ := tp.ReadDotBytes()
+				msg.data = string(data)
+				tp.PrintfLine("250 OK")
+				received <- msg
+			case "QUIT":
+				tp.PrintfLine("221 Tchau")
+				return
+			default:
+				tp.PrintfLine("502 Comando desconhecido")
+			}
+		}
+	}()
+	return listener.Addr().String(), received
+}
+
+func TestSMTPMailer(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should send alert with encoded subject",
+			run:  shouldSendAlertWithEncodedSubject,
+		},
+		{
+			name: "should return error when recipient is refused",
+			run:  shouldReturnErrorWhenRecipientIsRefused,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldSendAlertWithEncodedSubject(t *testing.T) {
+	addr, received := serveSMTP(t, "250 OK")
+	mailer := NewSMTPMailer(addr, "alertas@go-frete.local", time.Second)
+
+	err := mailer.SendAlert("compras@exemplo.com", "Alerta de cotação: USD abaixo de 5.0000", "USD abaixo de 5.0000\n\nCotação: 4.9000\n")
+	require.NoError(t, err)
+
+	msg := <-received
+	assert.Equal(t, "MAIL FROM:<alertas@go-frete.local> BODY=8BITMIME", msg.from)
+	assert.Equal(t, "RCPT TO:<compras@exemplo.com>", msg.to)
+	assert.Contains(t, msg.data, "Subject: =?utf-8?q?Alerta_de_cota=C3=A7=C3=A3o:_USD_abaixo_de_5.0000?=\n")
+	assert.Contains(t, msg.data, "Content-Type: text/plain; charset=utf-8\n")
+	assert.True(t, strings.HasSuffix(msg.data, "Cotação: 4.9000\n"))
+}
+
+func shouldReturnErrorWhenRecipientIsRefused(t *testing.T) {
+	addr, _ := serveSMTP(t, "550 Caixa inexistente")
+	mailer := NewSMTPMailer(addr, "alertas@go-frete.local", time.Second)
+
+	err := mailer.SendAlert("ninguem@exemplo.com", "Alerta", "corpo")
+
+	assert.ErrorContains(t, err, "Caixa inexistente")
+}
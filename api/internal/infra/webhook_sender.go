@@ -0,0 +1,72 @@
+package infra
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"go-frete/api/internal/domain"
+)
+
+var ErrPrivateAddress = errors.New("endereço de rede interna não permitido para webhook")
+
+// HTTPWebhookSender faz os POSTs das entregas de webhook. Como as URLs vêm dos
+// clientes, por padrão recusa endereços de loopback, rede privada e link-local:
+// a checagem é feita no IP já resolvido, na hora da conexão, o que vale também
+// para nomes que apontam para a rede interna. Redirecionamentos não são seguidos.
+type HTTPWebhookSender struct {
+	client *http.Client
+}
+
+func NewHTTPWebhookSender(timeout time.Duration, allowPrivate bool) *HTTPWebhookSender {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := addr.Addr().Unmap(); !ip.IsGlobalUnicast() || ip.IsPrivate() {
+				return ErrPrivateAddress
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &HTTPWebhookSender{client: &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// Send implementa domain.WebhookSender
+func (s *HTTPWebhookSender) Send(req domain.WebhookRequest) (int, error) {
+	httpReq, err := http.NewRequest(http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, err
+	}
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	httpReq.Header.Set("User-Agent", "go-frete-webhooks")
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Esvazia o corpo para reaproveitar a conexão
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,281 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-frete/api/internal/domain"
+	"go-frete/api/tests/mocks/loggermock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookStoreFake faz o papel das coleções webhooks e webhook_deliveries
+type webhookStoreFake struct {
+	mu         sync.Mutex
+	hooks      []domain.Webhook
+	deliveries []domain.WebhookDelivery
+}
+
+func (f *webhookStoreFake) SaveWebhook(w domain.Webhook) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hooks = append(f.hooks, w)
+	return nil
+}
+
+func (f *webhookStoreFake) GetWebhook(id string) (*domain.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, w := range f.hooks {
+		if w.ID == id {
+			return &w, nil
+		}
+	}
+	return nil, domain.ErrWebhookNotFound
+}
+
+func (f *webhookStoreFake) ListWebhooks(string) ([]domain.Webhook, error) {
+	return f.hooks, nil
+}
+
+func (f *webhookStoreFake) DeleteWebhook(string) error {
+	return errors.New("não usado")
+}
+
+func (f *webhookStoreFake) WebhooksFor(tipo string) ([]domain.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.Webhook
+	for _, w := range f.hooks {
+		if slices.Contains(w.Eventos, tipo) {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+func (f *webhookStoreFake) SaveDeliveries(deliveries []domain.WebhookDelivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deliveries = append(f.deliveries, deliveries...)
+	return nil
+}
+
+func (f *webhookStoreFake) ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.WebhookDelivery
+	for i, d := range f.deliveries {
+		if d.Status == domain.DeliveryPending && !d.ProximaTentativa.After(now) && len(out) < limit {
+			out = append(out, d)
+			f.deliveries[i].ProximaTentativa = now.Add(lease)
+		}
+	}
+	return out, nil
+}
+
+func (f *webhookStoreFake) UpdateDelivery(d domain.WebhookDelivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.deliveries {
+		if f.deliveries[i].ID == d.ID {
+			f.deliveries[i] = d
+		}
+	}
+	return nil
+}
+
+func (f *webhookStoreFake) GetDelivery(id string) (*domain.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, d := range f.deliveries {
+		if d.ID == id {
+			return &d, nil
+		}
+	}
+	return nil, domain.ErrDeliveryNotFound
+}
+
+func (f *webhookStoreFake) ListDeliveries(filter domain.DeliveryFilter) ([]domain.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.WebhookDelivery
+	for _, d := range f.deliveries {
+		if filter.Status == "" || d.Status == filter.Status {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func newWebhookLogger() *loggermock.LoggerMock {
+	loggerMock := new(loggermock.LoggerMock)
+	for _, level := range []string{"Debug", "Info", "Warn", "Error"} {
+		loggerMock.On(level, mock.Anything, mock.Anything).Return()
+	}
+	return loggerMock
+}
+
+// signedReceiver confere cada entrega como um cliente faria e responde com os
+// status da fila, um por entrega
+type signedReceiver struct {
+	secret   string
+	statuses []int
+	calls    atomic.Int32
+	mu       sync.Mutex
+	ids      []string
+	events   []domain.DomainEvent
+	failures []error
+}
+
+func (rc *signedReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	n := int(rc.calls.Add(1)) - 1
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	err := domain.VerifyWebhookSignature(rc.secret, r.Header.Get(domain.WebhookTimestampHeader), r.Header.Get(domain.WebhookSignatureHeader), body, 5*time.Minute, time.Now())
+	if err != nil {
+		rc.failures = append(rc.failures, err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	rc.ids = append(rc.ids, r.Header.Get(domain.WebhookIDHeader))
+	var event domain.DomainEvent
+	json.Unmarshal(body, &event)
+	rc.events = append(rc.events, event)
+	w.WriteHeader(rc.statuses[min(n, len(rc.statuses)-1)])
+}
+
+func TestWebhookSender(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should deliver signed event after receiver recovers",
+			run:  shouldDeliverSignedEventAfterReceiverRecovers,
+		},
+		{
+			name: "should dead letter and replay to receiver",
+			run:  shouldDeadLetterAndReplayToReceiver,
+		},
+		{
+			name: "should refuse private addresses by default",
+			run:  shouldRefusePrivateAddressesByDefault,
+		},
+		{
+			name: "should not follow redirects",
+			run:  shouldNotFollowRedirects,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+// newWebhookE2E cadastra o receptor como webhook de conversion.created e
+// devolve o caso de uso real, com o sender HTTP liberado para o loopback
+func newWebhookE2E(t *testing.T, receiver *signedReceiver, cfg domain.WebhookConfig) (*domain.WebhookUseCase, *webhookStoreFake) {
+	t.Helper()
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	store := &webhookStoreFake{}
+	uc := domain.NewWebhookUseCase(store, store, NewHTTPWebhookSender(time.Second, true), cfg, newWebhookLogger())
+	hook, err := uc.Create(context.Background(), server.URL+"/hooks/frete", []string{domain.EventConversionCreated})
+	require.NoError(t, err)
+	receiver.secret = hook.Segredo
+
+	event, err := domain.NewConversionCreated(domain.ConversionRecord{MoedaDestino: "USD", Cotacao: 5, ValorEntrada: 100, ValorConvertido: 20})
+	require.NoError(t, err)
+	require.NoError(t, uc.Publish(event))
+	return uc, store
+}
+
+func shouldDeliverSignedEventAfterReceiverRecovers(t *testing.T) {
+	receiver := &signedReceiver{statuses: []int{http.StatusServiceUnavailable, http.StatusOK}}
+	// Backoff mínimo para a segunda tentativa vencer logo
+	uc, store := newWebhookE2E(t, receiver, domain.WebhookConfig{Backoff: time.Millisecond})
+
+	n, err := uc.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Equal(t, 503, store.deliveries[0].UltimoStatusHTTP)
+
+	time.Sleep(5 * time.Millisecond)
+	n, err = uc.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	assert.Empty(t, receiver.failures)
+	require.Len(t, receiver.ids, 2)
+	assert.Equal(t, receiver.ids[0], receiver.ids[1])
+	assert.Equal(t, domain.EventConversionCreated, receiver.events[1].Tipo)
+	assert.Equal(t, "USD", receiver.events[1].Chave)
+	assert.Equal(t, domain.DeliveryDelivered, store.deliveries[0].Status)
+	assert.Equal(t, 2, store.deliveries[0].Tentativas)
+}
+
+func shouldDeadLetterAndReplayToReceiver(t *testing.T) {
+	receiver := &signedReceiver{statuses: []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusNoContent}}
+	uc, store := newWebhookE2E(t, receiver, domain.WebhookConfig{MaxAttempts: 2, Backoff: time.Millisecond})
+
+	for range 2 {
+		_, err := uc.DispatchOnce(context.Background())
+		require.NoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+	}
+	dead, err := uc.Deliveries(context.Background(), domain.DeliveryFilter{Status: domain.DeliveryDead})
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	require.Equal(t, domain.DeliveryDead, dead[0].Status)
+
+	_, err = uc.Replay(context.Background(), dead[0].ID)
+	require.NoError(t, err)
+	n, err := uc.DispatchOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, n)
+	assert.Equal(t, int32(3), receiver.calls.Load())
+	assert.Empty(t, receiver.failures)
+	assert.Equal(t, domain.DeliveryDelivered, store.deliveries[0].Status)
+}
+
+func shouldRefusePrivateAddressesByDefault(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	_, err := NewHTTPWebhookSender(time.Second, false).Send(domain.WebhookRequest{URL: server.URL, Body: []byte(`{}`)})
+
+	assert.ErrorIs(t, err, ErrPrivateAddress)
+	assert.Zero(t, calls.Load())
+}
+
+func shouldNotFollowRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+	}))
+	defer server.Close()
+
+	status, err := NewHTTPWebhookSender(time.Second, true).Send(domain.WebhookRequest{URL: server.URL, Body: []byte(`{}`)})
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusFound, status)
+}
@@ -0,0 +1,46 @@
+// Package transport reúne as regras comuns ao REST e ao gRPC, para que os dois
+// tratem id de requisição e intervalos de espera da mesma forma
+package transport
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"time"
+)
+
+// MaxRequestIDLength é o tamanho máximo aceito para um id de requisição vindo do cliente
+const MaxRequestIDLength = 128
+
+// NewRequestID gera um id aleatório de 32 caracteres hexadecimais
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// Sem entropia disponível: ainda assim devolve algo único o suficiente
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+// ValidRequestID aceita apenas ids curtos e imprimíveis para não poluir os logs
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > MaxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// CeilSeconds formata o intervalo em segundos inteiros, arredondados para cima,
+// como esperam o Retry-After e o RateLimit-Reset
+func CeilSeconds(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,58 @@
+package transport
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransport(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should accept only short printable request ids",
+			run:  shouldAcceptOnlyShortPrintableRequestIDs,
+		},
+		{
+			name: "should generate valid unique request ids",
+			run:  shouldGenerateValidUniqueRequestIDs,
+		},
+		{
+			name: "should round seconds up",
+			run:  shouldRoundSecondsUp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldAcceptOnlyShortPrintableRequestIDs(t *testing.T) {
+	assert.True(t, ValidRequestID("req-123"))
+	assert.True(t, ValidRequestID(strings.Repeat("a", MaxRequestIDLength)))
+	assert.False(t, ValidRequestID(""))
+	assert.False(t, ValidRequestID(strings.Repeat("a", MaxRequestIDLength+1)))
+	assert.False(t, ValidRequestID("com espaço"))
+	assert.False(t, ValidRequestID("quebra\nde linha"))
+	assert.False(t, ValidRequestID("acentuação"))
+}
+
+func shouldGenerateValidUniqueRequestIDs(t *testing.T) {
+	a, b := NewRequestID(), NewRequestID()
+	assert.Len(t, a, 32)
+	assert.True(t, ValidRequestID(a))
+	assert.NotEqual(t, a, b)
+}
+
+func shouldRoundSecondsUp(t *testing.T) {
+	assert.Equal(t, "0", CeilSeconds(-time.Second))
+	assert.Equal(t, "0", CeilSeconds(0))
+	assert.Equal(t, "1", CeilSeconds(time.Millisecond))
+	assert.Equal(t, "1", CeilSeconds(time.Second))
+	assert.Equal(t, "2", CeilSeconds(1500*time.Millisecond))
+}
@@ -0,0 +1,26 @@
+package logger
+
+import "context"
+
+type fieldsKey struct{}
+
+// WithContext devolve um contexto que carrega campos que devem acompanhar
+// todas as linhas de log emitidas a partir dele (ex: request_id).
+func WithContext(ctx context.Context, keysAndValues ...any) context.Context {
+	prev, _ := ctx.Value(fieldsKey{}).([]any)
+
+	fields := make([]any, 0, len(prev)+len(keysAndValues))
+	fields = append(fields, prev...)
+	fields = append(fields, keysAndValues...)
+
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+// FromContext devolve um logger filho de l com os campos do contexto
+func FromContext(ctx context.Context, l Logger) Logger {
+	fields, _ := ctx.Value(fieldsKey{}).([]any)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
@@ -1,26 +1,99 @@
 package logger
 
-import "go.uber.org/zap"
+import (
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
 
 type Logger interface {
+	Debug(msg string, keysAndValues ...any)
 	Info(msg string, keysAndValues ...any)
 	Warn(msg string, keysAndValues ...any)
 	Error(msg string, keysAndValues ...any)
 	Fatal(msg string, keysAndValues ...any)
+	// With devolve um logger filho que inclui os campos em todas as linhas
+	With(keysAndValues ...any) Logger
+}
+
+// LevelController permite consultar e trocar o nível de log em tempo de execução
+type LevelController interface {
+	Level() string
+	SetLevel(level string) error
+}
+
+// Config define como o logger é construído
+type Config struct {
+	Level    string // debug, info, warn, error
+	Format   string // json ou console
+	Sampling bool
+	// Chaves cujos valores nunca devem aparecer nos logs (ex: api_key, senha)
+	RedactKeys []string
 }
 
 type zapAdapter struct {
 	sugar *zap.SugaredLogger
+	level zap.AtomicLevel
+}
+
+// New constrói o logger a partir da configuração informada
+func New(cfg Config) (Logger, error) {
+	return build(cfg, zapcore.Lock(os.Stderr))
 }
 
-func New() Logger {
-	z, _ := zap.NewProduction()
+// build monta o logger escrevendo em out. A ordem dos cores importa: a
+// redação embrulha a amostragem e pergunta a ela se a linha sai.
+func build(cfg Config, out zapcore.WriteSyncer, opts ...zap.Option) (Logger, error) {
+	level := zap.NewAtomicLevel()
+	if cfg.Level != "" {
+		if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+			return nil, err
+		}
+	}
+
+	encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	if strings.EqualFold(cfg.Format, "console") {
+		encoder = zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	}
+
+	core := zapcore.NewCore(encoder, out, level)
+	if cfg.Sampling {
+		// Por segundo e por mensagem: as 100 primeiras e depois uma a cada 100
+		core = zapcore.NewSamplerWithOptions(core, time.Second, 100, 100)
+	}
+	if len(cfg.RedactKeys) > 0 {
+		core = newRedactCore(core, cfg.RedactKeys)
+	}
+
+	// Pula o próprio adapter para que o "caller" aponte para quem chamou o log
+	opts = append([]zap.Option{
+		zap.AddCaller(),
+		zap.AddCallerSkip(1),
+		zap.AddStacktrace(zap.ErrorLevel),
+		zap.ErrorOutput(zapcore.Lock(os.Stderr)),
+	}, opts...)
 
 	return &zapAdapter{
-		sugar: z.Sugar(),
+		sugar: zap.New(core, opts...).Sugar(),
+		level: level,
+	}, nil
+}
+
+// NewNop devolve um logger que descarta tudo (útil em CLIs e testes)
+func NewNop() Logger {
+	return &zapAdapter{
+		sugar: zap.NewNop().Sugar(),
+		level: zap.NewAtomicLevel(),
 	}
 }
 
+func (l *zapAdapter) Debug(msg string, keysAndValues ...any) {
+	l.sugar.Debugw(msg, keysAndValues...)
+}
+
 func (l *zapAdapter) Info(msg string, keysAndValues ...any) {
 	l.sugar.Infow(msg, keysAndValues...)
 }
@@ -36,3 +109,19 @@ func (l *zapAdapter) Fatal(msg string, keysAndValues ...any) {
 func (l *zapAdapter) Warn(msg string, keysAndValues ...any) {
 	l.sugar.Warnw(msg, keysAndValues...)
 }
+
+func (l *zapAdapter) With(keysAndValues ...any) Logger {
+	// O filho compartilha o mesmo AtomicLevel, então trocar o nível afeta todos
+	return &zapAdapter{
+		sugar: l.sugar.With(keysAndValues...),
+		level: l.level,
+	}
+}
+
+func (l *zapAdapter) Level() string {
+	return l.level.Level().String()
+}
+
+func (l *zapAdapter) SetLevel(level string) error {
+	return l.level.UnmarshalText([]byte(level))
+}
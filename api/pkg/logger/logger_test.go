@@ -0,0 +1,175 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// fixedClock faz todas as linhas caírem no mesmo segundo da amostragem
+type fixedClock struct{}
+
+func (fixedClock) Now() time.Time                         { return time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC) }
+func (fixedClock) NewTicker(d time.Duration) *time.Ticker { return time.NewTicker(d) }
+
+func newTestLogger(t *testing.T, cfg Config) (Logger, *bytes.Buffer) {
+	t.Helper()
+	var out bytes.Buffer
+	l, err := build(cfg, zapcore.AddSync(&out), zap.WithClock(fixedClock{}))
+	require.NoError(t, err)
+	return l, &out
+}
+
+// lines decodifica cada linha JSON escrita pelo logger
+func lines(t *testing.T, out *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for _, raw := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if raw == "" {
+			continue
+		}
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(raw), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestLogger(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "should redact configured keys ignoring case",
+			run:  shouldRedactConfiguredKeysIgnoringCase,
+		},
+		{
+			name: "should redact fields of child loggers",
+			run:  shouldRedactFieldsOfChildLoggers,
+		},
+		{
+			name: "should carry context fields into child logger",
+			run:  shouldCarryContextFieldsIntoChildLogger,
+		},
+		{
+			name: "should filter by level and change it at runtime",
+			run:  shouldFilterByLevelAndChangeItAtRuntime,
+		},
+		{
+			name: "should reject unknown level",
+			run:  shouldRejectUnknownLevel,
+		},
+		{
+			name: "should sample repeated messages",
+			run:  shouldSampleRepeatedMessages,
+		},
+		{
+			name: "should sample repeated messages with redaction",
+			run:  shouldSampleRepeatedMessagesWithRedaction,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
+
+func shouldRedactConfiguredKeysIgnoringCase(t *testing.T) {
+	l, out := newTestLogger(t, Config{RedactKeys: []string{" API_KEY ", "senha"}})
+
+	l.Info("Chave criada", "api_key", "fk_live_123", "Senha", "segredo", "plano", "pro")
+
+	entries := lines(t, out)
+	require.Len(t, entries, 1)
+	assert.Equal(t, redactedValue, entries[0]["api_key"])
+	assert.Equal(t, redactedValue, entries[0]["Senha"])
+	assert.Equal(t, "pro", entries[0]["plano"])
+	assert.Equal(t, "Chave criada", entries[0]["msg"])
+}
+
+func shouldRedactFieldsOfChildLoggers(t *testing.T) {
+	l, out := newTestLogger(t, Config{RedactKeys: []string{"api_key"}})
+
+	child := l.With("api_key", "fk_live_123", "request_id", "abc")
+	child.Info("Requisição recebida")
+	l.Info("Sem campos do filho")
+
+	entries := lines(t, out)
+	require.Len(t, entries, 2)
+	assert.Equal(t, redactedValue, entries[0]["api_key"])
+	assert.Equal(t, "abc", entries[0]["request_id"])
+	assert.NotContains(t, entries[1], "request_id")
+}
+
+func shouldCarryContextFieldsIntoChildLogger(t *testing.T) {
+	l, out := newTestLogger(t, Config{})
+
+	ctx := WithContext(context.Background(), "request_id", "abc")
+	ctx = WithContext(ctx, "moeda", "USD")
+	FromContext(ctx, l).Info("Conversão feita")
+	assert.Same(t, l, FromContext(context.Background(), l))
+
+	entries := lines(t, out)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "abc", entries[0]["request_id"])
+	assert.Equal(t, "USD", entries[0]["moeda"])
+}
+
+func shouldFilterByLevelAndChangeItAtRuntime(t *testing.T) {
+	l, out := newTestLogger(t, Config{Level: "warn", RedactKeys: []string{"api_key"}})
+	child := l.With("request_id", "abc")
+
+	child.Info("Descartada")
+	child.Warn("Mantida")
+	require.NoError(t, l.(LevelController).SetLevel("debug"))
+	// O filho compartilha o nível do pai
+	child.Debug("Mantida depois da troca")
+
+	entries := lines(t, out)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "warn", entries[0]["level"])
+	assert.Equal(t, "debug", entries[1]["level"])
+	assert.Equal(t, "debug", l.(LevelController).Level())
+}
+
+func shouldRejectUnknownLevel(t *testing.T) {
+	_, err := New(Config{Level: "verbose"})
+	assert.Error(t, err)
+
+	l, _ := newTestLogger(t, Config{})
+	assert.Error(t, l.(LevelController).SetLevel("verbose"))
+	assert.Equal(t, "info", l.(LevelController).Level())
+}
+
+func shouldSampleRepeatedMessages(t *testing.T) {
+	l, out := newTestLogger(t, Config{Sampling: true})
+
+	for range 250 {
+		l.Info("Cotação consultada")
+	}
+	l.Info("Outra mensagem")
+
+	// As 100 primeiras, a 200ª e a mensagem diferente
+	assert.Len(t, lines(t, out), 102)
+}
+
+func shouldSampleRepeatedMessagesWithRedaction(t *testing.T) {
+	l, out := newTestLogger(t, Config{Sampling: true, RedactKeys: []string{"api_key"}})
+
+	for range 250 {
+		l.Info("Cotação consultada", "api_key", "fk_live_123")
+	}
+
+	entries := lines(t, out)
+	assert.Len(t, entries, 101)
+	assert.Equal(t, redactedValue, entries[100]["api_key"])
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const redactedValue = "[REDACTED]"
+
+// redactCore troca o valor de campos sensíveis antes de chegarem ao encoder
+type redactCore struct {
+	zapcore.Core
+	keys map[string]struct{}
+}
+
+func newRedactCore(c zapcore.Core, keys []string) zapcore.Core {
+	set := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		set[strings.ToLower(strings.TrimSpace(k))] = struct{}{}
+	}
+	return &redactCore{Core: c, keys: set}
+}
+
+func (c *redactCore) redact(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		if _, ok := c.keys[strings.ToLower(f.Key)]; ok {
+			out[i] = zap.String(f.Key, redactedValue)
+			continue
+		}
+		out[i] = f
+	}
+	return out
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redact(fields)), keys: c.keys}
+}
+
+// Check pergunta ao core embrulhado (o nível e a amostragem) se a linha sai;
+// se sair, quem escreve é a redação
+func (c *redactCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Core.Check(e, nil) != nil {
+		return ce.AddCore(e, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(e zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(e, c.redact(fields))
+}
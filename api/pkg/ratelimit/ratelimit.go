@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Result descreve a decisão de um Take e alimenta os cabeçalhos RateLimit-*
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Quanto esperar até haver um token disponível (zero quando Allowed)
+	RetryAfter time.Duration
+	// Quanto falta para o bucket encher de novo
+	Reset time.Duration
+}
+
+// Bucket é um token bucket seguro para uso concorrente
+type Bucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens por segundo
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewBucket cria um bucket cheio que repõe ratePerSecond tokens por segundo até burst
+func NewBucket(ratePerSecond float64, burst int) *Bucket {
+	return &Bucket{rate: ratePerSecond, burst: float64(burst), tokens: float64(burst)}
+}
+
+// Take tenta consumir um token no instante informado
+func (b *Bucket) Take(now time.Time) Result {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+
+	res := Result{Limit: int(b.burst)}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else if b.rate > 0 {
+		res.RetryAfter = seconds((1 - b.tokens) / b.rate)
+	}
+
+	res.Remaining = int(math.Floor(b.tokens))
+	if b.rate > 0 {
+		res.Reset = seconds((b.burst - b.tokens) / b.rate)
+	}
+	return res
+}
+
+func (b *Bucket) refill(now time.Time) {
+	if !b.last.IsZero() {
+		elapsed := now.Sub(b.last).Seconds()
+		if elapsed > 0 {
+			b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+		}
+	}
+	b.last = now
+}
+
+func (b *Bucket) idleSince(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return now.Sub(b.last)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Intervalo entre varreduras de buckets ociosos
+const sweepEvery = time.Minute
+
+// Limiter mantém um bucket por chave (id da chave de API, IP...)
+type Limiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*Bucket
+	idleTTL   time.Duration
+	lastSweep time.Time
+}
+
+// NewLimiter descarta buckets sem uso há mais de idleTTL para não crescer sem limite
+func NewLimiter(idleTTL time.Duration) *Limiter {
+	return &Limiter{buckets: make(map[string]*Bucket), idleTTL: idleTTL}
+}
+
+// Take consome um token do bucket da chave, criando-o com os parâmetros informados
+func (l *Limiter) Take(key string, ratePerSecond float64, burst int, now time.Time) Result {
+	l.mu.Lock()
+	if now.Sub(l.lastSweep) > sweepEvery {
+		l.sweep(now)
+	}
+	b, ok := l.buckets[key]
+	if !ok || b.rate != ratePerSecond || b.burst != float64(burst) {
+		// Parâmetros mudaram (ex: chave trocou de plano): recomeça o bucket
+		b = NewBucket(ratePerSecond, burst)
+		l.buckets[key] = b
+	}
+	l.mu.Unlock()
+
+	return b.Take(now)
+}
+
+func (l *Limiter) sweep(now time.Time) {
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.idleSince(now) > l.idleTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
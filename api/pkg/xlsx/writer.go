@@ -0,0 +1,236 @@
+// Package xlsx grava planilhas XLSX linha a linha, sem montar o arquivo em
+// memória: cada linha vai direto para o zip de saída.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxRows é o limite de linhas de uma aba no Excel. Ao atingi-lo, o Writer
+// abre uma nova aba e repete o cabeçalho.
+const MaxRows = 1_048_576
+
+var ErrClosed = errors.New("planilha já fechada")
+
+// Estilos definidos em styles.xml
+const (
+	styleDefault = 0
+	styleDate    = 1
+	styleHeader  = 2
+)
+
+// Writer grava uma pasta de trabalho com uma ou mais abas de mesmo formato
+type Writer struct {
+	zip    *zip.Writer
+	sheet  *bufio.Writer
+	name   string
+	header []string
+	sheets int
+	rows   int
+	closed bool
+}
+
+// NewWriter começa a pasta de trabalho; as abas se chamam name, name 2, name 3...
+func NewWriter(w io.Writer, name string) *Writer {
+	return &Writer{zip: zip.NewWriter(w), name: name}
+}
+
+// WriteHeader grava a linha de cabeçalho em negrito, repetida em cada aba
+func (w *Writer) WriteHeader(columns ...string) error {
+	w.header = columns
+	cells := make([]any, len(columns))
+	for i, c := range columns {
+		cells[i] = c
+	}
+	return w.writeRow(cells, styleHeader)
+}
+
+// WriteRow grava uma linha. Aceita string, números, bool e time.Time (data e
+// hora em UTC); nil e time.Time zero deixam a célula vazia.
+func (w *Writer) WriteRow(cells ...any) error {
+	return w.writeRow(cells, styleDefault)
+}
+
+func (w *Writer) writeRow(cells []any, style int) error {
+	if w.closed {
+		return ErrClosed
+	}
+	if w.sheet == nil || w.rows == MaxRows {
+		if err := w.nextSheet(); err != nil {
+			return err
+		}
+	}
+
+	w.rows++
+	fmt.Fprintf(w.sheet, `<row r="%d">`, w.rows)
+	for i, cell := range cells {
+		ref := columnName(i) + strconv.Itoa(w.rows)
+		switch v := cell.(type) {
+		case nil:
+		case string:
+			fmt.Fprintf(w.sheet, `<c r="%s" t="inlineStr"%s><is><t xml:space="preserve">`, ref, styleAttr(style))
+			xml.EscapeText(w.sheet, []byte(v))
+			w.sheet.WriteString(`</t></is></c>`)
+		case bool:
+			b := 0
+			if v {
+				b = 1
+			}
+			fmt.Fprintf(w.sheet, `<c r="%s" t="b"><v>%d</v></c>`, ref, b)
+		case time.Time:
+			if !v.IsZero() {
+				fmt.Fprintf(w.sheet, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr(styleDate), formatNumber(serialDate(v)))
+			}
+		case float64:
+			fmt.Fprintf(w.sheet, `<c r="%s"><v>%s</v></c>`, ref, formatNumber(v))
+		case int:
+			fmt.Fprintf(w.sheet, `<c r="%s"><v>%d</v></c>`, ref, v)
+		default:
+			return fmt.Errorf("tipo de célula não suportado: %T", cell)
+		}
+	}
+	_, err := w.sheet.WriteString(`</row>`)
+	return err
+}
+
+// nextSheet fecha a aba atual e abre a próxima, repetindo o cabeçalho
+func (w *Writer) nextSheet() error {
+	if err := w.closeSheet(); err != nil {
+		return err
+	}
+	w.sheets++
+	f, err := w.zip.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", w.sheets))
+	if err != nil {
+		return err
+	}
+	w.sheet = bufio.NewWriter(f)
+	w.rows = 0
+	w.sheet.WriteString(xml.Header + `<worksheet xmlns="` + nsMain + `"><sheetData>`)
+
+	if w.sheets > 1 && len(w.header) > 0 {
+		return w.WriteHeader(w.header...)
+	}
+	return nil
+}
+
+func (w *Writer) closeSheet() error {
+	if w.sheet == nil {
+		return nil
+	}
+	w.sheet.WriteString(`</sheetData></worksheet>`)
+	err := w.sheet.Flush()
+	w.sheet = nil
+	return err
+}
+
+// Close fecha a última aba e grava as partes que descrevem a pasta de trabalho
+func (w *Writer) Close() error {
+	if w.closed {
+		return ErrClosed
+	}
+	// Uma planilha precisa de pelo menos uma aba, mesmo vazia
+	if w.sheets == 0 {
+		if err := w.nextSheet(); err != nil {
+			return err
+		}
+	}
+	if err := w.closeSheet(); err != nil {
+		return err
+	}
+	w.closed = true
+
+	var types, sheets, rels strings.Builder
+	for i := 1; i <= w.sheets; i++ {
+		name := w.name
+		if i > 1 {
+			name = fmt.Sprintf("%s %d", w.name, i)
+		}
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeAttr(name), i, i)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="`+nsRel+`/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="`+nsRel+`/styles" Target="styles.xml"/>`, w.sheets+1)
+
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			types.String() + `</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="` + nsPackageRels + `">` +
+			`<Relationship Id="rId1" Type="` + nsRel + `/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", `<workbook xmlns="` + nsMain + `" xmlns:r="` + nsRel + `"><sheets>` + sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="` + nsPackageRels + `">` + rels.String() + `</Relationships>`},
+		{"xl/styles.xml", stylesXML},
+	}
+	for _, p := range parts {
+		f, err := w.zip.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, xml.Header+p.body); err != nil {
+			return err
+		}
+	}
+	return w.zip.Close()
+}
+
+const (
+	nsMain        = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	nsRel         = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	nsPackageRels = "http://schemas.openxmlformats.org/package/2006/relationships"
+)
+
+// Estilo 0 padrão, 1 data e hora, 2 cabeçalho em negrito
+const stylesXML = `<styleSheet xmlns="` + nsMain + `">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`</cellXfs></styleSheet>`
+
+func styleAttr(style int) string {
+	if style == styleDefault {
+		return ""
+	}
+	return fmt.Sprintf(` s="%d"`, style)
+}
+
+// columnName converte o índice (0 = A) na letra da coluna: A..Z, AA..AZ...
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// serialDate converte para o número de dias desde 30/12/1899 usado pelo Excel
+func serialDate(t time.Time) float64 {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return t.UTC().Sub(epoch).Hours() / 24
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func escapeAttr(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -1,6 +1,8 @@
 package loggermock
 
 import (
+	"go-frete/api/pkg/logger"
+
 	"github.com/stretchr/testify/mock"
 )
 
@@ -8,6 +10,10 @@ type LoggerMock struct {
 	mock.Mock
 }
 
+func (l *LoggerMock) Debug(msg string, keysAndValues ...any) {
+	l.Called(msg, keysAndValues)
+}
+
 func (l *LoggerMock) Info(msg string, keysAndValues ...any) {
 	l.Called(msg, keysAndValues)
 }
@@ -23,3 +29,44 @@ func (l *LoggerMock) Error(msg string, keysAndValues ...any) {
 func (l *LoggerMock) Fatal(msg string, keysAndValues ...any) {
 	l.Called(msg, keysAndValues)
 }
+
+// With não registra chamada: devolve um filho que prefixa os campos e
+// repassa tudo para este mock, assim as expectativas continuam valendo
+func (l *LoggerMock) With(keysAndValues ...any) logger.Logger {
+	return &childLogger{parent: l, fields: keysAndValues}
+}
+
+type childLogger struct {
+	parent *LoggerMock
+	fields []any
+}
+
+func (c *childLogger) merge(keysAndValues []any) []any {
+	merged := make([]any, 0, len(c.fields)+len(keysAndValues))
+	merged = append(merged, c.fields...)
+	return append(merged, keysAndValues...)
+}
+
+func (c *childLogger) Debug(msg string, keysAndValues ...any) {
+	c.parent.Debug(msg, c.merge(keysAndValues)...)
+}
+
+func (c *childLogger) Info(msg string, keysAndValues ...any) {
+	c.parent.Info(msg, c.merge(keysAndValues)...)
+}
+
+func (c *childLogger) Warn(msg string, keysAndValues ...any) {
+	c.parent.Warn(msg, c.merge(keysAndValues)...)
+}
+
+func (c *childLogger) Error(msg string, keysAndValues ...any) {
+	c.parent.Error(msg, c.merge(keysAndValues)...)
+}
+
+func (c *childLogger) Fatal(msg string, keysAndValues ...any) {
+	c.parent.Fatal(msg, c.merge(keysAndValues)...)
+}
+
+func (c *childLogger) With(keysAndValues ...any) logger.Logger {
+	return &childLogger{parent: c.parent, fields: c.merge(keysAndValues)}
+}